@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -14,6 +15,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/processcreds"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -42,6 +44,16 @@ type CredentialConfig struct {
 	Profile   string
 	Filename  string
 	Token     string
+	// CredentialProcess is an external command printing the credentials, see
+	// https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-sourcing-external.html
+	CredentialProcess string
+	// WebIdentityTokenFile is the path of an OIDC token exchanged for the credentials of
+	// WebIdentityRoleARN through sts:AssumeRoleWithWebIdentity.
+	WebIdentityTokenFile string
+	WebIdentityRoleARN   string
+	// ExternalID and SessionTags are passed to sts:AssumeRole when RoleARN is set.
+	ExternalID  string
+	SessionTags map[string]string
 }
 
 type stsCredentialProvider struct {
@@ -142,10 +154,29 @@ func (c *CredentialConfig) assumeCredentials() client.ConfigProvider {
 		LogLevel:   SDKLogLevel(),
 		Logger:     SDKLogger{},
 	}
-	config.Credentials = newStsCredentials(rootCredentials, c.RoleARN, c.Region)
+	config.Credentials = newStsCredentials(rootCredentials, c.RoleARN, c.Region, c.assumeRoleOptions)
 	return getSession(config)
 }
 
+// assumeRoleOptions sets the external ID and the session tags of the assumed role.
+func (c *CredentialConfig) assumeRoleOptions(p *stscreds.AssumeRoleProvider) {
+	if c.ExternalID != "" {
+		p.ExternalID = aws.String(c.ExternalID)
+	}
+	if len(c.SessionTags) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(c.SessionTags))
+	for k := range c.SessionTags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	p.Tags = make([]*sts.Tag, 0, len(keys))
+	for _, k := range keys {
+		p.Tags = append(p.Tags, &sts.Tag{Key: aws.String(k), Value: aws.String(c.SessionTags[k])})
+	}
+}
+
 func (c *CredentialConfig) Credentials() client.ConfigProvider {
 	if c.RoleARN != "" {
 		return c.assumeCredentials()
@@ -172,7 +203,7 @@ func (s *stsCredentialProvider) Retrieve() (credentials.Value, error) {
 	return v, err
 }
 
-func newStsCredentials(c client.ConfigProvider, roleARN string, region string) *credentials.Credentials {
+func newStsCredentials(c client.ConfigProvider, roleARN string, region string, options ...func(*stscreds.AssumeRoleProvider)) *credentials.Credentials {
 	regional := &stscreds.AssumeRoleProvider{
 		Client: sts.New(c, &aws.Config{
 			Region:              aws.String(region),
@@ -200,9 +231,32 @@ func newStsCredentials(c client.ConfigProvider, roleARN string, region string) *
 		Duration: stscreds.DefaultDuration,
 	}
 
+	for _, option := range options {
+		option(regional)
+		option(partitional)
+	}
+
 	return credentials.NewCredentials(&stsCredentialProvider{regional: regional, partitional: partitional})
 }
 
+// newWebIdentityCredentials exchanges the token read from tokenFile for the credentials of roleARN.
+// sts:AssumeRoleWithWebIdentity is not signed so the session does not need any credentials.
+func newWebIdentityCredentials(tokenFile, roleARN, region string) *credentials.Credentials {
+	ses, err := session.NewSession(&aws.Config{
+		Region:              aws.String(region),
+		Credentials:         credentials.AnonymousCredentials,
+		STSRegionalEndpoint: endpoints.RegionalSTSEndpoint,
+		HTTPClient:          &http.Client{Timeout: 1 * time.Minute},
+		LogLevel:            SDKLogLevel(),
+		Logger:              SDKLogger{},
+	})
+	if err != nil {
+		log.Printf("E! Failed to create web identity session, error was '%s' \n", err)
+		return nil
+	}
+	return credentials.NewCredentials(stscreds.NewWebIdentityRoleProviderWithOptions(sts.New(ses), roleARN, "", stscreds.FetchTokenPath(tokenFile)))
+}
+
 // The partitional STS endpoint used to fallback when regional STS endpoint is not activated.
 func getFallbackEndpoint(region string) string {
 	partition := getPartition(region)
@@ -250,6 +304,30 @@ func init() {
 			return nil
 		},
 	}
+	webIdentityCredentialsProvider := RootCredentialsProvider{
+		Name: func() string {
+			return "WebIdentityCredentialsProvider"
+		},
+		Credentials: func(c *CredentialConfig) *credentials.Credentials {
+			if c.WebIdentityTokenFile != "" && c.WebIdentityRoleARN != "" {
+				log.Printf("I! will use web identity credentials provider with token file %s", c.WebIdentityTokenFile)
+				return newWebIdentityCredentials(c.WebIdentityTokenFile, c.WebIdentityRoleARN, c.Region)
+			}
+			return nil
+		},
+	}
+	processCredentialsProvider := RootCredentialsProvider{
+		Name: func() string {
+			return "ProcessCredentialsProvider"
+		},
+		Credentials: func(c *CredentialConfig) *credentials.Credentials {
+			if c.CredentialProcess != "" {
+				log.Printf("I! will use process credentials provider")
+				return processcreds.NewCredentials(c.CredentialProcess)
+			}
+			return nil
+		},
+	}
 	refreshableCredentialsProvider := RootCredentialsProvider{
 		Name: func() string {
 			return "RefreshableCredentialsProvider"
@@ -268,7 +346,7 @@ func init() {
 			return nil
 		},
 	}
-	credentialsChain = append(credentialsChain, staticCredentialsProvider, refreshableCredentialsProvider)
+	credentialsChain = append(credentialsChain, staticCredentialsProvider, webIdentityCredentialsProvider, processCredentialsProvider, refreshableCredentialsProvider)
 
 	//You can overwrite the default credentials chain by first importing the current file
 	//and then calling OverwriteCredentialsChain() with your own credentials chain
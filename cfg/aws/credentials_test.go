@@ -0,0 +1,72 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package aws
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/processcreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootCredentialsChain(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the credential process is quoted for a posix shell")
+	}
+	assert.Nil(t, getRootCredentialsFromChain(&CredentialConfig{}))
+
+	creds := getRootCredentialsFromChain(&CredentialConfig{
+		CredentialProcess: `echo '{"Version": 1, "AccessKeyId": "process_access", "SecretAccessKey": "process_secret"}'`,
+		Profile:           "ignored",
+	})
+	require.NotNil(t, creds)
+	value, err := creds.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "process_access", value.AccessKeyID)
+	assert.Equal(t, processcreds.ProviderName, value.ProviderName)
+
+	// static keys take precedence over the other sources
+	creds = getRootCredentialsFromChain(&CredentialConfig{
+		AccessKey:         "static_access",
+		SecretKey:         "static_secret",
+		CredentialProcess: "exit 1",
+	})
+	require.NotNil(t, creds)
+	value, err = creds.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "static_access", value.AccessKeyID)
+
+	// the web identity token file is only used with its role
+	assert.Nil(t, getRootCredentialsFromChain(&CredentialConfig{WebIdentityTokenFile: "/var/run/token"}))
+	assert.NotNil(t, getRootCredentialsFromChain(&CredentialConfig{
+		Region:               "us-west-2",
+		WebIdentityTokenFile: "/var/run/token",
+		WebIdentityRoleARN:   "arn:aws:iam::123456789012:role/web",
+	}))
+}
+
+func TestAssumeRoleOptions(t *testing.T) {
+	c := &CredentialConfig{
+		RoleARN:     "arn:aws:iam::123456789012:role/agent",
+		ExternalID:  "external",
+		SessionTags: map[string]string{"team": "platform", "env": "prod"},
+	}
+	p := &stscreds.AssumeRoleProvider{}
+	c.assumeRoleOptions(p)
+	assert.Equal(t, aws.String("external"), p.ExternalID)
+	assert.Equal(t, []*sts.Tag{
+		{Key: aws.String("env"), Value: aws.String("prod")},
+		{Key: aws.String("team"), Value: aws.String("platform")},
+	}, p.Tags)
+
+	p = &stscreds.AssumeRoleProvider{}
+	(&CredentialConfig{RoleARN: c.RoleARN}).assumeRoleOptions(p)
+	assert.Nil(t, p.ExternalID)
+	assert.Nil(t, p.Tags)
+}
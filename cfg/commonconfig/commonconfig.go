@@ -14,6 +14,12 @@ const (
 	CredentialSection = "credentials"
 	CredentialProfile = "shared_credential_profile"
 	CredentialFile    = "shared_credential_file"
+	CredentialProcess = "credential_process"
+	WebIdentityToken  = "web_identity_token_file"
+	WebIdentityRole   = "web_identity_role_arn"
+	RoleARN           = "role_arn"
+	ExternalID        = "external_id"
+	SessionTags       = "session_tags"
 	ProxySection      = "proxy"
 	HttpProxy         = "http_proxy"
 	HttpsProxy        = "https_proxy"
@@ -23,15 +29,23 @@ const (
 )
 
 type CommonConfig struct {
-	Credentials *Credentials
-	Proxy       *Proxy
-	SSL         *SSL
-	IMDS        *IMDS
+	Credentials  *Credentials
+	Proxy        *Proxy
+	SSL          *SSL
+	IMDS         *IMDS
+	ConfigSource *ConfigSource `toml:"config_source"`
 }
 
 type Credentials struct {
 	CredentialProfile *string `toml:"shared_credential_profile"`
 	CredentialFile    *string `toml:"shared_credential_file"`
+	CredentialProcess *string `toml:"credential_process"`
+	WebIdentityToken  *string `toml:"web_identity_token_file"`
+	WebIdentityRole   *string `toml:"web_identity_role_arn"`
+	// The role assumed to fetch the configurations from the config sources
+	RoleARN     *string           `toml:"role_arn"`
+	ExternalID  *string           `toml:"external_id"`
+	SessionTags map[string]string `toml:"session_tags"`
 }
 
 type Proxy struct {
@@ -49,6 +63,12 @@ type IMDS struct {
 	ImdsRetries *int `toml:"imds_retries"`
 }
 
+// ConfigSource lists the remote locations polled by the agent for configuration changes
+type ConfigSource struct {
+	Locations    []string `toml:"locations"`
+	PollInterval *string  `toml:"poll_interval"`
+}
+
 func New() *CommonConfig {
 	return &CommonConfig{}
 }
@@ -85,6 +105,18 @@ func (c CommonConfig) CredentialsMap() map[string]string {
 		result[CredentialFile] = *c.Credentials.CredentialFile
 	}
 
+	if c.Credentials.CredentialProcess != nil {
+		result[CredentialProcess] = *c.Credentials.CredentialProcess
+	}
+
+	if c.Credentials.WebIdentityToken != nil {
+		result[WebIdentityToken] = *c.Credentials.WebIdentityToken
+	}
+
+	if c.Credentials.WebIdentityRole != nil {
+		result[WebIdentityRole] = *c.Credentials.WebIdentityRole
+	}
+
 	return result
 }
 
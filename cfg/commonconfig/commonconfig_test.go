@@ -31,12 +31,30 @@ func TestCredentialOnly(t *testing.T) {
 	config.Parse(strings.NewReader(contents))
 	assert.Equal(t, "{profile_name}", *config.Credentials.CredentialProfile)
 	assert.Equal(t, "{file_name}", *config.Credentials.CredentialFile)
+	assert.Equal(t, "{role_arn}", *config.Credentials.RoleARN)
+	assert.NotContains(t, config.CredentialsMap(), RoleARN, "the role is only assumed to fetch the configurations")
 	assert.Nil(t, config.Proxy.HttpProxy)
 	assert.Nil(t, config.Proxy.HttpsProxy)
 	assert.Nil(t, config.Proxy.NoProxy)
 	assert.Nil(t, config.SSL)
 }
 
+func TestCredentialSources(t *testing.T) {
+	contents := `
+				[credentials]
+					credential_process = "{command}"
+					web_identity_token_file = "{token_file_path}"
+					web_identity_role_arn = "{role_arn}"
+				`
+	config := New()
+	config.Parse(strings.NewReader(contents))
+	assert.Equal(t, map[string]string{
+		CredentialProcess: "{command}",
+		WebIdentityToken:  "{token_file_path}",
+		WebIdentityRole:   "{role_arn}",
+	}, config.CredentialsMap())
+}
+
 func TestConfig(t *testing.T) {
 	contents := `
 				[credentials]
@@ -63,3 +81,15 @@ func TestSSLOnly(t *testing.T) {
 	config.Parse(strings.NewReader(contents))
 	assert.Equal(t, "{ca_bundle_file_path}", *config.SSL.CABundlePath)
 }
+
+func TestConfigSource(t *testing.T) {
+	contents := `
+				[config_source]
+					locations = ["s3:{bucket}/{key}", "appconfig:{application}/{environment}/{profile}"]
+					poll_interval = "10m"
+				`
+	config := New()
+	config.Parse(strings.NewReader(contents))
+	assert.Equal(t, []string{"s3:{bucket}/{key}", "appconfig:{application}/{environment}/{profile}"}, config.ConfigSource.Locations)
+	assert.Equal(t, "10m", *config.ConfigSource.PollInterval)
+}
@@ -0,0 +1,69 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/appconfigdata"
+	"github.com/aws/aws-sdk-go/service/appconfigdata/appconfigdataiface"
+
+	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
+)
+
+// appConfigSource fetches the configuration from an AWS AppConfig configuration profile, versioned by
+// the version label of the configuration. The configuration session is kept between the fetches so
+// AppConfig only returns the configuration when a new version has been deployed.
+type appConfigSource struct {
+	client                            appconfigdataiface.AppConfigDataAPI
+	application, environment, profile string
+	token                             *string
+}
+
+func newAppConfigSource(credentialConfig *configaws.CredentialConfig, name string) (*appConfigSource, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("appconfig location %s is not in the <application>/<environment>/<profile> format", name)
+	}
+	return &appConfigSource{
+		client:      appconfigdata.New(credentialConfig.Credentials(), sdkConfig()),
+		application: parts[0],
+		environment: parts[1],
+		profile:     parts[2],
+	}, nil
+}
+
+func (s *appConfigSource) Fetch(version string) ([]byte, string, error) {
+	if s.token == nil || version == "" {
+		session, err := s.client.StartConfigurationSession(&appconfigdata.StartConfigurationSessionInput{
+			ApplicationIdentifier:          aws.String(s.application),
+			EnvironmentIdentifier:          aws.String(s.environment),
+			ConfigurationProfileIdentifier: aws.String(s.profile),
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to start the appconfig configuration session: %w", err)
+		}
+		s.token = session.InitialConfigurationToken
+	}
+	output, err := s.client.GetLatestConfiguration(&appconfigdata.GetLatestConfigurationInput{
+		ConfigurationToken: s.token,
+	})
+	if err != nil {
+		// the token can't be reused after an error, start a new session next time
+		s.token = nil
+		return nil, "", fmt.Errorf("unable to get the latest appconfig configuration: %w", err)
+	}
+	s.token = output.NextPollConfigurationToken
+	newVersion := aws.StringValue(output.VersionLabel)
+	// an empty configuration means the session already returned the latest one
+	if len(output.Configuration) == 0 || (newVersion != "" && newVersion == version) {
+		return nil, version, ErrNotModified
+	}
+	if newVersion == "" {
+		newVersion = contentVersion(output.Configuration)
+	}
+	return output.Configuration, newVersion, nil
+}
@@ -0,0 +1,77 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/appconfigdata"
+	"github.com/aws/aws-sdk-go/service/appconfigdata/appconfigdataiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAppConfig returns the configuration once per session, like AppConfig does.
+type stubAppConfig struct {
+	appconfigdataiface.AppConfigDataAPI
+	content, versionLabel string
+	sessions, polls       int
+	returned              map[string]string
+	err                   error
+}
+
+func (s *stubAppConfig) StartConfigurationSession(input *appconfigdata.StartConfigurationSessionInput) (*appconfigdata.StartConfigurationSessionOutput, error) {
+	s.sessions++
+	return &appconfigdata.StartConfigurationSessionOutput{InitialConfigurationToken: aws.String("session-" + strconv.Itoa(s.sessions))}, nil
+}
+
+func (s *stubAppConfig) GetLatestConfiguration(input *appconfigdata.GetLatestConfigurationInput) (*appconfigdata.GetLatestConfigurationOutput, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.polls++
+	token := aws.StringValue(input.ConfigurationToken)
+	output := &appconfigdata.GetLatestConfigurationOutput{
+		NextPollConfigurationToken: aws.String(token + "-" + strconv.Itoa(s.polls)),
+		VersionLabel:               aws.String(s.versionLabel),
+	}
+	session := token[:len("session-1")]
+	if s.returned[session] != s.versionLabel {
+		s.returned[session] = s.versionLabel
+		output.Configuration = []byte(s.content)
+	}
+	return output, nil
+}
+
+func TestAppConfigSource(t *testing.T) {
+	stub := &stubAppConfig{content: `{"agent":{}}`, versionLabel: "v1", returned: map[string]string{}}
+	source := &appConfigSource{client: stub, application: "agent", environment: "prod", profile: "cwagent"}
+
+	config, version, err := source.Fetch("")
+	require.NoError(t, err)
+	assert.Equal(t, `{"agent":{}}`, string(config))
+	assert.Equal(t, "v1", version)
+
+	_, _, err = source.Fetch(version)
+	assert.ErrorIs(t, err, ErrNotModified)
+	assert.Equal(t, 1, stub.sessions)
+
+	stub.content, stub.versionLabel = `{"logs":{}}`, "v2"
+	config, version, err = source.Fetch(version)
+	require.NoError(t, err)
+	assert.Equal(t, `{"logs":{}}`, string(config))
+	assert.Equal(t, "v2", version)
+
+	// a new session is started after an error, the configuration of the same version isn't returned again
+	stub.err = errors.New("expired token")
+	_, _, err = source.Fetch(version)
+	assert.Error(t, err)
+	stub.err = nil
+	_, _, err = source.Fetch(version)
+	assert.ErrorIs(t, err, ErrNotModified)
+	assert.Equal(t, 2, stub.sessions)
+}
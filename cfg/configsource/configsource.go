@@ -0,0 +1,158 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
+	"github.com/aws/amazon-cloudwatch-agent/cfg/commonconfig"
+)
+
+const (
+	LocationDefault   = "default"
+	LocationSSM       = "ssm"
+	LocationFile      = "file"
+	LocationS3        = "s3"
+	LocationHTTPS     = "https"
+	LocationAppConfig = "appconfig"
+
+	LocationSeparator = ":"
+
+	// ModeFileName is the file, next to the configuration directory of the agent, recording the mode
+	// the configurations were fetched with so the poller fetches and translates them the same way.
+	ModeFileName = "config-mode"
+	modeAuto     = "auto"
+)
+
+// ErrNotModified is returned by Fetch when the configuration is still at the given version.
+var ErrNotModified = errors.New("configuration not modified")
+
+// Source is a location the JSON configuration of the agent is fetched from.
+type Source interface {
+	// Fetch returns the configuration and its version. The version is an opaque string, e.g. an
+	// ETag, given back to the next call so the configuration is only transferred when it changed.
+	Fetch(version string) (config []byte, newVersion string, err error)
+}
+
+// New returns the source of a location, e.g. "ssm:<parameter-store-name>", "file:<file-path>",
+// "s3:<bucket>/<key>", "https://<host>/<path>" or "appconfig:<application>/<environment>/<profile>".
+func New(location string, credentialConfig *configaws.CredentialConfig) (Source, error) {
+	scheme, name, err := split(location)
+	if err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case LocationSSM:
+		return newSSMSource(credentialConfig, name), nil
+	case LocationFile:
+		return newFileSource(name), nil
+	case LocationS3:
+		return newS3Source(credentialConfig, name)
+	case LocationHTTPS:
+		return newHTTPSSource(location)
+	case LocationAppConfig:
+		return newAppConfigSource(credentialConfig, name)
+	default:
+		return nil, fmt.Errorf("location type %s is not supported", scheme)
+	}
+}
+
+// RequiresRegion returns true when the location is fetched from an AWS service.
+func RequiresRegion(location string) bool {
+	scheme, _, _ := split(location)
+	return scheme == LocationSSM || scheme == LocationS3 || scheme == LocationAppConfig
+}
+
+// OutputFileName is the name of the file the configuration fetched from the location is saved as
+// in the configuration directory of the agent.
+func OutputFileName(location string) (string, error) {
+	scheme, name, err := split(location)
+	if err != nil {
+		return "", err
+	}
+	switch scheme {
+	case LocationFile:
+		return scheme + "_" + EscapeFilePath(filepath.Base(name)), nil
+	case LocationHTTPS:
+		return scheme + "_" + EscapeFilePath(strings.TrimPrefix(name, "//")), nil
+	default:
+		return scheme + "_" + EscapeFilePath(name), nil
+	}
+}
+
+func EscapeFilePath(filePath string) (escapedFilePath string) {
+	escapedFilePath = filepath.ToSlash(filePath)
+	escapedFilePath = strings.Replace(escapedFilePath, "/", "_", -1)
+	escapedFilePath = strings.Replace(escapedFilePath, " ", "_", -1)
+	escapedFilePath = strings.Replace(escapedFilePath, ":", "_", -1)
+	return
+}
+
+// WriteMode records the mode the configurations of the directory are fetched with.
+func WriteMode(dir string, mode string) error {
+	return os.WriteFile(modeFilePath(dir), []byte(mode), 0644)
+}
+
+// ReadMode returns the mode recorded by WriteMode for the configurations of the directory, or auto
+// when none was recorded.
+func ReadMode(dir string) string {
+	content, err := os.ReadFile(modeFilePath(dir))
+	if err != nil {
+		return modeAuto
+	}
+	if mode := strings.TrimSpace(string(content)); mode != "" {
+		return mode
+	}
+	return modeAuto
+}
+
+func modeFilePath(dir string) string {
+	return filepath.Join(filepath.Dir(filepath.Clean(dir)), ModeFileName)
+}
+
+// CredentialConfig returns the credentials used to fetch the configuration from the credentials
+// of the common-config resolved for the mode, assuming the role of the common-config if any.
+func CredentialConfig(region string, credentials map[string]string, cc *commonconfig.CommonConfig) *configaws.CredentialConfig {
+	credentialConfig := &configaws.CredentialConfig{
+		Region:               region,
+		Profile:              credentials[commonconfig.CredentialProfile],
+		Filename:             credentials[commonconfig.CredentialFile],
+		CredentialProcess:    credentials[commonconfig.CredentialProcess],
+		WebIdentityTokenFile: credentials[commonconfig.WebIdentityToken],
+		WebIdentityRoleARN:   credentials[commonconfig.WebIdentityRole],
+	}
+	if cc == nil || cc.Credentials == nil {
+		return credentialConfig
+	}
+	if cc.Credentials.RoleARN != nil {
+		credentialConfig.RoleARN = *cc.Credentials.RoleARN
+	}
+	if cc.Credentials.ExternalID != nil {
+		credentialConfig.ExternalID = *cc.Credentials.ExternalID
+	}
+	credentialConfig.SessionTags = cc.Credentials.SessionTags
+	return credentialConfig
+}
+
+func split(location string) (scheme string, name string, err error) {
+	locationArray := strings.SplitN(location, LocationSeparator, 2)
+	if len(locationArray) < 2 || locationArray[1] == "" {
+		return "", "", fmt.Errorf("location %s is malformed", location)
+	}
+	return locationArray[0], locationArray[1], nil
+}
+
+func sdkConfig() *aws.Config {
+	return &aws.Config{
+		LogLevel: configaws.SDKLogLevel(),
+		Logger:   configaws.SDKLogger{},
+	}
+}
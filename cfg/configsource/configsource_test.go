@@ -0,0 +1,97 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/cfg/commonconfig"
+)
+
+func TestOutputFileName(t *testing.T) {
+	testCases := map[string]string{
+		"ssm:AmazonCloudWatch-Config.json":     "ssm_AmazonCloudWatch-Config.json",
+		"file:/tmp/config dir/config.json":     "file_config.json",
+		"s3:my-bucket/cwagent/config.json":     "s3_my-bucket_cwagent_config.json",
+		"https://example.com:8443/config.json": "https_example.com_8443_config.json",
+		"appconfig:agent/prod/cwagent":         "appconfig_agent_prod_cwagent",
+	}
+	for location, want := range testCases {
+		got, err := OutputFileName(location)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, location)
+	}
+	_, err := OutputFileName("ssm")
+	assert.Error(t, err)
+	_, err = OutputFileName("s3:")
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	source, err := New("file:/tmp/config.json", nil)
+	require.NoError(t, err)
+	assert.Equal(t, &fileSource{path: "/tmp/config.json"}, source)
+
+	source, err = New("https://example.com/config.json", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/config.json", source.(*httpsSource).url)
+
+	for _, location := range []string{"ftp:example.com/config.json", "https:config.json", "s3:my-bucket", "appconfig:agent/prod", "ssm"} {
+		_, err = New(location, nil)
+		assert.Error(t, err, location)
+	}
+}
+
+func TestRequiresRegion(t *testing.T) {
+	assert.True(t, RequiresRegion("ssm:AmazonCloudWatch-Config.json"))
+	assert.True(t, RequiresRegion("s3:my-bucket/config.json"))
+	assert.True(t, RequiresRegion("appconfig:agent/prod/cwagent"))
+	assert.False(t, RequiresRegion("file:/tmp/config.json"))
+	assert.False(t, RequiresRegion("https://example.com/config.json"))
+	assert.False(t, RequiresRegion("default"))
+}
+
+func TestMode(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "amazon-cloudwatch-agent.d")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	assert.Equal(t, "auto", ReadMode(dir))
+	require.NoError(t, WriteMode(dir, "onPremise"))
+	assert.Equal(t, "onPremise", ReadMode(dir))
+	assert.FileExists(t, filepath.Join(filepath.Dir(dir), ModeFileName))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the mode is not recorded with the configurations")
+}
+
+func TestCredentialConfig(t *testing.T) {
+	c := CredentialConfig("us-west-2", map[string]string{
+		commonconfig.CredentialProfile: "AmazonCloudWatchAgent",
+		commonconfig.CredentialFile:    "/root/.aws/credentials",
+		commonconfig.CredentialProcess: "/usr/bin/credentials",
+	}, nil)
+	assert.Equal(t, "us-west-2", c.Region)
+	assert.Equal(t, "AmazonCloudWatchAgent", c.Profile)
+	assert.Equal(t, "/root/.aws/credentials", c.Filename)
+	assert.Equal(t, "/usr/bin/credentials", c.CredentialProcess)
+	assert.Empty(t, c.RoleARN)
+
+	cc, err := commonconfig.Parse(strings.NewReader(`
+		[credentials]
+			role_arn = "arn:aws:iam::123456789012:role/config"
+			external_id = "agent"
+			[credentials.session_tags]
+				team = "observability"
+	`))
+	require.NoError(t, err)
+	c = CredentialConfig("us-west-2", cc.CredentialsMap(), cc)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/config", c.RoleARN)
+	assert.Equal(t, "agent", c.ExternalID)
+	assert.Equal(t, map[string]string{"team": "observability"}, c.SessionTags)
+}
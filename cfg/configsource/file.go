@@ -0,0 +1,36 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+)
+
+// fileSource reads the configuration from a file of the host, versioned by the hash of its content.
+type fileSource struct {
+	path string
+}
+
+func newFileSource(path string) *fileSource {
+	return &fileSource{path: path}
+}
+
+func (s *fileSource) Fetch(version string) ([]byte, string, error) {
+	config, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, "", err
+	}
+	newVersion := contentVersion(config)
+	if newVersion == version {
+		return nil, version, ErrNotModified
+	}
+	return config, newVersion, nil
+}
+
+func contentVersion(config []byte) string {
+	sum := sha256.Sum256(config)
+	return hex.EncodeToString(sum[:])
+}
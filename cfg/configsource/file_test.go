@@ -0,0 +1,35 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"agent":{}}`), 0644))
+	source := newFileSource(path)
+
+	config, version, err := source.Fetch("")
+	require.NoError(t, err)
+	assert.Equal(t, `{"agent":{}}`, string(config))
+
+	_, _, err = source.Fetch(version)
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"logs":{}}`), 0644))
+	config, newVersion, err := source.Fetch(version)
+	require.NoError(t, err)
+	assert.Equal(t, `{"logs":{}}`, string(config))
+	assert.NotEqual(t, version, newVersion)
+
+	_, _, err = newFileSource(filepath.Join(t.TempDir(), "missing.json")).Fetch("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// maxConfigSize bounds the size of the configurations read from the network.
+	maxConfigSize = 10 * 1024 * 1024
+
+	httpTimeout = 1 * time.Minute
+)
+
+// httpsSource fetches the configuration from an HTTPS endpoint, versioned by the ETag header of the response.
+type httpsSource struct {
+	client *http.Client
+	url    string
+}
+
+func newHTTPSSource(location string) (*httpsSource, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("https location %s is malformed: %w", location, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("https location %s does not have a host", location)
+	}
+	return &httpsSource{
+		client: &http.Client{Timeout: httpTimeout},
+		url:    u.String(),
+	}, nil
+}
+
+func (s *httpsSource) Fetch(version string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if version != "" {
+		req.Header.Set("If-None-Match", version)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, version, ErrNotModified
+	default:
+		return nil, "", fmt.Errorf("unexpected status %s from %s", resp.Status, s.url)
+	}
+	config, err := io.ReadAll(io.LimitReader(resp.Body, maxConfigSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read the response from %s: %w", s.url, err)
+	}
+	newVersion := resp.Header.Get("ETag")
+	if newVersion == "" {
+		// without ETag the content itself tells if the configuration changed
+		newVersion = contentVersion(config)
+		if newVersion == version {
+			return nil, version, ErrNotModified
+		}
+	}
+	return config, newVersion, nil
+}
@@ -0,0 +1,63 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSSource(t *testing.T) {
+	content, etag := `{"agent":{}}`, `"etag-1"`
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config.json":
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			_, _ = w.Write([]byte(content))
+		case "/no-etag.json":
+			_, _ = w.Write([]byte(content))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source, err := newHTTPSSource(server.URL + "/config.json")
+	require.NoError(t, err)
+	source.client = server.Client()
+
+	config, version, err := source.Fetch("")
+	require.NoError(t, err)
+	assert.Equal(t, `{"agent":{}}`, string(config))
+	assert.Equal(t, `"etag-1"`, version)
+
+	_, _, err = source.Fetch(version)
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	content, etag = `{"logs":{}}`, `"etag-2"`
+	config, version, err = source.Fetch(version)
+	require.NoError(t, err)
+	assert.Equal(t, `{"logs":{}}`, string(config))
+	assert.Equal(t, `"etag-2"`, version)
+
+	// without ETag the content is compared
+	source.url = server.URL + "/no-etag.json"
+	_, version, err = source.Fetch("")
+	require.NoError(t, err)
+	_, _, err = source.Fetch(version)
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	source.url = server.URL + "/missing.json"
+	_, _, err = source.Fetch("")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotModified)
+}
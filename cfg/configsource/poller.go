@@ -0,0 +1,153 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator/context"
+)
+
+const (
+	DefaultPollInterval = 5 * time.Minute
+	MinPollInterval     = 1 * time.Minute
+)
+
+type polledSource struct {
+	location string
+	fileName string
+	source   Source
+	version  string
+}
+
+// Poller periodically fetches the configurations of the remote sources into the configuration directory
+// of the agent. When a configuration changed, it translates the configurations again and asks the agent
+// to reload. The generated files are restored to the last good configuration if the translation or the
+// validation of the new configuration fails.
+type Poller struct {
+	// Dir is the directory the JSON configurations are saved in.
+	Dir string
+	// GeneratedFiles are the files written by Translate, e.g. the TOML and YAML configurations.
+	GeneratedFiles []string
+	// Translate translates the JSON configurations of Dir, including the .tmp files.
+	Translate func() error
+	// Validate checks that the agent can load the translated configuration.
+	Validate func() error
+	// Reload asks the agent to reload its configuration.
+	Reload func()
+
+	sources []*polledSource
+}
+
+// Add registers the source of a location in the poller.
+func (p *Poller) Add(location string, source Source) error {
+	fileName, err := OutputFileName(location)
+	if err != nil {
+		return err
+	}
+	p.sources = append(p.sources, &polledSource{location: location, fileName: fileName, source: source})
+	return nil
+}
+
+// Run polls the sources every interval until stop is closed, or forever when stop is nil. The first
+// poll is delayed by a random jitter so a fleet of agents started together doesn't fetch its
+// configuration at the same time.
+func (p *Poller) Run(interval time.Duration, stop <-chan struct{}) {
+	if len(p.sources) == 0 {
+		return
+	}
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(interval))))
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if p.Poll() {
+				p.Reload()
+			}
+			timer.Reset(interval)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Poll fetches the configurations and applies the ones which changed. It returns true when the agent
+// should reload its configuration.
+func (p *Poller) Poll() bool {
+	var changed []string
+	for _, s := range p.sources {
+		config, version, err := s.source.Fetch(s.version)
+		if errors.Is(err, ErrNotModified) {
+			continue
+		}
+		if err != nil {
+			log.Printf("W! Unable to fetch the configuration from %s: %v", s.location, err)
+			continue
+		}
+		// the version is remembered even if the configuration is rejected below so the same invalid
+		// configuration isn't applied again and again
+		s.version = version
+		path := filepath.Join(p.Dir, s.fileName)
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, config) {
+			continue
+		}
+		if err = os.WriteFile(path+context.TmpFileSuffix, config, 0644); err != nil {
+			log.Printf("E! Unable to save the configuration from %s: %v", s.location, err)
+			continue
+		}
+		log.Printf("I! The configuration from %s changed, version %s", s.location, version)
+		changed = append(changed, path)
+	}
+	if len(changed) == 0 {
+		return false
+	}
+	if err := p.apply(changed); err != nil {
+		log.Printf("E! Rolled back to the last good configuration: %v", err)
+		return false
+	}
+	return true
+}
+
+// apply translates the changed configurations and, if the new configuration is valid, replaces the
+// previous JSON configurations by them.
+func (p *Poller) apply(changed []string) error {
+	backup := make(map[string][]byte, len(p.GeneratedFiles))
+	for _, path := range p.GeneratedFiles {
+		if content, err := os.ReadFile(path); err == nil {
+			backup[path] = content
+		}
+	}
+	err := p.Translate()
+	if err != nil {
+		err = fmt.Errorf("unable to translate the configuration: %w", err)
+	} else if err = p.Validate(); err != nil {
+		err = fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err != nil {
+		for _, path := range changed {
+			_ = os.Remove(path + context.TmpFileSuffix)
+		}
+		for _, path := range p.GeneratedFiles {
+			if content, ok := backup[path]; ok {
+				_ = os.WriteFile(path, content, 0644)
+			} else {
+				_ = os.Remove(path)
+			}
+		}
+		return err
+	}
+	for _, path := range changed {
+		if err = os.Rename(path+context.TmpFileSuffix, path); err != nil {
+			log.Printf("E! Unable to replace the configuration %s: %v", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSource struct {
+	config, version string
+	err             error
+}
+
+func (s *stubSource) Fetch(version string) ([]byte, string, error) {
+	if s.err != nil {
+		return nil, "", s.err
+	}
+	if version == s.version {
+		return nil, version, ErrNotModified
+	}
+	return []byte(s.config), s.version, nil
+}
+
+func TestPoller(t *testing.T) {
+	dir := t.TempDir()
+	toml := filepath.Join(t.TempDir(), "amazon-cloudwatch-agent.toml")
+	require.NoError(t, os.WriteFile(toml, []byte("good"), 0644))
+	jsonPath := filepath.Join(dir, "s3_my-bucket_config.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"agent":{}}`), 0644))
+
+	var translateErr, validateErr error
+	translations := 0
+	poller := &Poller{
+		Dir:            dir,
+		GeneratedFiles: []string{toml},
+		Translate: func() error {
+			translations++
+			// the new configuration is translated from the .tmp file
+			content, err := os.ReadFile(jsonPath + ".tmp")
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(toml, content, 0644))
+			return translateErr
+		},
+		Validate: func() error { return validateErr },
+	}
+	source := &stubSource{config: `{"agent":{}}`, version: "v1"}
+	require.NoError(t, poller.Add("s3:my-bucket/config.json", source))
+
+	// the configuration fetched by the downloader is unchanged
+	assert.False(t, poller.Poll())
+	assert.Equal(t, 0, translations)
+
+	// a new version is translated and replaces the previous configuration
+	source.config, source.version = `{"logs":{}}`, "v2"
+	assert.True(t, poller.Poll())
+	assert.Equal(t, 1, translations)
+	assertFile(t, jsonPath, `{"logs":{}}`)
+	assertFile(t, toml, `{"logs":{}}`)
+	assert.NoFileExists(t, jsonPath+".tmp")
+
+	// nothing happens until the next version
+	assert.False(t, poller.Poll())
+	assert.Equal(t, 1, translations)
+
+	// invalid configurations are rolled back
+	for i, err := range []*error{&translateErr, &validateErr} {
+		*err = errors.New("invalid")
+		source.config, source.version = `{"invalid":{}}`, "v"+string(rune('3'+i))
+		assert.False(t, poller.Poll())
+		assertFile(t, jsonPath, `{"logs":{}}`)
+		assertFile(t, toml, `{"logs":{}}`)
+		assert.NoFileExists(t, jsonPath+".tmp")
+		// the rejected version isn't applied again
+		assert.False(t, poller.Poll())
+		*err = nil
+	}
+	assert.Equal(t, 3, translations)
+
+	// fetch errors keep the current configuration
+	source.err = errors.New("unavailable")
+	assert.False(t, poller.Poll())
+	assert.Equal(t, 3, translations)
+}
+
+func assertFile(t *testing.T, path, want string) {
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+}
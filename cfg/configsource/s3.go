@@ -0,0 +1,61 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
+)
+
+// s3Source fetches the configuration from a S3 object, versioned by the ETag of the object.
+type s3Source struct {
+	client      s3iface.S3API
+	bucket, key string
+}
+
+func newS3Source(credentialConfig *configaws.CredentialConfig, name string) (*s3Source, error) {
+	bucket, key, ok := strings.Cut(name, "/")
+	if !ok || bucket == "" || key == "" {
+		return nil, fmt.Errorf("s3 location %s is not in the <bucket>/<key> format", name)
+	}
+	return &s3Source{
+		client: s3.New(credentialConfig.Credentials(), sdkConfig()),
+		bucket: bucket,
+		key:    key,
+	}, nil
+}
+
+func (s *s3Source) Fetch(version string) ([]byte, string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key),
+	}
+	if version != "" {
+		input.IfNoneMatch = aws.String(version)
+	}
+	output, err := s.client.GetObject(input)
+	if err != nil {
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotModified {
+			return nil, version, ErrNotModified
+		}
+		return nil, "", fmt.Errorf("unable to get object s3://%s/%s: %w", s.bucket, s.key, err)
+	}
+	defer output.Body.Close()
+	config, err := io.ReadAll(io.LimitReader(output.Body, maxConfigSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read object s3://%s/%s: %w", s.bucket, s.key, err)
+	}
+	return config, aws.StringValue(output.ETag), nil
+}
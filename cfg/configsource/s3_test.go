@@ -0,0 +1,64 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubS3 struct {
+	s3iface.S3API
+	content, etag string
+	inputs        []*s3.GetObjectInput
+}
+
+func (s *stubS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	s.inputs = append(s.inputs, input)
+	if aws.StringValue(input.Bucket) != "my-bucket" || aws.StringValue(input.Key) != "cwagent/config.json" {
+		return nil, awserr.NewRequestFailure(awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil), http.StatusNotFound, "request-id")
+	}
+	if aws.StringValue(input.IfNoneMatch) == s.etag {
+		return nil, awserr.NewRequestFailure(awserr.New("NotModified", "Not Modified", nil), http.StatusNotModified, "request-id")
+	}
+	return &s3.GetObjectOutput{
+		Body: io.NopCloser(strings.NewReader(s.content)),
+		ETag: aws.String(s.etag),
+	}, nil
+}
+
+func TestS3Source(t *testing.T) {
+	stub := &stubS3{content: `{"agent":{}}`, etag: `"etag-1"`}
+	source := &s3Source{client: stub, bucket: "my-bucket", key: "cwagent/config.json"}
+
+	config, version, err := source.Fetch("")
+	require.NoError(t, err)
+	assert.Equal(t, `{"agent":{}}`, string(config))
+	assert.Equal(t, `"etag-1"`, version)
+	assert.Nil(t, stub.inputs[0].IfNoneMatch)
+
+	_, _, err = source.Fetch(version)
+	assert.ErrorIs(t, err, ErrNotModified)
+	assert.Equal(t, `"etag-1"`, aws.StringValue(stub.inputs[1].IfNoneMatch))
+
+	stub.content, stub.etag = `{"logs":{}}`, `"etag-2"`
+	config, version, err = source.Fetch(version)
+	require.NoError(t, err)
+	assert.Equal(t, `{"logs":{}}`, string(config))
+	assert.Equal(t, `"etag-2"`, version)
+
+	source.key = "missing.json"
+	_, _, err = source.Fetch(version)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotModified)
+}
@@ -0,0 +1,43 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+
+	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
+)
+
+// ssmSource fetches the configuration from a SSM parameter, versioned by the parameter version.
+type ssmSource struct {
+	client ssmiface.SSMAPI
+	name   string
+}
+
+func newSSMSource(credentialConfig *configaws.CredentialConfig, name string) *ssmSource {
+	return &ssmSource{
+		client: ssm.New(credentialConfig.Credentials(), sdkConfig()),
+		name:   name,
+	}
+}
+
+func (s *ssmSource) Fetch(version string) ([]byte, string, error) {
+	output, err := s.client.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(s.name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to retrieve parameter store content: %w", err)
+	}
+	newVersion := strconv.FormatInt(aws.Int64Value(output.Parameter.Version), 10)
+	if version != "" && newVersion == version {
+		return nil, version, ErrNotModified
+	}
+	return []byte(aws.StringValue(output.Parameter.Value)), newVersion, nil
+}
@@ -0,0 +1,52 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package configsource
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSSM struct {
+	ssmiface.SSMAPI
+	parameter *ssm.Parameter
+	err       error
+}
+
+func (s *stubSSM) GetParameter(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &ssm.GetParameterOutput{Parameter: s.parameter}, nil
+}
+
+func TestSSMSource(t *testing.T) {
+	stub := &stubSSM{parameter: &ssm.Parameter{Value: aws.String(`{"agent":{}}`), Version: aws.Int64(3)}}
+	source := &ssmSource{client: stub, name: "AmazonCloudWatch-Config.json"}
+
+	config, version, err := source.Fetch("")
+	require.NoError(t, err)
+	assert.Equal(t, `{"agent":{}}`, string(config))
+	assert.Equal(t, "3", version)
+
+	_, _, err = source.Fetch(version)
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	stub.parameter = &ssm.Parameter{Value: aws.String(`{"logs":{}}`), Version: aws.Int64(4)}
+	config, version, err = source.Fetch(version)
+	require.NoError(t, err)
+	assert.Equal(t, `{"logs":{}}`, string(config))
+	assert.Equal(t, "4", version)
+
+	stub.err = errors.New("access denied")
+	_, _, err = source.Fetch(version)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotModified)
+}
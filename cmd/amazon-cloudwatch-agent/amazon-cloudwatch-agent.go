@@ -120,6 +120,11 @@ func reloadLoop(
 					reload <- true
 				}
 				cancel()
+			case <-configChanged:
+				log.Println("I! Reloading the changed config")
+				<-reload
+				reload <- true
+				cancel()
 			case <-stop:
 				cancel()
 			}
@@ -543,6 +548,12 @@ func main() {
 		return
 	}
 
+	// The config source poller is started once for the process, whether the agent runs as a service
+	// or from the console, and keeps polling across the reloads of the agent.
+	if *fService == "" {
+		startConfigSourcePoller()
+	}
+
 	if runtime.GOOS == "windows" && windowsRunAsService() {
 		programFiles := os.Getenv("ProgramFiles")
 		if programFiles == "" { // Should never happen
@@ -0,0 +1,118 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+
+	"github.com/aws/amazon-cloudwatch-agent/cfg/commonconfig"
+	"github.com/aws/amazon-cloudwatch-agent/cfg/configsource"
+	"github.com/aws/amazon-cloudwatch-agent/cfg/envconfig"
+	"github.com/aws/amazon-cloudwatch-agent/tool/paths"
+	"github.com/aws/amazon-cloudwatch-agent/translator/util"
+)
+
+// configChanged is notified by the config source poller to reload the agent the same way SIGHUP does.
+var configChanged = make(chan struct{}, 1)
+
+// startConfigSourcePoller polls the remote configurations listed in the config_source section of the
+// common-config for the lifetime of the process.
+func startConfigSourcePoller() {
+	if envconfig.IsRunningInContainer() || *fTest || *fTestWait != 0 || *fSchemaTest {
+		return
+	}
+	f, err := os.Open(paths.CommonConfigPath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	cc, err := commonconfig.Parse(f)
+	if err != nil {
+		log.Printf("W! Config source polling is disabled, unable to parse common-config: %v", err)
+		return
+	}
+	if cc.ConfigSource == nil || len(cc.ConfigSource.Locations) == 0 {
+		return
+	}
+	interval := configsource.DefaultPollInterval
+	if cc.ConfigSource.PollInterval != nil {
+		if interval, err = time.ParseDuration(*cc.ConfigSource.PollInterval); err != nil {
+			log.Printf("E! Config source polling is disabled, invalid poll_interval: %v", err)
+			return
+		}
+		if interval < configsource.MinPollInterval {
+			log.Printf("W! The config source poll_interval %v is too short, using %v", interval, configsource.MinPollInterval)
+			interval = configsource.MinPollInterval
+		}
+	}
+
+	go func() {
+		// the mode the configurations were fetched with by the ctl script
+		mode := util.DetectAgentMode(configsource.ReadMode(paths.JsonDirPath))
+		credentials := util.GetCredentials(mode, cc.CredentialsMap())
+		region, _ := util.DetectRegion(mode, credentials)
+		credentialConfig := configsource.CredentialConfig(region, credentials, cc)
+
+		generatedFiles := []string{*fTomlConfig, *fOtelConfig}
+		if envConfigPath, err := getEnvConfigPath(*fTomlConfig, *fEnvConfig); err == nil {
+			generatedFiles = append(generatedFiles, envConfigPath)
+		}
+		poller := &configsource.Poller{
+			Dir:            paths.JsonDirPath,
+			GeneratedFiles: generatedFiles,
+			Translate:      func() error { return translateConfig(mode) },
+			Validate:       validateConfig,
+			Reload: func() {
+				select {
+				case configChanged <- struct{}{}:
+				default:
+				}
+			},
+		}
+		for _, location := range cc.ConfigSource.Locations {
+			source, err := configsource.New(location, credentialConfig)
+			if err == nil {
+				err = poller.Add(location, source)
+			}
+			if err != nil {
+				log.Printf("E! Unable to poll the configuration from %s: %v", location, err)
+				continue
+			}
+			log.Printf("I! Polling the configuration from %s every %v", location, interval)
+		}
+		poller.Run(interval, nil)
+	}()
+}
+
+// translateConfig translates the JSON configurations the same way the ctl script appends a configuration.
+func translateConfig(mode string) error {
+	cmd := exec.Command(paths.TranslatorBinaryPath,
+		"--input", paths.JsonConfigPath,
+		"--input-dir", paths.JsonDirPath,
+		"--output", *fTomlConfig,
+		"--mode", mode,
+		"--config", paths.CommonConfigPath,
+		"--multi-config", "append",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("E! Config translation failed:\n%s", output)
+	}
+	return err
+}
+
+// validateConfig loads the translated TOML configuration like the schema test of the ctl script.
+func validateConfig() error {
+	c := config.NewConfig()
+	c.AllowUnusedFields = true
+	if err := loadTomlConfigIntoAgent(c); err != nil {
+		return err
+	}
+	return validateAgentFinalConfigAndPlugins(c)
+}
@@ -11,13 +11,8 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/ssm"
-
-	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
 	"github.com/aws/amazon-cloudwatch-agent/cfg/commonconfig"
+	"github.com/aws/amazon-cloudwatch-agent/cfg/configsource"
 	"github.com/aws/amazon-cloudwatch-agent/translator/config"
 	"github.com/aws/amazon-cloudwatch-agent/translator/context"
 	"github.com/aws/amazon-cloudwatch-agent/translator/util"
@@ -25,11 +20,7 @@ import (
 )
 
 const (
-	locationDefault = "default"
-	locationSSM     = "ssm"
-	locationFile    = "file"
-
-	locationSeparator = ":"
+	locationDefault = configsource.LocationDefault
 
 	exitErrorMessage = "Fail to fetch the config!"
 )
@@ -38,56 +29,19 @@ func defaultJsonConfig(mode string) (string, error) {
 	return config.DefaultJsonConfig(config.ToValidOs(""), mode), nil
 }
 
-func downloadFromSSM(region, parameterStoreName, mode string, credsConfig map[string]string) (string, error) {
+func download(location, region, mode string, cc *commonconfig.CommonConfig) (string, error) {
 	fmt.Printf("Region: %v\n", region)
-	fmt.Printf("credsConfig: %v\n", credsConfig)
-	var ses *session.Session
-	credsMap := util.GetCredentials(mode, credsConfig)
-	profile, profileOk := credsMap[commonconfig.CredentialProfile]
-	sharedConfigFile, sharedConfigFileOk := credsMap[commonconfig.CredentialFile]
-	rootconfig := &aws.Config{
-		Region:   aws.String(region),
-		LogLevel: configaws.SDKLogLevel(),
-		Logger:   configaws.SDKLogger{},
-	}
-	if profileOk || sharedConfigFileOk {
-		rootconfig.Credentials = credentials.NewCredentials(&credentials.SharedCredentialsProvider{
-			Filename: sharedConfigFile,
-			Profile:  profile,
-		})
-	}
-
-	ses, err := session.NewSession(rootconfig)
+	credentialConfig := configsource.CredentialConfig(region, util.GetCredentials(mode, cc.CredentialsMap()), cc)
+	source, err := configsource.New(location, credentialConfig)
 	if err != nil {
-		fmt.Printf("Error in creating session: %v\n", err)
 		return "", err
 	}
-
-	ssmClient := ssm.New(ses)
-	input := ssm.GetParameterInput{
-		Name:           aws.String(parameterStoreName),
-		WithDecryption: aws.Bool(true),
-	}
-	output, err := ssmClient.GetParameter(&input)
+	config, version, err := source.Fetch("")
 	if err != nil {
-		fmt.Printf("Error in retrieving parameter store content: %v\n", err)
 		return "", err
 	}
-
-	return *output.Parameter.Value, nil
-}
-
-func readFromFile(filePath string) (string, error) {
-	bytes, err := os.ReadFile(filePath)
-	return string(bytes), err
-}
-
-func EscapeFilePath(filePath string) (escapedFilePath string) {
-	escapedFilePath = filepath.ToSlash(filePath)
-	escapedFilePath = strings.Replace(escapedFilePath, "/", "_", -1)
-	escapedFilePath = strings.Replace(escapedFilePath, " ", "_", -1)
-	escapedFilePath = strings.Replace(escapedFilePath, ":", "_", -1)
-	return
+	fmt.Printf("Fetched version %s of the config\n", version)
+	return string(config), nil
 }
 
 /**
@@ -145,7 +99,7 @@ func main() {
 
 	region, _ = util.DetectRegion(mode, cc.CredentialsMap())
 
-	if region == "" && downloadLocation != locationDefault {
+	if region == "" && configsource.RequiresRegion(downloadLocation) {
 		fmt.Println("Unable to determine aws-region.")
 		if mode == config.ModeEC2 {
 			errorMessage = "E! Please check if you can access the metadata service. For example, on linux, run 'wget -q -O - http://169.254.169.254/latest/meta-data/instance-id && echo' "
@@ -179,31 +133,21 @@ func main() {
 			return nil
 		})
 
-	locationArray := strings.SplitN(downloadLocation, locationSeparator, 2)
-	if locationArray == nil || len(locationArray) < 2 && downloadLocation != locationDefault {
-		log.Panicf("E! downloadLocation %s is malformated.", downloadLocation)
-	}
-
 	var config, outputFilePath string
 	var err error
-	switch locationArray[0] {
-	case locationDefault:
+	if downloadLocation == locationDefault {
 		outputFilePath = locationDefault
 		if multiConfig != "remove" {
 			config, err = defaultJsonConfig(mode)
 		}
-	case locationSSM:
-		outputFilePath = locationSSM + "_" + EscapeFilePath(locationArray[1])
-		if multiConfig != "remove" {
-			config, err = downloadFromSSM(region, locationArray[1], mode, cc.CredentialsMap())
+	} else {
+		outputFilePath, err = configsource.OutputFileName(downloadLocation)
+		if err != nil {
+			log.Panicf("E! downloadLocation %s is malformated.", downloadLocation)
 		}
-	case locationFile:
-		outputFilePath = locationFile + "_" + EscapeFilePath(filepath.Base(locationArray[1]))
 		if multiConfig != "remove" {
-			config, err = readFromFile(locationArray[1])
+			config, err = download(downloadLocation, region, mode, cc)
 		}
-	default:
-		log.Panicf("E! Location type %s is not supported.", locationArray[0])
 	}
 
 	if err != nil {
@@ -218,6 +162,10 @@ func main() {
 		} else {
 			fmt.Printf("Successfully fetched the config and saved in %s\n", outputFilePath)
 		}
+		// The config source poller of the agent fetches and translates the configurations with the same mode
+		if err = configsource.WriteMode(outputDir, mode); err != nil {
+			fmt.Printf("Failed to record the mode %s: %v\n", mode, err)
+		}
 	} else {
 		outputFilePath = filepath.Join(outputDir, outputFilePath)
 		if err := os.Remove(outputFilePath); err != nil {
@@ -171,6 +171,75 @@ func TestInvalidLogFilterConfig(t *testing.T) {
 	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidLogFilesWithFilters.json", false, expectedErrorMap)
 }
 
+func TestValidLogSamplingConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validLogFilesWithSampling.json", true, map[string]int{})
+}
+
+func TestValidLogDestinationsConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validLogFilesWithDestinations.json", true, map[string]int{})
+}
+
+func TestValidMetricsSpillConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validMetricsWithSpill.json", true, map[string]int{})
+}
+
+func TestMetricsCardinalityLimitConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validMetricsWithCardinalityLimit.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["number_gte"] = 1
+	expectedErrorMap["enum"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidMetricsWithCardinalityLimit.json", false, expectedErrorMap)
+}
+
+func TestMetricsDerivedMetricsConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validMetricsWithDerivedMetrics.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["number_lte"] = 1
+	expectedErrorMap["enum"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidMetricsWithDerivedMetrics.json", false, expectedErrorMap)
+}
+
+func TestPrometheusMetricsDestinationConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validPrometheusWithCloudWatchDestination.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["enum"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidPrometheusWithInvalidDestination.json", false, expectedErrorMap)
+}
+
+func TestPrometheusRemoteWriteConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validPrometheusWithRemoteWrite.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["pattern"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidPrometheusWithInvalidRemoteWrite.json", false, expectedErrorMap)
+}
+
+func TestPrometheusECSServiceDiscoveryConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validPrometheusWithMultiClusterECSServiceDiscovery.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["required"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidPrometheusWithInvalidCloudMapServiceList.json", false, expectedErrorMap)
+}
+
+func TestKubernetesPodAnnotationsConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validKubernetesPodAnnotations.json", true, map[string]int{})
+}
+
+func TestKubernetesPromotedAttributesConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validKubernetesPromotedAttributes.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["additional_property_not_allowed"] = 1
+	expectedErrorMap["unique"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidKubernetesPromotedAttributes.json", false, expectedErrorMap)
+}
+
+func TestCredentialsAssumeRoleOptionsConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validCredentialsAssumeRoleOptions.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["string_gte"] = 1
+	expectedErrorMap["invalid_type"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidCredentialsAssumeRoleOptions.json", false, expectedErrorMap)
+}
+
 // Validate all sampleConfig files schema
 func TestSampleConfigSchema(t *testing.T) {
 	if files, err := os.ReadDir("../../translator/tocwconfig/sampleConfig/"); err == nil {
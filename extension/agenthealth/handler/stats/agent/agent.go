@@ -29,6 +29,12 @@ type Stats struct {
 	RunningInContainer        *int     `json:"ric,omitempty"`
 	RegionType                *string  `json:"rt,omitempty"`
 	Mode                      *string  `json:"m,omitempty"`
+	LogRedactions             *int64   `json:"lred,omitempty"`
+	LogEventsSampledOut       *int64   `json:"lsmp,omitempty"`
+	LogEventsRateLimited      *int64   `json:"lrl,omitempty"`
+	MetricDatumsDropped       *int64   `json:"mdd,omitempty"`
+	MetricDatumsReplayed      *int64   `json:"mdr,omitempty"`
+	MetricSeriesLimited       *int64   `json:"msl,omitempty"`
 }
 
 // Merge the other Stats into the current. If the field is not nil,
@@ -76,6 +82,24 @@ func (s *Stats) Merge(other Stats) {
 	if other.Mode != nil {
 		s.Mode = other.Mode
 	}
+	if other.LogRedactions != nil {
+		s.LogRedactions = other.LogRedactions
+	}
+	if other.LogEventsSampledOut != nil {
+		s.LogEventsSampledOut = other.LogEventsSampledOut
+	}
+	if other.LogEventsRateLimited != nil {
+		s.LogEventsRateLimited = other.LogEventsRateLimited
+	}
+	if other.MetricDatumsDropped != nil {
+		s.MetricDatumsDropped = other.MetricDatumsDropped
+	}
+	if other.MetricDatumsReplayed != nil {
+		s.MetricDatumsReplayed = other.MetricDatumsReplayed
+	}
+	if other.MetricSeriesLimited != nil {
+		s.MetricSeriesLimited = other.MetricSeriesLimited
+	}
 }
 
 func (s *Stats) Marshal() (string, error) {
@@ -35,6 +35,12 @@ func TestMerge(t *testing.T) {
 		RunningInContainer:        aws.Int(0),
 		RegionType:                aws.String("RegionType"),
 		Mode:                      aws.String("Mode"),
+		LogRedactions:             aws.Int64(3),
+		LogEventsSampledOut:       aws.Int64(4),
+		LogEventsRateLimited:      aws.Int64(5),
+		MetricDatumsDropped:       aws.Int64(6),
+		MetricDatumsReplayed:      aws.Int64(7),
+		MetricSeriesLimited:       aws.Int64(8),
 	})
 	assert.EqualValues(t, 1.5, *stats.CpuPercent)
 	assert.EqualValues(t, 133, *stats.MemoryBytes)
@@ -50,6 +56,12 @@ func TestMerge(t *testing.T) {
 	assert.EqualValues(t, 0, *stats.RunningInContainer)
 	assert.EqualValues(t, "RegionType", *stats.RegionType)
 	assert.EqualValues(t, "Mode", *stats.Mode)
+	assert.EqualValues(t, 3, *stats.LogRedactions)
+	assert.EqualValues(t, 4, *stats.LogEventsSampledOut)
+	assert.EqualValues(t, 5, *stats.LogEventsRateLimited)
+	assert.EqualValues(t, 6, *stats.MetricDatumsDropped)
+	assert.EqualValues(t, 7, *stats.MetricDatumsReplayed)
+	assert.EqualValues(t, 8, *stats.MetricSeriesLimited)
 }
 
 func TestMarshal(t *testing.T) {
@@ -0,0 +1,68 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// CounterLogRedactions is the number of matches redacted from log events.
+	CounterLogRedactions Counter = iota
+	// CounterLogEventsSampledOut is the number of log events dropped by sampling.
+	CounterLogEventsSampledOut
+	// CounterLogEventsRateLimited is the number of log events suppressed by rate limits.
+	CounterLogEventsRateLimited
+	// CounterMetricDatumsDropped is the number of metric datums dropped after PutMetricData failed.
+	CounterMetricDatumsDropped
+	// CounterMetricDatumsReplayed is the number of buffered metric datums sent once PutMetricData recovered.
+	CounterMetricDatumsReplayed
+	// CounterMetricSeriesLimited is the number of metrics over the cardinality limit that were dropped or rewritten.
+	CounterMetricSeriesLimited
+
+	counterCount
+)
+
+type Counter int
+
+var (
+	counterSingleton CounterSet
+	counterOnce      sync.Once
+)
+
+// CounterSet holds the running totals of the usage counters since the agent started.
+type CounterSet interface {
+	// Add increments the counter by delta.
+	Add(counter Counter, delta int64)
+	// Get returns the current total of the counter.
+	Get(counter Counter) int64
+}
+
+type counterSet struct {
+	counters [counterCount]atomic.Int64
+}
+
+var _ CounterSet = (*counterSet)(nil)
+
+func (p *counterSet) Add(counter Counter, delta int64) {
+	if counter < 0 || counter >= counterCount {
+		return
+	}
+	p.counters[counter].Add(delta)
+}
+
+func (p *counterSet) Get(counter Counter) int64 {
+	if counter < 0 || counter >= counterCount {
+		return 0
+	}
+	return p.counters[counter].Load()
+}
+
+func UsageCounters() CounterSet {
+	counterOnce.Do(func() {
+		counterSingleton = &counterSet{}
+	})
+	return counterSingleton
+}
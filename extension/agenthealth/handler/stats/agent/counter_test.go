@@ -0,0 +1,33 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterSet(t *testing.T) {
+	cs := &counterSet{}
+	assert.EqualValues(t, 0, cs.Get(CounterLogRedactions))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cs.Add(CounterLogRedactions, 2)
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 20, cs.Get(CounterLogRedactions))
+	cs.Add(counterCount, 1)
+	assert.EqualValues(t, 0, cs.Get(counterCount))
+	assert.EqualValues(t, 0, cs.Get(-1))
+}
+
+func TestUsageCounters(t *testing.T) {
+	assert.Same(t, UsageCounters(), UsageCounters())
+}
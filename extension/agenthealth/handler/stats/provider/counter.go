@@ -0,0 +1,73 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
+)
+
+const (
+	counterGetInterval = time.Minute
+)
+
+var (
+	counterSingleton *counterStats
+	counterOnce      sync.Once
+)
+
+type counterStats struct {
+	*intervalStats
+
+	counterSet agent.CounterSet
+}
+
+var _ agent.StatsProvider = (*counterStats)(nil)
+
+func (p *counterStats) updateLoop() {
+	ticker := time.NewTicker(p.interval)
+	for range ticker.C {
+		p.refresh()
+	}
+}
+
+func (p *counterStats) refresh() {
+	p.stats.Store(agent.Stats{
+		LogRedactions:        p.sparseCount(agent.CounterLogRedactions),
+		LogEventsSampledOut:  p.sparseCount(agent.CounterLogEventsSampledOut),
+		LogEventsRateLimited: p.sparseCount(agent.CounterLogEventsRateLimited),
+		MetricDatumsDropped:  p.sparseCount(agent.CounterMetricDatumsDropped),
+		MetricDatumsReplayed: p.sparseCount(agent.CounterMetricDatumsReplayed),
+		MetricSeriesLimited:  p.sparseCount(agent.CounterMetricSeriesLimited),
+	})
+}
+
+// sparseCount only includes the counters that have been incremented.
+func (p *counterStats) sparseCount(counter agent.Counter) *int64 {
+	if value := p.counterSet.Get(counter); value != 0 {
+		return aws.Int64(value)
+	}
+	return nil
+}
+
+func newCounterStats(counterSet agent.CounterSet, interval time.Duration) *counterStats {
+	cs := &counterStats{
+		intervalStats: newIntervalStats(interval),
+		counterSet:    counterSet,
+	}
+	cs.refresh()
+	go cs.updateLoop()
+	return cs
+}
+
+func GetCounterStats() agent.StatsProvider {
+	counterOnce.Do(func() {
+		counterSingleton = newCounterStats(agent.UsageCounters(), counterGetInterval)
+	})
+	return counterSingleton
+}
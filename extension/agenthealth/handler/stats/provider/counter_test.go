@@ -0,0 +1,58 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
+)
+
+type mockCounterSet map[agent.Counter]int64
+
+func (m mockCounterSet) Add(counter agent.Counter, delta int64) {
+	m[counter] += delta
+}
+
+func (m mockCounterSet) Get(counter agent.Counter) int64 {
+	return m[counter]
+}
+
+func TestCounterStats(t *testing.T) {
+	counters := mockCounterSet{}
+	provider := newCounterStats(counters, time.Hour)
+	assert.Equal(t, agent.Stats{}, provider.getStats())
+
+	counters.Add(agent.CounterLogRedactions, 5)
+	provider.refresh()
+	got := provider.getStats()
+	assert.NotNil(t, got.LogRedactions)
+	assert.EqualValues(t, 5, *got.LogRedactions)
+	assert.Nil(t, got.LogEventsSampledOut)
+
+	counters.Add(agent.CounterLogEventsSampledOut, 2)
+	counters.Add(agent.CounterLogEventsRateLimited, 3)
+	provider.refresh()
+	got = provider.getStats()
+	assert.EqualValues(t, 5, *got.LogRedactions)
+	assert.EqualValues(t, 2, *got.LogEventsSampledOut)
+	assert.EqualValues(t, 3, *got.LogEventsRateLimited)
+	assert.Nil(t, got.MetricDatumsDropped)
+
+	counters.Add(agent.CounterMetricDatumsDropped, 4)
+	counters.Add(agent.CounterMetricDatumsReplayed, 6)
+	provider.refresh()
+	got = provider.getStats()
+	assert.EqualValues(t, 4, *got.MetricDatumsDropped)
+	assert.EqualValues(t, 6, *got.MetricDatumsReplayed)
+	assert.Nil(t, got.MetricSeriesLimited)
+
+	counters.Add(agent.CounterMetricSeriesLimited, 7)
+	provider.refresh()
+	got = provider.getStats()
+	assert.EqualValues(t, 7, *got.MetricSeriesLimited)
+}
@@ -0,0 +1,243 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package diskqueue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	entrySuffix = ".entry"
+	tmpSuffix   = ".tmp"
+	dirMode     = 0755
+	fileMode    = 0600
+)
+
+var ErrEntryTooLarge = errors.New("entry is larger than the queue size limit")
+
+type entry struct {
+	id   uint64
+	size int64
+}
+
+// DiskQueue is a FIFO queue persisted in a directory with one file per entry.
+// Entries survive restarts and are reloaded in the order they were pushed. When
+// the total size of the entries goes over maxSize, the oldest entries are removed
+// to make room for the new one.
+//
+// An entry is flushed to disk before Push returns, so it survives a power loss once
+// accepted. The delivery is at-least-once: an entry delivered but not removed yet
+// when the agent stops is delivered again after the restart.
+type DiskQueue struct {
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	entries []entry
+	size    int64
+	nextID  uint64
+}
+
+// NewDiskQueue opens the queue stored in dir, creating the directory if needed,
+// and loads any entries left over from a previous run.
+func NewDiskQueue(dir string, maxSize int64) (*DiskQueue, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid disk queue size limit %d", maxSize)
+	}
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return nil, fmt.Errorf("failed to create disk queue directory %s: %w", dir, err)
+	}
+	q := &DiskQueue{dir: dir, maxSize: maxSize}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *DiskQueue) load() error {
+	files, err := os.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read disk queue directory %s: %w", q.dir, err)
+	}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(name, tmpSuffix) {
+			// left over from an interrupted push
+			_ = os.Remove(filepath.Join(q.dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, entrySuffix) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, entrySuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		if info.Size() == 0 {
+			// left over from a push interrupted by a power loss, the entries pushed are never empty
+			_ = os.Remove(filepath.Join(q.dir, name))
+			continue
+		}
+		q.entries = append(q.entries, entry{id: id, size: info.Size()})
+		q.size += info.Size()
+		if id >= q.nextID {
+			q.nextID = id + 1
+		}
+	}
+	sort.Slice(q.entries, func(i, j int) bool {
+		return q.entries[i].id < q.entries[j].id
+	})
+	return nil
+}
+
+// Push writes data as a new entry at the back of the queue. It returns the ID of
+// the new entry and the IDs of the entries that were evicted to stay within the
+// size limit.
+func (q *DiskQueue) Push(data []byte) (uint64, []uint64, error) {
+	size := int64(len(data))
+	if size > q.maxSize {
+		return 0, nil, ErrEntryTooLarge
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var evicted []uint64
+	for len(q.entries) > 0 && q.size+size > q.maxSize {
+		oldest := q.entries[0]
+		if err := q.remove(oldest.id); err != nil {
+			return 0, evicted, err
+		}
+		evicted = append(evicted, oldest.id)
+	}
+
+	id := q.nextID
+	path := q.path(id)
+	tmp := path + tmpSuffix
+	if err := writeFileSync(tmp, data); err != nil {
+		_ = os.Remove(tmp)
+		return 0, evicted, fmt.Errorf("failed to write disk queue entry %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return 0, evicted, fmt.Errorf("failed to commit disk queue entry %s: %w", path, err)
+	}
+	if err := syncDir(q.dir); err != nil {
+		_ = os.Remove(path)
+		return 0, evicted, fmt.Errorf("failed to commit disk queue entry %s: %w", path, err)
+	}
+	q.nextID++
+	q.entries = append(q.entries, entry{id: id, size: size})
+	q.size += size
+	return id, evicted, nil
+}
+
+// writeFileSync writes the data to the file and flushes it to disk, so it is never
+// renamed into the queue before its content is persisted.
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Peek returns the entry at the front of the queue without removing it.
+func (q *DiskQueue) Peek() (uint64, []byte, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.entries) == 0 {
+		return 0, nil, false, nil
+	}
+	id := q.entries[0].id
+	data, err := os.ReadFile(q.path(id))
+	if err != nil {
+		return id, nil, true, fmt.Errorf("failed to read disk queue entry %s: %w", q.path(id), err)
+	}
+	return id, data, true, nil
+}
+
+// IDs returns the IDs of the entries in the order they were pushed.
+func (q *DiskQueue) IDs() []uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ids := make([]uint64, 0, len(q.entries))
+	for _, e := range q.entries {
+		ids = append(ids, e.id)
+	}
+	return ids
+}
+
+// Read returns the entry with the given ID without removing it.
+func (q *DiskQueue) Read(id uint64) ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	data, err := os.ReadFile(q.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read disk queue entry %s: %w", q.path(id), err)
+	}
+	return data, nil
+}
+
+// Remove deletes the entry with the given ID. Removing an entry that is no
+// longer in the queue is not an error.
+func (q *DiskQueue) Remove(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.remove(id)
+}
+
+func (q *DiskQueue) remove(id uint64) error {
+	for i, e := range q.entries {
+		if e.id != id {
+			continue
+		}
+		if err := os.Remove(q.path(id)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove disk queue entry %s: %w", q.path(id), err)
+		}
+		q.entries = append(q.entries[:i], q.entries[i+1:]...)
+		q.size -= e.size
+		return nil
+	}
+	return nil
+}
+
+// Len returns the number of entries in the queue.
+func (q *DiskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// Size returns the total size in bytes of the entries in the queue.
+func (q *DiskQueue) Size() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+func (q *DiskQueue) path(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", id, entrySuffix))
+}
@@ -0,0 +1,121 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package diskqueue
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewDiskQueue(dir, 1024)
+	require.NoError(t, err)
+
+	_, _, ok, err := q.Peek()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	first, evicted, err := q.Push([]byte("first"))
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+	second, _, err := q.Push([]byte("second"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, q.Len())
+	assert.EqualValues(t, 11, q.Size())
+
+	id, data, ok, err := q.Peek()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, first, id)
+	assert.Equal(t, "first", string(data))
+
+	require.NoError(t, q.Remove(first))
+	require.NoError(t, q.Remove(first))
+	id, data, _, err = q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, second, id)
+	assert.Equal(t, "second", string(data))
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestDiskQueueRead(t *testing.T) {
+	q, err := NewDiskQueue(t.TempDir(), 1024)
+	require.NoError(t, err)
+	assert.Empty(t, q.IDs())
+	var ids []uint64
+	for _, s := range []string{"a", "b", "c"} {
+		id, _, err := q.Push([]byte(s))
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	assert.Equal(t, ids, q.IDs())
+
+	data, err := q.Read(ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(data))
+	require.NoError(t, q.Remove(ids[1]))
+	assert.Equal(t, []uint64{ids[0], ids[2]}, q.IDs())
+	_, err = q.Read(ids[1])
+	assert.Error(t, err)
+}
+
+func TestDiskQueueReload(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewDiskQueue(dir, 1024)
+	require.NoError(t, err)
+	for _, s := range []string{"a", "b", "c"} {
+		_, _, err = q.Push([]byte(s))
+		require.NoError(t, err)
+	}
+	// simulate a crash in the middle of a push
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000003.entry.tmp"), []byte("d"), 0600))
+	// and of a power loss after the rename of a push that was not flushed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000004.entry"), nil, 0600))
+
+	q, err = NewDiskQueue(dir, 1024)
+	require.NoError(t, err)
+	assert.Equal(t, 3, q.Len())
+	for _, s := range []string{"a", "b", "c"} {
+		id, data, ok, err := q.Peek()
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, s, string(data))
+		require.NoError(t, q.Remove(id))
+	}
+	id, _, err := q.Push([]byte("e"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, id)
+	_, err = os.Stat(filepath.Join(dir, "00000000000000000003.entry.tmp"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "00000000000000000004.entry"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDiskQueueEviction(t *testing.T) {
+	q, err := NewDiskQueue(t.TempDir(), 10)
+	require.NoError(t, err)
+	first, _, err := q.Push([]byte("1234"))
+	require.NoError(t, err)
+	second, _, err := q.Push([]byte("5678"))
+	require.NoError(t, err)
+	_, evicted, err := q.Push([]byte("9012"))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{first}, evicted)
+	id, _, _, err := q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, second, id)
+	assert.EqualValues(t, 8, q.Size())
+
+	_, _, err = q.Push([]byte("this entry is too large"))
+	assert.ErrorIs(t, err, ErrEntryTooLarge)
+	assert.Equal(t, 2, q.Len())
+
+	_, err = NewDiskQueue(t.TempDir(), 0)
+	assert.Error(t, err)
+}
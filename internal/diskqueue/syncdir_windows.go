@@ -0,0 +1,12 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+// +build windows
+
+package diskqueue
+
+// syncDir is a no-op, directories cannot be flushed on Windows and NTFS journals the renames.
+func syncDir(string) error {
+	return nil
+}
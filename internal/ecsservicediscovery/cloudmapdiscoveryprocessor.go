@@ -0,0 +1,140 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/aws/aws-sdk-go/service/servicediscovery/servicediscoveryiface"
+)
+
+const (
+	// DiscoverInstances is not paginated, 1000 is the maximum number of instances returned.
+	cloudMapMaxInstances = 1000
+)
+
+// Add the instances registered in the matching AWS Cloud Map services
+type CloudMapDiscoveryProcessor struct {
+	svcCloudMap      servicediscoveryiface.ServiceDiscoveryAPI
+	cloudMapServices []*CloudMapServiceConfig
+	stats            *ProcessorStats
+}
+
+func NewCloudMapDiscoveryProcessor(svcCloudMap servicediscoveryiface.ServiceDiscoveryAPI, cloudMapServices []*CloudMapServiceConfig, s *ProcessorStats) *CloudMapDiscoveryProcessor {
+	for _, v := range cloudMapServices {
+		v.init()
+	}
+
+	return &CloudMapDiscoveryProcessor{
+		svcCloudMap:      svcCloudMap,
+		cloudMapServices: cloudMapServices,
+		stats:            s,
+	}
+}
+
+func (p *CloudMapDiscoveryProcessor) Process(cluster string, taskList []*DecoratedTask) ([]*DecoratedTask, error) {
+	if len(p.cloudMapServices) == 0 {
+		return taskList, nil
+	}
+	namespaceIds, err := p.namespaceIds()
+	if err != nil {
+		return taskList, err
+	}
+
+	discovered := make(map[string]bool)
+	for _, v := range p.cloudMapServices {
+		namespaceId, ok := namespaceIds[v.NamespaceName]
+		if !ok {
+			continue
+		}
+		serviceNames, err := p.serviceNames(v, namespaceId)
+		if err != nil {
+			return taskList, err
+		}
+		for _, serviceName := range serviceNames {
+			// the instances are exported for every configuration matching their service, discover them once
+			if discovered[v.NamespaceName+"/"+serviceName] {
+				continue
+			}
+			discovered[v.NamespaceName+"/"+serviceName] = true
+
+			req := &servicediscovery.DiscoverInstancesInput{
+				NamespaceName: aws.String(v.NamespaceName),
+				ServiceName:   aws.String(serviceName),
+				HealthStatus:  aws.String(servicediscovery.HealthStatusFilterHealthyOrElseAll),
+				MaxResults:    aws.Int64(cloudMapMaxInstances),
+			}
+			resp, err := p.svcCloudMap.DiscoverInstances(req)
+			p.stats.AddStats(AWSCLIDiscoverInstances)
+			if err != nil {
+				return taskList, newServiceDiscoveryError("Failed to discover Cloud Map instances for "+v.NamespaceName+"/"+serviceName, &err)
+			}
+			for _, instance := range resp.Instances {
+				taskList = append(taskList, &DecoratedTask{CloudMapInstance: instance})
+			}
+		}
+	}
+	return taskList, nil
+}
+
+// namespaceIds maps the names of the configured namespaces to their ids.
+func (p *CloudMapDiscoveryProcessor) namespaceIds() (map[string]string, error) {
+	configured := make(map[string]bool)
+	for _, v := range p.cloudMapServices {
+		configured[v.NamespaceName] = true
+	}
+
+	namespaceIds := make(map[string]string)
+	req := &servicediscovery.ListNamespacesInput{}
+	for {
+		resp, err := p.svcCloudMap.ListNamespaces(req)
+		p.stats.AddStats(AWSCLIListNamespaces)
+		if err != nil {
+			return nil, newServiceDiscoveryError("Failed to list Cloud Map namespaces", &err)
+		}
+		for _, ns := range resp.Namespaces {
+			if configured[aws.StringValue(ns.Name)] {
+				namespaceIds[aws.StringValue(ns.Name)] = aws.StringValue(ns.Id)
+			}
+		}
+		if resp.NextToken == nil {
+			break
+		}
+		req.NextToken = resp.NextToken
+	}
+	return namespaceIds, nil
+}
+
+// serviceNames returns the names of the services of the namespace matching the configured pattern.
+func (p *CloudMapDiscoveryProcessor) serviceNames(config *CloudMapServiceConfig, namespaceId string) ([]string, error) {
+	var serviceNames []string
+	req := &servicediscovery.ListServicesInput{
+		Filters: []*servicediscovery.ServiceFilter{{
+			Name:      aws.String(servicediscovery.ServiceFilterNameNamespaceId),
+			Condition: aws.String(servicediscovery.FilterConditionEq),
+			Values:    []*string{aws.String(namespaceId)},
+		}},
+	}
+	for {
+		resp, err := p.svcCloudMap.ListServices(req)
+		p.stats.AddStats(AWSCLIListCloudMapServices)
+		if err != nil {
+			return nil, newServiceDiscoveryError("Failed to list Cloud Map services for "+config.NamespaceName, &err)
+		}
+		for _, s := range resp.Services {
+			if config.serviceNameRegex.MatchString(aws.StringValue(s.Name)) {
+				serviceNames = append(serviceNames, aws.StringValue(s.Name))
+			}
+		}
+		if resp.NextToken == nil {
+			break
+		}
+		req.NextToken = resp.NextToken
+	}
+	return serviceNames, nil
+}
+
+func (p *CloudMapDiscoveryProcessor) ProcessorName() string {
+	return "CloudMapDiscoveryProcessor"
+}
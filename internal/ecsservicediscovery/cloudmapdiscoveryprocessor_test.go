@@ -0,0 +1,133 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/aws/aws-sdk-go/service/servicediscovery/servicediscoveryiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubCloudMap struct {
+	servicediscoveryiface.ServiceDiscoveryAPI
+	namespaces map[string]string
+	services   map[string][]string
+	instances  map[string][]*servicediscovery.HttpInstanceSummary
+	err        error
+}
+
+func newStubCloudMap() *stubCloudMap {
+	return &stubCloudMap{
+		namespaces: map[string]string{"ns-1": "local", "ns-2": "other"},
+		services:   map[string][]string{"ns-1": {"api", "api-internal", "web"}, "ns-2": {"api"}},
+		instances: map[string][]*servicediscovery.HttpInstanceSummary{
+			"local/api": {
+				{
+					NamespaceName: aws.String("local"),
+					ServiceName:   aws.String("api"),
+					InstanceId:    aws.String("3333"),
+					Attributes: map[string]*string{
+						cloudMapInstanceIPv4Attribute:  aws.String("10.2.0.1"),
+						cloudMapInstancePortAttribute:  aws.String("8080"),
+						cloudMapECSClusterAttribute:    aws.String("cluster-c"),
+						cloudMapECSTaskFamilyAttribute: aws.String("api"),
+					},
+				},
+				{
+					// instances without an IPv4 address are skipped
+					NamespaceName: aws.String("local"),
+					ServiceName:   aws.String("api"),
+					InstanceId:    aws.String("4444"),
+					Attributes:    map[string]*string{},
+				},
+			},
+			"local/api-internal": {
+				{
+					NamespaceName: aws.String("local"),
+					ServiceName:   aws.String("api-internal"),
+					InstanceId:    aws.String("5555"),
+					Attributes:    map[string]*string{cloudMapInstanceIPv4Attribute: aws.String("10.2.0.2")},
+				},
+			},
+		},
+	}
+}
+
+func (s *stubCloudMap) ListNamespaces(*servicediscovery.ListNamespacesInput) (*servicediscovery.ListNamespacesOutput, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	output := &servicediscovery.ListNamespacesOutput{}
+	for id, name := range s.namespaces {
+		output.Namespaces = append(output.Namespaces, &servicediscovery.NamespaceSummary{Id: aws.String(id), Name: aws.String(name)})
+	}
+	return output, nil
+}
+
+func (s *stubCloudMap) ListServices(input *servicediscovery.ListServicesInput) (*servicediscovery.ListServicesOutput, error) {
+	output := &servicediscovery.ListServicesOutput{}
+	for _, name := range s.services[aws.StringValue(input.Filters[0].Values[0])] {
+		output.Services = append(output.Services, &servicediscovery.ServiceSummary{Name: aws.String(name)})
+	}
+	return output, nil
+}
+
+func (s *stubCloudMap) DiscoverInstances(input *servicediscovery.DiscoverInstancesInput) (*servicediscovery.DiscoverInstancesOutput, error) {
+	return &servicediscovery.DiscoverInstancesOutput{
+		Instances: s.instances[aws.StringValue(input.NamespaceName)+"/"+aws.StringValue(input.ServiceName)],
+	}, nil
+}
+
+func Test_CloudMapDiscoveryProcessor(t *testing.T) {
+	config := &ServiceDiscoveryConfig{
+		CloudMapServices: []*CloudMapServiceConfig{
+			{NamespaceName: "local", ServiceNamePattern: "^api", MetricsPath: "/stats", JobName: "api"},
+			{NamespaceName: "local", ServiceNamePattern: "^api-internal$", MetricsPorts: "9404;9405"},
+			{NamespaceName: "missing", ServiceNamePattern: ".*"},
+		},
+	}
+	var stats ProcessorStats
+	p := NewCloudMapDiscoveryProcessor(newStubCloudMap(), config.CloudMapServices, &stats)
+	taskList, err := p.Process("", []*DecoratedTask{{ServiceName: "existing"}})
+	require.NoError(t, err)
+	// api-internal matches two configurations but its instances are discovered once
+	assert.Equal(t, 4, len(taskList))
+	assert.Equal(t, 2, stats.GetStats(AWSCLIDiscoverInstances))
+
+	targets := make(map[string]*PrometheusTarget)
+	for _, task := range taskList[1:] {
+		task.ExporterInformation(config, regexp.MustCompile(prometheusLabelNamePattern), targets)
+	}
+	assert.Equal(t, 3, len(targets))
+	assert.Equal(t, &PrometheusTarget{
+		Targets: []string{"10.2.0.1:8080"},
+		Labels: map[string]string{
+			serviceNameLabel:       "api",
+			cloudMapNamespaceLabel: "local",
+			cloudMapInstanceLabel:  "3333",
+			taskClusterNameLabel:   "cluster-c",
+			taskFamilyLabel:        "api",
+			taskMetricsPathLabel:   "/stats",
+			taskJobNameLabel:       "api",
+		},
+	}, targets["10.2.0.1:8080/stats"])
+	// the configured ports are used instead of the registered one
+	assert.Contains(t, targets, "10.2.0.2:9404/metrics")
+	assert.Contains(t, targets, "10.2.0.2:9405/metrics")
+}
+
+func Test_CloudMapDiscoveryProcessor_Error(t *testing.T) {
+	stub := newStubCloudMap()
+	stub.err = errors.New("throttled")
+	var stats ProcessorStats
+	p := NewCloudMapDiscoveryProcessor(stub, []*CloudMapServiceConfig{{NamespaceName: "local", ServiceNamePattern: ".*"}}, &stats)
+	_, err := p.Process("", nil)
+	assert.Error(t, err)
+}
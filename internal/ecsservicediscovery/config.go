@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
 )
 
 const (
@@ -64,6 +66,8 @@ func (t *TaskDefinitionConfig) init() {
 		t.containerNameRegex = regexp.MustCompile(t.ContainerNamePattern)
 	}
 
+	// init is called by the processors of every cluster
+	t.metricsPortList = nil
 	ports := strings.Split(t.MetricsPorts, portSeparator)
 	for _, v := range ports {
 		if port, err := strconv.Atoi(strings.TrimSpace(v)); err != nil || port < 0 {
@@ -91,6 +95,7 @@ func (s *ServiceNameForTasksConfig) init() {
 		s.containerNameRegex = regexp.MustCompile(s.ContainerNamePattern)
 	}
 
+	s.metricsPortList = nil
 	ports := strings.Split(s.MetricsPorts, portSeparator)
 	for _, v := range ports {
 		if port, err := strconv.Atoi(strings.TrimSpace(v)); err != nil || port < 0 {
@@ -101,12 +106,119 @@ func (s *ServiceNameForTasksConfig) init() {
 	}
 }
 
+// TargetClusterConfig is an additional ECS cluster to discover the targets from. The cluster
+// is queried with the credentials of the role if it is set, e.g. for a cluster in another account.
+type TargetClusterConfig struct {
+	TargetCluster       string            `toml:"sd_target_cluster"`
+	TargetClusterRegion string            `toml:"sd_cluster_region"`
+	RoleARN             string            `toml:"sd_role_arn"`
+	ExternalID          string            `toml:"sd_external_id"`
+	SessionTags         map[string]string `toml:"sd_session_tags"`
+}
+
+func (c *TargetClusterConfig) String() string {
+	return fmt.Sprintf("TargetCluster: %v\nTargetClusterRegion: %v\nRoleARN: %v\nExternalID: %v\nSessionTags: %v\n",
+		c.TargetCluster,
+		c.TargetClusterRegion,
+		c.RoleARN,
+		c.ExternalID,
+		c.SessionTags,
+	)
+}
+
+// CloudMapServiceConfig discovers the instances registered in the AWS Cloud Map services of a namespace.
+type CloudMapServiceConfig struct {
+	NamespaceName      string `toml:"sd_namespace_name"`
+	ServiceNamePattern string `toml:"sd_service_name_pattern"`
+	JobName            string `toml:"sd_job_name"`
+	MetricsPath        string `toml:"sd_metrics_path"`
+	MetricsPorts       string `toml:"sd_metrics_ports"`
+
+	serviceNameRegex *regexp.Regexp
+	metricsPortList  []int
+}
+
+func (c *CloudMapServiceConfig) String() string {
+	return fmt.Sprintf("NamespaceName: %v\nServiceNamePattern: %v\nJobName: %v\nMetricsPath: %v\nMetricsPorts: %v\n",
+		c.NamespaceName,
+		c.ServiceNamePattern,
+		c.JobName,
+		c.MetricsPath,
+		c.MetricsPorts,
+	)
+}
+
+func (c *CloudMapServiceConfig) init() {
+	c.serviceNameRegex = regexp.MustCompile(c.ServiceNamePattern)
+
+	c.metricsPortList = nil
+	ports := strings.Split(c.MetricsPorts, portSeparator)
+	for _, v := range ports {
+		if port, err := strconv.Atoi(strings.TrimSpace(v)); err != nil || port < 0 {
+			continue
+		} else {
+			c.metricsPortList = append(c.metricsPortList, port)
+		}
+	}
+}
+
 type ServiceDiscoveryConfig struct {
 	Frequency            string                       `toml:"sd_frequency"`
 	ResultFile           string                       `toml:"sd_result_file"`
 	TargetCluster        string                       `toml:"sd_target_cluster"`
 	TargetClusterRegion  string                       `toml:"sd_cluster_region"`
+	TargetClusters       []*TargetClusterConfig       `toml:"sd_target_clusters"`
 	ServiceNamesForTasks []*ServiceNameForTasksConfig `toml:"service_name_list_for_tasks"`
 	DockerLabel          *DockerLabelConfig           `toml:"docker_label"`
 	TaskDefinitions      []*TaskDefinitionConfig      `toml:"task_definition_list"`
+	CloudMapServices     []*CloudMapServiceConfig     `toml:"cloud_map_service_list"`
+
+	// The credentials of the agent, which query the clusters or assume their roles.
+	Profile              string `toml:"profile"`
+	Filename             string `toml:"shared_credential_file"`
+	CredentialProcess    string `toml:"credential_process"`
+	WebIdentityTokenFile string `toml:"web_identity_token_file"`
+	WebIdentityRoleARN   string `toml:"web_identity_role_arn"`
+}
+
+// credentialConfig returns the credentials used to query the cluster.
+func (c *ServiceDiscoveryConfig) credentialConfig(cluster *TargetClusterConfig) *configaws.CredentialConfig {
+	return &configaws.CredentialConfig{
+		Region:               cluster.TargetClusterRegion,
+		RoleARN:              cluster.RoleARN,
+		ExternalID:           cluster.ExternalID,
+		SessionTags:          cluster.SessionTags,
+		Profile:              c.Profile,
+		Filename:             c.Filename,
+		CredentialProcess:    c.CredentialProcess,
+		WebIdentityTokenFile: c.WebIdentityTokenFile,
+		WebIdentityRoleARN:   c.WebIdentityRoleARN,
+	}
+}
+
+// clusters returns the ECS clusters to discover the targets from: the sd_target_cluster, if set,
+// followed by the sd_target_clusters.
+func (c *ServiceDiscoveryConfig) clusters() []*TargetClusterConfig {
+	var clusters []*TargetClusterConfig
+	if c.TargetCluster != "" {
+		clusters = append(clusters, &TargetClusterConfig{
+			TargetCluster:       c.TargetCluster,
+			TargetClusterRegion: c.TargetClusterRegion,
+		})
+	}
+	return append(clusters, c.TargetClusters...)
+}
+
+// cloudMapCluster returns the cluster whose region and role are used to query Cloud Map: the
+// sd_cluster_region if set, otherwise the first of the sd_target_clusters.
+func (c *ServiceDiscoveryConfig) cloudMapCluster() *TargetClusterConfig {
+	if c.TargetClusterRegion != "" || len(c.TargetClusters) == 0 {
+		return &TargetClusterConfig{TargetClusterRegion: c.TargetClusterRegion}
+	}
+	return c.TargetClusters[0]
+}
+
+// hasECSDiscovery returns true if the targets are discovered from the tasks of the ECS clusters.
+func (c *ServiceDiscoveryConfig) hasECSDiscovery() bool {
+	return c.DockerLabel != nil || len(c.TaskDefinitions) > 0 || len(c.ServiceNamesForTasks) > 0
 }
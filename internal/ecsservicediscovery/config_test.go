@@ -31,3 +31,29 @@ func Test_ServiceNameForTasksConfig_init(t *testing.T) {
 	config.init()
 	assert.True(t, reflect.DeepEqual(config.metricsPortList, []int{11, 12, 13, 14}))
 }
+
+func Test_ServiceDiscoveryConfig_credentialConfig(t *testing.T) {
+	config := ServiceDiscoveryConfig{
+		CredentialProcess:    "/usr/bin/creds",
+		WebIdentityTokenFile: "/var/run/token",
+		WebIdentityRoleARN:   "arn:aws:iam::123456789012:role/web",
+		TargetClusters: []*TargetClusterConfig{
+			{
+				TargetCluster:       "cluster-a",
+				TargetClusterRegion: "us-west-2",
+				RoleARN:             "arn:aws:iam::210987654321:role/monitoring",
+				ExternalID:          "external-id",
+				SessionTags:         map[string]string{"team": "monitoring"},
+			},
+		},
+	}
+
+	credentialConfig := config.credentialConfig(config.cloudMapCluster())
+	assert.Equal(t, "us-west-2", credentialConfig.Region)
+	assert.Equal(t, "arn:aws:iam::210987654321:role/monitoring", credentialConfig.RoleARN)
+	assert.Equal(t, "external-id", credentialConfig.ExternalID)
+	assert.Equal(t, map[string]string{"team": "monitoring"}, credentialConfig.SessionTags)
+	assert.Equal(t, "/usr/bin/creds", credentialConfig.CredentialProcess)
+	assert.Equal(t, "/var/run/token", credentialConfig.WebIdentityTokenFile)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/web", credentialConfig.WebIdentityRoleARN)
+}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 	"github.com/hashicorp/golang-lru/simplelru"
 )
 
@@ -20,14 +22,14 @@ const (
 
 // Add the Container instance metadata for ECS Clusters on Linux EC2 Instances
 type ContainerInstanceProcessor struct {
-	svcEc2 *ec2.EC2
-	svcEcs *ecs.ECS
+	svcEc2 ec2iface.EC2API
+	svcEcs ecsiface.ECSAPI
 	stats  *ProcessorStats
 
 	ec2MetaDataCache *simplelru.LRU
 }
 
-func NewContainerInstanceProcessor(ecs *ecs.ECS, ec2 *ec2.EC2, s *ProcessorStats) *ContainerInstanceProcessor {
+func NewContainerInstanceProcessor(ecs ecsiface.ECSAPI, ec2 ec2iface.EC2API, s *ProcessorStats) *ContainerInstanceProcessor {
 	p := &ContainerInstanceProcessor{
 		svcEcs: ecs,
 		svcEc2: ec2,
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
 )
 
 const (
@@ -30,6 +31,15 @@ const (
 	ec2VpcIdLabel        = "VpcId"
 	ec2SubnetIdLabel     = "SubnetId"
 
+	cloudMapNamespaceLabel = "CloudMapNamespace"
+	cloudMapInstanceLabel  = "CloudMapInstanceId"
+
+	// Attributes of the Cloud Map instances: https://docs.aws.amazon.com/cloud-map/latest/api/API_RegisterInstance.html
+	cloudMapInstanceIPv4Attribute  = "AWS_INSTANCE_IPV4"
+	cloudMapInstancePortAttribute  = "AWS_INSTANCE_PORT"
+	cloudMapECSClusterAttribute    = "ECS_CLUSTER_NAME"
+	cloudMapECSTaskFamilyAttribute = "ECS_TASK_DEFINITION_FAMILY"
+
 	//https://prometheus.io/docs/prometheus/latest/configuration/configuration/#scrape_config
 	defaultPrometheusMetricsPath = "/metrics"
 )
@@ -48,12 +58,23 @@ type DecoratedTask struct {
 	TaskDefinition *ecs.TaskDefinition
 	EC2Info        *EC2MetaData
 	ServiceName    string
+	// ClusterName is the ECS cluster the task was discovered from.
+	ClusterName string
+	// CloudMapInstance is set instead of the ECS task for the instances discovered in Cloud Map.
+	CloudMapInstance *servicediscovery.HttpInstanceSummary
 
 	DockerLabelBased    bool
 	TaskDefinitionBased bool
 }
 
 func (t *DecoratedTask) String() string {
+	if t.CloudMapInstance != nil {
+		return fmt.Sprintf("CloudMapInstance:\n\t\tNamespaceName: %v\n\t\tServiceName: %v\n\t\tInstanceId: %v\n",
+			aws.StringValue(t.CloudMapInstance.NamespaceName),
+			aws.StringValue(t.CloudMapInstance.ServiceName),
+			aws.StringValue(t.CloudMapInstance.InstanceId),
+		)
+	}
 	return fmt.Sprintf("Task:\n\t\tTaskArn: %v\n\t\tTaskDefinitionArn: %v\n\t\tEC2Info: %v\n\t\tDockerLabelBased: %v\n\t\tTaskDefinitionBased: %v\n",
 		aws.StringValue(t.Task.TaskArn),
 		aws.StringValue(t.Task.TaskDefinitionArn),
@@ -63,6 +84,14 @@ func (t *DecoratedTask) String() string {
 	)
 }
 
+// clusterNameFromARN returns the name of the cluster, which is configured either by name or by ARN.
+func clusterNameFromARN(cluster string) string {
+	if a, err := arn.Parse(cluster); err == nil {
+		return strings.TrimPrefix(a.Resource, "cluster/")
+	}
+	return cluster
+}
+
 func addExporterLabels(labels map[string]string, labelKey string, labelValue *string) {
 	if aws.StringValue(labelValue) != "" {
 		labels[labelKey] = *labelValue
@@ -159,6 +188,11 @@ func (t *DecoratedTask) generatePrometheusTarget(
 			addExporterLabels(labels, taskIdLabel, &taskId)
 		}
 	}
+	// The old ARN format does not contain the cluster name
+	if _, ok := labels[taskClusterNameLabel]; !ok {
+		clusterName := clusterNameFromARN(t.ClusterName)
+		addExporterLabels(labels, taskClusterNameLabel, &clusterName)
+	}
 
 	if t.EC2Info != nil {
 		addExporterLabels(labels, ec2InstanceTypeLabel, &t.EC2Info.InstanceType)
@@ -315,7 +349,57 @@ func (t *DecoratedTask) exportServiceEndpointBasedTarget(config *ServiceDiscover
 
 }
 
+func (t *DecoratedTask) exportCloudMapBasedTarget(config *ServiceDiscoveryConfig, targets map[string]*PrometheusTarget) {
+	instance := t.CloudMapInstance
+	ip := aws.StringValue(instance.Attributes[cloudMapInstanceIPv4Attribute])
+	if ip == "" {
+		return
+	}
+
+	for _, v := range config.CloudMapServices {
+		if v.NamespaceName != aws.StringValue(instance.NamespaceName) || !v.serviceNameRegex.MatchString(aws.StringValue(instance.ServiceName)) {
+			continue
+		}
+
+		ports := v.metricsPortList
+		if len(ports) == 0 {
+			// use the port the instance is registered with when there is no port configured
+			if port, err := strconv.Atoi(aws.StringValue(instance.Attributes[cloudMapInstancePortAttribute])); err == nil && port > 0 {
+				ports = []int{port}
+			}
+		}
+
+		for _, port := range ports {
+			metricsPath := defaultPrometheusMetricsPath
+			if v.MetricsPath != "" {
+				metricsPath = v.MetricsPath
+			}
+			targetKey := fmt.Sprintf("%s:%d%s", ip, port, metricsPath)
+			if _, ok := targets[targetKey]; ok {
+				continue
+			}
+
+			labels := make(map[string]string)
+			addExporterLabels(labels, serviceNameLabel, instance.ServiceName)
+			addExporterLabels(labels, cloudMapNamespaceLabel, instance.NamespaceName)
+			addExporterLabels(labels, cloudMapInstanceLabel, instance.InstanceId)
+			addExporterLabels(labels, taskClusterNameLabel, instance.Attributes[cloudMapECSClusterAttribute])
+			addExporterLabels(labels, taskFamilyLabel, instance.Attributes[cloudMapECSTaskFamilyAttribute])
+			addExporterLabels(labels, taskMetricsPathLabel, &v.MetricsPath)
+			addExporterLabels(labels, taskJobNameLabel, &v.JobName)
+			targets[targetKey] = &PrometheusTarget{
+				Targets: []string{fmt.Sprintf("%s:%d", ip, port)},
+				Labels:  labels,
+			}
+		}
+	}
+}
+
 func (t *DecoratedTask) ExporterInformation(config *ServiceDiscoveryConfig, dockerLabelRegex *regexp.Regexp, targets map[string]*PrometheusTarget) {
+	if t.CloudMapInstance != nil {
+		t.exportCloudMapBasedTarget(config, targets)
+		return
+	}
 	ip := t.getPrivateIp()
 	if ip == "" {
 		return
@@ -47,6 +47,35 @@ func TestGeneratePrometheusTargetOldARNFormat(t *testing.T) {
 	assert.Equal(t, "1234567890123456789", target.Labels["TaskId"])
 }
 
+func TestGeneratePrometheusTargetConfiguredClusterARN(t *testing.T) {
+	config := &ServiceDiscoveryConfig{
+		DockerLabel: &DockerLabelConfig{
+			JobNameLabel:     "FARGATE_PROMETHEUS_JOB_NAME",
+			PortLabel:        "FARGATE_PROMETHEUS_EXPORTER_PORT",
+			MetricsPathLabel: "ECS_PROMETHEUS_METRICS_PATH",
+		},
+	}
+	dockerLabelRegex := regexp.MustCompile(prometheusLabelNamePattern)
+	for _, useNewTaskArnFormat := range []bool{false, true} {
+		// The cluster is configured by ARN, the dimension is still its name.
+		fullTask := buildWorkloadFargateAwsvpc(useNewTaskArnFormat, true, false, "")
+		fullTask.ClusterName = "arn:aws:ecs:us-east-2:211220956907:cluster/ExampleCluster"
+		targets := make(map[string]*PrometheusTarget)
+		fullTask.ExporterInformation(config, dockerLabelRegex, targets)
+
+		target, ok := targets["10.0.0.129:9406/metrics"]
+		assert.True(t, ok, "Missing target: 10.0.0.129:9406/metrics")
+		assert.Equal(t, "ExampleCluster", target.Labels["TaskClusterName"])
+	}
+
+	// The cluster of the task ARN is kept over the configured one.
+	fullTask := buildWorkloadFargateAwsvpc(true, true, false, "")
+	fullTask.ClusterName = "OtherCluster"
+	targets := make(map[string]*PrometheusTarget)
+	fullTask.ExporterInformation(config, dockerLabelRegex, targets)
+	assert.Equal(t, "ExampleCluster", targets["10.0.0.129:9406/metrics"].Labels["TaskClusterName"])
+}
+
 func buildWorkloadFargateAwsvpc(useNewTaskArnFormat bool, dockerLabel bool, taskDef bool, serviceName string) *DecoratedTask {
 	networkMode := ecs.NetworkModeAwsvpc
 	taskAttachmentId := "775c6c63-b5f7-4a5b-8a60-8f8295a04cda"
@@ -16,6 +16,9 @@ const (
 	AWSCLIListServices               = "AWSCLI_ListServices"
 	AWSCLIListTasks                  = "AWSCLI_ListTasks"
 	AWSCLIDescribeTasks              = "AWSCLI_DescribeTasks"
+	AWSCLIListNamespaces             = "AWSCLI_ListNamespaces"
+	AWSCLIListCloudMapServices       = "AWSCLI_ListCloudMapServices"
+	AWSCLIDiscoverInstances          = "AWSCLI_DiscoverInstances"
 	LRUCacheGetEC2MetaData           = "LRUCache_Get_EC2MetaData"
 	LRUCacheGetTaskDefinition        = "LRUCache_Get_TaskDefinition"
 	LRUCacheSizeContainerInstance    = "LRUCache_Size_ContainerInstance"
@@ -9,8 +9,13 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/aws/aws-sdk-go/service/servicediscovery/servicediscoveryiface"
 
 	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
 )
@@ -18,33 +23,55 @@ import (
 type ServiceDiscovery struct {
 	Config *ServiceDiscoveryConfig
 
-	svcEcs *ecs.ECS
-	svcEc2 *ec2.EC2
+	stats            ProcessorStats
+	clusterPipelines []*clusterPipeline
+	exportProcessors []Processor
+}
 
-	stats             ProcessorStats
-	clusterProcessors []Processor
+// clusterPipeline discovers the tasks of one ECS cluster.
+type clusterPipeline struct {
+	cluster    *TargetClusterConfig
+	processors []Processor
 }
 
 func (sd *ServiceDiscovery) init() {
-	credentialConfig := &configaws.CredentialConfig{
-		Region: sd.Config.TargetClusterRegion,
+	if sd.Config.hasECSDiscovery() {
+		for _, cluster := range sd.Config.clusters() {
+			configProvider, awsConfig := newAWSConfig(sd.Config.credentialConfig(cluster))
+			sd.initClusterProcessorPipeline(cluster, ecs.New(configProvider, awsConfig), ec2.New(configProvider, awsConfig))
+		}
+	}
+
+	var svcCloudMap servicediscoveryiface.ServiceDiscoveryAPI
+	if len(sd.Config.CloudMapServices) > 0 {
+		cluster := sd.Config.cloudMapCluster()
+		svcCloudMap = servicediscovery.New(newAWSConfig(sd.Config.credentialConfig(cluster)))
 	}
-	configProvider := credentialConfig.Credentials()
-	sd.svcEcs = ecs.New(configProvider, aws.NewConfig().WithRegion(sd.Config.TargetClusterRegion).WithMaxRetries(AwsSdkLevelRetryCount))
-	sd.svcEc2 = ec2.New(configProvider, aws.NewConfig().WithRegion(sd.Config.TargetClusterRegion).WithMaxRetries(AwsSdkLevelRetryCount))
+	sd.initExportProcessorPipeline(svcCloudMap)
+}
+
+func newAWSConfig(credentialConfig *configaws.CredentialConfig) (client.ConfigProvider, *aws.Config) {
+	return credentialConfig.Credentials(), aws.NewConfig().WithRegion(credentialConfig.Region).WithMaxRetries(AwsSdkLevelRetryCount)
+}
 
-	sd.initClusterProcessorPipeline()
+func (sd *ServiceDiscovery) initClusterProcessorPipeline(cluster *TargetClusterConfig, svcEcs ecsiface.ECSAPI, svcEc2 ec2iface.EC2API) {
+	pipeline := &clusterPipeline{cluster: cluster}
+	pipeline.processors = append(pipeline.processors, NewTaskProcessor(svcEcs, &sd.stats))
+	pipeline.processors = append(pipeline.processors, NewTaskDefinitionProcessor(svcEcs, &sd.stats))
+	pipeline.processors = append(pipeline.processors, NewServiceEndpointDiscoveryProcessor(svcEcs, sd.Config.ServiceNamesForTasks, &sd.stats))
+	pipeline.processors = append(pipeline.processors, NewDockerLabelDiscoveryProcessor(sd.Config.DockerLabel))
+	pipeline.processors = append(pipeline.processors, NewTaskDefinitionDiscoveryProcessor(sd.Config.TaskDefinitions))
+	pipeline.processors = append(pipeline.processors, NewTaskFilterProcessor())
+	pipeline.processors = append(pipeline.processors, NewContainerInstanceProcessor(svcEcs, svcEc2, &sd.stats))
+	sd.clusterPipelines = append(sd.clusterPipelines, pipeline)
 }
 
-func (sd *ServiceDiscovery) initClusterProcessorPipeline() {
-	sd.clusterProcessors = append(sd.clusterProcessors, NewTaskProcessor(sd.svcEcs, &sd.stats))
-	sd.clusterProcessors = append(sd.clusterProcessors, NewTaskDefinitionProcessor(sd.svcEcs, &sd.stats))
-	sd.clusterProcessors = append(sd.clusterProcessors, NewServiceEndpointDiscoveryProcessor(sd.svcEcs, sd.Config.ServiceNamesForTasks, &sd.stats))
-	sd.clusterProcessors = append(sd.clusterProcessors, NewDockerLabelDiscoveryProcessor(sd.Config.DockerLabel))
-	sd.clusterProcessors = append(sd.clusterProcessors, NewTaskDefinitionDiscoveryProcessor(sd.Config.TaskDefinitions))
-	sd.clusterProcessors = append(sd.clusterProcessors, NewTaskFilterProcessor())
-	sd.clusterProcessors = append(sd.clusterProcessors, NewContainerInstanceProcessor(sd.svcEcs, sd.svcEc2, &sd.stats))
-	sd.clusterProcessors = append(sd.clusterProcessors, NewTargetsExportProcessor(sd.Config, &sd.stats))
+// initExportProcessorPipeline initializes the processors of the tasks discovered in all the clusters.
+func (sd *ServiceDiscovery) initExportProcessorPipeline(svcCloudMap servicediscoveryiface.ServiceDiscoveryAPI) {
+	if len(sd.Config.CloudMapServices) > 0 {
+		sd.exportProcessors = append(sd.exportProcessors, NewCloudMapDiscoveryProcessor(svcCloudMap, sd.Config.CloudMapServices, &sd.stats))
+	}
+	sd.exportProcessors = append(sd.exportProcessors, NewTargetsExportProcessor(sd.Config, &sd.stats))
 }
 
 func StartECSServiceDiscovery(sd *ServiceDiscovery, shutDownChan chan interface{}, wg *sync.WaitGroup) {
@@ -70,11 +97,23 @@ func StartECSServiceDiscovery(sd *ServiceDiscovery, shutDownChan chan interface{
 
 func (sd *ServiceDiscovery) work() {
 	sd.stats.ResetStats()
+	var tasks []*DecoratedTask
+	for _, pipeline := range sd.clusterPipelines {
+		var err error
+		var clusterTasks []*DecoratedTask
+		for _, p := range pipeline.processors {
+			clusterTasks, err = p.Process(pipeline.cluster.TargetCluster, clusterTasks)
+			// Ignore partial result to avoid overwriting existing targets
+			if err != nil {
+				log.Printf("E! ECS SD processor: %v got error for cluster %v: %v \n", p.ProcessorName(), pipeline.cluster.TargetCluster, err.Error())
+				return
+			}
+		}
+		tasks = append(tasks, clusterTasks...)
+	}
 	var err error
-	var clusterTasks []*DecoratedTask
-	for _, p := range sd.clusterProcessors {
-		clusterTasks, err = p.Process(sd.Config.TargetCluster, clusterTasks)
-		// Ignore partial result to avoid overwriting existing targets
+	for _, p := range sd.exportProcessors {
+		tasks, err = p.Process("", tasks)
 		if err != nil {
 			log.Printf("E! ECS SD processor: %v got error: %v \n", p.ProcessorName(), err.Error())
 			return
@@ -88,13 +127,27 @@ func (sd *ServiceDiscovery) validateConfig() bool {
 		return false
 	}
 
-	if sd.Config.DockerLabel == nil && len(sd.Config.TaskDefinitions) == 0 && len(sd.Config.ServiceNamesForTasks) == 0 {
-		log.Printf("E! Neither docker label based discovery, nor task definition based discovery, nor service name based discovery is enabled.\n")
+	if !sd.Config.hasECSDiscovery() && len(sd.Config.CloudMapServices) == 0 {
+		log.Printf("E! Neither docker label based discovery, nor task definition based discovery, nor service name based discovery, nor Cloud Map based discovery is enabled.\n")
 		return false
 	}
 
-	if sd.Config.TargetCluster == "" || sd.Config.TargetClusterRegion == "" {
-		log.Printf("E! Target ECS cluster info is not correct.\n")
+	if sd.Config.hasECSDiscovery() {
+		clusters := sd.Config.clusters()
+		if len(clusters) == 0 {
+			log.Printf("E! Target ECS cluster info is not correct.\n")
+			return false
+		}
+		for _, cluster := range clusters {
+			if cluster.TargetCluster == "" || cluster.TargetClusterRegion == "" {
+				log.Printf("E! Target ECS cluster info is not correct.\n")
+				return false
+			}
+		}
+	}
+
+	if len(sd.Config.CloudMapServices) > 0 && sd.Config.cloudMapCluster().TargetClusterRegion == "" {
+		log.Printf("E! Cloud Map region is not defined.\n")
 		return false
 	}
 
@@ -4,10 +4,17 @@
 package ecsservicediscovery
 
 import (
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func Test_ServiceDiscovery_InitPipelines(t *testing.T) {
@@ -16,9 +23,107 @@ func Test_ServiceDiscovery_InitPipelines(t *testing.T) {
 		TargetClusterRegion: "us-east-1",
 	}
 	p := &ServiceDiscovery{Config: &config}
-	p.initClusterProcessorPipeline()
+	p.initClusterProcessorPipeline(config.clusters()[0], &stubECS{}, nil)
+	p.initExportProcessorPipeline(nil)
 
-	assert.Equal(t, 8, len(p.clusterProcessors))
+	assert.Equal(t, 1, len(p.clusterPipelines))
+	assert.Equal(t, 7, len(p.clusterPipelines[0].processors))
+	assert.Equal(t, 1, len(p.exportProcessors))
+
+	config.CloudMapServices = []*CloudMapServiceConfig{{NamespaceName: "local", ServiceNamePattern: ".*"}}
+	p = &ServiceDiscovery{Config: &config}
+	p.initExportProcessorPipeline(&stubCloudMap{})
+	assert.Equal(t, 2, len(p.exportProcessors))
+}
+
+type stubECS struct {
+	ecsiface.ECSAPI
+	tasks           []*ecs.Task
+	taskDefinitions map[string]*ecs.TaskDefinition
+}
+
+func (s *stubECS) ListTasks(*ecs.ListTasksInput) (*ecs.ListTasksOutput, error) {
+	output := &ecs.ListTasksOutput{}
+	for _, task := range s.tasks {
+		output.TaskArns = append(output.TaskArns, task.TaskArn)
+	}
+	return output, nil
+}
+
+func (s *stubECS) DescribeTasks(*ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
+	return &ecs.DescribeTasksOutput{Tasks: s.tasks}, nil
+}
+
+func (s *stubECS) DescribeTaskDefinition(input *ecs.DescribeTaskDefinitionInput) (*ecs.DescribeTaskDefinitionOutput, error) {
+	return &ecs.DescribeTaskDefinitionOutput{TaskDefinition: s.taskDefinitions[aws.StringValue(input.TaskDefinition)]}, nil
+}
+
+func newStubFargateTask(taskArn string, ip string) *ecs.Task {
+	return &ecs.Task{
+		TaskArn:           aws.String(taskArn),
+		TaskDefinitionArn: aws.String("arn:aws:ecs:us-east-1:123456789012:task-definition/app:1"),
+		LaunchType:        aws.String(ecs.LaunchTypeFargate),
+		Attachments: []*ecs.Attachment{{
+			Type:    aws.String("ElasticNetworkInterface"),
+			Details: []*ecs.KeyValuePair{{Name: aws.String("privateIPv4Address"), Value: aws.String(ip)}},
+		}},
+	}
+}
+
+func Test_ServiceDiscovery_MultiCluster(t *testing.T) {
+	taskDefinitions := map[string]*ecs.TaskDefinition{
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/app:1": {
+			TaskDefinitionArn: aws.String("arn:aws:ecs:us-east-1:123456789012:task-definition/app:1"),
+			Family:            aws.String("app"),
+			Revision:          aws.Int64(1),
+			NetworkMode:       aws.String(ecs.NetworkModeAwsvpc),
+			ContainerDefinitions: []*ecs.ContainerDefinition{{
+				Name:         aws.String("app"),
+				PortMappings: []*ecs.PortMapping{{ContainerPort: aws.Int64(9404), HostPort: aws.Int64(9404)}},
+			}},
+		},
+	}
+	config := ServiceDiscoveryConfig{
+		ResultFile:          filepath.Join(t.TempDir(), "ecs_sd_targets.yaml"),
+		TargetCluster:       "cluster-a",
+		TargetClusterRegion: "us-east-1",
+		TargetClusters: []*TargetClusterConfig{
+			{TargetCluster: "cluster-b", TargetClusterRegion: "us-west-2", RoleARN: "arn:aws:iam::210987654321:role/monitoring"},
+		},
+		TaskDefinitions: []*TaskDefinitionConfig{
+			{TaskDefArnPattern: ".*:task-definition/app:[0-9]+", MetricsPorts: "9404", JobName: "app"},
+		},
+		CloudMapServices: []*CloudMapServiceConfig{
+			{NamespaceName: "local", ServiceNamePattern: "^api$", MetricsPath: "/stats"},
+		},
+	}
+	p := &ServiceDiscovery{Config: &config}
+	p.initClusterProcessorPipeline(config.clusters()[0], &stubECS{
+		tasks:           []*ecs.Task{newStubFargateTask("arn:aws:ecs:us-east-1:123456789012:task/cluster-a/1111", "10.0.0.1")},
+		taskDefinitions: taskDefinitions,
+	}, nil)
+	// the old task ARN format does not contain the cluster name
+	p.initClusterProcessorPipeline(config.clusters()[1], &stubECS{
+		tasks:           []*ecs.Task{newStubFargateTask("arn:aws:ecs:us-west-2:210987654321:task/2222", "10.1.0.1")},
+		taskDefinitions: taskDefinitions,
+	}, nil)
+	p.initExportProcessorPipeline(newStubCloudMap())
+	p.work()
+
+	content, err := os.ReadFile(config.ResultFile)
+	require.NoError(t, err)
+	var targets []*PrometheusTarget
+	require.NoError(t, yaml.Unmarshal(content, &targets))
+	clusterByTarget := make(map[string]string)
+	for _, target := range targets {
+		require.Len(t, target.Targets, 1)
+		clusterByTarget[target.Targets[0]] = target.Labels[taskClusterNameLabel]
+	}
+	assert.Equal(t, map[string]string{
+		"10.0.0.1:9404": "cluster-a",
+		"10.1.0.1:9404": "cluster-b",
+		"10.2.0.1:8080": "cluster-c",
+	}, clusterByTarget)
 }
 
 func Test_StartECSServiceDiscovery_NilConfig(t *testing.T) {
@@ -26,7 +131,7 @@ func Test_StartECSServiceDiscovery_NilConfig(t *testing.T) {
 	p := &ServiceDiscovery{}
 	wg.Add(1)
 	StartECSServiceDiscovery(p, nil, &wg)
-	assert.Equal(t, 0, len(p.clusterProcessors))
+	assert.Equal(t, 0, len(p.clusterPipelines))
 }
 
 func Test_StartECSServiceDiscovery_NoServiceDiscovery(t *testing.T) {
@@ -38,7 +143,7 @@ func Test_StartECSServiceDiscovery_NoServiceDiscovery(t *testing.T) {
 	p := &ServiceDiscovery{Config: &config}
 	wg.Add(1)
 	StartECSServiceDiscovery(p, nil, &wg)
-	assert.Equal(t, 0, len(p.clusterProcessors))
+	assert.Equal(t, 0, len(p.clusterPipelines))
 }
 
 func Test_StartECSServiceDiscovery_BadFrequency(t *testing.T) {
@@ -54,7 +159,7 @@ func Test_StartECSServiceDiscovery_BadFrequency(t *testing.T) {
 	p := &ServiceDiscovery{Config: &config}
 	wg.Add(1)
 	StartECSServiceDiscovery(p, nil, &wg)
-	assert.Equal(t, 0, len(p.clusterProcessors))
+	assert.Equal(t, 0, len(p.clusterPipelines))
 }
 
 func Test_StartECSServiceDiscovery_BadClusterConfig(t *testing.T) {
@@ -68,5 +173,32 @@ func Test_StartECSServiceDiscovery_BadClusterConfig(t *testing.T) {
 	p := &ServiceDiscovery{Config: &config}
 	wg.Add(1)
 	StartECSServiceDiscovery(p, nil, &wg)
-	assert.Equal(t, 0, len(p.clusterProcessors))
+	assert.Equal(t, 0, len(p.clusterPipelines))
+}
+
+func Test_StartECSServiceDiscovery_BadTargetClustersConfig(t *testing.T) {
+	var wg sync.WaitGroup
+	config := ServiceDiscoveryConfig{
+		Frequency:      "1s",
+		TargetClusters: []*TargetClusterConfig{{TargetCluster: "test"}},
+		DockerLabel: &DockerLabelConfig{
+			PortLabel: "TARGET_LABEL",
+		},
+	}
+	p := &ServiceDiscovery{Config: &config}
+	wg.Add(1)
+	StartECSServiceDiscovery(p, nil, &wg)
+	assert.Equal(t, 0, len(p.clusterPipelines))
+}
+
+func Test_ServiceDiscovery_ValidateCloudMapOnlyConfig(t *testing.T) {
+	config := ServiceDiscoveryConfig{
+		Frequency:        "1s",
+		CloudMapServices: []*CloudMapServiceConfig{{NamespaceName: "local", ServiceNamePattern: ".*"}},
+	}
+	p := &ServiceDiscovery{Config: &config}
+	assert.False(t, p.validateConfig())
+
+	config.TargetClusterRegion = "us-east-1"
+	assert.True(t, p.validateConfig())
 }
@@ -8,12 +8,13 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 )
 
 // Tag the Tasks that match the Service Name based Service Discovery
 type ServiceEndpointDiscoveryProcessor struct {
 	serviceNamesForTasksConfig []*ServiceNameForTasksConfig
-	svcEcs                     *ecs.ECS
+	svcEcs                     ecsiface.ECSAPI
 	stats                      *ProcessorStats
 }
 
@@ -24,7 +25,7 @@ func minInt(a int, b int) int {
 	return b
 }
 
-func NewServiceEndpointDiscoveryProcessor(ecs *ecs.ECS, serviceNamesForTasks []*ServiceNameForTasksConfig, s *ProcessorStats) *ServiceEndpointDiscoveryProcessor {
+func NewServiceEndpointDiscoveryProcessor(ecs ecsiface.ECSAPI, serviceNamesForTasks []*ServiceNameForTasksConfig, s *ProcessorStats) *ServiceEndpointDiscoveryProcessor {
 	for _, v := range serviceNamesForTasks {
 		v.init()
 	}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 	"github.com/hashicorp/golang-lru/simplelru"
 )
 
@@ -18,13 +19,13 @@ const (
 
 // Decorate the tasks with the ECS task definition
 type TaskDefinitionProcessor struct {
-	svcEcs *ecs.ECS
+	svcEcs ecsiface.ECSAPI
 	stats  *ProcessorStats
 
 	taskDefCache *simplelru.LRU
 }
 
-func NewTaskDefinitionProcessor(ecs *ecs.ECS, s *ProcessorStats) *TaskDefinitionProcessor {
+func NewTaskDefinitionProcessor(ecs ecsiface.ECSAPI, s *ProcessorStats) *TaskDefinitionProcessor {
 	p := &TaskDefinitionProcessor{
 		svcEcs: ecs,
 		stats:  s,
@@ -7,15 +7,16 @@ import (
 	"log"
 
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 )
 
 // Get all running tasks for the target cluster
 type TaskProcessor struct {
-	svcEcs *ecs.ECS
+	svcEcs ecsiface.ECSAPI
 	stats  *ProcessorStats
 }
 
-func NewTaskProcessor(ecs *ecs.ECS, s *ProcessorStats) *TaskProcessor {
+func NewTaskProcessor(ecs ecsiface.ECSAPI, s *ProcessorStats) *TaskProcessor {
 	return &TaskProcessor{
 		svcEcs: ecs,
 		stats:  s,
@@ -42,7 +43,7 @@ func (p *TaskProcessor) Process(cluster string, taskList []*DecoratedTask) ([]*D
 		}
 
 		for i := 0; i < len(descTaskResp.Tasks); i++ {
-			taskList = append(taskList, &DecoratedTask{Task: descTaskResp.Tasks[i], TaskDefinition: nil, EC2Info: nil})
+			taskList = append(taskList, &DecoratedTask{Task: descTaskResp.Tasks[i], TaskDefinition: nil, EC2Info: nil, ClusterName: cluster})
 		}
 
 		if listTaskResp.NextToken == nil {
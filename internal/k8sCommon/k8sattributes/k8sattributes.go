@@ -0,0 +1,95 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sattributes
+
+import (
+	"strings"
+)
+
+const (
+	// CloudWatch limits, see https://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_Dimension.html
+	maxNameLength  = 255
+	maxValueLength = 1024
+)
+
+// Config is the allow-list of pod labels, pod annotations and namespace labels which are
+// promoted to metric attributes/dimensions. Keys are matched exactly, e.g. "team" or
+// "app.kubernetes.io/version", and are sanitised with SanitizeName to build the attribute name.
+type Config struct {
+	PodLabels       []string `toml:"pod_labels" mapstructure:"pod_labels,omitempty"`
+	PodAnnotations  []string `toml:"pod_annotations" mapstructure:"pod_annotations,omitempty"`
+	NamespaceLabels []string `toml:"namespace_labels" mapstructure:"namespace_labels,omitempty"`
+}
+
+func (c *Config) IsEmpty() bool {
+	return c == nil || (len(c.PodLabels) == 0 && len(c.PodAnnotations) == 0 && len(c.NamespaceLabels) == 0)
+}
+
+// Names returns the de-duplicated sanitised attribute names in the order of the allow-lists.
+func (c *Config) Names() []string {
+	if c.IsEmpty() {
+		return nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, keys := range [][]string{c.PodLabels, c.PodAnnotations, c.NamespaceLabels} {
+		for _, key := range keys {
+			name := SanitizeName(key)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Promote returns the allow-listed values keyed by their sanitised name. When the same name is
+// found in several sources the most specific one wins: pod labels, then pod annotations, then
+// namespace labels. Empty values are dropped as CloudWatch rejects empty dimension values.
+func (c *Config) Promote(podLabels, podAnnotations, namespaceLabels map[string]string) map[string]string {
+	if c.IsEmpty() {
+		return nil
+	}
+	attributes := make(map[string]string)
+	copyAllowed(attributes, c.NamespaceLabels, namespaceLabels)
+	copyAllowed(attributes, c.PodAnnotations, podAnnotations)
+	copyAllowed(attributes, c.PodLabels, podLabels)
+	return attributes
+}
+
+func copyAllowed(attributes map[string]string, keys []string, source map[string]string) {
+	for _, key := range keys {
+		value, ok := source[key]
+		name := SanitizeName(key)
+		if !ok || value == "" || name == "" {
+			continue
+		}
+		if len(value) > maxValueLength {
+			value = value[:maxValueLength]
+		}
+		attributes[name] = value
+	}
+}
+
+// SanitizeName turns a label or annotation key into an attribute name by replacing every character
+// other than ASCII letters, digits and underscores with an underscore,
+// e.g. "app.kubernetes.io/version" becomes "app_kubernetes_io_version".
+func SanitizeName(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	name := b.String()
+	if len(name) > maxNameLength {
+		name = name[:maxNameLength]
+	}
+	return name
+}
@@ -0,0 +1,57 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sattributes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeName(t *testing.T) {
+	assert.Equal(t, "team", SanitizeName("team"))
+	assert.Equal(t, "app_kubernetes_io_version", SanitizeName("app.kubernetes.io/version"))
+	assert.Equal(t, "cost_center", SanitizeName("cost-center"))
+	assert.Equal(t, 255, len(SanitizeName(strings.Repeat("a", 300))))
+}
+
+func TestConfig_IsEmpty(t *testing.T) {
+	var c *Config
+	assert.True(t, c.IsEmpty())
+	assert.True(t, (&Config{}).IsEmpty())
+	assert.False(t, (&Config{NamespaceLabels: []string{"team"}}).IsEmpty())
+}
+
+func TestConfig_Names(t *testing.T) {
+	c := &Config{
+		PodLabels:       []string{"team", "app.kubernetes.io/version"},
+		PodAnnotations:  []string{"app.kubernetes.io/version", "cost-center"},
+		NamespaceLabels: []string{"team"},
+	}
+	assert.Equal(t, []string{"team", "app_kubernetes_io_version", "cost_center"}, c.Names())
+}
+
+func TestConfig_Promote(t *testing.T) {
+	c := &Config{
+		PodLabels:       []string{"team", "app.kubernetes.io/version"},
+		PodAnnotations:  []string{"cost-center", "team"},
+		NamespaceLabels: []string{"team", "env"},
+	}
+	attributes := c.Promote(
+		map[string]string{"app.kubernetes.io/version": "1.2.0", "ignored": "value"},
+		map[string]string{"cost-center": "", "team": "annotated"},
+		map[string]string{"team": "platform", "env": strings.Repeat("v", 2000)},
+	)
+	assert.Equal(t, 3, len(attributes))
+	// pod annotations take precedence over namespace labels, empty values are dropped
+	assert.Equal(t, "annotated", attributes["team"])
+	assert.Equal(t, "1.2.0", attributes["app_kubernetes_io_version"])
+	assert.Equal(t, 1024, len(attributes["env"]))
+
+	attributes = c.Promote(map[string]string{"team": "payments"}, nil, map[string]string{"team": "platform"})
+	assert.Equal(t, map[string]string{"team": "payments"}, attributes)
+
+	assert.Nil(t, (&Config{}).Promote(map[string]string{"team": "payments"}, nil, nil))
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -16,6 +17,8 @@ type ObjStore struct {
 
 	refreshed bool
 	objs      map[types.UID]interface{}
+	// keys indexes the objs by their namespace/name key.
+	keys map[string]types.UID
 
 	transformFunc func(interface{}) (interface{}, error)
 }
@@ -24,9 +27,18 @@ func NewObjStore(transformFunc func(interface{}) (interface{}, error)) *ObjStore
 	return &ObjStore{
 		transformFunc: transformFunc,
 		objs:          map[types.UID]interface{}{},
+		keys:          map[string]types.UID{},
 	}
 }
 
+// objKey returns the namespace/name key of the object, or the name if it is not namespaced.
+func objKey(o metav1.Object) string {
+	if o.GetNamespace() == "" {
+		return o.GetName()
+	}
+	return o.GetNamespace() + "/" + o.GetName()
+}
+
 // Track whether the underlying data store is refreshed or not.
 // Calling this func itself will reset the state to false.
 func (s *ObjStore) Refreshed() bool {
@@ -57,6 +69,7 @@ func (s *ObjStore) Add(obj interface{}) error {
 	defer s.Unlock()
 
 	s.objs[o.GetUID()] = toCacheObj
+	s.keys[objKey(o)] = o.GetUID()
 	s.refreshed = true
 
 	return nil
@@ -79,6 +92,9 @@ func (s *ObjStore) Delete(obj interface{}) error {
 	defer s.Unlock()
 
 	delete(s.objs, o.GetUID())
+	if key := objKey(o); s.keys[key] == o.GetUID() {
+		delete(s.keys, key)
+	}
 
 	s.refreshed = true
 
@@ -114,9 +130,18 @@ func (s *ObjStore) Get(obj interface{}) (item interface{}, exists bool, err erro
 	return nil, false, nil
 }
 
-// GetByKey implements the GetByKey method of the store interface.
+// GetByKey implements the GetByKey method of the store interface. The key is the
+// namespace/name of the object, or its name if it is not namespaced.
 func (s *ObjStore) GetByKey(key string) (item interface{}, exists bool, err error) {
-	return nil, false, nil
+	s.RLock()
+	defer s.RUnlock()
+
+	uid, ok := s.keys[key]
+	if !ok {
+		return nil, false, nil
+	}
+	item, exists = s.objs[uid]
+	return item, exists, nil
 }
 
 // Replace will delete the contents of the store, using instead the
@@ -124,6 +149,7 @@ func (s *ObjStore) GetByKey(key string) (item interface{}, exists bool, err erro
 func (s *ObjStore) Replace(list []interface{}, _ string) error {
 	s.Lock()
 	s.objs = map[types.UID]interface{}{}
+	s.keys = map[string]types.UID{}
 	s.Unlock()
 
 	for _, o := range list {
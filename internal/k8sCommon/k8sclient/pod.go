@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,8 +21,38 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
+const (
+	// AnnotationPrefix is the prefix of the pod annotations configuring the collection of the pod
+	// metrics and logs, e.g. cloudwatch.aws/scrape-port.
+	AnnotationPrefix = "cloudwatch.aws/"
+
+	// AnnotationScrapePort is the semicolon separated ports the Prometheus metrics are scraped from.
+	AnnotationScrapePort = AnnotationPrefix + "scrape-port"
+	// AnnotationScrapePath is the Prometheus metrics path, default to /metrics.
+	AnnotationScrapePath = AnnotationPrefix + "scrape-path"
+	// AnnotationScrapeJob is the Prometheus scrape job name.
+	AnnotationScrapeJob = AnnotationPrefix + "scrape-job"
+	// AnnotationLogGroup is the log group the container logs of the pod are published to.
+	AnnotationLogGroup = AnnotationPrefix + "log-group"
+	// AnnotationMultilinePattern is the regex matching the first line of the multiline log events of the pod.
+	AnnotationMultilinePattern = AnnotationPrefix + "multiline-pattern"
+)
+
+// AnnotatedPod is a pod with annotations prefixed by AnnotationPrefix.
+type AnnotatedPod struct {
+	Namespace   string
+	Name        string
+	NodeName    string
+	PodIP       string
+	Annotations map[string]string
+}
+
 type PodClient interface {
 	NamespaceToRunningPodNum() map[string]int
+	// AnnotatedPods returns the running pods with annotations prefixed by AnnotationPrefix.
+	AnnotatedPods() []*AnnotatedPod
+	// AnnotatedPod returns the pod if it has annotations prefixed by AnnotationPrefix, nil otherwise.
+	AnnotatedPod(namespace string, name string) *AnnotatedPod
 
 	Init()
 	Shutdown()
@@ -52,6 +83,44 @@ func (c *podClient) NamespaceToRunningPodNum() map[string]int {
 	return c.namespaceToRunningPodNumMap
 }
 
+func (c *podClient) AnnotatedPods() []*AnnotatedPod {
+	if !c.inited {
+		c.Init()
+	}
+	var pods []*AnnotatedPod
+	for _, obj := range c.store.List() {
+		pod := obj.(*podInfo)
+		if pod.phase == v1.PodRunning && len(pod.annotations) > 0 {
+			pods = append(pods, pod.annotatedPod())
+		}
+	}
+	return pods
+}
+
+func (c *podClient) AnnotatedPod(namespace string, name string) *AnnotatedPod {
+	if !c.inited {
+		c.Init()
+	}
+	obj, ok, _ := c.store.GetByKey(namespace + "/" + name)
+	if !ok {
+		return nil
+	}
+	if pod := obj.(*podInfo); len(pod.annotations) > 0 {
+		return pod.annotatedPod()
+	}
+	return nil
+}
+
+func (p *podInfo) annotatedPod() *AnnotatedPod {
+	return &AnnotatedPod{
+		Namespace:   p.namespace,
+		Name:        p.name,
+		NodeName:    p.nodeName,
+		PodIP:       p.podIP,
+		Annotations: p.annotations,
+	}
+}
+
 func (c *podClient) refresh() {
 	c.Lock()
 	defer c.Unlock()
@@ -114,7 +183,18 @@ func transformFuncPod(obj interface{}) (interface{}, error) {
 	}
 	info := new(podInfo)
 	info.namespace = pod.Namespace
+	info.name = pod.Name
+	info.nodeName = pod.Spec.NodeName
+	info.podIP = pod.Status.PodIP
 	info.phase = pod.Status.Phase
+	for k, v := range pod.Annotations {
+		if strings.HasPrefix(k, AnnotationPrefix) {
+			if info.annotations == nil {
+				info.annotations = make(map[string]string)
+			}
+			info.annotations[k] = v
+		}
+	}
 	return info, nil
 }
 
@@ -9,5 +9,10 @@ import (
 
 type podInfo struct {
 	namespace string
+	name      string
+	nodeName  string
+	podIP     string
 	phase     v1.PodPhase
+	// only the annotations with the AnnotationPrefix are kept
+	annotations map[string]string
 }
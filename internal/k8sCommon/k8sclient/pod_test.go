@@ -183,3 +183,53 @@ func TestPodClient_NamespaceToRunningPodNum(t *testing.T) {
 	log.Printf("NamespaceToRunningPodNum (len=%v): %v", len(resultMap), awsutil.Prettify(resultMap))
 	assert.DeepEqual(t, resultMap, expectedMap)
 }
+
+func TestPodClient_AnnotatedPods(t *testing.T) {
+	client, stopChan := setUpPodClient()
+	defer close(stopChan)
+
+	annotatedPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "0f1a4b7e-6a1e-4d36-9b8a-4e3c1f0f2a11",
+			Name:      "guestbook-annotated",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationScrapePort:      "9404",
+				AnnotationLogGroup:        "/apps/guestbook",
+				"kubernetes.io/psp":       "eks.privileged",
+				"cloudwatch.aws.com/else": "ignored",
+			},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "ip-192-168-1-1.ec2.internal",
+		},
+		Status: v1.PodStatus{
+			Phase: "Running",
+			PodIP: "192.168.1.10",
+		},
+	}
+	pendingPod := annotatedPod.DeepCopy()
+	pendingPod.UID = "6c2d8f3e-5b7a-4e0f-8a1d-2b3c4d5e6f70"
+	pendingPod.Name = "guestbook-pending"
+	pendingPod.Status.Phase = "Pending"
+	client.store.Replace(append([]interface{}{annotatedPod, pendingPod}, podArray...), "")
+
+	expected := &AnnotatedPod{
+		Namespace: "default",
+		Name:      "guestbook-annotated",
+		NodeName:  "ip-192-168-1-1.ec2.internal",
+		PodIP:     "192.168.1.10",
+		Annotations: map[string]string{
+			AnnotationScrapePort: "9404",
+			AnnotationLogGroup:   "/apps/guestbook",
+		},
+	}
+	assert.DeepEqual(t, client.AnnotatedPods(), []*AnnotatedPod{expected})
+	assert.DeepEqual(t, client.AnnotatedPod("default", "guestbook-annotated"), expected)
+	assert.Assert(t, client.AnnotatedPod("default", "guestbook-pending") != nil)
+	assert.Assert(t, client.AnnotatedPod("default", "guestbook-qbdv8") == nil)
+	assert.Assert(t, client.AnnotatedPod("kube-system", "guestbook-annotated") == nil)
+
+	client.store.Delete(annotatedPod)
+	assert.Assert(t, client.AnnotatedPod("default", "guestbook-annotated") == nil)
+}
@@ -0,0 +1,174 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sannotationdiscovery
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-cloudwatch-agent/cfg/envconfig"
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+)
+
+const (
+	portSeparator = ";"
+
+	namespaceLabel   = "Namespace"
+	podNameLabel     = "PodName"
+	jobNameLabel     = "job"
+	metricsPathLabel = "__metrics_path__"
+
+	//https://prometheus.io/docs/prometheus/latest/configuration/configuration/#scrape_config
+	defaultPrometheusMetricsPath = "/metrics"
+)
+
+type ServiceDiscoveryConfig struct {
+	Frequency  string `toml:"sd_frequency"`
+	ResultFile string `toml:"sd_result_file"`
+}
+
+type PrometheusTarget struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+// ServiceDiscovery exports the Prometheus targets of the pods annotated with cloudwatch.aws/scrape-port
+// running on the node of the agent into the file_sd file configured by sd_result_file.
+type ServiceDiscovery struct {
+	Config *ServiceDiscoveryConfig
+
+	// nodeName is the node the pods are discovered on, all the nodes if empty
+	nodeName string
+	pods     func() []*k8sclient.AnnotatedPod
+}
+
+func (sd *ServiceDiscovery) init() {
+	sd.nodeName = os.Getenv(envconfig.HostName)
+	sd.pods = func() []*k8sclient.AnnotatedPod {
+		podClient := k8sclient.Get().Pod
+		if podClient == nil {
+			return nil
+		}
+		return podClient.AnnotatedPods()
+	}
+}
+
+func StartK8sAnnotationDiscovery(sd *ServiceDiscovery, shutDownChan chan interface{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if !sd.validateConfig() {
+		return
+	}
+
+	frequency, _ := time.ParseDuration(sd.Config.Frequency)
+	sd.init()
+	t := time.NewTicker(frequency)
+	defer t.Stop()
+	for {
+		select {
+		case <-shutDownChan:
+			return
+		case <-t.C:
+			if err := sd.work(); err != nil {
+				log.Printf("E! Kubernetes annotation discovery got error: %v", err)
+			}
+		}
+	}
+}
+
+func (sd *ServiceDiscovery) work() error {
+	// Dedup Key for Targets: target + metricsPath
+	targets := make(map[string]*PrometheusTarget)
+	for _, pod := range sd.pods() {
+		if sd.nodeName != "" && pod.NodeName != sd.nodeName {
+			continue
+		}
+		addPodTargets(pod, targets)
+	}
+
+	keys := make([]string, 0, len(targets))
+	for k := range targets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	targetsArr := make([]*PrometheusTarget, 0, len(targets))
+	for _, k := range keys {
+		targetsArr = append(targetsArr, targets[k])
+	}
+
+	m, err := yaml.Marshal(targetsArr)
+	if err != nil {
+		return fmt.Errorf("fail to marshal Prometheus targets: %w", err)
+	}
+	tmpResultFilePath := sd.Config.ResultFile + "_temp"
+	if err = os.WriteFile(tmpResultFilePath, m, 0644); err != nil {
+		return fmt.Errorf("fail to write Prometheus targets into file %v: %w", tmpResultFilePath, err)
+	}
+	if err = os.Rename(tmpResultFilePath, sd.Config.ResultFile); err != nil {
+		os.Remove(tmpResultFilePath)
+		return fmt.Errorf("fail to rename tmp result file %v to %v: %w", tmpResultFilePath, sd.Config.ResultFile, err)
+	}
+	return nil
+}
+
+func addPodTargets(pod *k8sclient.AnnotatedPod, targets map[string]*PrometheusTarget) {
+	portsAnnotation, ok := pod.Annotations[k8sclient.AnnotationScrapePort]
+	if !ok || pod.PodIP == "" {
+		return
+	}
+	metricsPath := pod.Annotations[k8sclient.AnnotationScrapePath]
+	if metricsPath == "" {
+		metricsPath = defaultPrometheusMetricsPath
+	}
+	for _, v := range strings.Split(portsAnnotation, portSeparator) {
+		port, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || port <= 0 || port > 65535 {
+			log.Printf("W! Invalid %v annotation %q of pod %v/%v", k8sclient.AnnotationScrapePort, portsAnnotation, pod.Namespace, pod.Name)
+			continue
+		}
+		target := fmt.Sprintf("%s:%d", pod.PodIP, port)
+		if _, ok := targets[target+metricsPath]; ok {
+			continue
+		}
+		labels := map[string]string{
+			namespaceLabel:   pod.Namespace,
+			podNameLabel:     pod.Name,
+			metricsPathLabel: metricsPath,
+		}
+		if job := pod.Annotations[k8sclient.AnnotationScrapeJob]; job != "" {
+			labels[jobNameLabel] = job
+		}
+		targets[target+metricsPath] = &PrometheusTarget{
+			Targets: []string{target},
+			Labels:  labels,
+		}
+	}
+}
+
+func (sd *ServiceDiscovery) validateConfig() bool {
+	if sd.Config == nil {
+		return false
+	}
+
+	if sd.Config.ResultFile == "" {
+		log.Printf("E! Kubernetes annotation discovery result file is not defined.\n")
+		return false
+	}
+
+	_, err := time.ParseDuration(sd.Config.Frequency)
+	if err != nil {
+		log.Printf("E! Invalid Kubernetes annotation discovery frequency: %v.\n", sd.Config.Frequency)
+		return false
+	}
+
+	return true
+}
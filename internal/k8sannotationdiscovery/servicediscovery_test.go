@@ -0,0 +1,103 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sannotationdiscovery
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+)
+
+func TestServiceDiscovery_Work(t *testing.T) {
+	sd := &ServiceDiscovery{
+		Config:   &ServiceDiscoveryConfig{Frequency: "1m", ResultFile: filepath.Join(t.TempDir(), "k8s_sd_targets.yaml")},
+		nodeName: "node-1",
+		pods: func() []*k8sclient.AnnotatedPod {
+			return []*k8sclient.AnnotatedPod{
+				{
+					Namespace: "default",
+					Name:      "app",
+					NodeName:  "node-1",
+					PodIP:     "10.0.0.1",
+					Annotations: map[string]string{
+						k8sclient.AnnotationScrapePort: "9404; 9405;invalid",
+						k8sclient.AnnotationScrapePath: "/stats",
+						k8sclient.AnnotationScrapeJob:  "app",
+					},
+				},
+				{
+					// annotated for the logs only
+					Namespace:   "default",
+					Name:        "logs-only",
+					NodeName:    "node-1",
+					PodIP:       "10.0.0.2",
+					Annotations: map[string]string{k8sclient.AnnotationLogGroup: "/apps/logs-only"},
+				},
+				{
+					// scraped by the agent of the other node
+					Namespace:   "default",
+					Name:        "other-node",
+					NodeName:    "node-2",
+					PodIP:       "10.0.1.1",
+					Annotations: map[string]string{k8sclient.AnnotationScrapePort: "9404"},
+				},
+				{
+					Namespace:   "kube-system",
+					Name:        "no-ip-yet",
+					NodeName:    "node-1",
+					Annotations: map[string]string{k8sclient.AnnotationScrapePort: "9404"},
+				},
+			}
+		},
+	}
+	require.NoError(t, sd.work())
+
+	content, err := os.ReadFile(sd.Config.ResultFile)
+	require.NoError(t, err)
+	var targets []*PrometheusTarget
+	require.NoError(t, yaml.Unmarshal(content, &targets))
+	labels := map[string]string{
+		namespaceLabel:   "default",
+		podNameLabel:     "app",
+		metricsPathLabel: "/stats",
+		jobNameLabel:     "app",
+	}
+	assert.Equal(t, []*PrometheusTarget{
+		{Targets: []string{"10.0.0.1:9404"}, Labels: labels},
+		{Targets: []string{"10.0.0.1:9405"}, Labels: labels},
+	}, targets)
+
+	sd.nodeName = ""
+	require.NoError(t, sd.work())
+	content, err = os.ReadFile(sd.Config.ResultFile)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(content, &targets))
+	assert.Len(t, targets, 3)
+	assert.Equal(t, map[string]string{
+		namespaceLabel:   "default",
+		podNameLabel:     "other-node",
+		metricsPathLabel: defaultPrometheusMetricsPath,
+	}, targets[2].Labels)
+}
+
+func TestStartK8sAnnotationDiscovery_BadConfig(t *testing.T) {
+	for _, config := range []*ServiceDiscoveryConfig{
+		nil,
+		{Frequency: "1m"},
+		{Frequency: "xyz", ResultFile: "/tmp/k8s_sd_targets.yaml"},
+	} {
+		var wg sync.WaitGroup
+		sd := &ServiceDiscovery{Config: config}
+		wg.Add(1)
+		StartK8sAnnotationDiscovery(sd, nil, &wg)
+		assert.Nil(t, sd.pods)
+	}
+}
@@ -13,16 +13,24 @@ import (
 type NonBlockingFifoQueue struct {
 	queue   *list.List
 	maxSize int
+	dropFn  func(value interface{})
 	sync.Mutex
 }
 
 func NewNonBlockingFifoQueue(size int) *NonBlockingFifoQueue {
+	return NewNonBlockingFifoQueueWithDropFn(size, nil)
+}
+
+// NewNonBlockingFifoQueueWithDropFn creates a queue that calls dropFn with the value dropped from the front
+// when the queue is full, so it is not lost silently.
+func NewNonBlockingFifoQueueWithDropFn(size int, dropFn func(value interface{})) *NonBlockingFifoQueue {
 	if size <= 0 {
 		log.Panic("E! Queue Size should be larger than 0!")
 	}
 	return &NonBlockingFifoQueue{
 		queue:   list.New(),
 		maxSize: size,
+		dropFn:  dropFn,
 	}
 }
 
@@ -39,11 +47,16 @@ func (u *NonBlockingFifoQueue) Dequeue() (interface{}, bool) {
 
 func (u *NonBlockingFifoQueue) Enqueue(value interface{}) {
 	u.Lock()
-	defer u.Unlock()
-
+	var dropped interface{}
 	if u.queue.Len() == u.maxSize {
 		log.Printf("W! message is dropped due to nonblocking fifo queue is full")
-		u.queue.Remove(u.queue.Front())
+		dropped = u.queue.Remove(u.queue.Front())
 	}
 	u.queue.PushBack(value)
+	u.Unlock()
+
+	// The drop function can be slow, e.g. writing to disk, so it is called outside the lock.
+	if dropped != nil && u.dropFn != nil {
+		u.dropFn(dropped)
+	}
 }
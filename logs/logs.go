@@ -7,6 +7,8 @@ import (
 	"context"
 	"errors"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -17,6 +19,10 @@ import (
 
 var ErrOutputStopped = errors.New("Output plugin stopped")
 
+// The number of events queued for each destination of a LogSrc, so a slow
+// destination does not hold back the other destinations until its queue is full.
+const destQueueSize = 100
+
 // A LogCollection is a collection of LogSrc, a plugin which can provide many LogSrc
 type LogCollection interface {
 	FindLogSrc() []LogSrc
@@ -42,6 +48,24 @@ type LogSrc interface {
 	Stop()
 }
 
+// A LogDestination is a destination a LogSrc publishes its events to, in addition
+// to its Destination.
+type LogDestination struct {
+	// Name of the LogBackend, the alias of the output plugin when set.
+	Name      string
+	Group     string
+	Stream    string
+	Retention int
+	Class     string
+}
+
+// A MultiDestLogSrc is a LogSrc publishing its events to additional destinations.
+// The events are done once all the destinations are done with them.
+type MultiDestLogSrc interface {
+	LogSrc
+	AdditionalDestinations() []LogDestination
+}
+
 // A LogBackend is able to return a LogDest of a given name.
 // The same name should always return the same LogDest.
 type LogBackend interface {
@@ -54,20 +78,29 @@ type LogDest interface {
 	Publish(events []LogEvent) error
 }
 
+// A LogEventProcessor is able to modify the log events before they are published.
+// A LogBackend implementing it processes all the events collected by the agent,
+// whatever their destination, e.g. so that redacted data never reaches any of them.
+// Returning nil drops the event.
+type LogEventProcessor interface {
+	ProcessLogEvent(LogEvent) LogEvent
+}
+
 // LogAgent is the agent handles pure log pipelines
 type LogAgent struct {
 	Config                    *config.Config
 	backends                  map[string]LogBackend
-	destNames                 map[LogDest]string
+	processors                []LogEventProcessor
 	collections               []LogCollection
 	retentionAlreadyAttempted map[string]bool
+	// Guards the creation of the destinations, which happens concurrently when events are routed
+	mu sync.Mutex
 }
 
 func NewLogAgent(c *config.Config) *LogAgent {
 	return &LogAgent{
 		Config:                    c,
 		backends:                  make(map[string]LogBackend),
-		destNames:                 make(map[LogDest]string),
 		retentionAlreadyAttempted: make(map[string]bool),
 	}
 }
@@ -88,6 +121,9 @@ func (l *LogAgent) Run(ctx context.Context) {
 			name = output.Config.Name
 		}
 		l.backends[name] = backend
+		if processor, ok := backend.(LogEventProcessor); ok {
+			l.processors = append(l.processors, processor)
+		}
 	}
 
 	for _, input := range l.Config.Inputs {
@@ -110,22 +146,26 @@ func (l *LogAgent) Run(ctx context.Context) {
 			for _, c := range l.collections {
 				srcs := c.FindLogSrc()
 				for _, src := range srcs {
-					dname := src.Destination()
-					logGroup := src.Group()
-					logStream := src.Stream()
-					description := src.Description()
-					retention := src.Retention()
-					logGroupClass := src.Class()
-					backend, ok := l.backends[dname]
-					if !ok {
-						log.Printf("E! [logagent] Failed to find destination %s for log source %s/%s(%s) ", dname, logGroup, logStream, description)
+					destinations := []LogDestination{{
+						Name:      src.Destination(),
+						Group:     src.Group(),
+						Stream:    src.Stream(),
+						Retention: src.Retention(),
+						Class:     src.Class(),
+					}}
+					if multiDestSrc, ok := src.(MultiDestLogSrc); ok {
+						destinations = append(destinations, multiDestSrc.AdditionalDestinations()...)
+					}
+					var routes []*logRoute
+					for _, d := range destinations {
+						if route := l.createRoute(src, d); route != nil {
+							routes = append(routes, route)
+						}
+					}
+					if len(routes) == 0 {
 						continue
 					}
-					retention = l.checkRetentionAlreadyAttempted(retention, logGroup)
-					dest := backend.CreateDest(logGroup, logStream, retention, logGroupClass)
-					l.destNames[dest] = dname
-					log.Printf("I! [logagent] piping log from %s/%s(%s) to %s with retention %d", logGroup, logStream, description, dname, retention)
-					go l.runSrcToDest(src, dest)
+					go l.runSrcToDests(src, routes)
 				}
 			}
 		case <-ctx.Done():
@@ -134,30 +174,183 @@ func (l *LogAgent) Run(ctx context.Context) {
 	}
 }
 
-func (l *LogAgent) runSrcToDest(src LogSrc, dest LogDest) {
-	eventsCh := make(chan LogEvent)
-	defer src.Stop()
+// A logRoute pipes the events of a LogSrc to one of its destinations.
+type logRoute struct {
+	name     string
+	dest     LogDest
+	eventsCh chan LogEvent
+}
 
+func (l *LogAgent) createRoute(src LogSrc, d LogDestination) *logRoute {
+	backend, ok := l.backends[d.Name]
+	if !ok {
+		log.Printf("E! [logagent] Failed to find destination %s for log source %s/%s(%s) ", d.Name, d.Group, d.Stream, src.Description())
+		return nil
+	}
+	var dest LogDest
+	if hasEventFieldPlaceholders(d.Group) || hasEventFieldPlaceholders(d.Stream) {
+		dest = newRoutingDest(l, backend, d)
+		log.Printf("I! [logagent] routing log from %s/%s(%s) to %s based on the log events", d.Group, d.Stream, src.Description(), d.Name)
+	} else {
+		dest = l.createDest(backend, d)
+		log.Printf("I! [logagent] piping log from %s/%s(%s) to %s with retention %d", d.Group, d.Stream, src.Description(), d.Name, d.Retention)
+	}
+	return &logRoute{name: d.Name, dest: dest}
+}
+
+// createDest creates the destination in the backend, setting the retention of the
+// log group only the first time it is seen.
+func (l *LogAgent) createDest(backend LogBackend, d LogDestination) LogDest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	retention := l.checkRetentionAlreadyAttempted(d.Retention, d.Group)
+	return backend.CreateDest(d.Group, d.Stream, retention, d.Class)
+}
+
+// runSrcToDests publishes the events of the source to each destination from its
+// own goroutine. The source is stopped as soon as one of the destinations stops,
+// and the events are only done once all the destinations are done with them, so
+// the source does not move past events that were not published everywhere.
+func (l *LogAgent) runSrcToDests(src LogSrc, routes []*logRoute) {
+	var stopOnce sync.Once
+	stopSrc := func() { stopOnce.Do(src.Stop) }
+	defer stopSrc()
+
+	var wg sync.WaitGroup
+	for _, route := range routes {
+		route.eventsCh = make(chan LogEvent, destQueueSize)
+		wg.Add(1)
+		go func(route *logRoute) {
+			defer wg.Done()
+			if !l.publishToDest(src, route) {
+				stopSrc()
+				// Keep draining so the other destinations are not blocked until the source stops.
+				for range route.eventsCh {
+				}
+			}
+		}(route)
+	}
+
+	var order eventOrder
 	src.SetOutput(func(e LogEvent) {
 		if e == nil {
-			close(eventsCh)
+			for _, route := range routes {
+				close(route.eventsCh)
+			}
 			log.Printf("I! [logagent] Log src has stopped for %v/%v(%v)", src.Group(), src.Stream(), src.Description())
 			return
 		}
-		eventsCh <- e
+		processed := l.processLogEvent(e)
+		if processed == nil {
+			order.drop(e)
+			return
+		}
+		e = order.forward(processed)
+		if len(routes) > 1 {
+			e = newFanOutEvent(e, len(routes))
+		}
+		for _, route := range routes {
+			route.eventsCh <- e
+		}
 	})
 
-	for e := range eventsCh {
-		err := dest.Publish([]LogEvent{e})
+	wg.Wait()
+}
+
+// publishToDest publishes the events of the route until the source stops, and
+// returns false if the destination stopped first.
+func (l *LogAgent) publishToDest(src LogSrc, route *logRoute) bool {
+	for e := range route.eventsCh {
+		err := route.dest.Publish([]LogEvent{e})
 		if err == ErrOutputStopped {
-			log.Printf("I! [logagent] Log destination %v has stopped, finalizing %v/%v", l.destNames[dest], src.Group(), src.Stream())
-			return
+			log.Printf("I! [logagent] Log destination %v has stopped, finalizing %v/%v", route.name, src.Group(), src.Stream())
+			return false
 		}
 		if err != nil {
-			log.Printf("E! [logagent] Failed to publish log to %v, error: %v", l.destNames[dest], err)
-			return
+			log.Printf("E! [logagent] Failed to publish log to %v, error: %v", route.name, err)
+			return false
+		}
+	}
+	return true
+}
+
+// processLogEvent applies the processors once to the event, before it is published to
+// any of the destinations. It returns nil if the event is dropped.
+func (l *LogAgent) processLogEvent(e LogEvent) LogEvent {
+	for _, processor := range l.processors {
+		if e = processor.ProcessLogEvent(e); e == nil {
+			return nil
 		}
 	}
+	return e
+}
+
+// eventOrder completes the events dropped by the processors in order with the events
+// published before them. A source can save its position once an event is done, so a
+// dropped event is only done after the last published event is, and never moves the
+// source past events still queued or spilled by the destinations.
+type eventOrder struct {
+	mu   sync.Mutex
+	last *orderedEvent
+}
+
+// forward returns the event to publish, which completes the events dropped after it.
+func (o *eventOrder) forward(e LogEvent) LogEvent {
+	oe := &orderedEvent{LogEvent: e, order: o}
+	o.mu.Lock()
+	o.last = oe
+	o.mu.Unlock()
+	return oe
+}
+
+// drop completes the event once the last published event is done.
+func (o *eventOrder) drop(e LogEvent) {
+	o.mu.Lock()
+	if o.last != nil && !o.last.done {
+		o.last.dropped = append(o.last.dropped, e)
+		o.mu.Unlock()
+		return
+	}
+	o.mu.Unlock()
+	e.Done()
+}
+
+type orderedEvent struct {
+	LogEvent
+	order   *eventOrder
+	done    bool
+	dropped []LogEvent
+}
+
+func (e *orderedEvent) Done() {
+	e.order.mu.Lock()
+	e.done = true
+	dropped := e.dropped
+	e.dropped = nil
+	e.order.mu.Unlock()
+	e.LogEvent.Done()
+	for _, d := range dropped {
+		d.Done()
+	}
+}
+
+// fanOutEvent is an event published to several destinations, which is done once
+// all of them are done with it.
+type fanOutEvent struct {
+	LogEvent
+	remaining atomic.Int32
+}
+
+func newFanOutEvent(e LogEvent, destinations int) *fanOutEvent {
+	fe := &fanOutEvent{LogEvent: e}
+	fe.remaining.Store(int32(destinations))
+	return fe
+}
+
+func (e *fanOutEvent) Done() {
+	if e.remaining.Add(-1) == 0 {
+		e.LogEvent.Done()
+	}
 }
 
 func (l *LogAgent) checkRetentionAlreadyAttempted(retention int, logGroup string) int {
@@ -4,9 +4,16 @@
 package logs
 
 import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,3 +27,272 @@ func TestRetentionAlreadySet(t *testing.T) {
 	assert.Equal(t, -1, secondAttempt)
 	assert.True(t, l.retentionAlreadyAttempted["logGroup1"])
 }
+
+type testEvent struct {
+	msg  string
+	done *int
+}
+
+func (e *testEvent) Message() string { return e.msg }
+func (e *testEvent) Time() time.Time { return time.Time{} }
+func (e *testEvent) Done()           { *e.done++ }
+
+type testSrc struct {
+	events []LogEvent
+}
+
+func (s *testSrc) SetOutput(fn func(LogEvent)) {
+	go func() {
+		for _, e := range s.events {
+			fn(e)
+		}
+		fn(nil)
+	}()
+}
+func (s *testSrc) Group() string       { return "group" }
+func (s *testSrc) Stream() string      { return "stream" }
+func (s *testSrc) Destination() string { return "dest" }
+func (s *testSrc) Description() string { return "description" }
+func (s *testSrc) Retention() int      { return -1 }
+func (s *testSrc) Class() string       { return "" }
+func (s *testSrc) Stop()               {}
+
+type testDest struct {
+	messages []string
+}
+
+func (d *testDest) Publish(events []LogEvent) error {
+	for _, e := range events {
+		d.messages = append(d.messages, e.Message())
+	}
+	return nil
+}
+
+type testProcessor struct{}
+
+func (testProcessor) ProcessLogEvent(e LogEvent) LogEvent {
+	if e.Message() == "drop" {
+		return nil
+	}
+	return &testEvent{msg: strings.ToUpper(e.Message())}
+}
+
+func TestRunSrcToDestWithProcessor(t *testing.T) {
+	l := NewLogAgent(config.NewConfig())
+	var done int
+	src := &testSrc{events: []LogEvent{
+		&testEvent{msg: "keep", done: &done},
+		&testEvent{msg: "drop", done: &done},
+	}}
+	dest := &testDest{}
+	l.processors = []LogEventProcessor{testProcessor{}}
+	l.runSrcToDests(src, []*logRoute{{dest: dest}})
+	assert.Equal(t, []string{"KEEP"}, dest.messages)
+	// testDest never completes the published events, so the dropped event waits for them.
+	assert.Equal(t, 0, done, "the dropped event should not be done before the published one")
+}
+
+// heldDest keeps the published events to complete them later.
+type heldDest struct {
+	mu     sync.Mutex
+	events []LogEvent
+}
+
+func (d *heldDest) Publish(events []LogEvent) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, events...)
+	return nil
+}
+
+type offsetEvent struct {
+	msg    string
+	offset int
+	done   *[]int
+}
+
+func (e *offsetEvent) Message() string { return e.msg }
+func (e *offsetEvent) Time() time.Time { return time.Time{} }
+func (e *offsetEvent) Done()           { *e.done = append(*e.done, e.offset) }
+
+func TestRunSrcToDestsDroppedEventOrder(t *testing.T) {
+	l := NewLogAgent(config.NewConfig())
+	var done []int
+	src := &testSrc{events: []LogEvent{
+		&offsetEvent{msg: "drop", offset: 1, done: &done},
+		&offsetEvent{msg: "first", offset: 2, done: &done},
+		&offsetEvent{msg: "drop", offset: 3, done: &done},
+		&offsetEvent{msg: "drop", offset: 4, done: &done},
+		&offsetEvent{msg: "second", offset: 5, done: &done},
+	}}
+	dest := &heldDest{}
+	l.processors = []LogEventProcessor{wrappingProcessor{}}
+	l.runSrcToDests(src, []*logRoute{{dest: dest}})
+
+	// Nothing was published before the first event, so it is done right away.
+	assert.Equal(t, []int{1}, done)
+	assert.Len(t, dest.events, 2)
+	// The dropped events are only done once the event published before them is.
+	dest.events[0].Done()
+	assert.Equal(t, []int{1, 2, 3, 4}, done)
+	dest.events[1].Done()
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, done)
+}
+
+type countingEvent struct {
+	msg  string
+	done *atomic.Int32
+}
+
+func (e *countingEvent) Message() string { return e.msg }
+func (e *countingEvent) Time() time.Time { return time.Time{} }
+func (e *countingEvent) Done()           { e.done.Add(1) }
+
+// ackingDest is done with the events once they are published, or stops after the
+// given number of events when stopAfter is positive.
+type ackingDest struct {
+	mu        sync.Mutex
+	messages  []string
+	stopAfter int
+}
+
+func (d *ackingDest) Publish(events []LogEvent) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopAfter > 0 && len(d.messages) == d.stopAfter {
+		return ErrOutputStopped
+	}
+	for _, e := range events {
+		d.messages = append(d.messages, e.Message())
+		e.Done()
+	}
+	return nil
+}
+
+type upperEvent struct {
+	LogEvent
+}
+
+func (e upperEvent) Message() string { return strings.ToUpper(e.LogEvent.Message()) }
+
+// wrappingProcessor keeps the events it modifies so they are done with the original.
+type wrappingProcessor struct{}
+
+func (wrappingProcessor) ProcessLogEvent(e LogEvent) LogEvent {
+	if e.Message() == "drop" {
+		return nil
+	}
+	return upperEvent{LogEvent: e}
+}
+
+type stoppableSrc struct {
+	testSrc
+	stopped atomic.Bool
+}
+
+func (s *stoppableSrc) Stop() { s.stopped.Store(true) }
+
+func TestRunSrcToDestsFanOut(t *testing.T) {
+	l := NewLogAgent(config.NewConfig())
+	var done atomic.Int32
+	src := &stoppableSrc{testSrc: testSrc{events: []LogEvent{
+		&countingEvent{msg: "first", done: &done},
+		&countingEvent{msg: "drop", done: &done},
+		&countingEvent{msg: "second", done: &done},
+	}}}
+	first, second := &ackingDest{}, &ackingDest{}
+	l.processors = []LogEventProcessor{wrappingProcessor{}}
+	l.runSrcToDests(src, []*logRoute{{dest: first}, {dest: second}})
+
+	// the events are processed once for all the destinations
+	assert.Equal(t, []string{"FIRST", "SECOND"}, first.messages)
+	assert.Equal(t, []string{"FIRST", "SECOND"}, second.messages)
+	assert.EqualValues(t, 3, done.Load())
+	assert.True(t, src.stopped.Load())
+}
+
+func TestRunSrcToDestsStoppedDest(t *testing.T) {
+	l := NewLogAgent(config.NewConfig())
+	var done atomic.Int32
+	src := &stoppableSrc{testSrc: testSrc{events: []LogEvent{
+		&countingEvent{msg: "first", done: &done},
+		&countingEvent{msg: "second", done: &done},
+		&countingEvent{msg: "third", done: &done},
+	}}}
+	healthy, stopping := &ackingDest{}, &ackingDest{stopAfter: 1}
+	l.runSrcToDests(src, []*logRoute{{dest: healthy}, {dest: stopping}})
+
+	assert.Equal(t, []string{"first", "second", "third"}, healthy.messages)
+	assert.Equal(t, []string{"first"}, stopping.messages)
+	// Only the event published to both destinations is done.
+	assert.EqualValues(t, 1, done.Load())
+	assert.True(t, src.stopped.Load())
+}
+
+func TestFanOutEventDone(t *testing.T) {
+	var done int
+	e := newFanOutEvent(&testEvent{msg: "msg", done: &done}, 3)
+	assert.Equal(t, "msg", e.Message())
+	e.Done()
+	e.Done()
+	assert.Equal(t, 0, done)
+	e.Done()
+	assert.Equal(t, 1, done)
+}
+
+type testBackend struct {
+	dest LogDest
+}
+
+func (b *testBackend) CreateDest(string, string, int, string) LogDest { return b.dest }
+func (b *testBackend) Connect() error                                 { return nil }
+func (b *testBackend) Close() error                                   { return nil }
+func (b *testBackend) Write([]telegraf.Metric) error                  { return nil }
+func (b *testBackend) SampleConfig() string                           { return "" }
+func (b *testBackend) Description() string                            { return "" }
+
+// processingBackend is a backend processing the events, like the redaction of cloudwatchlogs.
+type processingBackend struct {
+	testBackend
+	wrappingProcessor
+}
+
+type testCollection struct {
+	srcs []LogSrc
+}
+
+func (c *testCollection) FindLogSrc() []LogSrc {
+	srcs := c.srcs
+	c.srcs = nil
+	return srcs
+}
+func (c *testCollection) Start(telegraf.Accumulator) error  { return nil }
+func (c *testCollection) Gather(telegraf.Accumulator) error { return nil }
+func (c *testCollection) SampleConfig() string              { return "" }
+func (c *testCollection) Description() string               { return "" }
+
+func TestRunProcessesEventsForAllDestinations(t *testing.T) {
+	c := config.NewConfig()
+	dest := &ackingDest{}
+	c.Outputs = []*models.RunningOutput{
+		{Output: &processingBackend{testBackend: testBackend{dest: &ackingDest{}}}, Config: &models.OutputConfig{Name: "cloudwatchlogs"}},
+		{Output: &testBackend{dest: dest}, Config: &models.OutputConfig{Name: "dest"}},
+	}
+	var done atomic.Int32
+	src := &testSrc{events: []LogEvent{
+		&countingEvent{msg: "secret", done: &done},
+		&countingEvent{msg: "drop", done: &done},
+	}}
+	c.Inputs = []*models.RunningInput{
+		{Input: &testCollection{srcs: []LogSrc{src}}, Config: &models.InputConfig{Name: "logfile"}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewLogAgent(c).Run(ctx)
+
+	// the destination is not the processing backend, but still gets the processed events
+	assert.Eventually(t, func() bool { return done.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+	dest.mu.Lock()
+	defer dest.mu.Unlock()
+	assert.Equal(t, []string{"SECRET"}, dest.messages)
+}
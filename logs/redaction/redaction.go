@@ -0,0 +1,212 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package redaction
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+const (
+	ActionMask = "mask"
+	ActionHash = "hash"
+	ActionDrop = "drop"
+
+	DetectorCreditCard   = "credit_card"
+	DetectorAWSAccessKey = "aws_access_key"
+	DetectorEmail        = "email"
+	DetectorJWT          = "jwt"
+	DetectorIPAddress    = "ip_address"
+
+	defaultMaskValue = "****"
+	// Number of hex characters of the SHA-256 kept for the hash action, enough to
+	// correlate values across events without making the events much longer.
+	hashLength = 16
+)
+
+type detector struct {
+	expression *regexp.Regexp
+	// Optional check on the matches to reduce the false positives of the expression.
+	validate func(string) bool
+}
+
+var detectors = map[string]detector{
+	DetectorCreditCard: {
+		expression: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		validate:   isLuhnValid,
+	},
+	DetectorAWSAccessKey: {
+		expression: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AROA|ANPA|ANVA|APKA)[A-Z0-9]{16}\b`),
+	},
+	DetectorEmail: {
+		expression: regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
+	},
+	DetectorJWT: {
+		expression: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]*\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+	},
+	// IPv6 addresses start and end with a group of hex digits, so the "::" of C++ or
+	// Ruby names like std::vector is not taken for an address.
+	DetectorIPAddress: {
+		expression: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|\b[0-9A-Fa-f]{1,4}(?::[0-9A-Fa-f]{0,4}){1,6}:[0-9A-Fa-f]{1,4}\b`),
+		validate: func(s string) bool {
+			return net.ParseIP(s) != nil
+		},
+	},
+}
+
+// Rule redacts the matches of either a built-in detector or a custom regular expression.
+type Rule struct {
+	// Name of the built-in detector, exclusive with the expression.
+	Detector string `toml:"detector"`
+	// Custom regular expression, exclusive with the detector.
+	Expression string `toml:"expression"`
+	// One of mask, hash or drop. Defaults to mask.
+	Action string `toml:"action"`
+	// The value replacing the matches of the mask action, "****" by default.
+	MaskValue string `toml:"mask_value"`
+
+	detector detector
+}
+
+// Config of the redaction applied to the log events before they are published.
+type Config struct {
+	Rules []*Rule `toml:"rules"`
+}
+
+func (r *Rule) init() error {
+	switch {
+	case r.Detector != "" && r.Expression != "":
+		return fmt.Errorf("redaction rule can only have one of detector %s and expression %s", r.Detector, r.Expression)
+	case r.Detector != "":
+		d, ok := detectors[r.Detector]
+		if !ok {
+			return fmt.Errorf("redaction detector %s is incorrect, valid detectors are: %v", r.Detector, ValidDetectors())
+		}
+		r.detector = d
+	case r.Expression != "":
+		expression, err := regexp.Compile(r.Expression)
+		if err != nil {
+			return fmt.Errorf("redaction regex has issue, regexp: Compile( %v ): %v", r.Expression, err.Error())
+		}
+		r.detector = detector{expression: expression}
+	default:
+		return fmt.Errorf("redaction rule needs either a detector or an expression")
+	}
+
+	switch r.Action {
+	case "":
+		r.Action = ActionMask
+	case ActionMask, ActionHash, ActionDrop:
+	default:
+		return fmt.Errorf("redaction action %s is incorrect, valid actions are: %v", r.Action, []string{ActionMask, ActionHash, ActionDrop})
+	}
+	if r.MaskValue == "" {
+		r.MaskValue = defaultMaskValue
+	}
+	return nil
+}
+
+// ValidDetectors returns the names of the built-in detectors.
+func ValidDetectors() []string {
+	return []string{DetectorCreditCard, DetectorAWSAccessKey, DetectorEmail, DetectorJWT, DetectorIPAddress}
+}
+
+// Redactor applies the rules of the config to the log events, in order.
+type Redactor struct {
+	rules    []*Rule
+	counters agent.CounterSet
+}
+
+var _ logs.LogEventProcessor = (*Redactor)(nil)
+
+func NewRedactor(config Config) (*Redactor, error) {
+	for _, rule := range config.Rules {
+		if err := rule.init(); err != nil {
+			return nil, err
+		}
+	}
+	return &Redactor{rules: config.Rules, counters: agent.UsageCounters()}, nil
+}
+
+// ProcessLogEvent returns the event with the matches redacted, the same event if
+// nothing matched, or nil if a rule with the drop action matched.
+func (r *Redactor) ProcessLogEvent(e logs.LogEvent) logs.LogEvent {
+	msg, redactions, drop := r.Redact(e.Message())
+	if redactions > 0 {
+		r.counters.Add(agent.CounterLogRedactions, int64(redactions))
+	}
+	if drop {
+		return nil
+	}
+	if redactions == 0 {
+		return e
+	}
+	return &redactedEvent{LogEvent: e, msg: msg}
+}
+
+// Redact returns the message with the matches of the rules replaced, the number
+// of matches, and whether the message should be dropped.
+func (r *Redactor) Redact(msg string) (string, int, bool) {
+	var redactions int
+	for _, rule := range r.rules {
+		var count int
+		msg = rule.detector.expression.ReplaceAllStringFunc(msg, func(match string) string {
+			if rule.detector.validate != nil && !rule.detector.validate(match) {
+				return match
+			}
+			count++
+			switch rule.Action {
+			case ActionHash:
+				return hash(match)
+			default:
+				return rule.MaskValue
+			}
+		})
+		redactions += count
+		if count > 0 && rule.Action == ActionDrop {
+			return "", redactions, true
+		}
+	}
+	return msg, redactions, false
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// isLuhnValid checks the digits of a credit card number with the Luhn algorithm.
+func isLuhnValid(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	var sum int
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+type redactedEvent struct {
+	logs.LogEvent
+	msg string
+}
+
+func (e *redactedEvent) Message() string {
+	return e.msg
+}
@@ -0,0 +1,133 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// The log group and stream names of a LogSrc can reference the fields of its JSON log
+// events as {$.field}, with the nested fields separated by dots, e.g. /apps/{$.tenant}.
+// The LogAgent then routes each event to the destination of the names resolved from it.
+var eventFieldPlaceholderRegexp = regexp.MustCompile(`\{\$\.([^{}]+)\}`)
+
+// The characters not allowed in the log group names are replaced in the field values.
+var invalidNameCharRegexp = regexp.MustCompile(`[^\.\-_/#A-Za-z0-9]`)
+
+// The value of the fields that are missing from the log events.
+const missingEventFieldValue = "unknown"
+
+// Max number of destinations created by a routing destination. The events routed to
+// other names are published to the destination of the missing fields instead, so a
+// field with an unbounded number of values does not create unbounded log streams.
+var maxRoutedDests = 1000
+
+func hasEventFieldPlaceholders(name string) bool {
+	return eventFieldPlaceholderRegexp.MatchString(name)
+}
+
+type routeKey struct {
+	group, stream string
+}
+
+// routingDest is a LogDest publishing each event to the destination of the log group and
+// stream names resolved from its fields. It is only used by the goroutine of its route.
+type routingDest struct {
+	agent    *LogAgent
+	backend  LogBackend
+	template LogDestination
+	dests    map[routeKey]LogDest
+	limitHit bool
+}
+
+var _ LogDest = (*routingDest)(nil)
+
+func newRoutingDest(agent *LogAgent, backend LogBackend, template LogDestination) *routingDest {
+	return &routingDest{
+		agent:    agent,
+		backend:  backend,
+		template: template,
+		dests:    make(map[routeKey]LogDest),
+	}
+}
+
+func (r *routingDest) Publish(events []LogEvent) error {
+	for _, e := range events {
+		if err := r.dest(e).Publish([]LogEvent{e}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *routingDest) dest(e LogEvent) LogDest {
+	fields := parseEventFields(e.Message())
+	key := routeKey{
+		group:  resolveEventFields(r.template.Group, fields),
+		stream: resolveEventFields(r.template.Stream, fields),
+	}
+	if dest, ok := r.dests[key]; ok {
+		return dest
+	}
+	if len(r.dests) >= maxRoutedDests {
+		if !r.limitHit {
+			r.limitHit = true
+			log.Printf("W! [logagent] Routed log events of %s/%s to %d destinations, the events with other names are published with %q as their field values", r.template.Group, r.template.Stream, maxRoutedDests, missingEventFieldValue)
+		}
+		key = routeKey{
+			group:  resolveEventFields(r.template.Group, nil),
+			stream: resolveEventFields(r.template.Stream, nil),
+		}
+		if dest, ok := r.dests[key]; ok {
+			return dest
+		}
+	}
+	d := r.template
+	d.Group, d.Stream = key.group, key.stream
+	dest := r.agent.createDest(r.backend, d)
+	r.dests[key] = dest
+	return dest
+}
+
+// parseEventFields returns the fields of the JSON log event, or nil if it is not JSON.
+func parseEventFields(msg string) map[string]interface{} {
+	if !strings.HasPrefix(strings.TrimSpace(msg), "{") {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(msg), &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// resolveEventFields replaces the {$.field} placeholders of the name with the values of the
+// fields, or with the missing field value when the field is not found.
+func resolveEventFields(name string, fields map[string]interface{}) string {
+	return eventFieldPlaceholderRegexp.ReplaceAllStringFunc(name, func(placeholder string) string {
+		path := eventFieldPlaceholderRegexp.FindStringSubmatch(placeholder)[1]
+		var val interface{} = fields
+		for _, key := range strings.Split(path, ".") {
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				return missingEventFieldValue
+			}
+			if val, ok = m[key]; !ok {
+				return missingEventFieldValue
+			}
+		}
+		switch val.(type) {
+		case nil, map[string]interface{}, []interface{}:
+			return missingEventFieldValue
+		}
+		if s := invalidNameCharRegexp.ReplaceAllString(fmt.Sprint(val), "_"); s != "" {
+			return s
+		}
+		return missingEventFieldValue
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type routedDest struct {
+	group, stream string
+	retention     int
+	messages      []string
+}
+
+func (d *routedDest) Publish(events []LogEvent) error {
+	for _, e := range events {
+		d.messages = append(d.messages, e.Message())
+	}
+	return nil
+}
+
+type routingBackend struct {
+	dests []*routedDest
+}
+
+func (b *routingBackend) CreateDest(group, stream string, retention int, _ string) LogDest {
+	d := &routedDest{group: group, stream: stream, retention: retention}
+	b.dests = append(b.dests, d)
+	return d
+}
+
+func TestResolveEventFields(t *testing.T) {
+	testCases := map[string]struct {
+		msg  string
+		want string
+	}{
+		"field":         {msg: `{"tenant":"acme","level":"info"}`, want: "/apps/acme/info"},
+		"nested field":  {msg: `{"tenant":"acme","level":{"name":"warn"}}`, want: "/apps/acme/unknown"},
+		"number":        {msg: `{"tenant":42,"level":"error"}`, want: "/apps/42/error"},
+		"missing field": {msg: `{"level":"error"}`, want: "/apps/unknown/error"},
+		"invalid chars": {msg: `{"tenant":"a c:m*e","level":""}`, want: "/apps/a_c_m_e/unknown"},
+		"not json":      {msg: `tenant=acme`, want: "/apps/unknown/unknown"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, resolveEventFields("/apps/{$.tenant}/{$.level}", parseEventFields(testCase.msg)))
+		})
+	}
+	assert.Equal(t, "/apps/warn", resolveEventFields("/apps/{$.level.name}", parseEventFields(`{"level":{"name":"warn"}}`)))
+	assert.True(t, hasEventFieldPlaceholders("/apps/{$.tenant}"))
+	assert.False(t, hasEventFieldPlaceholders("/apps/{instance_id}"))
+}
+
+func TestRoutingDest(t *testing.T) {
+	l := NewLogAgent(config.NewConfig())
+	backend := &routingBackend{}
+	r := newRoutingDest(l, backend, LogDestination{Name: "dest", Group: "/apps/{$.tenant}", Stream: "stream", Retention: 7})
+
+	var done int
+	var events []LogEvent
+	for _, msg := range []string{`{"tenant":"a","n":1}`, `{"tenant":"b","n":2}`, `{"tenant":"a","n":3}`, `not json`} {
+		events = append(events, &testEvent{msg: msg, done: &done})
+	}
+	assert.NoError(t, r.Publish(events))
+
+	assert.Len(t, backend.dests, 3)
+	assert.Equal(t, routedDest{group: "/apps/a", stream: "stream", retention: 7, messages: []string{`{"tenant":"a","n":1}`, `{"tenant":"a","n":3}`}}, *backend.dests[0])
+	assert.Equal(t, routedDest{group: "/apps/b", stream: "stream", retention: 7, messages: []string{`{"tenant":"b","n":2}`}}, *backend.dests[1])
+	assert.Equal(t, routedDest{group: "/apps/unknown", stream: "stream", retention: 7, messages: []string{`not json`}}, *backend.dests[2])
+}
+
+func TestRoutingDestLimit(t *testing.T) {
+	defer func(limit int) { maxRoutedDests = limit }(maxRoutedDests)
+	maxRoutedDests = 2
+
+	l := NewLogAgent(config.NewConfig())
+	backend := &routingBackend{}
+	r := newRoutingDest(l, backend, LogDestination{Name: "dest", Group: "group", Stream: "{$.id}"})
+	var done int
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, r.Publish([]LogEvent{&testEvent{msg: fmt.Sprintf(`{"id":"%d"}`, i), done: &done}}))
+	}
+	var streams []string
+	for _, d := range backend.dests {
+		streams = append(streams, fmt.Sprintf("%s:%d", d.stream, len(d.messages)))
+	}
+	assert.Equal(t, []string{"0:1", "1:1", "unknown:3"}, streams)
+}
+
+func TestCreateRouteWithEventFields(t *testing.T) {
+	l := NewLogAgent(config.NewConfig())
+	backend := &routingBackend{}
+	l.backends["dest"] = backend
+
+	route := l.createRoute(&testSrc{}, LogDestination{Name: "dest", Group: "/apps/{$.tenant}", Stream: "stream"})
+	_, ok := route.dest.(*routingDest)
+	assert.True(t, ok)
+	assert.Empty(t, backend.dests, "the destinations are created when the events are routed")
+
+	route = l.createRoute(&testSrc{}, LogDestination{Name: "dest", Group: "group", Stream: "stream"})
+	assert.Equal(t, backend.dests[0], route.dest)
+	assert.Nil(t, l.createRoute(&testSrc{}, LogDestination{Name: "missing"}))
+}
@@ -6,6 +6,7 @@ package distribution
 import (
 	"errors"
 	"math"
+	"sort"
 
 	"go.opentelemetry.io/collector/pdata/pmetric"
 )
@@ -45,6 +46,12 @@ type Distribution interface {
 	ConvertToOtel(dp pmetric.HistogramDataPoint)
 
 	ConvertFromOtel(dp pmetric.HistogramDataPoint, unit string)
+
+	// scale is the factor converting the values to the unit
+	ConvertFromOtelExponentialHistogram(dp pmetric.ExponentialHistogramDataPoint, unit string, scale float64)
+
+	// scale is the factor converting the values to the unit
+	ConvertFromOtelSummary(dp pmetric.SummaryDataPoint, unit string, scale float64)
 }
 
 var NewDistribution func() Distribution
@@ -55,3 +62,70 @@ var NewDistribution func() Distribution
 func IsSupportedValue(value, min, max float64) bool {
 	return !math.IsNaN(value) && value >= min && value <= max
 }
+
+// ExponentialHistogramValuesAndCounts returns the value and count of each bucket of the exponential histogram.
+// The value of a bucket is the midpoint of its bounds on the exponential scale, i.e. base^(index+0.5),
+// which is also how the SEH1 buckets are represented. The negative buckets have negative values.
+func ExponentialHistogramValuesAndCounts(dp pmetric.ExponentialHistogramDataPoint) (values []float64, counts []float64) {
+	values = []float64{}
+	counts = []float64{}
+	if dp.ZeroCount() > 0 {
+		values = append(values, 0)
+		counts = append(counts, float64(dp.ZeroCount()))
+	}
+	// base = 2^(2^-scale)
+	exponentFactor := math.Exp2(-float64(dp.Scale()))
+	addBuckets := func(buckets pmetric.ExponentialHistogramDataPointBuckets, sign float64) {
+		for i := 0; i < buckets.BucketCounts().Len(); i++ {
+			count := buckets.BucketCounts().At(i)
+			if count == 0 {
+				continue
+			}
+			index := float64(buckets.Offset()) + float64(i)
+			values = append(values, sign*math.Exp2((index+0.5)*exponentFactor))
+			counts = append(counts, float64(count))
+		}
+	}
+	addBuckets(dp.Positive(), 1)
+	addBuckets(dp.Negative(), -1)
+	return
+}
+
+// SummaryValuesAndCounts returns the values of the quantiles of the summary, each counted for the samples
+// between the previous quantile and its own, and the samples above the last quantile counted for it.
+// A summary without quantiles is represented by its mean.
+func SummaryValuesAndCounts(dp pmetric.SummaryDataPoint) (values []float64, counts []float64) {
+	values = []float64{}
+	counts = []float64{}
+	sampleCount := float64(dp.Count())
+	if sampleCount == 0 {
+		return
+	}
+	quantiles := make([]pmetric.SummaryDataPointValueAtQuantile, 0, dp.QuantileValues().Len())
+	for i := 0; i < dp.QuantileValues().Len(); i++ {
+		q := dp.QuantileValues().At(i)
+		if q.Quantile() < 0 || q.Quantile() > 1 || math.IsNaN(q.Value()) {
+			continue
+		}
+		quantiles = append(quantiles, q)
+	}
+	if len(quantiles) == 0 {
+		return []float64{dp.Sum() / sampleCount}, []float64{sampleCount}
+	}
+	sort.Slice(quantiles, func(i, j int) bool {
+		return quantiles[i].Quantile() < quantiles[j].Quantile()
+	})
+	previous := 0.0
+	for i, q := range quantiles {
+		rank := q.Quantile()
+		if i == len(quantiles)-1 {
+			rank = 1
+		}
+		if count := (rank - previous) * sampleCount; count > 0 {
+			values = append(values, q.Value())
+			counts = append(counts, count)
+		}
+		previous = rank
+	}
+	return
+}
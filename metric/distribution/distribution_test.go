@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 )
 
 func TestIsAcceptedValue(t *testing.T) {
@@ -28,3 +29,48 @@ func TestIsAcceptedValue(t *testing.T) {
 		assert.Equal(t, testCase.want, IsSupportedValue(testCase.input, MinValue, MaxValue))
 	}
 }
+
+func TestExponentialHistogramValuesAndCounts(t *testing.T) {
+	dp := pmetric.NewExponentialHistogramDataPoint()
+	// base = 2
+	dp.SetScale(0)
+	dp.SetZeroCount(3)
+	dp.Positive().SetOffset(1)
+	dp.Positive().BucketCounts().FromRaw([]uint64{1, 0, 2})
+	dp.Negative().SetOffset(-1)
+	dp.Negative().BucketCounts().FromRaw([]uint64{4})
+	values, counts := ExponentialHistogramValuesAndCounts(dp)
+	assert.InDeltaSlice(t, []float64{0, math.Pow(2, 1.5), math.Pow(2, 3.5), -math.Pow(2, -0.5)}, values, 1e-9)
+	assert.Equal(t, []float64{3, 1, 2, 4}, counts)
+
+	// base = 2^(1/4)
+	dp = pmetric.NewExponentialHistogramDataPoint()
+	dp.SetScale(2)
+	dp.Positive().SetOffset(4)
+	dp.Positive().BucketCounts().FromRaw([]uint64{5})
+	values, counts = ExponentialHistogramValuesAndCounts(dp)
+	assert.InDeltaSlice(t, []float64{math.Pow(2, 4.5/4)}, values, 1e-9)
+	assert.Equal(t, []float64{5}, counts)
+}
+
+func TestSummaryValuesAndCounts(t *testing.T) {
+	dp := pmetric.NewSummaryDataPoint()
+	values, counts := SummaryValuesAndCounts(dp)
+	assert.Empty(t, values)
+	assert.Empty(t, counts)
+
+	dp.SetCount(100)
+	dp.SetSum(5000)
+	values, counts = SummaryValuesAndCounts(dp)
+	assert.Equal(t, []float64{50}, values)
+	assert.Equal(t, []float64{100}, counts)
+
+	for _, q := range [][2]float64{{0.99, 99}, {0, 1}, {0.5, 40}, {0.9, 80}, {1.5, 1000}} {
+		qv := dp.QuantileValues().AppendEmpty()
+		qv.SetQuantile(q[0])
+		qv.SetValue(q[1])
+	}
+	values, counts = SummaryValuesAndCounts(dp)
+	assert.Equal(t, []float64{40, 80, 99}, values)
+	assert.InDeltaSlice(t, []float64{50, 40, 10}, counts, 1e-9)
+}
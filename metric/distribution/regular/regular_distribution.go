@@ -173,6 +173,58 @@ func (rd *RegularDistribution) ConvertFromOtel(dp pmetric.HistogramDataPoint, un
 	}
 }
 
+// ConvertFromOtelExponentialHistogram adds the buckets of the exponential histogram as entries. The sum,
+// min and max of the data point are kept unless some of its buckets are not supported, e.g. negative ones.
+func (rd *RegularDistribution) ConvertFromOtelExponentialHistogram(dp pmetric.ExponentialHistogramDataPoint, unit string, scale float64) {
+	values, counts := distribution.ExponentialHistogramValuesAndCounts(dp)
+	if !rd.addEntries(values, counts, unit, scale) {
+		return
+	}
+	if dp.HasSum() {
+		rd.sum = dp.Sum() * scale
+	}
+	if dp.HasMin() {
+		rd.minimum = dp.Min() * scale
+	}
+	if dp.HasMax() {
+		rd.maximum = dp.Max() * scale
+	}
+}
+
+// ConvertFromOtelSummary adds the quantiles of the summary as entries. The sum of the data point
+// is kept, and so are its 0 and 1 quantiles as the min and max, unless some of them are not supported.
+func (rd *RegularDistribution) ConvertFromOtelSummary(dp pmetric.SummaryDataPoint, unit string, scale float64) {
+	values, counts := distribution.SummaryValuesAndCounts(dp)
+	if !rd.addEntries(values, counts, unit, scale) || rd.sampleCount == 0 {
+		return
+	}
+	rd.sum = dp.Sum() * scale
+	for i := 0; i < dp.QuantileValues().Len(); i++ {
+		q := dp.QuantileValues().At(i)
+		switch q.Quantile() {
+		case 0:
+			rd.minimum = q.Value() * scale
+		case 1:
+			rd.maximum = q.Value() * scale
+		}
+	}
+}
+
+// addEntries returns false if any of the values is not supported, after adding the others.
+func (rd *RegularDistribution) addEntries(values []float64, counts []float64, unit string, scale float64) bool {
+	if rd.unit == "" {
+		rd.unit = unit
+	}
+	supported := true
+	for i := range values {
+		if err := rd.AddEntryWithUnit(values[i]*scale, counts[i], unit); err != nil {
+			log.Printf("D! Dropping the entry of the distribution: %v", err)
+			supported = false
+		}
+	}
+	return supported
+}
+
 func (regularDist *RegularDistribution) GetCount(value float64) float64 {
 	return regularDist.buckets[value]
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 )
@@ -88,6 +89,43 @@ func TestRegularDistribution(t *testing.T) {
 	assert.ErrorIs(t, anotherDist.AddEntry(distribution.MinValue*1.001, 1), distribution.ErrUnsupportedValue)
 }
 
+func TestRegularDistribution_ConvertFromOtel(t *testing.T) {
+	// base = 2
+	exponentialHistogram := pmetric.NewExponentialHistogramDataPoint()
+	exponentialHistogram.SetCount(4)
+	exponentialHistogram.SetSum(20)
+	exponentialHistogram.SetMin(2.5)
+	exponentialHistogram.SetMax(9)
+	exponentialHistogram.Positive().SetOffset(1)
+	exponentialHistogram.Positive().BucketCounts().FromRaw([]uint64{1, 0, 3})
+	dist := NewRegularDistribution()
+	dist.ConvertFromOtelExponentialHistogram(exponentialHistogram, "Count", 1)
+	assert.Equal(t, 4.0, dist.SampleCount())
+	assert.Equal(t, 20.0, dist.Sum())
+	assert.Equal(t, 2.5, dist.Minimum())
+	assert.Equal(t, 9.0, dist.Maximum())
+	assert.Equal(t, "Count", dist.Unit())
+	assert.Equal(t, 1.0, dist.(*RegularDistribution).GetCount(math.Pow(2, 1.5)))
+	assert.Equal(t, 3.0, dist.(*RegularDistribution).GetCount(math.Pow(2, 3.5)))
+
+	summary := pmetric.NewSummaryDataPoint()
+	summary.SetCount(10)
+	summary.SetSum(300)
+	for _, q := range [][2]float64{{0.5, 20}, {1, 90}} {
+		qv := summary.QuantileValues().AppendEmpty()
+		qv.SetQuantile(q[0])
+		qv.SetValue(q[1])
+	}
+	dist = NewRegularDistribution()
+	dist.ConvertFromOtelSummary(summary, "Count", 1)
+	assert.Equal(t, 10.0, dist.SampleCount())
+	assert.Equal(t, 300.0, dist.Sum())
+	assert.Equal(t, 20.0, dist.Minimum())
+	assert.Equal(t, 90.0, dist.Maximum())
+	assert.Equal(t, 5.0, dist.(*RegularDistribution).GetCount(20))
+	assert.Equal(t, 5.0, dist.(*RegularDistribution).GetCount(90))
+}
+
 func cloneRegularDistribution(dist *RegularDistribution) *RegularDistribution {
 	clonedDist := &RegularDistribution{
 		maximum:     dist.maximum,
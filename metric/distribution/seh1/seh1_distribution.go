@@ -186,6 +186,58 @@ func (sd *SEH1Distribution) ConvertFromOtel(dp pmetric.HistogramDataPoint, unit
 	}
 }
 
+// ConvertFromOtelExponentialHistogram adds the buckets of the exponential histogram as entries. The sum,
+// min and max of the data point are kept unless some of its buckets are not supported, e.g. negative ones.
+func (sd *SEH1Distribution) ConvertFromOtelExponentialHistogram(dp pmetric.ExponentialHistogramDataPoint, unit string, scale float64) {
+	values, counts := distribution.ExponentialHistogramValuesAndCounts(dp)
+	if !sd.addEntries(values, counts, unit, scale) {
+		return
+	}
+	if dp.HasSum() {
+		sd.sum = dp.Sum() * scale
+	}
+	if dp.HasMin() {
+		sd.minimum = dp.Min() * scale
+	}
+	if dp.HasMax() {
+		sd.maximum = dp.Max() * scale
+	}
+}
+
+// ConvertFromOtelSummary adds the quantiles of the summary as entries. The sum of the data point
+// is kept, and so are its 0 and 1 quantiles as the min and max, unless some of them are not supported.
+func (sd *SEH1Distribution) ConvertFromOtelSummary(dp pmetric.SummaryDataPoint, unit string, scale float64) {
+	values, counts := distribution.SummaryValuesAndCounts(dp)
+	if !sd.addEntries(values, counts, unit, scale) || sd.sampleCount == 0 {
+		return
+	}
+	sd.sum = dp.Sum() * scale
+	for i := 0; i < dp.QuantileValues().Len(); i++ {
+		q := dp.QuantileValues().At(i)
+		switch q.Quantile() {
+		case 0:
+			sd.minimum = q.Value() * scale
+		case 1:
+			sd.maximum = q.Value() * scale
+		}
+	}
+}
+
+// addEntries returns false if any of the values is not supported, after adding the others.
+func (sd *SEH1Distribution) addEntries(values []float64, counts []float64, unit string, scale float64) bool {
+	if sd.unit == "" {
+		sd.unit = unit
+	}
+	supported := true
+	for i := range values {
+		if err := sd.AddEntryWithUnit(values[i]*scale, counts[i], unit); err != nil {
+			log.Printf("D! Dropping the entry of the distribution: %v", err)
+			supported = false
+		}
+	}
+	return supported
+}
+
 func (seh1Distribution *SEH1Distribution) CanAdd(value float64, sizeLimit int) bool {
 	if seh1Distribution.Size() < sizeLimit {
 		return true
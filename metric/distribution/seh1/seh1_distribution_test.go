@@ -6,9 +6,12 @@ package seh1
 import (
 	"math"
 	"math/big"
+	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 )
@@ -90,6 +93,129 @@ func TestSEH1Distribution(t *testing.T) {
 	assert.ErrorIs(t, anotherDist.AddEntry(distribution.MinValue*1.001, 1), distribution.ErrUnsupportedValue)
 }
 
+func TestSEH1Distribution_ConvertFromOtelExponentialHistogram(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	samples := make([]float64, 10000)
+	for i := range samples {
+		samples[i] = math.Exp(r.NormFloat64() + 3)
+	}
+	for _, scale := range []int32{2, 5, 8} {
+		expected := NewSEH1Distribution()
+		for _, sample := range samples {
+			assert.NoError(t, expected.AddEntryWithUnit(sample, 1, "Milliseconds"))
+		}
+		dist := NewSEH1Distribution()
+		dist.ConvertFromOtelExponentialHistogram(newExponentialHistogram(samples, scale), "Milliseconds", 1)
+		assert.Equal(t, expected.SampleCount(), dist.SampleCount())
+		assert.InDelta(t, expected.Sum(), dist.Sum(), 1e-6)
+		assert.Equal(t, expected.Minimum(), dist.Minimum())
+		assert.Equal(t, expected.Maximum(), dist.Maximum())
+		assert.Equal(t, "Milliseconds", dist.Unit())
+		for _, p := range []float64{0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+			// The exponential buckets are at most 19% wide at scale 2, so the percentile is off by up to 2 SEH1 buckets.
+			want := percentile(expected, p)
+			assert.InEpsilon(t, want, percentile(dist, p), 0.21, "scale %d, p%v", scale, p*100)
+			if scale >= 5 {
+				assert.InEpsilon(t, want, percentile(dist, p), 0.1, "scale %d, p%v", scale, p*100)
+			}
+		}
+	}
+
+	// The data point is scaled to the unit.
+	dp := newExponentialHistogram([]float64{1000, 2000, 3000}, 8)
+	dist := NewSEH1Distribution()
+	dist.ConvertFromOtelExponentialHistogram(dp, "Microseconds", 0.001)
+	assert.Equal(t, 3.0, dist.SampleCount())
+	assert.InDelta(t, 6.0, dist.Sum(), 1e-9)
+	assert.InDelta(t, 1.0, dist.Minimum(), 1e-9)
+	assert.InDelta(t, 3.0, dist.Maximum(), 1e-9)
+	assert.Equal(t, 3, dist.Size())
+
+	// The negative buckets are dropped along with the sum, min and max of the data point.
+	dp.SetMin(-1)
+	dp.Negative().BucketCounts().FromRaw([]uint64{1})
+	dp.SetZeroCount(1)
+	dist = NewSEH1Distribution()
+	dist.ConvertFromOtelExponentialHistogram(dp, "", 1)
+	assert.Equal(t, 4.0, dist.SampleCount())
+	assert.Equal(t, 0.0, dist.Minimum())
+	assert.InEpsilon(t, 3000.0, dist.Maximum(), 0.01)
+	assert.InEpsilon(t, 6000.0, dist.Sum(), 0.01)
+}
+
+func TestSEH1Distribution_ConvertFromOtelSummary(t *testing.T) {
+	dp := pmetric.NewSummaryDataPoint()
+	dp.SetCount(100)
+	dp.SetSum(4500)
+	for _, q := range [][2]float64{{0, 2}, {0.5, 40}, {0.9, 80}, {1, 120}} {
+		qv := dp.QuantileValues().AppendEmpty()
+		qv.SetQuantile(q[0])
+		qv.SetValue(q[1])
+	}
+	dist := NewSEH1Distribution()
+	dist.ConvertFromOtelSummary(dp, "Seconds", 1)
+	assert.Equal(t, 100.0, dist.SampleCount())
+	assert.Equal(t, 4500.0, dist.Sum())
+	assert.Equal(t, 2.0, dist.Minimum())
+	assert.Equal(t, 120.0, dist.Maximum())
+	assert.Equal(t, "Seconds", dist.Unit())
+	assert.InEpsilon(t, 40.0, percentile(dist, 0.5), 0.1)
+	assert.InEpsilon(t, 80.0, percentile(dist, 0.9), 0.1)
+	assert.InEpsilon(t, 120.0, percentile(dist, 0.95), 0.1)
+
+	dist = NewSEH1Distribution()
+	dist.ConvertFromOtelSummary(pmetric.NewSummaryDataPoint(), "Seconds", 1)
+	assert.Equal(t, 0, dist.Size())
+}
+
+// newExponentialHistogram returns the exponential histogram of the samples, which are positive.
+func newExponentialHistogram(samples []float64, scale int32) pmetric.ExponentialHistogramDataPoint {
+	dp := pmetric.NewExponentialHistogramDataPoint()
+	dp.SetScale(scale)
+	buckets := map[int32]uint64{}
+	minIndex, maxIndex := int32(math.MaxInt32), int32(math.MinInt32)
+	for _, sample := range samples {
+		// The buckets are (base^index, base^(index+1)].
+		index := int32(math.Ceil(math.Log2(sample)*math.Exp2(float64(scale)))) - 1
+		buckets[index]++
+		minIndex = min(minIndex, index)
+		maxIndex = max(maxIndex, index)
+		dp.SetSum(dp.Sum() + sample)
+		if dp.Count() == 0 || sample < dp.Min() {
+			dp.SetMin(sample)
+		}
+		if dp.Count() == 0 || sample > dp.Max() {
+			dp.SetMax(sample)
+		}
+		dp.SetCount(dp.Count() + 1)
+	}
+	dp.Positive().SetOffset(minIndex)
+	for index := minIndex; index <= maxIndex; index++ {
+		dp.Positive().BucketCounts().Append(buckets[index])
+	}
+	return dp
+}
+
+// percentile returns the value of the distribution at the rank.
+func percentile(dist distribution.Distribution, rank float64) float64 {
+	values, counts := dist.ValuesAndCounts()
+	indexes := make([]int, len(values))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return values[indexes[i]] < values[indexes[j]]
+	})
+	var total float64
+	for _, i := range indexes {
+		total += counts[i]
+		if total >= rank*dist.SampleCount() {
+			return values[i]
+		}
+	}
+	return dist.Maximum()
+}
+
 func cloneSEH1Distribution(dist *SEH1Distribution) *SEH1Distribution {
 	clonedDist := &SEH1Distribution{
 		maximum:     dist.maximum,
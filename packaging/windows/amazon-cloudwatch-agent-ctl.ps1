@@ -27,7 +27,7 @@ $UsageString = @"
         usage:  amazon-cloudwatch-agent-ctl.ps1 -a
                 stop|start|status|fetch-config|append-config|remove-config|set-log-level
                 [-m ec2|onPremise|onPrem|auto]
-                [-c default|all|ssm:<parameter-store-name>|file:<file-path>]
+                [-c default|all|ssm:<parameter-store-name>|file:<file-path>|s3:<bucket>/<key>|https://<host>/<path>|appconfig:<application>/<environment>/<profile>]
                 [-s]
                 [-l INFO|DEBUG|WARN|ERROR|OFF]
 
@@ -57,6 +57,9 @@ $UsageString = @"
             default:                                default configuration for quick trial.
             ssm:<parameter-store-name>:             ssm parameter store name.
             file:<file-path>:                       file path on the host.
+            s3:<bucket>/<key>:                      s3 object.
+            https://<host>/<path>:                  https url.
+            appconfig:<application>/<environment>/<profile>: AWS AppConfig configuration profile.
             all:                                    all existing configs. Only apply to remove-config action.
 
         -s: optionally restart after configuring the agent configuration
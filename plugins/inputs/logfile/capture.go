@@ -0,0 +1,132 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The file path can name the parts matched by the files as {name}, e.g. /var/log/apps/{service}/*.log,
+// which the log group and stream names reference the same way, e.g. /apps/{service}. A named part
+// matches a single path element, like *. The {a,b} alternatives of the glob are left unchanged.
+var filePathCaptureRegexp = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// compileFilePathCaptures returns the glob matching the files of the file path, and the regexp
+// capturing the named parts from the matched files, which is nil if the file path has none.
+func compileFilePathCaptures(filePath string) (string, *regexp.Regexp, error) {
+	if !filePathCaptureRegexp.MatchString(filePath) {
+		return filePath, nil, nil
+	}
+	var glob, expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(filePath); {
+		rest := filePath[i:]
+		if loc := filePathCaptureRegexp.FindStringSubmatchIndex(rest); loc != nil && loc[0] == 0 {
+			glob.WriteString("*")
+			fmt.Fprintf(&expr, `(?P<%s>[^/\\]+)`, rest[loc[2]:loc[3]])
+			i += loc[1]
+			continue
+		}
+		switch c := filePath[i]; {
+		case strings.HasPrefix(rest, "**"):
+			glob.WriteString("**")
+			expr.WriteString(".*")
+			i += 2
+			continue
+		case c == '*':
+			expr.WriteString(`[^/\\]*`)
+		case c == '?':
+			expr.WriteString(`[^/\\]`)
+		case c == '{':
+			// Alternatives, e.g. {a,b}
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return "", nil, fmt.Errorf("file_path %s has an unclosed {", filePath)
+			}
+			glob.WriteString(rest[:end+1])
+			alternatives := strings.Split(rest[1:end], ",")
+			for j, alternative := range alternatives {
+				alternatives[j] = regexp.QuoteMeta(alternative)
+			}
+			expr.WriteString("(?:" + strings.Join(alternatives, "|") + ")")
+			i += end + 1
+			continue
+		case c == '[':
+			// Character class, e.g. [0-9] or [!a]
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return "", nil, fmt.Errorf("file_path %s has an unclosed [", filePath)
+			}
+			glob.WriteString(rest[:end+1])
+			class := rest[1:end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+			continue
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+		glob.WriteByte(filePath[i])
+		i++
+	}
+	expr.WriteString("$")
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return "", nil, fmt.Errorf("file_path %s has issue, regexp: Compile( %v ): %v", filePath, expr.String(), err)
+	}
+	return glob.String(), re, nil
+}
+
+// filePathCaptures returns the named parts of the file path matched by the file.
+func (config *FileConfig) filePathCaptures(filename string) map[string]string {
+	if config.filePathCaptureP == nil {
+		return nil
+	}
+	match := config.filePathCaptureP.FindStringSubmatch(filename)
+	if match == nil {
+		return nil
+	}
+	captures := make(map[string]string)
+	for i, name := range config.filePathCaptureP.SubexpNames() {
+		if name != "" {
+			captures[name] = match[i]
+		}
+	}
+	return captures
+}
+
+// filePathCaptureKey returns the values of the named parts matched by the file, which tell apart
+// the files of the different services or applications of the file path.
+func (config *FileConfig) filePathCaptureKey(filename string) string {
+	captures := config.filePathCaptures(filename)
+	names := make([]string, 0, len(captures))
+	for name := range captures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var key strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&key, "%s=%s/", name, captures[name])
+	}
+	return key.String()
+}
+
+// resolveFilePathCaptures replaces the {name} references of the log group or stream name with
+// the named parts of the file path. The references to unknown names are left unchanged.
+func resolveFilePathCaptures(name string, captures map[string]string) string {
+	if len(captures) == 0 {
+		return name
+	}
+	return filePathCaptureRegexp.ReplaceAllStringFunc(name, func(reference string) string {
+		if val, ok := captures[reference[1:len(reference)-1]]; ok {
+			return val
+		}
+		return reference
+	})
+}
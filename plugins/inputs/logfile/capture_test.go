@@ -0,0 +1,70 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileFilePathCaptures(t *testing.T) {
+	testCases := map[string]struct {
+		filePath string
+		glob     string
+		filename string
+		captures map[string]string
+	}{
+		"no captures": {
+			filePath: "/var/log/{a,b}/*.log",
+			glob:     "/var/log/{a,b}/*.log",
+			filename: "/var/log/a/app.log",
+		},
+		"single capture": {
+			filePath: "/var/log/apps/{service}/*.log",
+			glob:     "/var/log/apps/*/*.log",
+			filename: "/var/log/apps/web/app.log",
+			captures: map[string]string{"service": "web"},
+		},
+		"several captures": {
+			filePath: "/var/log/{env}/**/{service}-[0-9].{log,txt}",
+			glob:     "/var/log/*/**/*-[0-9].{log,txt}",
+			filename: "/var/log/prod/a/b/api-1.txt",
+			captures: map[string]string{"env": "prod", "service": "api"},
+		},
+		"no match": {
+			filePath: "/var/log/apps/{service}/*.log",
+			glob:     "/var/log/apps/*/*.log",
+			filename: "/var/log/other/web/app.log",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			config := &FileConfig{FilePath: testCase.filePath}
+			require.NoError(t, config.init())
+			assert.Equal(t, testCase.glob, config.globPath)
+			assert.Equal(t, testCase.captures, config.filePathCaptures(testCase.filename))
+		})
+	}
+
+	_, _, err := compileFilePathCaptures("/var/log/{service}/[0-9.log")
+	assert.Error(t, err)
+}
+
+func TestFilePathCaptureKey(t *testing.T) {
+	config := &FileConfig{FilePath: "/var/log/{env}/{service}/*.log"}
+	require.NoError(t, config.init())
+	assert.Equal(t, "env=prod/service=web/", config.filePathCaptureKey("/var/log/prod/web/a.log"))
+	assert.Equal(t, config.filePathCaptureKey("/var/log/prod/web/a.log"), config.filePathCaptureKey("/var/log/prod/web/b.log"))
+	assert.NotEqual(t, config.filePathCaptureKey("/var/log/prod/web/a.log"), config.filePathCaptureKey("/var/log/prod/api/a.log"))
+	assert.Equal(t, "", (&FileConfig{}).filePathCaptureKey("/var/log/a.log"))
+}
+
+func TestResolveFilePathCaptures(t *testing.T) {
+	captures := map[string]string{"service": "web"}
+	assert.Equal(t, "/apps/web", resolveFilePathCaptures("/apps/{service}", captures))
+	assert.Equal(t, "/apps/web/{instance_id}/{$.tenant}", resolveFilePathCaptures("/apps/{service}/{instance_id}/{$.tenant}", captures))
+	assert.Equal(t, "/apps/{service}", resolveFilePathCaptures("/apps/{service}", nil))
+}
@@ -0,0 +1,205 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	compressionGzip  = "gzip"
+	compressionBzip2 = "bzip2"
+	compressionZstd  = "zstd"
+	compressionZip   = "zip"
+	compressionTar   = "tar"
+	compressionRar   = "rar"
+)
+
+// compressionFormat determines the compression of the file based on the file name
+// suffix. It returns an empty string if the file is not compressed.
+func compressionFormat(filename string) string {
+	switch filepath.Ext(filename) {
+	case ".gz", ".tgz":
+		return compressionGzip
+	case ".bz2":
+		return compressionBzip2
+	case ".zst":
+		return compressionZstd
+	case ".zip":
+		return compressionZip
+	case ".tar":
+		return compressionTar
+	case ".rar":
+		return compressionRar
+	}
+	return ""
+}
+
+// isTarball returns true if the compressed file contains a tar archive.
+func isTarball(filename string) bool {
+	ext := filepath.Ext(filename)
+	return ext == ".tgz" || ext == ".tar" || filepath.Ext(strings.TrimSuffix(filename, ext)) == ".tar"
+}
+
+// canDecompress returns true if the content of the compressed file can be read.
+func canDecompress(filename string) bool {
+	format := compressionFormat(filename)
+	return format != "" && format != compressionRar
+}
+
+// openCompressedFile returns a reader of the decompressed content of the file.
+// The files of tar and zip archives are read one after the other.
+func openCompressedFile(filename string) (io.ReadCloser, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader
+	closers := []io.Closer{f}
+	switch compressionFormat(filename) {
+	case compressionGzip:
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read gzip file %s: %w", filename, err)
+		}
+		r = gr
+		closers = append(closers, gr)
+	case compressionBzip2:
+		r = bzip2.NewReader(f)
+	case compressionZstd:
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read zstd file %s: %w", filename, err)
+		}
+		r = zr
+		closers = append(closers, closerFunc(func() error {
+			zr.Close()
+			return nil
+		}))
+	case compressionZip:
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read zip file %s: %w", filename, err)
+		}
+		zipr := &zipReader{files: zr.File}
+		r = zipr
+		closers = append(closers, zipr)
+	case compressionTar:
+		r = f
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported compressed file %s", filename)
+	}
+
+	if isTarball(filename) {
+		r = &tarReader{reader: tar.NewReader(r)}
+	}
+	return &compressedReader{Reader: r, closers: closers}, nil
+}
+
+type closerFunc func() error
+
+func (fn closerFunc) Close() error {
+	return fn()
+}
+
+type compressedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *compressedReader) Close() error {
+	var errs []error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		errs = append(errs, r.closers[i].Close())
+	}
+	return errors.Join(errs...)
+}
+
+// tarReader concatenates the regular files of a tar archive.
+type tarReader struct {
+	reader  *tar.Reader
+	current bool
+}
+
+func (r *tarReader) Read(p []byte) (int, error) {
+	for {
+		if r.current {
+			n, err := r.reader.Read(p)
+			if err != io.EOF {
+				return n, err
+			}
+			r.current = false
+			if n > 0 {
+				return n, nil
+			}
+		}
+		header, err := r.reader.Next()
+		if err != nil {
+			return 0, err
+		}
+		r.current = header.Typeflag == tar.TypeReg
+	}
+}
+
+// zipReader concatenates the files of a zip archive.
+type zipReader struct {
+	files   []*zip.File
+	current io.ReadCloser
+}
+
+func (r *zipReader) Read(p []byte) (int, error) {
+	for {
+		if r.current != nil {
+			n, err := r.current.Read(p)
+			if err != io.EOF {
+				return n, err
+			}
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+		}
+		if len(r.files) == 0 {
+			return 0, io.EOF
+		}
+		file := r.files[0]
+		r.files = r.files[1:]
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		var err error
+		if r.current, err = file.Open(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (r *zipReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
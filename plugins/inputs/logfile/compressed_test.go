@@ -0,0 +1,114 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionFormat(t *testing.T) {
+	testCases := map[string]string{
+		"/tmp/logfile.log":        "",
+		"/tmp/logfile.log.1":      "",
+		"/tmp/logfile.log.gz":     compressionGzip,
+		"/tmp/logfile.tgz":        compressionGzip,
+		"/tmp/logfile.log.bz2":    compressionBzip2,
+		"/tmp/logfile.log.zst":    compressionZstd,
+		"/tmp/logfile.log.zip":    compressionZip,
+		"/tmp/logfile.log.tar":    compressionTar,
+		"/tmp/logfile.log.tar.gz": compressionGzip,
+		"/tmp/logfile.log.rar":    compressionRar,
+	}
+	for filename, expected := range testCases {
+		assert.Equal(t, expected, compressionFormat(filename), filename)
+	}
+	assert.True(t, isTarball("/tmp/logfile.log.tar.gz"))
+	assert.True(t, isTarball("/tmp/logfile.tgz"))
+	assert.False(t, isTarball("/tmp/logfile.log.gz"))
+	assert.False(t, canDecompress("/tmp/logfile.log.rar"))
+	assert.False(t, canDecompress("/tmp/logfile.log"))
+}
+
+func TestOpenCompressedFile(t *testing.T) {
+	const content = "line 1\nline 2\n"
+	dir := t.TempDir()
+
+	testCases := map[string]func(w io.Writer){
+		"test.log.gz": func(w io.Writer) {
+			gw := gzip.NewWriter(w)
+			_, _ = gw.Write([]byte(content))
+			require.NoError(t, gw.Close())
+		},
+		"test.log.zst": func(w io.Writer) {
+			zw, err := zstd.NewWriter(w)
+			require.NoError(t, err)
+			_, _ = zw.Write([]byte(content))
+			require.NoError(t, zw.Close())
+		},
+		"test.log.zip": func(w io.Writer) {
+			zw := zip.NewWriter(w)
+			_, err := zw.Create("dir/")
+			require.NoError(t, err)
+			fw, err := zw.Create("dir/first.log")
+			require.NoError(t, err)
+			_, _ = fw.Write([]byte("line 1\n"))
+			fw, err = zw.Create("second.log")
+			require.NoError(t, err)
+			_, _ = fw.Write([]byte("line 2\n"))
+			require.NoError(t, zw.Close())
+		},
+		"test.log.tar": func(w io.Writer) {
+			writeTar(t, w)
+		},
+		"test.log.tar.gz": func(w io.Writer) {
+			gw := gzip.NewWriter(w)
+			writeTar(t, gw)
+			require.NoError(t, gw.Close())
+		},
+	}
+	for name, write := range testCases {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(dir, name)
+			f, err := os.Create(filename)
+			require.NoError(t, err)
+			write(f)
+			require.NoError(t, f.Close())
+
+			r, err := openCompressedFile(filename)
+			require.NoError(t, err)
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(got))
+			assert.NoError(t, r.Close())
+		})
+	}
+
+	invalid := filepath.Join(dir, "invalid.log.gz")
+	require.NoError(t, os.WriteFile(invalid, []byte("not gzip"), 0600))
+	_, err := openCompressedFile(invalid)
+	assert.Error(t, err)
+	_, err = openCompressedFile(filepath.Join(dir, "test.log.rar"))
+	assert.Error(t, err)
+}
+
+func writeTar(t *testing.T, w io.Writer) {
+	tw := tar.NewWriter(w)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for _, line := range []string{"line 1\n", "line 2\n"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "dir/" + line[:6], Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(line))}))
+		_, err := tw.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+}
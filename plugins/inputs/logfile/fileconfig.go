@@ -66,11 +66,21 @@ type FileConfig struct {
 	//Indicate whether it is a named pipe.
 	Pipe bool `toml:"pipe"`
 
+	//Indicate whether to read the compressed files matching the file path once from the beginning,
+	//instead of skipping them. This covers rotated files that are compressed before they are fully read.
+	ReadCompressed bool `toml:"read_compressed"`
+
+	//Indicate whether to apply the cloudwatch.aws/log-group and cloudwatch.aws/multiline-pattern annotations
+	//of the pods to their container log files, e.g. /var/log/containers/*.log.
+	KubernetesPodAnnotations bool `toml:"kubernetes_pod_annotations"`
+
 	//Indicate logType for scroll
 	LogType string `toml:"log_type"`
 
 	//Log Destination override
 	Destination string `toml:"destination"`
+	//Destinations the log events are also published to, e.g. a log group in another account.
+	AdditionalDestinations []*DestinationConfig `toml:"additional_destinations"`
 
 	//Max size for a single log event to be in bytes
 	MaxEventSize int `toml:"max_event_size"`
@@ -83,6 +93,17 @@ type FileConfig struct {
 
 	Filters []*LogFilter `toml:"filters"`
 
+	//Parse the log events in a structured format and re-serialize them as JSON.
+	Parser *LogParser `toml:"parser"`
+
+	//Turn the log events matching a pattern into metrics emitted by the metrics pipeline.
+	MetricFilters []*MetricFilter `toml:"metric_filters"`
+
+	//Keep a fraction of the log events, at random or based on the hash of a field.
+	Sampling *LogSampling `toml:"sampling"`
+	//Limit the number of log events published per second for all the files of the file config.
+	RateLimit *LogRateLimit `toml:"rate_limit"`
+
 	//Time *time.Location Go type timezone info.
 	TimezoneLoc *time.Location
 	//Regexp go type timestampFromLogLine regex
@@ -94,6 +115,48 @@ type FileConfig struct {
 	//Decoder object
 	Enc         encoding.Encoding
 	sampleCount int
+
+	//Glob of the file path with its named parts replaced, and the regexp capturing them
+	globPath         string
+	filePathCaptureP *regexp.Regexp
+}
+
+// The destination config presents an additional destination of the log events of a file config.
+// The log group, stream, class and retention default to the ones of the file config when not set.
+type DestinationConfig struct {
+	//The name of the log output plugin, its alias when set.
+	Destination string `toml:"destination"`
+	//The log group name for the destination.
+	LogGroupName string `toml:"log_group_name"`
+	//log stream name
+	LogStreamName string `toml:"log_stream_name"`
+	//log group class
+	LogGroupClass string `toml:"log_group_class"`
+	//Indicate retention in days for log group
+	RetentionInDays int `toml:"retention_in_days"`
+}
+
+func (d *DestinationConfig) logDestination(group, stream, class string, retention int) logs.LogDestination {
+	dest := logs.LogDestination{
+		Name:      d.Destination,
+		Group:     d.LogGroupName,
+		Stream:    d.LogStreamName,
+		Class:     d.LogGroupClass,
+		Retention: d.RetentionInDays,
+	}
+	if dest.Group == "" {
+		dest.Group = group
+	}
+	if dest.Stream == "" {
+		dest.Stream = stream
+	}
+	if dest.Class == "" {
+		dest.Class = class
+	}
+	if dest.Retention == 0 {
+		dest.Retention = retention
+	}
+	return dest
 }
 
 // Initialize some variables in the FileConfig object based on the rest info fetched from the configuration file.
@@ -108,6 +171,9 @@ func (config *FileConfig) init() error {
 			}
 		}
 	}
+	if config.globPath, config.filePathCaptureP, err = compileFilePathCaptures(config.FilePath); err != nil {
+		return err
+	}
 	//If the log group name is not specified, we will use the part before the last dot in the file path as the log group name.
 	if config.LogGroupName == "" && !config.PublishMultiLogs {
 		config.LogGroupName = logGroupName(config.FilePath)
@@ -160,6 +226,30 @@ func (config *FileConfig) init() error {
 		}
 	}
 
+	if config.Parser != nil {
+		if err = config.Parser.init(); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range config.MetricFilters {
+		if err = f.init(); err != nil {
+			return err
+		}
+	}
+
+	if config.Sampling != nil {
+		if err = config.Sampling.init(); err != nil {
+			return err
+		}
+	}
+
+	if config.RateLimit != nil {
+		if err = config.RateLimit.init(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -241,6 +331,20 @@ func shouldPublishHelper(filters []*LogFilter, event logs.LogEvent) bool {
 	return true
 }
 
+// ParseMessage returns the message parsed by the parser, or the original message
+// if it cannot be parsed so that the event is still published.
+func ParseMessage(logGroupName, logStreamName string, parser *LogParser, msg string) string {
+	parsed, err := parser.Parse(msg)
+	failedCount := 0
+	if err != nil {
+		failedCount = 1
+		parsed = msg
+	}
+	profiler.Profiler.AddStats([]string{"logfile", logGroupName, logStreamName, "messages", "parse_failed"}, float64(failedCount))
+
+	return parsed
+}
+
 // The default log group name calculation logic if the log group name is not specified.
 // It will use the part before the last dot in the file path, e.g.
 // file path: "/tmp/TestLogFile.log.2017-07-11-14" -> log group name: "/tmp/TestLogFile.log"
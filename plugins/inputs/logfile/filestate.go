@@ -0,0 +1,264 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The state file only had the offset and the file name before versioning was introduced.
+	stateVersion1 = 1
+	// Version 2 adds the identity of the file after the offset and the file name.
+	stateVersion2 = 2
+
+	// Number of bytes from the start of the file used to compute its fingerprint.
+	fingerprintSize = 1024
+
+	stateVersionKey         = "version"
+	stateFingerprintKey     = "fingerprint"
+	stateFingerprintSizeKey = "fingerprint_size"
+	stateDeviceKey          = "device"
+	stateInodeKey           = "inode"
+)
+
+// fileIdentity identifies a file independently of its path, so that a rotated or
+// truncated file reusing the same path can be told apart from the original one.
+type fileIdentity struct {
+	// Hash of the first fingerprintSize bytes, or of the whole file if it is smaller.
+	fingerprint     string
+	fingerprintSize int64
+	// Zero when the platform does not provide them.
+	device, inode uint64
+}
+
+func (id fileIdentity) isZero() bool {
+	return id == fileIdentity{}
+}
+
+// complete returns true when the fingerprint covers the full fingerprintSize, in
+// which case the content is considered unique enough to identify the file even
+// when it has been copied to a new inode.
+func (id fileIdentity) complete() bool {
+	return id.fingerprintSize >= fingerprintSize
+}
+
+// fileState is the content of a state file in the file state folder.
+type fileState struct {
+	version  int
+	offset   int64
+	filename string
+	identity fileIdentity
+}
+
+func (s fileState) String() string {
+	var b strings.Builder
+	b.WriteString(strconv.FormatInt(s.offset, 10))
+	b.WriteString("\n")
+	b.WriteString(s.filename)
+	if s.identity.isZero() {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n%s=%d", stateVersionKey, stateVersion2)
+	fmt.Fprintf(&b, "\n%s=%s", stateFingerprintKey, s.identity.fingerprint)
+	fmt.Fprintf(&b, "\n%s=%d", stateFingerprintSizeKey, s.identity.fingerprintSize)
+	fmt.Fprintf(&b, "\n%s=%d", stateDeviceKey, s.identity.device)
+	fmt.Fprintf(&b, "\n%s=%d", stateInodeKey, s.identity.inode)
+	return b.String()
+}
+
+// parseFileState reads both the original offset and file name format and the
+// versioned format. The offset and file name are always the first two lines so
+// the state files can still be read by older agents.
+func parseFileState(content string) (fileState, error) {
+	lines := strings.Split(content, "\n")
+	state := fileState{version: stateVersion1}
+	var err error
+	if state.offset, err = strconv.ParseInt(lines[0], 10, 64); err != nil {
+		return state, err
+	}
+	if len(lines) > 1 {
+		state.filename = lines[1]
+	}
+	if len(lines) < 3 {
+		return state, nil
+	}
+
+	values := make(map[string]string)
+	for _, line := range lines[2:] {
+		if k, v, ok := strings.Cut(line, "="); ok {
+			values[k] = v
+		}
+	}
+	if state.version, err = strconv.Atoi(values[stateVersionKey]); err != nil {
+		return state, fmt.Errorf("invalid state file version %q: %w", values[stateVersionKey], err)
+	}
+	if state.version != stateVersion2 {
+		return state, fmt.Errorf("unsupported state file version %d", state.version)
+	}
+	state.identity.fingerprint = values[stateFingerprintKey]
+	if state.identity.fingerprintSize, err = strconv.ParseInt(values[stateFingerprintSizeKey], 10, 64); err != nil {
+		return state, fmt.Errorf("invalid state file fingerprint size: %w", err)
+	}
+	if state.identity.device, err = strconv.ParseUint(values[stateDeviceKey], 10, 64); err != nil {
+		return state, fmt.Errorf("invalid state file device: %w", err)
+	}
+	if state.identity.inode, err = strconv.ParseUint(values[stateInodeKey], 10, 64); err != nil {
+		return state, fmt.Errorf("invalid state file inode: %w", err)
+	}
+	return state, nil
+}
+
+func readFileState(path string) (fileState, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	return parseFileState(string(content))
+}
+
+// getFileIdentity computes the identity of the file currently at the path.
+func getFileIdentity(filename string) (fileIdentity, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fileIdentity{}, err
+	}
+	defer f.Close()
+
+	var id fileIdentity
+	if id.device, id.inode, err = fileDeviceAndInode(f); err != nil {
+		return fileIdentity{}, err
+	}
+	h := sha256.New()
+	if id.fingerprintSize, err = io.CopyN(h, f, fingerprintSize); err != nil && !errors.Is(err, io.EOF) {
+		return fileIdentity{}, err
+	}
+	id.fingerprint = hex.EncodeToString(h.Sum(nil))
+	return id, nil
+}
+
+// matchesFile returns true if the file at the path is the same file the identity
+// was computed from. The start of the file has to be unchanged, and if the
+// fingerprint is too short to be trusted on its own, it also has to be the same
+// device and inode.
+func (id fileIdentity) matchesFile(filename string) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	device, inode, err := fileDeviceAndInode(f)
+	if err != nil {
+		return false, err
+	}
+	sameInode := id.inode == 0 || inode == 0 || (id.device == device && id.inode == inode)
+	if !sameInode && !id.complete() {
+		return false, nil
+	}
+
+	prefix := make([]byte, id.fingerprintSize)
+	if _, err = io.ReadFull(f, prefix); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			// The file is now smaller than the fingerprint, so it has been truncated.
+			return false, nil
+		}
+		return false, err
+	}
+	return id.matchesContent(prefix), nil
+}
+
+// matchesContent returns true if the content starts with the fingerprinted bytes.
+func (id fileIdentity) matchesContent(content []byte) bool {
+	if int64(len(content)) < id.fingerprintSize {
+		return false
+	}
+	sum := sha256.Sum256(content[:id.fingerprintSize])
+	return hex.EncodeToString(sum[:]) == id.fingerprint
+}
+
+// stateIndex holds the states of the state folder by the identity of their files, so the
+// files whose state is not found by their path are matched without reading every state file.
+type stateIndex struct {
+	states []indexedState
+	// positions of the states by device and inode, and by complete fingerprint
+	byInode       map[[2]uint64][]int
+	byFingerprint map[string][]int
+	// positions of the states saved without an inode, which any file can match
+	withoutInode []int
+}
+
+type indexedState struct {
+	path  string
+	state fileState
+}
+
+func newStateIndex() *stateIndex {
+	return &stateIndex{byInode: make(map[[2]uint64][]int), byFingerprint: make(map[string][]int)}
+}
+
+func (idx *stateIndex) add(path string, state fileState) {
+	i := len(idx.states)
+	idx.states = append(idx.states, indexedState{path: path, state: state})
+	if state.identity.inode == 0 {
+		idx.withoutInode = append(idx.withoutInode, i)
+	} else {
+		key := [2]uint64{state.identity.device, state.identity.inode}
+		idx.byInode[key] = append(idx.byInode[key], i)
+	}
+	if state.identity.complete() {
+		idx.byFingerprint[state.identity.fingerprint] = append(idx.byFingerprint[state.identity.fingerprint], i)
+	}
+}
+
+// find returns the first state, in the order of the state folder, that could belong to the
+// file with the identity and that matches, skipping the state file at skipPath.
+func (idx *stateIndex) find(id fileIdentity, skipPath string, match func(fileState) bool) (fileState, bool) {
+	var candidates []int
+	if id.inode == 0 {
+		// Any state with enough of the same content can match the file.
+		for i := range idx.states {
+			candidates = append(candidates, i)
+		}
+	} else {
+		candidates = append(candidates, idx.byInode[[2]uint64{id.device, id.inode}]...)
+		candidates = append(candidates, idx.withoutInode...)
+		if id.complete() {
+			candidates = append(candidates, idx.byFingerprint[id.fingerprint]...)
+		}
+		sort.Ints(candidates)
+	}
+	for j, i := range candidates {
+		if j > 0 && candidates[j-1] == i {
+			continue
+		}
+		if s := idx.states[i]; s.path != skipPath && match(s.state) {
+			return s.state, true
+		}
+	}
+	return fileState{}, false
+}
+
+// findContent returns the first state with a complete fingerprint of the start of the
+// content, skipping the state file at skipPath.
+func (idx *stateIndex) findContent(content []byte, skipPath string) (fileState, bool) {
+	if len(content) < fingerprintSize {
+		return fileState{}, false
+	}
+	sum := sha256.Sum256(content[:fingerprintSize])
+	for _, i := range idx.byFingerprint[hex.EncodeToString(sum[:])] {
+		if s := idx.states[i]; s.path != skipPath {
+			return s.state, true
+		}
+	}
+	return fileState{}, false
+}
@@ -0,0 +1,92 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileState(t *testing.T) {
+	state, err := parseFileState("123\n/tmp/logfile.log")
+	require.NoError(t, err)
+	assert.Equal(t, fileState{version: stateVersion1, offset: 123, filename: "/tmp/logfile.log"}, state)
+
+	state, err = parseFileState("123")
+	require.NoError(t, err)
+	assert.EqualValues(t, 123, state.offset)
+
+	expected := fileState{
+		version:  stateVersion2,
+		offset:   456,
+		filename: "/tmp/logfile.log",
+		identity: fileIdentity{fingerprint: "abc", fingerprintSize: 10, device: 1, inode: 2},
+	}
+	content := expected.String()
+	assert.True(t, strings.HasPrefix(content, "456\n/tmp/logfile.log\n"), "the offset and file name should stay readable by older agents")
+	state, err = parseFileState(content)
+	require.NoError(t, err)
+	assert.Equal(t, expected, state)
+
+	_, err = parseFileState("abc\n/tmp/logfile.log")
+	assert.Error(t, err)
+	_, err = parseFileState("456\n/tmp/logfile.log\nversion=3")
+	assert.Error(t, err)
+	_, err = parseFileState("456\n/tmp/logfile.log\nversion=2\nfingerprint=abc\nfingerprint_size=x")
+	assert.Error(t, err)
+}
+
+func TestFileIdentity(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.log")
+	require.NoError(t, os.WriteFile(filename, []byte("first line\n"), 0600))
+
+	id, err := getFileIdentity(filename)
+	require.NoError(t, err)
+	assert.EqualValues(t, 11, id.fingerprintSize)
+	assert.False(t, id.complete())
+
+	// Appending does not change the identity
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(strings.Repeat("x", 2*fingerprintSize) + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	match, err := id.matchesFile(filename)
+	require.NoError(t, err)
+	assert.True(t, match)
+
+	full, err := getFileIdentity(filename)
+	require.NoError(t, err)
+	assert.True(t, full.complete())
+
+	// Copying the file keeps the content, which is enough once the fingerprint is complete
+	copied := filepath.Join(dir, "test.log.1")
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(copied, content, 0600))
+	match, err = full.matchesFile(copied)
+	require.NoError(t, err)
+	assert.True(t, match)
+	match, err = id.matchesFile(copied)
+	require.NoError(t, err)
+	assert.False(t, match, "a short fingerprint should also require the same inode")
+
+	// Truncating and writing new content changes the identity
+	require.NoError(t, os.WriteFile(filename, []byte("other line\n"), 0600))
+	match, err = id.matchesFile(filename)
+	require.NoError(t, err)
+	assert.False(t, match)
+	match, err = full.matchesFile(filename)
+	require.NoError(t, err)
+	assert.False(t, match)
+
+	_, err = id.matchesFile(filepath.Join(dir, "missing.log"))
+	assert.Error(t, err)
+}
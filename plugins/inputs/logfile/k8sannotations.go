@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+)
+
+var (
+	// /var/log/containers/<pod>_<namespace>_<container>-<container id>.log
+	containerLogFileP = regexp.MustCompile(`^([^_]+)_([^_]+)_.+-[0-9a-f]{64}\.log$`)
+	// /var/log/pods/<namespace>_<pod>_<pod uid>/<container>/<restart count>.log
+	podLogDirP = regexp.MustCompile(`^([^_]+)_([^_]+)_[0-9a-f-]+$`)
+)
+
+// podAnnotations returns the cloudwatch.aws/ annotations of the pod, nil if it has none or when
+// not running in Kubernetes.
+var podAnnotations = func(namespace, name string) map[string]string {
+	podClient := k8sclient.Get().Pod
+	if podClient == nil {
+		return nil
+	}
+	if pod := podClient.AnnotatedPod(namespace, name); pod != nil {
+		return pod.Annotations
+	}
+	return nil
+}
+
+// kubernetesPodOfLogFile returns the namespace and name of the pod of a container log file.
+func kubernetesPodOfLogFile(filename string) (namespace string, name string, ok bool) {
+	if m := containerLogFileP.FindStringSubmatch(filepath.Base(filename)); m != nil {
+		return m[2], m[1], true
+	}
+	dir := filepath.Base(filepath.Dir(filepath.Dir(filename)))
+	if m := podLogDirP.FindStringSubmatch(dir); m != nil && strings.HasSuffix(filename, ".log") {
+		return m[1], m[2], true
+	}
+	return "", "", false
+}
+
+// applyPodAnnotations overrides the log group and the multiline start check of a container log
+// file with the annotations of its pod. The annotations are read when the file is discovered, so
+// a new container picks the changed annotations up.
+func (t *LogFile) applyPodAnnotations(filename string, groupName string, mlCheck func(string) bool) (string, func(string) bool) {
+	namespace, name, ok := kubernetesPodOfLogFile(filename)
+	if !ok {
+		return groupName, mlCheck
+	}
+	annotations := podAnnotations(namespace, name)
+	if group := annotations[k8sclient.AnnotationLogGroup]; group != "" {
+		groupName = group
+	}
+	if pattern := annotations[k8sclient.AnnotationMultilinePattern]; pattern != "" {
+		if p, err := regexp.Compile(pattern); err != nil {
+			t.Log.Errorf("Invalid %v annotation of pod %v/%v: %v", k8sclient.AnnotationMultilinePattern, namespace, name, err)
+		} else {
+			mlCheck = p.MatchString
+		}
+	}
+	return groupName, mlCheck
+}
@@ -0,0 +1,115 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sclient"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+func TestKubernetesPodOfLogFile(t *testing.T) {
+	containerID := strings.Repeat("0123456789abcdef", 4)
+	testCases := map[string]struct {
+		filename  string
+		namespace string
+		name      string
+		ok        bool
+	}{
+		"container": {
+			filename:  "/var/log/containers/guestbook-qbdv8_default_php-redis-" + containerID + ".log",
+			namespace: "default",
+			name:      "guestbook-qbdv8",
+			ok:        true,
+		},
+		"pod": {
+			filename:  "/var/log/pods/kube-system_coredns-7554568866-26jdf_75ab40d2-552a-4c05-82c9-0ddcb3008657/coredns/0.log",
+			namespace: "kube-system",
+			name:      "coredns-7554568866-26jdf",
+			ok:        true,
+		},
+		"other": {
+			filename: "/var/log/messages",
+		},
+		"container_without_id": {
+			filename: "/var/log/containers/guestbook-qbdv8_default_php-redis.log",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			namespace, podName, ok := kubernetesPodOfLogFile(testCase.filename)
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.namespace, namespace)
+			assert.Equal(t, testCase.name, podName)
+		})
+	}
+}
+
+func TestLogsKubernetesPodAnnotations(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	original := podAnnotations
+	defer func() { podAnnotations = original }()
+	podAnnotations = func(namespace, name string) map[string]string {
+		if namespace == "default" && name == "annotated" {
+			return map[string]string{
+				k8sclient.AnnotationLogGroup:         "/apps/annotated",
+				k8sclient.AnnotationMultilinePattern: "^begin",
+			}
+		}
+		return nil
+	}
+
+	dir := t.TempDir()
+	containerID := strings.Repeat("0123456789abcdef", 4)
+	annotated := filepath.Join(dir, "annotated_default_app-"+containerID+".log")
+	other := filepath.Join(dir, "other_default_app-"+containerID+".log")
+	require.NoError(t, os.WriteFile(annotated, []byte("begin1\nappend1\nbegin2\n"), 0644))
+	require.NoError(t, os.WriteFile(other, []byte("line\n"), 0644))
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = t.TempDir()
+	tt.FileConfig = []FileConfig{{
+		FilePath:                 filepath.Join(dir, "*.log"),
+		LogGroupName:             "/apps/default",
+		PublishMultiLogs:         true,
+		FromBeginning:            true,
+		KubernetesPodAnnotations: true,
+	}}
+	require.NoError(t, tt.FileConfig[0].init())
+	tt.started = true
+
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 2)
+	groups := map[string]string{}
+	for _, lsrc := range lsrcs {
+		groups[lsrc.Description()] = lsrc.Group()
+		if lsrc.Description() != annotated {
+			lsrc.Stop()
+			continue
+		}
+		evts := make(chan logs.LogEvent, 2)
+		lsrc.SetOutput(func(e logs.LogEvent) {
+			if e != nil {
+				evts <- e
+			}
+		})
+		assert.Equal(t, "begin1\nappend1", (<-evts).Message())
+		assert.Equal(t, "begin2", (<-evts).Message())
+		lsrc.Stop()
+	}
+	assert.Equal(t, map[string]string{
+		annotated: "/apps/annotated",
+		other:     "/apps/default",
+	}, groups)
+	tt.Stop()
+}
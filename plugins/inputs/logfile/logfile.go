@@ -9,8 +9,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -22,6 +23,10 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
 )
 
+// archiveReadSuffix is appended to the state file of a compressed file for the marker
+// saved once it has been fully read.
+const archiveReadSuffix = ".fully_read"
+
 type LogFile struct {
 	//array of file config for file to be monitored.
 	FileConfig []FileConfig `toml:"file_config"`
@@ -33,14 +38,20 @@ type LogFile struct {
 	Log telegraf.Logger `toml:"-"`
 
 	configs           map[*FileConfig]map[string]*tailerSrc
+	stateIndex        *stateIndex
+	readArchivesMu    sync.Mutex
+	readArchives      map[string]fileIdentity
 	done              chan struct{}
 	removeTailerSrcCh chan *tailerSrc
 	started           bool
+	startOnce         sync.Once
+	startErr          error
 }
 
 func NewLogFile() *LogFile {
 	return &LogFile{
 		configs:           make(map[*FileConfig]map[string]*tailerSrc),
+		readArchives:      make(map[string]fileIdentity),
 		done:              make(chan struct{}),
 		removeTailerSrcCh: make(chan *tailerSrc, 100),
 	}
@@ -56,6 +67,12 @@ const sampleConfig = `
   ##
   ## See https://github.com/gobwas/glob for more examples
   ##
+  ## The file path can name a path element as {name}, e.g. "/var/log/apps/{service}/*.log",
+  ## to tail the most recent file of each service and reference it in the log group and
+  ## stream names, e.g. "/apps/{service}". The log group and stream names can also reference
+  ## the fields of the JSON log events as {$.field}, e.g. "/apps/{$.tenant}", to route each
+  ## log event to the log group or stream of its field values.
+  ##
   ## Default log output destination name for all file_configs
   ## each file_config can override its own destination if needed
   destination = "cloudwatchlogs"
@@ -85,6 +102,48 @@ const sampleConfig = `
       max_event_size = 262144
       ## Suffix to be added to truncated logline to indicate its truncation, defaults to "[Truncated...]"
       truncate_suffix = "[Truncated...]"
+      ## Apply the cloudwatch.aws/log-group and cloudwatch.aws/multiline-pattern annotations
+      ## of the pods to their container log files, e.g. /var/log/containers/*.log
+      kubernetes_pod_annotations = false
+      ## Read the compressed files (.gz, .bz2, .zst, .zip, .tar) that match file_path once,
+      ## from the beginning, instead of skipping them
+      read_compressed = false
+      ## Parse the log events and re-serialize them as JSON. The format is one of
+      ## json, logfmt, syslog_rfc3164, syslog_rfc5424 or regex (with named capture groups).
+      # [inputs.logs.file_config.parser]
+      #   format = "regex"
+      #   pattern = "^(?P<level>\\w+) (?P<message>.*)$"
+      #   drop_fields = ["debug"]
+      #   mask_fields = ["password"]
+      #   [inputs.logs.file_config.parser.rename_fields]
+      #     msg = "message"
+      ## Turn the log events matching a pattern into metrics, counting the events or
+      ## using the numeric value of a named capture group. Dimension values can
+      ## reference the named capture groups as {name}.
+      # [[inputs.logs.file_config.metric_filters]]
+      #   metric_name = "RequestLatency"
+      #   pattern = "status=(?P<status>\\d+) latency=(?P<latency>[\\d.]+)ms"
+      #   value_group = "latency"
+      #   unit = "Milliseconds"
+      #   [inputs.logs.file_config.metric_filters.dimensions]
+      #     Status = "{status}"
+      ## Keep a fraction of the log events, at random or based on the hash of a field
+      ## of the JSON log events so the events with the same value are kept together.
+      # [inputs.logs.file_config.sampling]
+      #   rate = 0.1
+      #   hash_field = "request_id"
+      ## Limit the log events published per second for all the files of this file config.
+      ## The events over the limit are dropped, or summarized as "N lines suppressed".
+      # [inputs.logs.file_config.rate_limit]
+      #   events_per_second = 100.0
+      #   burst = 200
+      #   on_overflow = "summarize"
+      ## Publish the log events to other log outputs as well, referenced by their alias.
+      ## The file offset only moves forward once all the destinations published the events.
+      # [[inputs.logs.file_config.additional_destinations]]
+      #   destination = "cloudwatchlogs_archive"
+      #   log_group_name = "security-archive"
+      #   retention_in_days = 365
 
 `
 
@@ -96,11 +155,37 @@ func (t *LogFile) Description() string {
 	return "Stream a log file, like the tail -f command"
 }
 
+// Gather adds the metrics of the metric filters recorded since the previous gather.
 func (t *LogFile) Gather(acc telegraf.Accumulator) error {
+	now := time.Now()
+	for i := range t.FileConfig {
+		for _, f := range t.FileConfig[i].MetricFilters {
+			f.gather(acc, now)
+		}
+	}
 	return nil
 }
 
+// Start is called by the log agent without an accumulator, and by the metrics
+// pipeline with one when the input is also used for the metric filters.
 func (t *LogFile) Start(acc telegraf.Accumulator) error {
+	t.startOnce.Do(func() {
+		t.startErr = t.start()
+	})
+	if t.startErr != nil {
+		return t.startErr
+	}
+	if acc != nil {
+		for i := range t.FileConfig {
+			for _, f := range t.FileConfig[i].MetricFilters {
+				f.start()
+			}
+		}
+	}
+	return nil
+}
+
+func (t *LogFile) start() error {
 	// Create the log file state folder.
 	err := os.MkdirAll(t.FileStateFolder, 0755)
 	if err != nil {
@@ -151,6 +236,9 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 	var srcs []logs.LogSrc
 
 	t.cleanUpStoppedTailerSrc()
+	// The state folder is indexed again by the first file of this pass whose state is not
+	// found by its path.
+	t.stateIndex = nil
 
 	// Create a "tailer" for each file
 	for i := range t.FileConfig {
@@ -168,19 +256,32 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 
 			if _, ok := dests[filename]; ok {
 				continue
+			}
+
+			archive := fileconfig.ReadCompressed && canDecompress(filename)
+			if archive {
+				if t.archiveRead(filename) {
+					continue
+				}
 			} else if fileconfig.AutoRemoval {
 				// This logic means auto_removal does not work with publish_multi_logs
-				for _, dst := range dests {
-					// Stop all other tailers in favor of the newly found file
-					dst.tailer.StopAtEOF()
+				key := fileconfig.filePathCaptureKey(filename)
+				for dstFilename, dst := range dests {
+					// Stop all other tailers of the same named parts in favor of the newly found file
+					if fileconfig.filePathCaptureKey(dstFilename) == key {
+						dst.tailer.StopAtEOF()
+					}
 				}
 			}
 
 			var seekFile *tail.SeekInfo
 			offset, err := t.restoreState(filename)
+			if err != nil && archive {
+				offset, err = t.restoreCompressedState(filename)
+			}
 			if err == nil { // Missing state file would be an error too
 				seekFile = &tail.SeekInfo{Whence: io.SeekStart, Offset: offset}
-			} else if !fileconfig.Pipe && !fileconfig.FromBeginning {
+			} else if !archive && !fileconfig.Pipe && !fileconfig.FromBeginning {
 				seekFile = &tail.SeekInfo{Whence: io.SeekEnd, Offset: 0}
 			}
 
@@ -189,20 +290,38 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				isutf16 = true
 			}
 
-			tailer, err := tail.TailFile(filename,
-				tail.Config{
-					ReOpen:      false,
-					Follow:      true,
-					Location:    seekFile,
-					MustExist:   true,
-					Pipe:        fileconfig.Pipe,
-					Poll:        true,
-					MaxLineSize: fileconfig.MaxEventSize,
-					IsUTF16:     isutf16,
-				})
+			var tailer *tail.Tail
+			if archive {
+				// Compressed files are read once from the beginning, or from where the
+				// previous run stopped, since they are not expected to change.
+				var r io.ReadCloser
+				if r, err = openCompressedFile(filename); err == nil {
+					tailer, err = tail.TailReader(filename, r,
+						tail.Config{
+							Location:    seekFile,
+							MaxLineSize: fileconfig.MaxEventSize,
+							IsUTF16:     isutf16,
+						})
+				}
+			} else {
+				tailer, err = tail.TailFile(filename,
+					tail.Config{
+						ReOpen:      false,
+						Follow:      true,
+						Location:    seekFile,
+						MustExist:   true,
+						Pipe:        fileconfig.Pipe,
+						Poll:        true,
+						MaxLineSize: fileconfig.MaxEventSize,
+						IsUTF16:     isutf16,
+					})
+			}
 
 			if err != nil {
 				t.Log.Errorf("Failed to tail file %v with error: %v", filename, err)
+				if archive {
+					t.markArchiveRead(filename)
+				}
 				continue
 			}
 
@@ -211,8 +330,12 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				mlCheck = fileconfig.isMultilineStart
 			}
 
-			groupName := fileconfig.LogGroupName
-			streamName := fileconfig.LogStreamName
+			captures := fileconfig.filePathCaptures(filename)
+			groupName := resolveFilePathCaptures(fileconfig.LogGroupName, captures)
+			streamName := resolveFilePathCaptures(fileconfig.LogStreamName, captures)
+			if fileconfig.KubernetesPodAnnotations {
+				groupName, mlCheck = t.applyPodAnnotations(filename, groupName, mlCheck)
+			}
 
 			// In case of multilog, the group and stream has to be generated here
 			// since it is based on the actual file name
@@ -220,7 +343,7 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				if groupName == "" {
 					groupName = generateLogGroupName(filename)
 				} else {
-					streamName = generateLogStreamName(filename, fileconfig.LogStreamName)
+					streamName = generateLogStreamName(filename, streamName)
 				}
 			}
 
@@ -229,21 +352,38 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				destination = t.Destination
 			}
 
+			var additionalDestinations []logs.LogDestination
+			for _, d := range fileconfig.AdditionalDestinations {
+				dest := d.logDestination(groupName, streamName, fileconfig.LogGroupClass, fileconfig.RetentionInDays)
+				dest.Group = resolveFilePathCaptures(dest.Group, captures)
+				dest.Stream = resolveFilePathCaptures(dest.Stream, captures)
+				additionalDestinations = append(additionalDestinations, dest)
+			}
+
 			src := NewTailerSrc(
 				groupName, streamName,
-				t.Destination,
+				destination,
+				additionalDestinations,
 				t.getStateFilePath(filename),
 				fileconfig.LogGroupClass,
 				tailer,
-				fileconfig.AutoRemoval,
+				fileconfig.AutoRemoval && !archive,
 				mlCheck,
 				fileconfig.Filters,
+				fileconfig.Parser,
+				fileconfig.MetricFilters,
+				fileconfig.Sampling,
+				fileconfig.RateLimit,
 				fileconfig.timestampFromLogLine,
 				fileconfig.Enc,
 				fileconfig.MaxEventSize,
 				fileconfig.TruncateSuffix,
 				fileconfig.RetentionInDays,
 			)
+			src.inputDone = t.done
+			if archive {
+				src.fullyReadFn = func() { t.saveArchiveRead(filename) }
+			}
 
 			src.AddCleanUpFn(func(ts *tailerSrc) func() {
 				return func() {
@@ -266,6 +406,9 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 
 func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 	filePath := fileconfig.FilePath
+	if fileconfig.globPath != "" {
+		filePath = fileconfig.globPath
+	}
 	blacklistP := fileconfig.BlacklistRegexP
 	g, err := globpath.Compile(filePath)
 	if err != nil {
@@ -273,8 +416,10 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 	}
 
 	var targetFileList []string
-	var targetFileName string
-	var targetModTime time.Time
+	var archives []string
+	// The most recent file, for each of the values of the named parts of the file path
+	targetFileNames := make(map[string]string)
+	targetModTimes := make(map[string]time.Time)
 	for matchedFileName, matchedFileInfo := range g.Match() {
 
 		// we do not allow customer to monitor the file in t.FileStateFolder, it will monitor all of the state files
@@ -282,7 +427,8 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 			continue
 		}
 
-		if isCompressedFile(matchedFileName) {
+		archive := fileconfig.ReadCompressed && canDecompress(matchedFileName)
+		if isCompressedFile(matchedFileName) && !archive {
 			continue
 		}
 
@@ -298,49 +444,141 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 		if blacklistP != nil && blacklistP.MatchString(fileBaseName) {
 			continue
 		}
-		if !fileconfig.PublishMultiLogs {
-			if targetFileName == "" || matchedFileInfo.ModTime().After(targetModTime) {
-				targetFileName = matchedFileName
-				targetModTime = matchedFileInfo.ModTime()
+		if archive {
+			// Compressed files are rotated files, so they do not compete with the file being written to.
+			archives = append(archives, matchedFileName)
+		} else if !fileconfig.PublishMultiLogs {
+			key := fileconfig.filePathCaptureKey(matchedFileName)
+			if _, ok := targetFileNames[key]; !ok || matchedFileInfo.ModTime().After(targetModTimes[key]) {
+				targetFileNames[key] = matchedFileName
+				targetModTimes[key] = matchedFileInfo.ModTime()
 			}
 		} else {
 			targetFileList = append(targetFileList, matchedFileName)
 		}
 	}
-	//If targetFileName != "", it means customer doesn't enable publish_multi_logs feature, targetFileList should be empty in this case.
-	if targetFileName != "" {
-		targetFileList = append(targetFileList, targetFileName)
+	//If targetFileNames is not empty, it means customer doesn't enable publish_multi_logs feature, targetFileList should be empty in this case.
+	keys := make([]string, 0, len(targetFileNames))
+	for key := range targetFileNames {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		targetFileList = append(targetFileList, targetFileNames[key])
 	}
 
-	return targetFileList, nil
+	return append(targetFileList, archives...), nil
 }
 
 // The plugin will look at the state folder, and restore the offset of the file seeked if such state exists.
+// When the state has the identity of the file, the offset is only restored if the file at the path is still
+// the same file. Otherwise the file has been rotated or truncated and is read from the beginning, unless the
+// state of another path matches it, which happens when a file is renamed by the rotation.
 func (t *LogFile) restoreState(filename string) (int64, error) {
 	filePath := t.getStateFilePath(filename)
 
 	if _, err := os.Stat(filePath); err != nil {
 		t.Log.Debugf("The state file %s for %s does not exist: %v", filePath, filename, err)
+		if offset, ok := t.restoreStateByIdentity(filename, filePath); ok {
+			return offset, nil
+		}
 		return 0, err
 	}
 
-	byteArray, err := os.ReadFile(filePath)
+	state, err := readFileState(filePath)
 	if err != nil {
-		t.Log.Warnf("Issue encountered when reading offset from file %s: %v", filename, err)
+		t.Log.Warnf("Issue encountered when reading offset from state file %s for %s: %v", filePath, filename, err)
 		return 0, err
 	}
 
-	offset, err := strconv.ParseInt(strings.Split(string(byteArray), "\n")[0], 10, 64)
+	if !state.identity.isZero() {
+		match, err := state.identity.matchesFile(filename)
+		if err != nil {
+			t.Log.Warnf("Issue encountered when checking the identity of file %s: %v", filename, err)
+			return 0, err
+		}
+		if !match {
+			if offset, ok := t.restoreStateByIdentity(filename, filePath); ok {
+				return offset, nil
+			}
+			t.Log.Infof("The file %s has been rotated or truncated since its state was saved, reading from the beginning", filename)
+			return 0, nil
+		}
+	}
+
+	if state.offset < 0 {
+		return 0, fmt.Errorf("negative state file offset, %v, %v", filePath, state.offset)
+	}
+	t.Log.Infof("Reading from offset %v in %s", state.offset, filename)
+	return state.offset, nil
+}
+
+// restoreStateByIdentity looks for a state file saved under another path with the identity of the file.
+func (t *LogFile) restoreStateByIdentity(filename, stateFilePath string) (int64, bool) {
+	id, err := getFileIdentity(filename)
+	if err != nil {
+		return 0, false
+	}
+	state, ok := t.getStateIndex().find(id, stateFilePath, func(state fileState) bool {
+		match, err := state.identity.matchesFile(filename)
+		return err == nil && match
+	})
+	if !ok {
+		return 0, false
+	}
+	t.Log.Infof("Reading from offset %v in %s, which was previously tailed as %s", state.offset, filename, state.filename)
+	return state.offset, true
+}
+
+// restoreCompressedState looks for the state of the file that was compressed into the archive by the
+// rotation before it was fully read. The decompressed content starts with the fingerprint of that file.
+func (t *LogFile) restoreCompressedState(filename string) (int64, error) {
+	r, err := openCompressedFile(filename)
 	if err != nil {
-		t.Log.Warnf("Issue encountered when parsing offset value %v: %v", byteArray, err)
 		return 0, err
 	}
+	defer r.Close()
+	prefix := make([]byte, fingerprintSize)
+	n, err := io.ReadFull(r, prefix)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return 0, err
+	}
+	prefix = prefix[:n]
+
+	// Without an inode to compare, only a complete fingerprint identifies the file.
+	state, ok := t.getStateIndex().findContent(prefix, t.getStateFilePath(filename))
+	if !ok {
+		return 0, fmt.Errorf("no state found for the content of %s", filename)
+	}
+	t.Log.Infof("Reading from offset %v in %s, which was previously tailed uncompressed as %s", state.offset, filename, state.filename)
+	return state.offset, nil
+}
 
-	if offset < 0 {
-		return 0, fmt.Errorf("negative state file offset, %v, %v", filePath, offset)
+// getStateIndex returns the index of the states in the state folder, which is loaded at
+// most once per FindLogSrc pass.
+func (t *LogFile) getStateIndex() *stateIndex {
+	if t.stateIndex != nil {
+		return t.stateIndex
+	}
+	t.stateIndex = newStateIndex()
+	if t.FileStateFolder == "" {
+		return t.stateIndex
+	}
+	files, err := filepath.Glob(t.FileStateFolder + string(filepath.Separator) + "*")
+	if err != nil {
+		return t.stateIndex
+	}
+	for _, file := range files {
+		if strings.Contains(file, logscommon.WindowsEventLogPrefix) || strings.HasSuffix(file, archiveReadSuffix) {
+			continue
+		}
+		state, err := readFileState(file)
+		if err != nil || state.identity.isZero() || state.offset <= 0 {
+			continue
+		}
+		t.stateIndex.add(file, state)
 	}
-	t.Log.Infof("Reading from offset %v in %s", offset, filename)
-	return offset, nil
+	return t.stateIndex
 }
 
 func (t *LogFile) getStateFilePath(filename string) string {
@@ -389,10 +627,13 @@ func (t *LogFile) cleanUpStoppedTailerSrc() {
 	for {
 		select {
 		case rts := <-t.removeTailerSrcCh:
-			for _, dsts := range t.configs {
+			for fileconfig, dsts := range t.configs {
 				for n, ts := range dsts {
 					if ts == rts {
 						delete(dsts, n)
+						if fileconfig.ReadCompressed && canDecompress(n) {
+							t.markArchiveRead(n)
+						}
 					}
 				}
 			}
@@ -402,23 +643,84 @@ func (t *LogFile) cleanUpStoppedTailerSrc() {
 	}
 }
 
-// Compressed file should be skipped.
-// This func is to determine whether the file is compressed or not based on the file name suffix.
-func isCompressedFile(filename string) bool {
-	suffix := filepath.Ext(filename)
-	switch suffix {
-	case
-		".tar",
-		".zst",
-		".gz",
-		".zip",
-		".bz2",
-		".rar":
+// Remember the compressed files that have been read, so they are only read again
+// if a different file is rotated to the same path.
+func (t *LogFile) markArchiveRead(filename string) {
+	id, err := getFileIdentity(filename)
+	if err != nil {
+		return
+	}
+	t.readArchivesMu.Lock()
+	defer t.readArchivesMu.Unlock()
+	t.readArchives[filename] = id
+}
+
+// archiveRead returns true if the compressed file has already been read, in this run or
+// in a previous one, and forgets it when a different file has been rotated to the same path.
+func (t *LogFile) archiveRead(filename string) bool {
+	t.readArchivesMu.Lock()
+	defer t.readArchivesMu.Unlock()
+	if id, ok := t.readArchives[filename]; ok {
+		if match, err := id.matchesFile(filename); err == nil && match {
+			return true
+		}
+		delete(t.readArchives, filename)
+	}
+	if id, ok := t.savedArchiveRead(filename); ok {
+		t.readArchives[filename] = id
 		return true
 	}
 	return false
 }
 
+// saveArchiveRead writes the size and the identity of the compressed file to the state
+// folder once it has been fully read, so it is not decompressed again after a restart.
+func (t *LogFile) saveArchiveRead(filename string) {
+	stateFilePath := t.getStateFilePath(filename)
+	if stateFilePath == "" {
+		return
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return
+	}
+	id, err := getFileIdentity(filename)
+	if err != nil {
+		return
+	}
+	state := fileState{offset: info.Size(), filename: filename, identity: id}
+	if err = os.WriteFile(stateFilePath+archiveReadSuffix, []byte(state.String()), stateFileMode); err != nil {
+		t.Log.Errorf("Failed to save that the compressed file %s has been read: %v", filename, err)
+	}
+}
+
+// savedArchiveRead returns the identity of the compressed file if it matches the one saved
+// once it was fully read. A marker left by a different file at the same path is removed.
+func (t *LogFile) savedArchiveRead(filename string) (fileIdentity, bool) {
+	stateFilePath := t.getStateFilePath(filename)
+	if stateFilePath == "" {
+		return fileIdentity{}, false
+	}
+	markerPath := stateFilePath + archiveReadSuffix
+	state, err := readFileState(markerPath)
+	if err != nil {
+		return fileIdentity{}, false
+	}
+	if info, err := os.Stat(filename); err == nil && info.Size() == state.offset {
+		if match, err := state.identity.matchesFile(filename); err == nil && match {
+			return state.identity, true
+		}
+	}
+	_ = os.Remove(markerPath)
+	return fileIdentity{}, false
+}
+
+// Compressed file should be skipped, unless read_compressed is enabled.
+// This func is to determine whether the file is compressed or not based on the file name suffix.
+func isCompressedFile(filename string) bool {
+	return compressionFormat(filename) != ""
+}
+
 func escapeFilePath(filePath string) string {
 	escapedFilePath := filepath.ToSlash(filePath)
 	escapedFilePath = strings.Replace(escapedFilePath, "/", "_", -1)
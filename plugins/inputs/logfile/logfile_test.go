@@ -4,15 +4,18 @@
 package logfile
 
 import (
+	"compress/gzip"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/text/encoding/simplifiedchinese"
@@ -89,6 +92,43 @@ func TestLogs(t *testing.T) {
 	tt.Stop()
 }
 
+func TestLogsAdditionalDestinations(t *testing.T) {
+	tmpfile, err := createTempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.Destination = "cloudwatchlogs"
+	tt.FileConfig = []FileConfig{{
+		FilePath:        tmpfile.Name(),
+		LogGroupName:    "group",
+		LogStreamName:   "stream",
+		RetentionInDays: 7,
+		Destination:     "cloudwatchlogs_primary",
+		AdditionalDestinations: []*DestinationConfig{
+			{Destination: "cloudwatchlogs_archive", LogGroupName: "archive", RetentionInDays: 365},
+			{Destination: "file"},
+		},
+	}}
+	require.NoError(t, tt.FileConfig[0].init())
+	tt.started = true
+
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	lsrc := lsrcs[0]
+	assert.Equal(t, "cloudwatchlogs_primary", lsrc.Destination())
+	multiDestSrc, ok := lsrc.(logs.MultiDestLogSrc)
+	require.True(t, ok)
+	assert.Equal(t, []logs.LogDestination{
+		{Name: "cloudwatchlogs_archive", Group: "archive", Stream: "stream", Retention: 365},
+		{Name: "file", Group: "group", Stream: "stream", Retention: 7},
+	}, multiDestSrc.AdditionalDestinations())
+
+	lsrc.Stop()
+	tt.Stop()
+}
+
 func TestLogsEncoding(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	//2 * rune_len when it is coded in gbk encoding.
@@ -226,6 +266,84 @@ func TestRestoreState(t *testing.T) {
 	tt.Stop()
 }
 
+func TestRestoreStateWithFileIdentity(t *testing.T) {
+	tmpfolder := t.TempDir()
+	logFilePath := filepath.Join(t.TempDir(), "logfile.log")
+	require.NoError(t, os.WriteFile(logFilePath, []byte(strings.Repeat("line\n", 100)), 0600))
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = tmpfolder
+
+	id, err := getFileIdentity(logFilePath)
+	require.NoError(t, err)
+	state := fileState{offset: 250, filename: logFilePath, identity: id}
+	require.NoError(t, os.WriteFile(tt.getStateFilePath(logFilePath), []byte(state.String()), 0600))
+
+	roffset, err := tt.restoreState(logFilePath)
+	require.NoError(t, err)
+	assert.EqualValues(t, 250, roffset)
+
+	// Rotate the file by renaming it and create a new one with the same path
+	rotatedFilePath := logFilePath + ".1"
+	require.NoError(t, os.Rename(logFilePath, rotatedFilePath))
+	require.NoError(t, os.WriteFile(logFilePath, []byte("new line\n"), 0600))
+
+	roffset, err = tt.restoreState(logFilePath)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, roffset, "the new file should be read from the beginning")
+
+	roffset, err = tt.restoreState(rotatedFilePath)
+	require.NoError(t, err)
+	assert.EqualValues(t, 250, roffset, "the rotated file should continue from the saved offset")
+
+	// Truncate the file in place, like copytruncate
+	require.NoError(t, os.Truncate(rotatedFilePath, 0))
+	_, err = tt.restoreState(rotatedFilePath)
+	assert.Error(t, err, "there is no state for the truncated file")
+
+	tt.Stop()
+}
+
+func TestRestoreStateByIdentityIndexedOncePerPass(t *testing.T) {
+	tmpfolder := t.TempDir()
+	dir := t.TempDir()
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = tmpfolder
+	tt.started = true
+
+	// Save the states of files that are then rotated by renaming them
+	var rotated []string
+	for i := 0; i < 3; i++ {
+		logFilePath := filepath.Join(dir, fmt.Sprintf("app%d.log", i))
+		require.NoError(t, os.WriteFile(logFilePath, []byte(strings.Repeat(fmt.Sprintf("line %d\n", i), 300)), 0600))
+		id, err := getFileIdentity(logFilePath)
+		require.NoError(t, err)
+		state := fileState{offset: int64(100 + i), filename: logFilePath, identity: id}
+		require.NoError(t, os.WriteFile(tt.getStateFilePath(logFilePath), []byte(state.String()), 0600))
+		require.NoError(t, os.Rename(logFilePath, logFilePath+".1"))
+		rotated = append(rotated, logFilePath+".1")
+	}
+
+	for i, filename := range rotated {
+		roffset, err := tt.restoreState(filename)
+		require.NoError(t, err)
+		assert.EqualValues(t, 100+i, roffset)
+	}
+	// The state folder is read once for all the files of the pass
+	index := tt.stateIndex
+	require.NotNil(t, index)
+	assert.Len(t, index.states, 3)
+	_, err := tt.restoreState(rotated[0])
+	require.NoError(t, err)
+	assert.Same(t, index, tt.stateIndex)
+
+	// and again in the next pass
+	tt.FindLogSrc()
+	assert.Nil(t, tt.stateIndex)
+}
+
 func TestMultipleFilesForSameConfig(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	tmpfile1, err := createTempFile("", "tmp1_")
@@ -280,6 +398,48 @@ func TestMultipleFilesForSameConfig(t *testing.T) {
 	tt.Stop()
 }
 
+func TestLogsFilePathCaptures(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for _, f := range []struct {
+		path string
+		age  time.Duration
+	}{
+		{path: "web/old.log", age: time.Hour},
+		{path: "web/new.log"},
+		{path: "api/app.log"},
+	} {
+		filename := filepath.Join(dir, f.path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, os.WriteFile(filename, []byte("line\n"), 0644))
+		require.NoError(t, os.Chtimes(filename, now.Add(-f.age), now.Add(-f.age)))
+	}
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = t.TempDir()
+	tt.FileConfig = []FileConfig{{
+		FilePath:      filepath.Join(dir, "{service}", "*.log"),
+		LogGroupName:  "/apps/{service}",
+		LogStreamName: "{service}-{instance_id}",
+	}}
+	require.NoError(t, tt.FileConfig[0].init())
+	tt.started = true
+
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 2, "the most recent file of each service should be tailed")
+	got := map[string]string{}
+	for _, lsrc := range lsrcs {
+		got[lsrc.Description()] = lsrc.Group() + " " + lsrc.Stream()
+		lsrc.Stop()
+	}
+	assert.Equal(t, map[string]string{
+		filepath.Join(dir, "web", "new.log"): "/apps/web web-{instance_id}",
+		filepath.Join(dir, "api", "app.log"): "/apps/api api-{instance_id}",
+	}, got)
+	tt.Stop()
+}
+
 func TestLogsMultilineEvent(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	logEntryString := "multiline begin1\n append line1\nmultiline begin2\n append line2"
@@ -1128,3 +1288,157 @@ func TestGenerateLogGroupName(t *testing.T) {
 		logGroupName,
 		expectLogGroup))
 }
+
+func TestLogsCompressedFileReadOnce(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	dir := t.TempDir()
+	stateDir := t.TempDir()
+	filename := filepath.Join(dir, "test.log.gz")
+	f, err := os.Create(filename)
+	require.NoError(t, err)
+	gw := gzip.NewWriter(f)
+	_, err = gw.Write([]byte("line 1\nline 2\n"))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	require.NoError(t, f.Close())
+
+	newLogFile := func() *LogFile {
+		tt := NewLogFile()
+		tt.Log = TestLogger{t}
+		tt.FileStateFolder = stateDir
+		tt.FileConfig = []FileConfig{{FilePath: filename, ReadCompressed: true}}
+		tt.FileConfig[0].init()
+		tt.started = true
+		return tt
+	}
+
+	tt := newLogFile()
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+
+	var messages []string
+	done := make(chan struct{})
+	lsrcs[0].SetOutput(func(e logs.LogEvent) {
+		if e == nil {
+			close(done)
+			return
+		}
+		messages = append(messages, e.Message())
+		e.Done()
+	})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("compressed file was not read to the end")
+	}
+	assert.Equal(t, []string{"line 1", "line 2"}, messages)
+	lsrcs[0].Stop()
+	assert.Eventually(t, func() bool {
+		state, err := readFileState(tt.getStateFilePath(filename))
+		return err == nil && state.offset == int64(len("line 1\nline 2\n"))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The archive is only read once
+	assert.Eventually(t, func() bool {
+		return len(tt.FindLogSrc()) == 0 && len(tt.readArchives) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	tt.Stop()
+
+	// A restart skips the archive without decompressing it, since it was fully read
+	tt = newLogFile()
+	assert.FileExists(t, tt.getStateFilePath(filename)+archiveReadSuffix)
+	assert.Empty(t, tt.FindLogSrc())
+	tt.Stop()
+
+	// Without the marker, a restart resumes from the saved state instead of reading the archive again
+	require.NoError(t, os.Remove(tt.getStateFilePath(filename)+archiveReadSuffix))
+	tt = newLogFile()
+	lsrcs = tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	done = make(chan struct{})
+	lsrcs[0].SetOutput(func(e logs.LogEvent) {
+		if e == nil {
+			close(done)
+			return
+		}
+		t.Errorf("unexpected event after restart: %v", e.Message())
+	})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("compressed file was not read to the end")
+	}
+	lsrcs[0].Stop()
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(tt.getStateFilePath(filename) + archiveReadSuffix)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	tt.Stop()
+
+	// A different archive rotated to the same path is read
+	f, err = os.Create(filename)
+	require.NoError(t, err)
+	gw = gzip.NewWriter(f)
+	_, err = gw.Write([]byte("line 3\n"))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	require.NoError(t, f.Close())
+	tt = newLogFile()
+	lsrcs = tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	assert.NoFileExists(t, tt.getStateFilePath(filename)+archiveReadSuffix)
+	lsrcs[0].Stop()
+	tt.Stop()
+}
+
+func TestLogsMetricFilters(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	tmpfile, err := createTempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString("DEBUG cache hit\nERROR timeout\nDEBUG cache miss\n")
+	require.NoError(t, err)
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = t.TempDir()
+	tt.FileConfig = []FileConfig{{
+		FilePath:      tmpfile.Name(),
+		FromBeginning: true,
+		Filters:       []*LogFilter{{Type: excludeFilterType, Expression: "^DEBUG"}},
+		MetricFilters: []*MetricFilter{{MetricName: "DebugLines", Pattern: "^DEBUG"}},
+	}}
+	// Started by the log agent, then by the metrics pipeline.
+	require.NoError(t, tt.Start(nil))
+	acc := &testutil.Accumulator{}
+	require.NoError(t, tt.Start(acc))
+
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	done := make(chan struct{})
+	lsrcs[0].SetOutput(func(e logs.LogEvent) {
+		if e == nil {
+			return
+		}
+		// Only the events passing the filters are published.
+		assert.Equal(t, "ERROR timeout", e.Message())
+		close(done)
+	})
+	<-done
+
+	// The last line may still be read after the published event.
+	var count int64
+	assert.Eventually(t, func() bool {
+		acc.ClearMetrics()
+		require.NoError(t, tt.Gather(acc))
+		for _, m := range acc.GetTelegrafMetrics() {
+			assert.Equal(t, "DebugLines", m.Name())
+			assert.Equal(t, map[string]string{aggregationIntervalTagKey: "1m0s"}, m.Tags())
+			count += m.Fields()["value"].(int64)
+		}
+		return count == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lsrcs[0].Stop()
+	tt.Stop()
+}
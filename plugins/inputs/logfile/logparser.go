@@ -0,0 +1,300 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-logfmt/logfmt"
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/rfc3164"
+	"github.com/leodido/go-syslog/v4/rfc5424"
+)
+
+const (
+	jsonParserFormat      = "json"
+	logfmtParserFormat    = "logfmt"
+	rfc3164ParserFormat   = "syslog_rfc3164"
+	rfc5424ParserFormat   = "syslog_rfc5424"
+	regexParserFormat     = "regex"
+	defaultMaskedValue    = "****"
+	fieldPathSeparator    = "."
+	syslogMessageField    = "message"
+	syslogTimestampLayout = time.RFC3339Nano
+)
+
+var (
+	validParserFormats    = []string{jsonParserFormat, logfmtParserFormat, rfc3164ParserFormat, rfc5424ParserFormat, regexParserFormat}
+	validParserFormatsSet = map[string]bool{
+		jsonParserFormat:    true,
+		logfmtParserFormat:  true,
+		rfc3164ParserFormat: true,
+		rfc5424ParserFormat: true,
+		regexParserFormat:   true,
+	}
+)
+
+// LogParser extracts the fields of a log event in one of the supported formats,
+// applies the field transformations and re-serializes the event as JSON.
+// Nested fields of JSON events can be referenced with dots, e.g. "request.user".
+type LogParser struct {
+	Format string `toml:"format"`
+	// Regex with named capture groups, only used by the regex format.
+	Pattern      string            `toml:"pattern"`
+	DropFields   []string          `toml:"drop_fields"`
+	RenameFields map[string]string `toml:"rename_fields"`
+	MaskFields   []string          `toml:"mask_fields"`
+	// The value replacing masked fields, "****" by default.
+	MaskValue string `toml:"mask_value"`
+
+	patternP *regexp.Regexp
+	parseFn  func(string) (map[string]interface{}, error)
+}
+
+func (p *LogParser) init() error {
+	if _, present := validParserFormatsSet[p.Format]; !present {
+		return fmt.Errorf("parser format %s is incorrect, valid formats are: %v", p.Format, validParserFormats)
+	}
+
+	switch p.Format {
+	case jsonParserFormat:
+		p.parseFn = parseJSON
+	case logfmtParserFormat:
+		p.parseFn = parseLogfmt
+	// The syslog machines keep the parsing state, so a new one is used for each
+	// message since the parser is shared by all the files of the file config.
+	case rfc3164ParserFormat:
+		p.parseFn = func(msg string) (map[string]interface{}, error) {
+			return parseSyslog(rfc3164.NewMachine(rfc3164.WithYear(rfc3164.CurrentYear{})), msg)
+		}
+	case rfc5424ParserFormat:
+		p.parseFn = func(msg string) (map[string]interface{}, error) {
+			return parseSyslog(rfc5424.NewMachine(), msg)
+		}
+	case regexParserFormat:
+		var err error
+		if p.patternP, err = regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("parser pattern has issue, regexp: Compile( %v ): %v", p.Pattern, err.Error())
+		}
+		if !hasNamedGroup(p.patternP) {
+			return fmt.Errorf("parser pattern %v has no named capture group", p.Pattern)
+		}
+		p.parseFn = p.parseRegex
+	}
+
+	for from, to := range p.RenameFields {
+		if from == "" || to == "" {
+			return fmt.Errorf("parser rename_fields has an empty field name: %q -> %q", from, to)
+		}
+	}
+	if p.MaskValue == "" {
+		p.MaskValue = defaultMaskedValue
+	}
+	return nil
+}
+
+// Parse returns the event re-serialized as JSON after the fields are dropped,
+// renamed and masked. An error is returned when the message is not in the
+// configured format, in which case the caller should keep the original message.
+func (p *LogParser) Parse(msg string) (string, error) {
+	fields, err := p.parseFn(msg)
+	if err != nil {
+		return "", err
+	}
+
+	for _, field := range p.DropFields {
+		removeField(fields, field)
+	}
+	for from, to := range p.RenameFields {
+		if val, ok := removeField(fields, from); ok {
+			setField(fields, to, val)
+		}
+	}
+	for _, field := range p.MaskFields {
+		if _, ok := getField(fields, field); ok {
+			setField(fields, field, p.MaskValue)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err = enc.Encode(fields); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func parseJSON(msg string) (map[string]interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(msg))
+	// Keep the numbers as they were written instead of converting them to float64
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, errors.New("the message is not a JSON object")
+	}
+	return fields, nil
+}
+
+func parseLogfmt(msg string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	hasValue := false
+	dec := logfmt.NewDecoder(strings.NewReader(msg))
+	for dec.ScanRecord() {
+		for dec.ScanKeyval() {
+			if dec.Value() != nil {
+				hasValue = true
+			}
+			fields[string(dec.Key())] = string(dec.Value())
+		}
+	}
+	if err := dec.Err(); err != nil {
+		return nil, err
+	}
+	// Plain text is a valid sequence of keys without values in logfmt, so at
+	// least one key=value pair is required for the message to be considered logfmt.
+	if !hasValue {
+		return nil, errors.New("the message has no logfmt key=value pair")
+	}
+	return fields, nil
+}
+
+func parseSyslog(machine syslog.Machine, msg string) (map[string]interface{}, error) {
+	m, err := machine.Parse([]byte(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	var base *syslog.Base
+	switch sm := m.(type) {
+	case *rfc3164.SyslogMessage:
+		base = &sm.Base
+	case *rfc5424.SyslogMessage:
+		base = &sm.Base
+		fields["version"] = sm.Version
+		if sm.StructuredData != nil {
+			fields["structured_data"] = *sm.StructuredData
+		}
+	default:
+		return nil, fmt.Errorf("unexpected syslog message type %T", m)
+	}
+
+	if base.Priority != nil {
+		fields["priority"] = *base.Priority
+	}
+	if base.FacilityLevel() != nil {
+		fields["facility"] = *base.FacilityLevel()
+	}
+	if base.SeverityLevel() != nil {
+		fields["severity"] = *base.SeverityLevel()
+	}
+	if base.Timestamp != nil {
+		fields["timestamp"] = base.Timestamp.Format(syslogTimestampLayout)
+	}
+	for key, val := range map[string]*string{
+		"hostname":         base.Hostname,
+		"appname":          base.Appname,
+		"procid":           base.ProcID,
+		"msgid":            base.MsgID,
+		syslogMessageField: base.Message,
+	} {
+		if val != nil {
+			fields[key] = *val
+		}
+	}
+	return fields, nil
+}
+
+func (p *LogParser) parseRegex(msg string) (map[string]interface{}, error) {
+	match := p.patternP.FindStringSubmatch(msg)
+	if match == nil {
+		return nil, errors.New("the message does not match the parser pattern")
+	}
+	fields := make(map[string]interface{})
+	for i, name := range p.patternP.SubexpNames() {
+		if i > 0 && name != "" {
+			fields[name] = match[i]
+		}
+	}
+	return fields, nil
+}
+
+func hasNamedGroup(r *regexp.Regexp) bool {
+	for _, name := range r.SubexpNames() {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getField returns the value at the dotted path. A key containing the full path
+// takes precedence over nested objects, so keys with dots can still be used.
+func getField(fields map[string]interface{}, path string) (interface{}, bool) {
+	if val, ok := fields[path]; ok {
+		return val, true
+	}
+	key, rest, found := strings.Cut(path, fieldPathSeparator)
+	if !found {
+		return nil, false
+	}
+	nested, ok := fields[key].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return getField(nested, rest)
+}
+
+func removeField(fields map[string]interface{}, path string) (interface{}, bool) {
+	if val, ok := fields[path]; ok {
+		delete(fields, path)
+		return val, true
+	}
+	key, rest, found := strings.Cut(path, fieldPathSeparator)
+	if !found {
+		return nil, false
+	}
+	nested, ok := fields[key].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return removeField(nested, rest)
+}
+
+// setField sets the value at the dotted path, creating the intermediate objects
+// that do not exist yet. If a key containing the full path already exists, or a
+// non-object value is in the way, the value is set on the key with dots instead.
+func setField(fields map[string]interface{}, path string, val interface{}) {
+	if _, ok := fields[path]; ok {
+		fields[path] = val
+		return
+	}
+	key, rest, found := strings.Cut(path, fieldPathSeparator)
+	if !found {
+		fields[path] = val
+		return
+	}
+	existing, exists := fields[key]
+	if !exists {
+		nested := make(map[string]interface{})
+		fields[key] = nested
+		setField(nested, rest, val)
+		return
+	}
+	if nested, ok := existing.(map[string]interface{}); ok {
+		setField(nested, rest, val)
+		return
+	}
+	fields[path] = val
+}
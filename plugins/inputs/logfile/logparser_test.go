@@ -0,0 +1,147 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/profiler"
+)
+
+func TestLogParserInit(t *testing.T) {
+	testCases := map[string]struct {
+		parser  LogParser
+		wantErr bool
+	}{
+		"json":            {parser: LogParser{Format: jsonParserFormat}},
+		"logfmt":          {parser: LogParser{Format: logfmtParserFormat}},
+		"syslog_rfc3164":  {parser: LogParser{Format: rfc3164ParserFormat}},
+		"syslog_rfc5424":  {parser: LogParser{Format: rfc5424ParserFormat}},
+		"regex":           {parser: LogParser{Format: regexParserFormat, Pattern: `^(?P<level>\w+)`}},
+		"unknown format":  {parser: LogParser{Format: "xml"}, wantErr: true},
+		"invalid regex":   {parser: LogParser{Format: regexParserFormat, Pattern: `(?P<level>`}, wantErr: true},
+		"unnamed regex":   {parser: LogParser{Format: regexParserFormat, Pattern: `^(\w+)`}, wantErr: true},
+		"empty rename to": {parser: LogParser{Format: jsonParserFormat, RenameFields: map[string]string{"a": ""}}, wantErr: true},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := testCase.parser.init()
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, defaultMaskedValue, testCase.parser.MaskValue)
+			}
+		})
+	}
+}
+
+func TestLogParserParse(t *testing.T) {
+	testCases := map[string]struct {
+		parser  LogParser
+		input   string
+		want    string
+		wantErr bool
+	}{
+		"json": {
+			parser: LogParser{Format: jsonParserFormat},
+			input:  `{"level":"info","count":12345678901234567890,"msg":"<ok>"}`,
+			want:   `{"count":12345678901234567890,"level":"info","msg":"<ok>"}`,
+		},
+		"json not an object": {
+			parser:  LogParser{Format: jsonParserFormat},
+			input:   `[1, 2]`,
+			wantErr: true,
+		},
+		"json invalid": {
+			parser:  LogParser{Format: jsonParserFormat},
+			input:   `plain text`,
+			wantErr: true,
+		},
+		"logfmt": {
+			parser: LogParser{Format: logfmtParserFormat},
+			input:  `level=warn msg="disk almost full" retry`,
+			want:   `{"level":"warn","msg":"disk almost full","retry":""}`,
+		},
+		"logfmt plain text": {
+			parser:  LogParser{Format: logfmtParserFormat},
+			input:   `just some words`,
+			wantErr: true,
+		},
+		"syslog rfc3164": {
+			parser: LogParser{Format: rfc3164ParserFormat, DropFields: []string{"timestamp"}},
+			input:  `<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed`,
+			want:   `{"appname":"su","facility":"auth","hostname":"mymachine","message":"'su root' failed","priority":34,"procid":"123","severity":"critical"}`,
+		},
+		"syslog rfc5424": {
+			parser: LogParser{Format: rfc5424ParserFormat},
+			input:  `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3"] An application event`,
+			want:   `{"appname":"evntslog","facility":"local4","hostname":"mymachine.example.com","message":"An application event","msgid":"ID47","priority":165,"severity":"notice","structured_data":{"exampleSDID@32473":{"iut":"3"}},"timestamp":"2003-10-11T22:14:15.003Z","version":1}`,
+		},
+		"syslog invalid": {
+			parser:  LogParser{Format: rfc5424ParserFormat},
+			input:   `not syslog`,
+			wantErr: true,
+		},
+		"regex": {
+			parser: LogParser{Format: regexParserFormat, Pattern: `^(?P<level>\w+) \[(\w+)\] (?P<message>.*)$`},
+			input:  `ERROR [main] something broke`,
+			want:   `{"level":"ERROR","message":"something broke"}`,
+		},
+		"regex no match": {
+			parser:  LogParser{Format: regexParserFormat, Pattern: `^(?P<level>\d+)$`},
+			input:   `ERROR [main] something broke`,
+			wantErr: true,
+		},
+		"drop, rename and mask": {
+			parser: LogParser{
+				Format:       jsonParserFormat,
+				DropFields:   []string{"debug", "request.headers", "missing"},
+				RenameFields: map[string]string{"msg": "message", "request.user": "user.name", "a.b": "c"},
+				MaskFields:   []string{"password", "user.name", "missing.field"},
+			},
+			input: `{"msg":"login","password":"secret","debug":true,"a.b":1,` +
+				`"request":{"user":"alice","headers":{"x":"y"},"path":"/"}}`,
+			want: `{"c":1,"message":"login","password":"****","request":{"path":"/"},"user":{"name":"****"}}`,
+		},
+		"custom mask value": {
+			parser: LogParser{Format: logfmtParserFormat, MaskFields: []string{"token"}, MaskValue: "[REDACTED]"},
+			input:  `token=abc user=bob`,
+			want:   `{"token":"[REDACTED]","user":"bob"}`,
+		},
+		"rename onto a non-object": {
+			parser: LogParser{Format: jsonParserFormat, RenameFields: map[string]string{"a": "b.c"}},
+			input:  `{"a":1,"b":2}`,
+			want:   `{"b":2,"b.c":1}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, testCase.parser.init())
+			got, err := testCase.parser.Parse(testCase.input)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	parser := &LogParser{Format: jsonParserFormat}
+	require.NoError(t, parser.init())
+
+	assert.Equal(t, `{"a":"b"}`, ParseMessage(t.Name(), t.Name(), parser, `{ "a": "b" }`))
+	assert.Equal(t, "not json", ParseMessage(t.Name(), t.Name(), parser, "not json"))
+
+	stats := profiler.Profiler.GetStats()
+	statKey := fmt.Sprintf("logfile_%s_%s_messages_parse_failed", t.Name(), t.Name())
+	assert.EqualValues(t, 1, stats[statKey])
+}
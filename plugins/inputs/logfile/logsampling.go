@@ -0,0 +1,105 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	dropOverflowAction      = "drop"
+	summarizeOverflowAction = "summarize"
+)
+
+var (
+	validOverflowActions    = []string{dropOverflowAction, summarizeOverflowAction}
+	validOverflowActionsSet = map[string]bool{
+		dropOverflowAction:      true,
+		summarizeOverflowAction: true,
+	}
+)
+
+// LogSampling keeps a fraction of the log events. The events are kept at random,
+// or based on the hash of a field so all the events with the same value of the
+// field, like a request id, are either kept or dropped together.
+type LogSampling struct {
+	// Fraction of the events kept, between 0 and 1.
+	Rate float64 `toml:"rate"`
+	// Field of the JSON events hashed to keep or drop them. Nested fields can be
+	// referenced with dots. The whole message is hashed when the field is missing.
+	HashField string `toml:"hash_field"`
+
+	randFn func() float64
+}
+
+func (s *LogSampling) init() error {
+	if s.Rate < 0 || s.Rate > 1 {
+		return fmt.Errorf("sampling rate %v is incorrect, it must be between 0 and 1", s.Rate)
+	}
+	if s.randFn == nil {
+		s.randFn = rand.Float64
+	}
+	return nil
+}
+
+// Sample returns whether the message is kept.
+func (s *LogSampling) Sample(msg string) bool {
+	if s.HashField == "" {
+		return s.randFn() < s.Rate
+	}
+	key := msg
+	if fields, err := parseJSON(msg); err == nil {
+		if val, ok := getField(fields, s.HashField); ok {
+			key = fmt.Sprint(val)
+		}
+	}
+	// The hash is stable across agents, so they sample the same values.
+	sum := sha256.Sum256([]byte(key))
+	return float64(binary.BigEndian.Uint64(sum[:8])) < s.Rate*math.MaxUint64
+}
+
+// LogRateLimit limits the number of log events published per second with a token
+// bucket, shared by all the files of the file config. The events over the limit
+// are dropped, or counted and replaced by a summary event when summarized.
+type LogRateLimit struct {
+	EventsPerSecond float64 `toml:"events_per_second"`
+	// Number of events that can be published at once, defaults to the events per second.
+	Burst int `toml:"burst"`
+	// One of drop or summarize. Defaults to drop.
+	OnOverflow string `toml:"on_overflow"`
+
+	limiter *rate.Limiter
+}
+
+func (r *LogRateLimit) init() error {
+	if r.EventsPerSecond <= 0 {
+		return fmt.Errorf("rate limit events_per_second %v is incorrect, it must be positive", r.EventsPerSecond)
+	}
+	if r.OnOverflow == "" {
+		r.OnOverflow = dropOverflowAction
+	}
+	if _, present := validOverflowActionsSet[r.OnOverflow]; !present {
+		return fmt.Errorf("rate limit on_overflow %s is incorrect, valid actions are: %v", r.OnOverflow, validOverflowActions)
+	}
+	if r.Burst <= 0 {
+		r.Burst = int(math.Ceil(r.EventsPerSecond))
+	}
+	r.limiter = rate.NewLimiter(rate.Limit(r.EventsPerSecond), r.Burst)
+	return nil
+}
+
+func (r *LogRateLimit) allow(now time.Time) bool {
+	return r.limiter.AllowN(now, 1)
+}
+
+func (r *LogRateLimit) summarize() bool {
+	return r.OnOverflow == summarizeOverflowAction
+}
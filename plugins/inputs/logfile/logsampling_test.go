@@ -0,0 +1,152 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
+)
+
+func TestLogSamplingInit(t *testing.T) {
+	assert.NoError(t, (&LogSampling{Rate: 0}).init())
+	assert.NoError(t, (&LogSampling{Rate: 1}).init())
+	assert.Error(t, (&LogSampling{Rate: -0.1}).init())
+	assert.Error(t, (&LogSampling{Rate: 1.5}).init())
+}
+
+func TestLogSamplingRandom(t *testing.T) {
+	values := []float64{0.05, 0.5, 0.09, 0.1}
+	s := &LogSampling{Rate: 0.1, randFn: func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}}
+	require.NoError(t, s.init())
+	assert.True(t, s.Sample("a"))
+	assert.False(t, s.Sample("a"))
+	assert.True(t, s.Sample("a"))
+	assert.False(t, s.Sample("a"))
+}
+
+func TestLogSamplingHashField(t *testing.T) {
+	s := &LogSampling{Rate: 0.5, HashField: "request.id"}
+	require.NoError(t, s.init())
+
+	var kept int
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		got := s.Sample(fmt.Sprintf(`{"request":{"id":%q},"msg":"first"}`, id))
+		// All the events of the same request are kept or dropped together.
+		assert.Equal(t, got, s.Sample(fmt.Sprintf(`{"msg":"second","request":{"id":%q}}`, id)))
+		if got {
+			kept++
+		}
+	}
+	assert.InDelta(t, 500, kept, 100)
+
+	// Messages without the field are hashed as a whole.
+	assert.Equal(t, s.Sample("plain text"), s.Sample("plain text"))
+	assert.True(t, (&LogSampling{Rate: 1, HashField: "id"}).Sample(`{"id":"a"}`))
+	assert.False(t, (&LogSampling{Rate: 0, HashField: "id"}).Sample(`{"id":"a"}`))
+}
+
+func TestLogRateLimitInit(t *testing.T) {
+	r := &LogRateLimit{EventsPerSecond: 2.5}
+	require.NoError(t, r.init())
+	assert.Equal(t, 3, r.Burst)
+	assert.Equal(t, dropOverflowAction, r.OnOverflow)
+	assert.False(t, r.summarize())
+
+	assert.Error(t, (&LogRateLimit{}).init())
+	assert.Error(t, (&LogRateLimit{EventsPerSecond: 1, OnOverflow: "block"}).init())
+	assert.NoError(t, (&LogRateLimit{EventsPerSecond: 1, OnOverflow: summarizeOverflowAction}).init())
+}
+
+func TestLogRateLimitAllow(t *testing.T) {
+	r := &LogRateLimit{EventsPerSecond: 10, Burst: 2}
+	require.NoError(t, r.init())
+	now := time.Now()
+	assert.True(t, r.allow(now))
+	assert.True(t, r.allow(now))
+	assert.False(t, r.allow(now))
+	assert.False(t, r.allow(now.Add(50*time.Millisecond)))
+	assert.True(t, r.allow(now.Add(100*time.Millisecond)))
+}
+
+func TestTailerSrcRateLimitSummary(t *testing.T) {
+	rateLimit := &LogRateLimit{EventsPerSecond: 0.001, Burst: 2, OnOverflow: summarizeOverflowAction}
+	require.NoError(t, rateLimit.init())
+	var published []string
+	ts := &tailerSrc{
+		group:     t.Name(),
+		stream:    t.Name(),
+		rateLimit: rateLimit,
+		outputFn: func(e logs.LogEvent) {
+			published = append(published, e.Message())
+		},
+	}
+	before := agent.UsageCounters().Get(agent.CounterLogEventsRateLimited)
+
+	for i := 1; i <= 5; i++ {
+		ts.publish(&LogEvent{msg: fmt.Sprintf("line %d", i), offset: fileOffset{offset: int64(i)}, src: ts})
+	}
+	assert.Equal(t, []string{"line 1", "line 2"}, published)
+	assert.EqualValues(t, 3, ts.suppressed)
+	assert.Equal(t, fileOffset{offset: 5}, ts.suppressedOffset)
+
+	ts.publishSuppressed()
+	assert.Equal(t, "3 lines suppressed by the rate limit of 0.001 events per second", published[2])
+	ts.publishSuppressed()
+	assert.Len(t, published, 3)
+	assert.EqualValues(t, before+3, agent.UsageCounters().Get(agent.CounterLogEventsRateLimited))
+}
+
+func TestTailerSrcRateLimitSummaryOnStop(t *testing.T) {
+	rateLimit := &LogRateLimit{EventsPerSecond: 0.001, Burst: 1, OnOverflow: summarizeOverflowAction}
+	require.NoError(t, rateLimit.init())
+	var published []string
+	ts := &tailerSrc{
+		group:     t.Name(),
+		stream:    t.Name(),
+		rateLimit: rateLimit,
+		tailer:    &tail.Tail{Filename: t.Name(), Lines: make(chan *tail.Line)},
+		done:      make(chan struct{}),
+		outputFn: func(e logs.LogEvent) {
+			if e != nil {
+				published = append(published, e.Message())
+			}
+		},
+	}
+	for i := 1; i <= 3; i++ {
+		ts.publish(&LogEvent{msg: fmt.Sprintf("line %d", i), offset: fileOffset{offset: int64(i)}, src: ts})
+	}
+	ts.Stop()
+	ts.runTail()
+	assert.Equal(t, []string{"line 1", "2 lines suppressed by the rate limit of 0.001 events per second"}, published)
+}
+
+func TestTailerSrcSamplingCounter(t *testing.T) {
+	sampling := &LogSampling{Rate: 0}
+	require.NoError(t, sampling.init())
+	ts := &tailerSrc{
+		group:    t.Name(),
+		stream:   t.Name(),
+		sampling: sampling,
+		outputFn: func(e logs.LogEvent) {
+			assert.Fail(t, "no event should be published")
+		},
+	}
+	before := agent.UsageCounters().Get(agent.CounterLogEventsSampledOut)
+	ts.publish(&LogEvent{msg: "dropped", src: ts})
+	ts.publish(&LogEvent{msg: "dropped", src: ts})
+	assert.EqualValues(t, before+2, agent.UsageCounters().Get(agent.CounterLogEventsSampledOut))
+}
@@ -0,0 +1,194 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	// Special tags handled by the cloudwatch output. The aggregation interval makes
+	// it combine the data points of the same interval into a single datum.
+	aggregationIntervalTagKey       = "aws:AggregationInterval"
+	unitTagKey                      = "aws:Unit"
+	metricFilterAggregationInterval = time.Minute
+	metricFilterValueField          = "value"
+	// Max number of captured values kept for a dimension set between two gathers,
+	// the values above it are dropped so the memory stays bounded.
+	maxMetricFilterValues = 10000
+)
+
+// dimensionPlaceholder references a named capture group of the pattern in a dimension value.
+var dimensionPlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// MetricFilter turns the log events matching the pattern into a metric, either the
+// number of matching events or the numeric value captured by a named group.
+type MetricFilter struct {
+	MetricName string `toml:"metric_name"`
+	Pattern    string `toml:"pattern"`
+	// Named capture group holding the value. The matching events are counted when empty.
+	ValueGroup string `toml:"value_group"`
+	Unit       string `toml:"unit"`
+	// Value emitted for the intervals without any matching event.
+	DefaultValue *float64 `toml:"default_value"`
+	// Dimension values can reference the named capture groups as {name}.
+	Dimensions map[string]string `toml:"dimensions"`
+
+	patternP   *regexp.Regexp
+	valueIndex int
+
+	// The events are only recorded once the input runs in a metrics pipeline,
+	// otherwise nothing would ever gather them.
+	started atomic.Bool
+	mu      sync.Mutex
+	points  map[string]*metricFilterPoint
+}
+
+type metricFilterPoint struct {
+	tags   map[string]string
+	count  int64
+	values []float64
+}
+
+func (f *MetricFilter) init() error {
+	if f.MetricName == "" {
+		return fmt.Errorf("metric filter has no metric name for pattern %v", f.Pattern)
+	}
+	var err error
+	if f.patternP, err = regexp.Compile(f.Pattern); err != nil {
+		return fmt.Errorf("metric filter regex has issue, regexp: Compile( %v ): %v", f.Pattern, err.Error())
+	}
+	if f.ValueGroup != "" {
+		if f.valueIndex = f.patternP.SubexpIndex(f.ValueGroup); f.valueIndex < 0 {
+			return fmt.Errorf("metric filter pattern %v has no capture group named %s", f.Pattern, f.ValueGroup)
+		}
+	}
+	dynamic := false
+	for key, val := range f.Dimensions {
+		for _, placeholder := range dimensionPlaceholder.FindAllStringSubmatch(val, -1) {
+			if f.patternP.SubexpIndex(placeholder[1]) < 0 {
+				return fmt.Errorf("metric filter dimension %s references unknown capture group %s", key, placeholder[1])
+			}
+			dynamic = true
+		}
+	}
+	// The dimensions of the default value would be unknown without a matching event.
+	if f.DefaultValue != nil && dynamic {
+		return fmt.Errorf("metric filter %s cannot have a default value with dimensions from capture groups", f.MetricName)
+	}
+	f.points = make(map[string]*metricFilterPoint)
+	return nil
+}
+
+func (f *MetricFilter) start() {
+	f.started.Store(true)
+}
+
+// Match records the event in the metric if it matches the pattern. Events whose
+// captured value is not a finite number are ignored, CloudWatch rejects the others.
+func (f *MetricFilter) Match(msg string) {
+	if !f.started.Load() {
+		return
+	}
+	match := f.patternP.FindStringSubmatch(msg)
+	if match == nil {
+		return
+	}
+	var value float64
+	if f.valueIndex > 0 {
+		var err error
+		if value, err = strconv.ParseFloat(strings.TrimSpace(match[f.valueIndex]), 64); err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return
+		}
+	}
+
+	tags := f.tags(match)
+	key := tagsKey(tags)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	point, ok := f.points[key]
+	if !ok {
+		point = &metricFilterPoint{tags: tags}
+		f.points[key] = point
+	}
+	if f.valueIndex > 0 {
+		if len(point.values) < maxMetricFilterValues {
+			point.values = append(point.values, value)
+		}
+	} else {
+		point.count++
+	}
+}
+
+// gather adds the metrics recorded since the previous gather to the accumulator.
+func (f *MetricFilter) gather(acc telegraf.Accumulator, now time.Time) {
+	f.mu.Lock()
+	points := f.points
+	f.points = make(map[string]*metricFilterPoint)
+	f.mu.Unlock()
+
+	if len(points) == 0 && f.DefaultValue != nil {
+		acc.AddFields(f.MetricName, map[string]interface{}{metricFilterValueField: *f.DefaultValue}, f.withSpecialTags(f.tags(nil)), now)
+		return
+	}
+	for _, point := range points {
+		tags := f.withSpecialTags(point.tags)
+		if f.valueIndex > 0 {
+			for _, value := range point.values {
+				acc.AddFields(f.MetricName, map[string]interface{}{metricFilterValueField: value}, tags, now)
+			}
+		} else {
+			acc.AddFields(f.MetricName, map[string]interface{}{metricFilterValueField: point.count}, tags, now)
+		}
+	}
+}
+
+// tags expands the dimensions with the capture groups of the match. Dimensions
+// that expand to an empty value are left out since CloudWatch rejects them.
+func (f *MetricFilter) tags(match []string) map[string]string {
+	tags := make(map[string]string, len(f.Dimensions))
+	for key, val := range f.Dimensions {
+		val = dimensionPlaceholder.ReplaceAllStringFunc(val, func(placeholder string) string {
+			if index := f.patternP.SubexpIndex(placeholder[1 : len(placeholder)-1]); index >= 0 && index < len(match) {
+				return match[index]
+			}
+			return ""
+		})
+		if val != "" {
+			tags[key] = val
+		}
+	}
+	return tags
+}
+
+func (f *MetricFilter) withSpecialTags(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags)+2)
+	for key, val := range tags {
+		result[key] = val
+	}
+	result[aggregationIntervalTagKey] = metricFilterAggregationInterval.String()
+	if f.Unit != "" {
+		result[unitTagKey] = f.Unit
+	}
+	return result
+}
+
+func tagsKey(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, val := range tags {
+		pairs = append(pairs, key+"="+val)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,106 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricFilterInit(t *testing.T) {
+	defaultValue := 0.0
+	testCases := map[string]struct {
+		filter  *MetricFilter
+		wantErr bool
+	}{
+		"count":              {filter: &MetricFilter{MetricName: "Errors", Pattern: "ERROR"}},
+		"value":              {filter: &MetricFilter{MetricName: "Latency", Pattern: `latency=(?P<latency>\d+)`, ValueGroup: "latency"}},
+		"default value":      {filter: &MetricFilter{MetricName: "Errors", Pattern: "ERROR", DefaultValue: &defaultValue, Dimensions: map[string]string{"App": "web"}}},
+		"no metric name":     {filter: &MetricFilter{Pattern: "ERROR"}, wantErr: true},
+		"invalid pattern":    {filter: &MetricFilter{MetricName: "Errors", Pattern: "("}, wantErr: true},
+		"unknown value":      {filter: &MetricFilter{MetricName: "Latency", Pattern: `(?P<latency>\d+)`, ValueGroup: "duration"}, wantErr: true},
+		"unknown dimension":  {filter: &MetricFilter{MetricName: "Errors", Pattern: "ERROR", Dimensions: map[string]string{"Level": "{level}"}}, wantErr: true},
+		"dynamic w/ default": {filter: &MetricFilter{MetricName: "Errors", Pattern: `(?P<level>ERROR)`, DefaultValue: &defaultValue, Dimensions: map[string]string{"Level": "{level}"}}, wantErr: true},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := testCase.filter.init()
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMetricFilterCount(t *testing.T) {
+	f := &MetricFilter{
+		MetricName: "Errors",
+		Pattern:    `(?P<level>ERROR|WARN) \[(?P<module>\w*)\]`,
+		Dimensions: map[string]string{"Level": "{level}", "Module": "{module}", "App": "web"},
+	}
+	require.NoError(t, f.init())
+
+	// Events before the start are not recorded.
+	f.Match("ERROR [db] not recorded")
+	f.start()
+	for _, msg := range []string{"ERROR [db] timeout", "INFO [db] ok", "ERROR [db] refused", "WARN [] slow", "ERROR [http] 500"} {
+		f.Match(msg)
+	}
+
+	acc := &testutil.Accumulator{}
+	now := time.Now()
+	f.gather(acc, now)
+	require.Len(t, acc.Metrics, 3)
+	for _, want := range []struct {
+		tags  map[string]string
+		count int64
+	}{
+		{tags: map[string]string{"Level": "ERROR", "Module": "db", "App": "web"}, count: 2},
+		{tags: map[string]string{"Level": "ERROR", "Module": "http", "App": "web"}, count: 1},
+		{tags: map[string]string{"Level": "WARN", "App": "web"}, count: 1},
+	} {
+		want.tags[aggregationIntervalTagKey] = "1m0s"
+		acc.AssertContainsTaggedFields(t, "Errors", map[string]interface{}{"value": want.count}, want.tags)
+	}
+
+	// Nothing is emitted for an interval without matches when there is no default value.
+	acc.ClearMetrics()
+	f.gather(acc, now)
+	assert.Empty(t, acc.Metrics)
+}
+
+func TestMetricFilterValue(t *testing.T) {
+	defaultValue := 0.0
+	f := &MetricFilter{
+		MetricName:   "Latency",
+		Pattern:      `latency=(?P<latency>\S+)ms`,
+		ValueGroup:   "latency",
+		Unit:         "Milliseconds",
+		DefaultValue: &defaultValue,
+		Dimensions:   map[string]string{"App": "web"},
+	}
+	require.NoError(t, f.init())
+	f.start()
+	for _, msg := range []string{"latency=12ms", "latency=3.5ms", "latency=NaNms?", "latency=fastms", "no latency"} {
+		f.Match(msg)
+	}
+
+	acc := &testutil.Accumulator{}
+	f.gather(acc, time.Now())
+	tags := map[string]string{"App": "web", aggregationIntervalTagKey: "1m0s", unitTagKey: "Milliseconds"}
+	require.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "Latency", map[string]interface{}{"value": 12.0}, tags)
+	acc.AssertContainsTaggedFields(t, "Latency", map[string]interface{}{"value": 3.5}, tags)
+
+	acc.ClearMetrics()
+	f.gather(acc, time.Now())
+	require.Len(t, acc.Metrics, 1)
+	acc.AssertContainsTaggedFields(t, "Latency", map[string]interface{}{"value": 0.0}, tags)
+}
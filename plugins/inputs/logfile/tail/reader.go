@@ -0,0 +1,81 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package tail
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// TailReader reads the lines of a stream that does not change once written, such
+// as a decompressed archive, and closes the Lines channel at EOF. The offsets of
+// the lines are positions in the stream. Since the stream cannot be seeked, the
+// Location can only skip forward from the start of the stream.
+func TailReader(filename string, r io.ReadCloser, config Config) (*Tail, error) {
+	t := &Tail{
+		Filename:      filename,
+		Lines:         make(chan *Line),
+		Config:        config,
+		FileDeletedCh: make(chan bool),
+	}
+
+	if t.Logger == nil {
+		t.Logger = models.NewLogger("inputs", "tail", "")
+	}
+
+	if t.MaxLineSize > 0 {
+		// add 2 to account for newline characters
+		t.reader = bufio.NewReaderSize(r, t.MaxLineSize+2)
+	} else {
+		t.reader = bufio.NewReader(r)
+	}
+
+	go t.readStream(r)
+
+	return t, nil
+}
+
+func (tail *Tail) readStream(r io.ReadCloser) {
+	defer tail.Done()
+	defer tail.close()
+	defer r.Close()
+
+	if tail.Location != nil && tail.Location.Whence == io.SeekStart && tail.Location.Offset > 0 {
+		n, err := io.CopyN(io.Discard, tail.reader, tail.Location.Offset)
+		tail.curOffset = n
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			tail.Killf("Seek error on %s: %s", tail.Filename, err)
+			return
+		}
+		tail.Logger.Debugf("Skipped %s - %+v\n", tail.Filename, tail.Location)
+	}
+
+	for {
+		line, err := tail.readLine()
+		if err == io.EOF {
+			if line != "" {
+				tail.sendLine(line, tail.curOffset)
+			}
+			return
+		} else if err != nil {
+			tail.Killf("Error reading %s: %s", tail.Filename, err)
+			return
+		}
+
+		tail.sendLine(line, tail.curOffset)
+
+		select {
+		case <-tail.Dying():
+			if tail.Err() == errStopAtEOF {
+				continue
+			}
+			return
+		default:
+		}
+	}
+}
@@ -5,14 +5,15 @@ package logfile
 
 import (
 	"bytes"
+	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"sync"
 	"time"
 
 	"golang.org/x/text/encoding"
 
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
 )
@@ -24,6 +25,9 @@ const (
 
 var (
 	multilineWaitPeriod = 1 * time.Second
+	// How long to wait for the last event of a file that has been read to the end
+	// to be published before the final state is saved, unless the agent is stopping.
+	lastOffsetWaitTimeout = 30 * time.Second
 )
 
 type fileOffset struct {
@@ -61,6 +65,7 @@ type tailerSrc struct {
 	stream          string
 	class           string
 	destination     string
+	destinations    []logs.LogDestination
 	stateFilePath   string
 	tailer          *tail.Tail
 	autoRemoval     bool
@@ -73,21 +78,49 @@ type tailerSrc struct {
 	outputFn        func(logs.LogEvent)
 	isMLStart       func(string) bool
 	filters         []*LogFilter
+	parser          *LogParser
+	metricFilters   []*MetricFilter
+	sampling        *LogSampling
+	rateLimit       *LogRateLimit
 	offsetCh        chan fileOffset
+	eofCh           chan fileOffset
 	done            chan struct{}
 	startTailerOnce sync.Once
 	cleanUpFns      []func()
+
+	// number of events suppressed by the rate limit since the last summary, and the
+	// offset of the last one, only accessed by the runTail goroutine
+	suppressed       int64
+	suppressedOffset fileOffset
+
+	// identity of the tailed file and the truncation sequence it was computed for
+	identity    fileIdentity
+	identitySeq int64
+
+	// closed when the input is stopped, the outputs are stopped too by the time the source
+	// is stopped so the last offset is not waited for
+	inputDone <-chan struct{}
+
+	// called once every event up to the end of the file has been published, set for the
+	// compressed files which are not read again after that
+	fullyReadFn func()
 }
 
-// Verify tailerSrc implements LogSrc
-var _ logs.LogSrc = (*tailerSrc)(nil)
+// Verify tailerSrc implements MultiDestLogSrc
+var _ logs.MultiDestLogSrc = (*tailerSrc)(nil)
 
 func NewTailerSrc(
-	group, stream, destination, stateFilePath, logClass string,
+	group, stream, destination string,
+	additionalDestinations []logs.LogDestination,
+	stateFilePath, logClass string,
 	tailer *tail.Tail,
 	autoRemoval bool,
 	isMultilineStartFn func(string) bool,
 	filters []*LogFilter,
+	parser *LogParser,
+	metricFilters []*MetricFilter,
+	sampling *LogSampling,
+	rateLimit *LogRateLimit,
 	timestampFn func(string) time.Time,
 	enc encoding.Encoding,
 	maxEventSize int,
@@ -98,12 +131,17 @@ func NewTailerSrc(
 		group:           group,
 		stream:          stream,
 		destination:     destination,
+		destinations:    additionalDestinations,
 		stateFilePath:   stateFilePath,
 		class:           logClass,
 		tailer:          tailer,
 		autoRemoval:     autoRemoval,
 		isMLStart:       isMultilineStartFn,
 		filters:         filters,
+		parser:          parser,
+		metricFilters:   metricFilters,
+		sampling:        sampling,
+		rateLimit:       rateLimit,
 		timestampFn:     timestampFn,
 		enc:             enc,
 		maxEventSize:    maxEventSize,
@@ -111,6 +149,7 @@ func NewTailerSrc(
 		retentionInDays: retentionInDays,
 
 		offsetCh: make(chan fileOffset, 2000),
+		eofCh:    make(chan fileOffset, 1),
 		done:     make(chan struct{}),
 	}
 	go ts.runSaveState()
@@ -141,6 +180,10 @@ func (ts *tailerSrc) Destination() string {
 	return ts.destination
 }
 
+func (ts *tailerSrc) AdditionalDestinations() []logs.LogDestination {
+	return ts.destinations
+}
+
 func (ts *tailerSrc) Retention() int {
 	return ts.retentionInDays
 }
@@ -181,6 +224,8 @@ func (ts *tailerSrc) runTail() {
 		select {
 		case line, ok := <-ts.tailer.Lines:
 			if !ok {
+				ts.eofCh <- *fo
+				ts.publishSuppressed()
 				if msgBuf.Len() > 0 {
 					msg := msgBuf.String()
 					e := &LogEvent{
@@ -190,9 +235,7 @@ func (ts *tailerSrc) runTail() {
 						src:    ts,
 					}
 
-					if ShouldPublish(ts.group, ts.stream, ts.filters, e) {
-						ts.outputFn(e)
-					}
+					ts.publish(e)
 				}
 				return
 			}
@@ -243,11 +286,7 @@ func (ts *tailerSrc) runTail() {
 					offset: *fo,
 					src:    ts,
 				}
-				// Note: This only checks against the truncated log message, so it is not necessary to load
-				//       the entire log message for filtering.
-				if ShouldPublish(ts.group, ts.stream, ts.filters, e) {
-					ts.outputFn(e)
-				}
+				ts.publish(e)
 			}
 
 			msgBuf.Reset()
@@ -255,6 +294,7 @@ func (ts *tailerSrc) runTail() {
 			fo.SetOffset(line.Offset)
 			cnt = 0
 		case <-t.C:
+			ts.publishSuppressed()
 			if msgBuf.Len() > 0 {
 				cnt++
 			}
@@ -270,17 +310,62 @@ func (ts *tailerSrc) runTail() {
 				offset: *fo,
 				src:    ts,
 			}
-			if ShouldPublish(ts.group, ts.stream, ts.filters, e) {
-				ts.outputFn(e)
-			}
+			ts.publish(e)
 			msgBuf.Reset()
 			cnt = 0
 		case <-ts.done:
+			ts.publishSuppressed()
 			return
 		}
 	}
 }
 
+// publish sends the event to the output if it passes the filters, after it is
+// parsed into the configured structured format. The metric filters see all the
+// events, so the events only wanted as metrics can be excluded from the output.
+func (ts *tailerSrc) publish(e *LogEvent) {
+	for _, f := range ts.metricFilters {
+		f.Match(e.msg)
+	}
+	// Note: This only checks against the truncated log message, so it is not necessary to load
+	//       the entire log message for filtering.
+	if !ShouldPublish(ts.group, ts.stream, ts.filters, e) {
+		return
+	}
+	if ts.parser != nil {
+		e.msg = ParseMessage(ts.group, ts.stream, ts.parser, e.msg)
+	}
+	if ts.sampling != nil && !ts.sampling.Sample(e.msg) {
+		agent.UsageCounters().Add(agent.CounterLogEventsSampledOut, 1)
+		return
+	}
+	if ts.rateLimit != nil && !ts.rateLimit.allow(time.Now()) {
+		agent.UsageCounters().Add(agent.CounterLogEventsRateLimited, 1)
+		if ts.rateLimit.summarize() {
+			ts.suppressed++
+			ts.suppressedOffset = e.offset
+		}
+		return
+	}
+	ts.outputFn(e)
+}
+
+// publishSuppressed publishes the summary of the events suppressed by the rate
+// limit. It is called at most once per multiline wait period so the summaries
+// do not add up to the volume the rate limit is meant to reduce.
+func (ts *tailerSrc) publishSuppressed() {
+	if ts.suppressed == 0 {
+		return
+	}
+	e := &LogEvent{
+		msg:    fmt.Sprintf("%d lines suppressed by the rate limit of %v events per second", ts.suppressed, ts.rateLimit.EventsPerSecond),
+		offset: ts.suppressedOffset,
+		src:    ts,
+	}
+	ts.suppressed = 0
+	ts.outputFn(e)
+}
+
 func (ts *tailerSrc) cleanUp() {
 	if ts.autoRemoval {
 		if err := os.Remove(ts.tailer.Filename); err != nil {
@@ -313,7 +398,7 @@ func (ts *tailerSrc) runSaveState() {
 			if offset == lastSavedOffset {
 				continue
 			}
-			err := ts.saveState(offset.offset)
+			err := ts.saveState(offset)
 			if err != nil {
 				log.Printf("E! [logfile] Error happened when saving file state %s to file state folder %s: %v", ts.tailer.Filename, ts.stateFilePath, err)
 				continue
@@ -327,20 +412,76 @@ func (ts *tailerSrc) runSaveState() {
 			}
 			return
 		case <-ts.done:
-			err := ts.saveState(offset.offset)
+			var fullyRead bool
+			offset, fullyRead = ts.waitForLastOffset(offset)
+			err := ts.saveState(offset)
 			if err != nil {
 				log.Printf("E! [logfile] Error happened during final file state saving of logfile %s to file state folder %s, duplicate log maybe sent at next start: %v", ts.tailer.Filename, ts.stateFilePath, err)
 			}
+			if fullyRead && ts.fullyReadFn != nil {
+				ts.fullyReadFn()
+			}
 			return
 		}
 	}
 }
 
-func (ts *tailerSrc) saveState(offset int64) error {
-	if ts.stateFilePath == "" || offset == 0 {
+// waitForLastOffset waits for the last event to be published when the tailer has
+// reached the end of the file, so that it is not read again after a restart. It
+// returns true if every event up to the end of the file has been published.
+func (ts *tailerSrc) waitForLastOffset(offset fileOffset) (fileOffset, bool) {
+	var last fileOffset
+	select {
+	case last = <-ts.eofCh:
+	default:
+		return offset, false
+	}
+
+	timer := time.NewTimer(lastOffsetWaitTimeout)
+	defer timer.Stop()
+	for offset.seq < last.seq || (offset.seq == last.seq && offset.offset < last.offset) {
+		select {
+		case o := <-ts.offsetCh:
+			if o.seq > offset.seq || (o.seq == offset.seq && o.offset > offset.offset) {
+				offset = o
+			}
+		case <-timer.C:
+			return offset, false
+		case <-ts.inputDone:
+			return offset, false
+		}
+	}
+	return offset, true
+}
+
+func (ts *tailerSrc) saveState(offset fileOffset) error {
+	if ts.stateFilePath == "" || offset.offset == 0 {
 		return nil
 	}
 
-	content := []byte(strconv.FormatInt(offset, 10) + "\n" + ts.tailer.Filename)
-	return os.WriteFile(ts.stateFilePath, content, stateFileMode)
+	state := fileState{
+		offset:   offset.offset,
+		filename: ts.tailer.Filename,
+		identity: ts.fileIdentity(offset.seq),
+	}
+	return os.WriteFile(ts.stateFilePath, []byte(state.String()), stateFileMode)
+}
+
+// fileIdentity returns the identity of the tailed file. It is recomputed until the
+// fingerprint is complete, and again after the file has been truncated.
+func (ts *tailerSrc) fileIdentity(seq int64) fileIdentity {
+	if ts.identity.complete() && ts.identitySeq == seq {
+		return ts.identity
+	}
+	id, err := getFileIdentity(ts.tailer.Filename)
+	if err != nil {
+		return ts.identity
+	}
+	if !ts.identity.isZero() && ts.identitySeq == seq && (id.device != ts.identity.device || id.inode != ts.identity.inode) {
+		// The path now points to another file, keep the identity of the one being tailed
+		return ts.identity
+	}
+	ts.identity = id
+	ts.identitySeq = seq
+	return id
 }
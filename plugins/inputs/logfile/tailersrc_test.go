@@ -60,12 +60,17 @@ func TestTailerSrc(t *testing.T) {
 	require.Equal(t, beforeCount+1, tail.OpenFileCount.Load())
 	ts := NewTailerSrc(
 		"groupName", "streamName",
-		"destination", statefile.Name(),
+		"destination", nil,
+		statefile.Name(),
 		util.InfrequentAccessLogGroupClass,
 		tailer,
 		false, // AutoRemoval
 		regexp.MustCompile("^[\\S]").MatchString,
 		nil,
+		nil, // parser
+		nil, // metric filters
+		nil, // sampling
+		nil, // rate limit
 		parseRFC3339Timestamp,
 		nil, // encoding
 		defaultMaxEventSize,
@@ -142,6 +147,23 @@ func TestTailerSrc(t *testing.T) {
 	assert.Eventually(t, func() bool { return tail.OpenFileCount.Load() <= beforeCount }, 3*time.Second, time.Second)
 }
 
+func TestWaitForLastOffsetInputStopped(t *testing.T) {
+	inputDone := make(chan struct{})
+	ts := &tailerSrc{
+		offsetCh:  make(chan fileOffset),
+		eofCh:     make(chan fileOffset, 1),
+		inputDone: inputDone,
+	}
+	close(inputDone)
+	ts.eofCh <- fileOffset{offset: 100}
+	start := time.Now()
+	// The last event is not waited for once the input is stopped, it is not published anymore.
+	offset, fullyRead := ts.waitForLastOffset(fileOffset{offset: 10})
+	assert.Equal(t, fileOffset{offset: 10}, offset)
+	assert.False(t, fullyRead)
+	assert.Less(t, time.Since(start), lastOffsetWaitTimeout)
+}
+
 func TestOffsetDoneCallBack(t *testing.T) {
 	original := multilineWaitPeriod
 	defer resetState(original)
@@ -170,13 +192,17 @@ func TestOffsetDoneCallBack(t *testing.T) {
 
 	ts := NewTailerSrc(
 		"groupName", "streamName",
-		"destination",
+		"destination", nil,
 		statefile.Name(),
 		util.InfrequentAccessLogGroupClass,
 		tailer,
 		false, // AutoRemoval
 		regexp.MustCompile("^[\\S]").MatchString,
 		nil,
+		nil, // parser
+		nil, // metric filters
+		nil, // sampling
+		nil, // rate limit
 		parseRFC3339Timestamp,
 		nil, // encoding
 		defaultMaxEventSize,
@@ -389,13 +415,17 @@ func setupTailer(t *testing.T, multiLineFn func(string) bool, maxEventSize int)
 	ts := NewTailerSrc(
 		t.Name(),
 		t.Name(),
-		"destination",
+		"destination", nil,
 		util.InfrequentAccessLogGroupClass,
 		statefile.Name(),
 		tailer,
 		false, // AutoRemoval
 		multiLineFn,
 		config.Filters,
+		config.Parser,
+		config.MetricFilters,
+		config.Sampling,
+		config.RateLimit,
 		parseRFC3339Timestamp,
 		nil, // encoding
 		maxEventSize,
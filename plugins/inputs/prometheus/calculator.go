@@ -6,6 +6,8 @@ package prometheus
 import (
 	"log"
 	"strings"
+
+	"github.com/prometheus/prometheus/model/value"
 )
 
 type Calculator struct {
@@ -25,15 +27,21 @@ func (c *Calculator) Calculate(pmb PrometheusMetricBatch) (result PrometheusMetr
 	var gauges PrometheusMetricBatch
 	var counters PrometheusMetricBatch
 	var summaries PrometheusMetricBatch
+	var histograms PrometheusMetricBatch
 
-	for _, pm := range pmb {
+	for _, pm := range assembleClassicHistograms(pmb) {
 		if pm.isGauge() {
 			gauges = appendValidValue(gauges, pm)
 		} else if pm.isCounter() {
 			if calculatedMetric := c.deltaCalculator.calculate(pm); calculatedMetric != nil {
 				counters = append(counters, calculatedMetric)
 			}
-		} else if pm.isSummary() {
+		} else if pm.isHistogram() && pm.histogram != nil {
+			// publish the samples observed since the previous scrape
+			if calculatedMetric := c.deltaCalculator.calculate(pm); calculatedMetric != nil && calculatedMetric.histogram.count > 0 {
+				histograms = append(histograms, calculatedMetric)
+			}
+		} else if pm.isSummary() || pm.isHistogram() {
 			// calculate the delta for <basename>_count and <basename>_sum metrics as well
 			if strings.HasSuffix(pm.metricName, histogramSummaryCountSuffix) ||
 				strings.HasSuffix(pm.metricName, histogramSummarySumSuffix) {
@@ -49,9 +57,32 @@ func (c *Calculator) Calculate(pmb PrometheusMetricBatch) (result PrometheusMetr
 	result = append(result, gauges...)
 	result = append(result, counters...)
 	result = append(result, summaries...)
+	result = append(result, histograms...)
 	return
 }
 
+// ForgetStale removes the previous data points of the series that received a staleness
+// marker, whatever their type. The markers sent when a target is removed never reach
+// Calculate, since the metadata of the target is gone.
+func (c *Calculator) ForgetStale(pmb PrometheusMetricBatch) {
+	for _, pm := range pmb {
+		if !value.IsStaleNaN(pm.metricValue) {
+			continue
+		}
+		c.deltaCalculator.forget(pm)
+		if _, ok := pm.tags[bucketLabel]; ok && strings.HasSuffix(pm.metricName, histogramBucketSuffix) {
+			// the data points of a classic histogram are cached under the name of the histogram
+			tags := make(map[string]string, len(pm.tags))
+			for k, v := range pm.tags {
+				if k != bucketLabel {
+					tags[k] = v
+				}
+			}
+			c.deltaCalculator.forget(&PrometheusMetric{tags: tags, metricName: strings.TrimSuffix(pm.metricName, histogramBucketSuffix)})
+		}
+	}
+}
+
 func NewCalculator() *Calculator {
 	return &Calculator{
 		deltaCalculator: NewDeltaCalculator(),
@@ -0,0 +1,48 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculator_ForgetStale(t *testing.T) {
+	newCounter := func(timeInMS int64, v float64) *PrometheusMetric {
+		return &PrometheusMetric{
+			metricName:  "requests_total",
+			metricType:  "counter",
+			metricValue: v,
+			timeInMS:    timeInMS,
+			tags:        map[string]string{"job": "test", prometheusMetricTypeKey: "counter"},
+		}
+	}
+	staleNaN := math.Float64frombits(value.StaleNaN)
+
+	c := NewCalculator()
+	assert.Empty(t, c.Calculate(PrometheusMetricBatch{newCounter(1000, 10)}))
+	assert.Empty(t, c.Calculate(buildClassicHistogram(1000, 9, map[string]float64{"1": 2, "+Inf": 7})))
+
+	// The staleness markers of a removed target have no type.
+	stale := PrometheusMetricBatch{
+		{metricName: "requests_total", metricValue: staleNaN, timeInMS: 2000, tags: map[string]string{"job": "test"}},
+	}
+	for _, pm := range buildClassicHistogram(2000, staleNaN, map[string]float64{"1": staleNaN, "+Inf": staleNaN}) {
+		pm.metricType = ""
+		stale = append(stale, pm)
+	}
+	c.ForgetStale(stale)
+
+	// Once the target is back, its first values are only used as the base of the deltas.
+	assert.Empty(t, c.Calculate(PrometheusMetricBatch{newCounter(3000, 50)}))
+	result := c.Calculate(buildClassicHistogram(3000, 20, map[string]float64{"1": 3, "+Inf": 9}))
+	assert.Nil(t, findMetric(result, "latency"))
+	result = c.Calculate(PrometheusMetricBatch{newCounter(4000, 55)})
+	require.Len(t, result, 1)
+	assert.Equal(t, 5.0, result[0].metricValue)
+}
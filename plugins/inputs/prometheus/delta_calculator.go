@@ -16,8 +16,9 @@ const (
 )
 
 type dataPoint struct {
-	value    float64
-	timeInMS int64
+	value     float64
+	histogram *prometheusHistogram
+	timeInMS  int64
 }
 type DeltaCalculator struct {
 	preDataPoints       *mapWithExpiry.MapWithExpiry
@@ -37,11 +38,18 @@ func (dc *DeltaCalculator) calculate(pm *PrometheusMetric) (res *PrometheusMetri
 	}
 
 	curVal := pm.metricValue
+	curHistogram := pm.histogram
 	curTimeInMS := pm.timeInMS
 	if v, ok := dc.preDataPoints.Get(metricKey); ok {
 		preDataPoint := v.(dataPoint)
 		if curTimeInMS > preDataPoint.timeInMS {
-			if curVal >= preDataPoint.value {
+			if curHistogram != nil {
+				// the histogram has been reset if there is no delta, keep the current histogram as delta
+				if delta, ok := curHistogram.delta(preDataPoint.histogram); ok {
+					pm.histogram = delta
+					pm.metricValue = delta.count
+				}
+			} else if curVal >= preDataPoint.value {
 				pm.metricValue = curVal - preDataPoint.value
 			} else {
 				// the counter has been reset, keep the current value as delta
@@ -57,11 +65,16 @@ func (dc *DeltaCalculator) calculate(pm *PrometheusMetric) (res *PrometheusMetri
 		dc.lastCleanUpTimeInMs = curTimeInMS
 	}
 
-	dc.preDataPoints.Set(metricKey, dataPoint{value: curVal, timeInMS: curTimeInMS})
+	dc.preDataPoints.Set(metricKey, dataPoint{value: curVal, histogram: curHistogram, timeInMS: curTimeInMS})
 
 	return
 }
 
+// forget removes the previous data point of the metric.
+func (dc *DeltaCalculator) forget(pm *PrometheusMetric) {
+	dc.preDataPoints.Delete(getUniqMetricKey(pm))
+}
+
 func NewDeltaCalculator() *DeltaCalculator {
 	return &DeltaCalculator{preDataPoints: mapWithExpiry.NewMapWithExpiry(CacheTTL), lastCleanUpTimeInMs: 0}
 }
@@ -0,0 +1,210 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/value"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/seh1"
+)
+
+const bucketLabel = "le"
+
+// histogramBucket is a bucket of a histogram with the number of samples between its bounds,
+// not the cumulative count of the classic histograms.
+type histogramBucket struct {
+	lower float64
+	upper float64
+	// value represents the samples of the bucket in the distribution.
+	value float64
+	count float64
+}
+
+// prometheusHistogram is either a classic histogram reassembled from its _bucket series
+// or a native histogram.
+type prometheusHistogram struct {
+	buckets []histogramBucket
+	count   float64
+}
+
+// newClassicHistogram reassembles the histogram from the cumulative counts of its buckets,
+// keyed by their upper bound.
+func newClassicHistogram(cumulativeCounts map[float64]float64) *prometheusHistogram {
+	bounds := make([]float64, 0, len(cumulativeCounts))
+	for upper := range cumulativeCounts {
+		bounds = append(bounds, upper)
+	}
+	sort.Float64s(bounds)
+	h := &prometheusHistogram{}
+	lower := math.Inf(-1)
+	var previous float64
+	for _, upper := range bounds {
+		cumulative := cumulativeCounts[upper]
+		h.buckets = append(h.buckets, histogramBucket{
+			lower: lower,
+			upper: upper,
+			value: classicBucketValue(lower, upper),
+			count: math.Max(cumulative-previous, 0),
+		})
+		h.count = math.Max(h.count, cumulative)
+		lower = upper
+		previous = cumulative
+	}
+	return h
+}
+
+// classicBucketValue is the midpoint of the bucket. The first bucket is assumed to start
+// at 0 if its upper bound is positive, and the +Inf bucket is represented by its lower bound.
+func classicBucketValue(lower, upper float64) float64 {
+	switch {
+	case math.IsInf(lower, -1) && math.IsInf(upper, 1):
+		return 0
+	case math.IsInf(lower, -1):
+		if upper > 0 {
+			return upper / 2
+		}
+		return upper
+	case math.IsInf(upper, 1):
+		return lower
+	default:
+		return (lower + upper) / 2
+	}
+}
+
+// newNativeHistogram converts the buckets of the native histogram. The samples of a bucket
+// are represented by the geometric mean of its bounds, the zero bucket by 0.
+func newNativeHistogram(fh *histogram.FloatHistogram) *prometheusHistogram {
+	h := &prometheusHistogram{count: fh.Count}
+	it := fh.AllBucketIterator()
+	for it.Next() {
+		b := it.At()
+		if b.Count == 0 {
+			continue
+		}
+		var v float64
+		switch {
+		case b.Lower > 0:
+			v = math.Sqrt(b.Lower * b.Upper)
+		case b.Upper < 0:
+			v = -math.Sqrt(b.Lower * b.Upper)
+		}
+		h.buckets = append(h.buckets, histogramBucket{lower: b.Lower, upper: b.Upper, value: v, count: b.Count})
+	}
+	return h
+}
+
+// nativeHistogramValue is the value of the metric of a native histogram, its sample
+// count unless the histogram is a staleness marker.
+func nativeHistogramValue(fh *histogram.FloatHistogram) float64 {
+	if value.IsStaleNaN(fh.Sum) {
+		return fh.Sum
+	}
+	return fh.Count
+}
+
+// delta returns the samples observed since the previous histogram, or false if the
+// histogram has been reset in between.
+func (h *prometheusHistogram) delta(previous *prometheusHistogram) (*prometheusHistogram, bool) {
+	if previous == nil || h.count < previous.count {
+		return nil, false
+	}
+	type bounds struct{ lower, upper float64 }
+	previousCounts := make(map[bounds]float64, len(previous.buckets))
+	for _, b := range previous.buckets {
+		previousCounts[bounds{b.lower, b.upper}] = b.count
+	}
+	d := &prometheusHistogram{count: h.count - previous.count, buckets: make([]histogramBucket, 0, len(h.buckets))}
+	for _, b := range h.buckets {
+		count := b.count - previousCounts[bounds{b.lower, b.upper}]
+		if count < 0 {
+			return nil, false
+		}
+		b.count = count
+		d.buckets = append(d.buckets, b)
+	}
+	return d, true
+}
+
+// distribution adds the samples of each bucket to a distribution, dropping the ones the
+// distribution does not support, e.g. negative values.
+func (h *prometheusHistogram) distribution() distribution.Distribution {
+	newDistribution := distribution.NewDistribution
+	if newDistribution == nil {
+		newDistribution = seh1.NewSEH1Distribution
+	}
+	dist := newDistribution()
+	for _, b := range h.buckets {
+		if b.count <= 0 {
+			continue
+		}
+		if err := dist.AddEntry(b.value, b.count); err != nil {
+			log.Printf("D! Dropping the bucket (%v, %v] of the prometheus histogram: %v", b.lower, b.upper, err)
+		}
+	}
+	return dist
+}
+
+// assembleClassicHistograms replaces the _bucket series of the classic histograms with one
+// metric per histogram. The _sum and _count series are kept as they are.
+func assembleClassicHistograms(pmb PrometheusMetricBatch) (result PrometheusMetricBatch) {
+	type classicHistogram struct {
+		pm     *PrometheusMetric
+		counts map[float64]float64
+	}
+	var histograms []*classicHistogram
+	byKey := map[string]*classicHistogram{}
+	for _, pm := range pmb {
+		if !pm.isHistogram() || pm.histogram != nil || !strings.HasSuffix(pm.metricName, histogramBucketSuffix) {
+			result = append(result, pm)
+			continue
+		}
+		upper, err := strconv.ParseFloat(pm.tags[bucketLabel], 64)
+		if err != nil {
+			log.Printf("D! Drop prometheus histogram bucket with invalid %s label: %v", bucketLabel, pm)
+			continue
+		}
+		tags := make(map[string]string, len(pm.tags))
+		for k, v := range pm.tags {
+			if k != bucketLabel {
+				tags[k] = v
+			}
+		}
+		base := &PrometheusMetric{
+			tags:       tags,
+			metricName: strings.TrimSuffix(pm.metricName, histogramBucketSuffix),
+			metricType: pm.metricType,
+			timeInMS:   pm.timeInMS,
+		}
+		key := getUniqMetricKey(base)
+		h, ok := byKey[key]
+		if !ok {
+			h = &classicHistogram{pm: base, counts: map[float64]float64{}}
+			byKey[key] = h
+			histograms = append(histograms, h)
+		}
+		// A stale or invalid bucket invalidates the whole histogram.
+		if !pm.isValueValid() {
+			h.pm.metricValue = pm.metricValue
+		}
+		h.counts[upper] = pm.metricValue
+	}
+	for _, h := range histograms {
+		if h.pm.isValueValid() {
+			h.pm.histogram = newClassicHistogram(h.counts)
+			h.pm.metricValue = h.pm.histogram.count
+		} else {
+			h.pm.histogram = &prometheusHistogram{}
+		}
+		result = append(result, h.pm)
+	}
+	return result
+}
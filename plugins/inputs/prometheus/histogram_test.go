@@ -0,0 +1,181 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+)
+
+func buildClassicHistogram(timeInMS int64, sum float64, cumulativeCounts map[string]float64) (result PrometheusMetricBatch) {
+	newMetric := func(name string, v float64, tags map[string]string) *PrometheusMetric {
+		tags["job"] = "test"
+		return &PrometheusMetric{metricName: name, metricType: "histogram", metricValue: v, timeInMS: timeInMS, tags: tags}
+	}
+	for le, v := range cumulativeCounts {
+		result = append(result, newMetric("latency_bucket", v, map[string]string{"le": le}))
+	}
+	result = append(result, newMetric("latency_sum", sum, map[string]string{}))
+	result = append(result, newMetric("latency_count", cumulativeCounts["+Inf"], map[string]string{}))
+	return result
+}
+
+func findMetric(pmb PrometheusMetricBatch, name string) *PrometheusMetric {
+	for _, pm := range pmb {
+		if pm.metricName == name {
+			return pm
+		}
+	}
+	return nil
+}
+
+func TestAssembleClassicHistograms(t *testing.T) {
+	pmb := assembleClassicHistograms(buildClassicHistogram(1000, 9, map[string]float64{"1": 2, "2": 5, "5": 6, "+Inf": 7}))
+	require.Len(t, pmb, 3)
+	assert.NotNil(t, findMetric(pmb, "latency_sum"))
+	assert.NotNil(t, findMetric(pmb, "latency_count"))
+	pm := findMetric(pmb, "latency")
+	require.NotNil(t, pm)
+	assert.Equal(t, map[string]string{"job": "test"}, pm.tags)
+	assert.Equal(t, 7.0, pm.metricValue)
+	assert.Equal(t, &prometheusHistogram{
+		count: 7,
+		buckets: []histogramBucket{
+			{lower: math.Inf(-1), upper: 1, value: 0.5, count: 2},
+			{lower: 1, upper: 2, value: 1.5, count: 3},
+			{lower: 2, upper: 5, value: 3.5, count: 1},
+			{lower: 5, upper: math.Inf(1), value: 5, count: 1},
+		},
+	}, pm.histogram)
+
+	// A stale bucket makes the whole histogram stale.
+	pmb = buildClassicHistogram(1000, 9, map[string]float64{"1": 2, "+Inf": math.Float64frombits(value.StaleNaN)})
+	pm = findMetric(assembleClassicHistograms(pmb), "latency")
+	require.NotNil(t, pm)
+	assert.False(t, pm.isValueValid())
+}
+
+func TestNewNativeHistogram(t *testing.T) {
+	h := newNativeHistogram(&histogram.FloatHistogram{
+		Schema:          0,
+		ZeroThreshold:   0.001,
+		ZeroCount:       1,
+		Count:           8,
+		Sum:             10,
+		PositiveSpans:   []histogram.Span{{Offset: 0, Length: 2}},
+		PositiveBuckets: []float64{2, 3},
+		NegativeSpans:   []histogram.Span{{Offset: 1, Length: 1}},
+		NegativeBuckets: []float64{2},
+	})
+	assert.Equal(t, 8.0, h.count)
+	require.Len(t, h.buckets, 4)
+	assert.Equal(t, histogramBucket{lower: -2, upper: -1, value: -math.Sqrt2, count: 2}, h.buckets[0])
+	assert.Equal(t, histogramBucket{lower: -0.001, upper: 0.001, value: 0, count: 1}, h.buckets[1])
+	assert.InDelta(t, math.Sqrt(0.5), h.buckets[2].value, 1e-9)
+	assert.Equal(t, 2.0, h.buckets[2].count)
+	assert.InDelta(t, math.Sqrt2, h.buckets[3].value, 1e-9)
+	assert.Equal(t, 3.0, h.buckets[3].count)
+
+	dist := h.distribution()
+	// The negative bucket is not supported by the distribution.
+	assert.Equal(t, 6.0, dist.SampleCount())
+	assert.Equal(t, 0.0, dist.Minimum())
+}
+
+func TestHistogramDelta(t *testing.T) {
+	previous := newClassicHistogram(map[float64]float64{1: 2, math.Inf(1): 3})
+	delta, ok := newClassicHistogram(map[float64]float64{1: 4, math.Inf(1): 7}).delta(previous)
+	require.True(t, ok)
+	assert.Equal(t, 4.0, delta.count)
+	assert.Equal(t, 2.0, delta.buckets[0].count)
+	assert.Equal(t, 2.0, delta.buckets[1].count)
+
+	// The sample count or the count of a bucket decreased.
+	_, ok = newClassicHistogram(map[float64]float64{1: 1, math.Inf(1): 2}).delta(previous)
+	assert.False(t, ok)
+	_, ok = newClassicHistogram(map[float64]float64{1: 1, math.Inf(1): 5}).delta(previous)
+	assert.False(t, ok)
+}
+
+func TestCalculator_Histograms(t *testing.T) {
+	c := NewCalculator()
+	// The first scrape is only used as the base of the delta.
+	assert.Empty(t, c.Calculate(buildClassicHistogram(1000, 9, map[string]float64{"1": 2, "2": 5, "+Inf": 7})))
+
+	result := c.Calculate(buildClassicHistogram(2000, 15, map[string]float64{"1": 3, "2": 6, "+Inf": 10}))
+	require.Len(t, result, 3)
+	assert.Equal(t, 6.0, findMetric(result, "latency_sum").metricValue)
+	assert.Equal(t, 3.0, findMetric(result, "latency_count").metricValue)
+	pm := findMetric(result, "latency")
+	require.NotNil(t, pm)
+	assert.Equal(t, 3.0, pm.histogram.count)
+	dist := pm.histogram.distribution()
+	assert.Equal(t, 3.0, dist.SampleCount())
+
+	// Without any new sample there is no histogram to publish.
+	result = c.Calculate(buildClassicHistogram(3000, 15, map[string]float64{"1": 3, "2": 6, "+Inf": 10}))
+	assert.Nil(t, findMetric(result, "latency"))
+
+	// After a reset the current histogram is the delta.
+	result = c.Calculate(buildClassicHistogram(4000, 1, map[string]float64{"1": 1, "2": 1, "+Inf": 1}))
+	pm = findMetric(result, "latency")
+	require.NotNil(t, pm)
+	assert.Equal(t, 1.0, pm.histogram.count)
+}
+
+func Test_metricAppender_AppendHistogram(t *testing.T) {
+	mr := metricsReceiver{}
+	ma := mr.Appender(nil)
+	ls := []labels.Label{
+		{Name: "__name__", Value: "latency"},
+		{Name: "tag_a", Value: "a"},
+	}
+	_, err := ma.AppendHistogram(0, ls, 10, &histogram.Histogram{
+		Schema:          0,
+		Count:           5,
+		Sum:             6,
+		PositiveSpans:   []histogram.Span{{Offset: 0, Length: 2}},
+		PositiveBuckets: []int64{2, 1},
+	}, nil)
+	assert.NoError(t, err)
+	mac, _ := ma.(*metricAppender)
+	require.Len(t, mac.batch, 1)
+	pm := mac.batch[0]
+	assert.Equal(t, "latency", pm.metricName)
+	assert.Equal(t, 5.0, pm.metricValue)
+	assert.Equal(t, map[string]string{"tag_a": "a"}, pm.tags)
+	require.Len(t, pm.histogram.buckets, 2)
+	assert.Equal(t, 2.0, pm.histogram.buckets[0].count)
+	assert.Equal(t, 3.0, pm.histogram.buckets[1].count)
+
+	_, err = ma.AppendHistogram(0, ls, 20, &histogram.Histogram{Sum: math.Float64frombits(value.StaleNaN)}, nil)
+	assert.NoError(t, err)
+	assert.False(t, mac.batch[1].isValueValid())
+}
+
+func TestMergeMetrics_Histograms(t *testing.T) {
+	pmb := PrometheusMetricBatch{
+		{metricName: "latency_sum", metricValue: 6, tags: map[string]string{"job": "test"}},
+		{metricName: "latency", histogram: newClassicHistogram(map[float64]float64{1: 2, math.Inf(1): 3}), tags: map[string]string{"job": "test"}},
+	}
+	mms := mergeMetrics(pmb)
+	require.Len(t, mms, 2)
+	for _, mm := range mms {
+		if mm.histogram {
+			_, ok := mm.fields["latency"].(distribution.Distribution)
+			assert.True(t, ok)
+			assert.Len(t, mm.fields, 1)
+		} else {
+			assert.Equal(t, map[string]interface{}{"latency_sum": 6.0}, mm.fields)
+		}
+	}
+}
@@ -398,3 +398,28 @@ func TestNewMetricsTypeHandler_HandleRelabelName(t *testing.T) {
 	assert.Equal(t, expectedMetric1, *result[0])
 	assert.Equal(t, expectedMetric2, *result[1])
 }
+
+func TestNewMetricsTypeHandler_HandleScrapeReportMetrics(t *testing.T) {
+	metricsTypeHandler := NewMetricsTypeHandler()
+	metricsTypeHandler.SetScrapeManager(&mockScrapeManager{})
+	pmb := make(PrometheusMetricBatch, 0)
+	// The report metrics are not relabeled by the metric relabel configs.
+	for _, name := range []string{"up", "scrape_duration_seconds", "scrape_samples_scraped", "scrape_series_added"} {
+		pmb = append(pmb, &PrometheusMetric{
+			metricName:            name,
+			jobBeforeRelabel:      "job1",
+			instanceBeforeRelabel: "instance1",
+			tags:                  map[string]string{"job": "job1", "instance": "instance1"},
+		})
+	}
+
+	result := metricsTypeHandler.Handle(pmb)
+	require.Equal(t, 4, len(result))
+	for _, pm := range result[:3] {
+		assert.Equal(t, string(model.MetricTypeGauge), pm.metricType, pm.metricName)
+		assert.Equal(t, string(model.MetricTypeGauge), pm.tags[prometheusMetricTypeKey])
+	}
+	// The other internal metrics are kept untyped and dropped by the filter.
+	assert.Empty(t, result[3].metricType)
+	assert.Len(t, NewMetricsFilter().Filter(result), 3)
+}
@@ -20,10 +20,10 @@ type MetricsFilter struct {
 // Filter out and Log the unsupported metric types
 func (mf *MetricsFilter) Filter(pmb PrometheusMetricBatch) (result PrometheusMetricBatch) {
 	for _, pm := range pmb {
-		if !pm.isGauge() && !pm.isCounter() && !pm.isSummary() {
+		if !pm.isGauge() && !pm.isCounter() && !pm.isSummary() && !pm.isHistogram() {
 			if mf.droppedMetrics == nil {
 				mf.droppedMetrics = make(map[string]string, mf.maxDropMetricsLogged)
-				log.Println("I! Drop Prometheus metrics with unsupported types. Only Gauge, Counter and Summary are supported.")
+				log.Println("I! Drop Prometheus metrics with unsupported types. Only Gauge, Counter, Histogram and Summary are supported.")
 				log.Printf("I! Please enable CWAgent debug mode to view the first %d dropped metrics \n", mf.maxDropMetricsLogged)
 			}
 
@@ -18,6 +18,8 @@ type metricMaterial struct {
 	tags     map[string]string
 	fields   map[string]interface{}
 	timeInMS int64
+	// histogram is set if the fields are distributions
+	histogram bool
 }
 
 type metricsHandler struct {
@@ -43,13 +45,16 @@ func (mh *metricsHandler) start(shutDownChan chan interface{}, wg *sync.WaitGrou
 }
 
 func (mh *metricsHandler) handle(pmb PrometheusMetricBatch) {
+	// Forget the previous values of the stale series, even if their type is no longer known
+	mh.calculator.ForgetStale(pmb)
+
 	// Add metric type info
 	pmb = mh.mtHandler.Handle(pmb)
 
-	// Filter out Histogram and untyped Metrics and adding logging
+	// Filter out untyped Metrics and adding logging
 	pmb = mh.filter.Filter(pmb)
 
-	// do calculation: calculate delta for counter
+	// do calculation: calculate delta for counter, summary count and sum, and histogram
 	pmb = mh.calculator.Calculate(pmb)
 
 	// do merge: merge metrics which are sharing same tags
@@ -59,6 +64,13 @@ func (mh *metricsHandler) handle(pmb PrometheusMetricBatch) {
 	mh.setEmfMetadata(metricMaterials)
 
 	for _, metricMaterial := range metricMaterials {
+		if metricMaterial.histogram {
+			// The buckets are kept by the cloudwatch output. The awsemf exporter only
+			// publishes the count, sum, min and max of the histograms, estimated from the
+			// midpoints of the buckets, so the percentiles need metrics_destination cloudwatch.
+			mh.acc.AddHistogram("prometheus", metricMaterial.fields, metricMaterial.tags, time.UnixMilli(metricMaterial.timeInMS))
+			continue
+		}
 		mh.acc.AddFields("prometheus", metricMaterial.fields, metricMaterial.tags, time.UnixMilli(metricMaterial.timeInMS))
 	}
 }
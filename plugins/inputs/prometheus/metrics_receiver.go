@@ -31,6 +31,10 @@ type PrometheusMetric struct {
 	metricValue             float64
 	metricType              string
 	timeInMS                int64 // Unix time in milli-seconds
+	// histogram is set for the histograms, whose metricValue is their sample count.
+	histogram *prometheusHistogram
+	// pushed is set for the metrics received with remote write, which have no scrape target.
+	pushed bool
 }
 
 func (pm *PrometheusMetric) isValueValid() bool {
@@ -67,6 +71,15 @@ func (mr *metricsReceiver) feed(batch PrometheusMetricBatch) error {
 }
 
 func (ma *metricAppender) Append(ref storage.SeriesRef, ls labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	pm, err := newPrometheusMetric(ls, t, v)
+	if err != nil {
+		return 0, err
+	}
+	ma.batch = append(ma.batch, pm)
+	return 0, nil //return 0 to indicate caching is not supported
+}
+
+func newPrometheusMetric(ls labels.Labels, t int64, v float64) (*PrometheusMetric, error) {
 	metricName := ""
 
 	labelMap := make(map[string]string, len(ls))
@@ -81,7 +94,7 @@ func (ma *metricAppender) Append(ref storage.SeriesRef, ls labels.Labels, t int6
 	if metricName == "" {
 		// The error should never happen, print log here for debugging
 		log.Println("E! receive invalid prometheus metric, metricName is missing")
-		return 0, errors.New("metricName of the times-series is missing")
+		return nil, errors.New("metricName of the times-series is missing")
 	}
 
 	pm := &PrometheusMetric{
@@ -99,8 +112,7 @@ func (ma *metricAppender) Append(ref storage.SeriesRef, ls labels.Labels, t int6
 	delete(labelMap, savedScrapeInstanceLabel)
 
 	pm.tags = labelMap
-	ma.batch = append(ma.batch, pm)
-	return 0, nil //return 0 to indicate caching is not supported
+	return pm, nil
 }
 
 func (ma *metricAppender) Commit() error {
@@ -123,7 +135,19 @@ func (ma *metricAppender) UpdateMetadata(ref storage.SeriesRef, l labels.Labels,
 	return ref, nil
 }
 
+// AppendHistogram receives the native histograms, which are only scraped with the protobuf format.
 func (ma *metricAppender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram, fh *histogram.FloatHistogram) (storage.SeriesRef, error) {
-	// This code should no longer be used
-	return ref, nil
+	if fh == nil {
+		if h == nil {
+			return 0, nil
+		}
+		fh = h.ToFloat(nil)
+	}
+	pm, err := newPrometheusMetric(l, t, nativeHistogramValue(fh))
+	if err != nil {
+		return 0, err
+	}
+	pm.histogram = newNativeHistogram(fh)
+	ma.batch = append(ma.batch, pm)
+	return 0, nil
 }
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/scrape"
 )
 
@@ -102,12 +103,18 @@ type ScrapeManager interface {
 
 type metricsTypeHandler struct {
 	ms metadataService
+	// pushedMetadata is the metadata of the metrics received with remote write.
+	pushedMetadata metadataCache
 }
 
 func NewMetricsTypeHandler() *metricsTypeHandler {
 	return &metricsTypeHandler{}
 }
 
+func (mth *metricsTypeHandler) SetRemoteWriteMetadata(mc metadataCache) {
+	mth.pushedMetadata = mc
+}
+
 func (mth *metricsTypeHandler) SetScrapeManager(scrapeManager ScrapeManager) {
 	if scrapeManager != nil {
 		mth.ms = &metadataServiceImpl{sm: scrapeManager}
@@ -141,18 +148,30 @@ func (mth *metricsTypeHandler) Handle(pmb PrometheusMetricBatch) (result Prometh
 		return nil
 	}
 
-	jobName, instanceId, err := getScrapeTargetInfo(pmb)
-	if err != nil {
-		log.Printf("E! Failed to get Job Name and Instance ID from scrape targetss %s", err)
-		return nil
-	}
+	var jobName, instanceId string
+	var mc metadataCache
+	if pmb[0].pushed {
+		// All the metrics of a batch are either scraped or pushed with remote write.
+		if mth.pushedMetadata == nil {
+			log.Printf("E! Drop %d prometheus metrics received without remote write metadata", len(pmb))
+			return nil
+		}
+		mc = mth.pushedMetadata
+	} else {
+		var err error
+		jobName, instanceId, err = getScrapeTargetInfo(pmb)
+		if err != nil {
+			log.Printf("E! Failed to get Job Name and Instance ID from scrape targetss %s", err)
+			return nil
+		}
 
-	mc, err := mth.ms.Get(jobName, instanceId)
-	if err != nil {
-		log.Printf("E! metricsTypeHandler.mc.Get(jobName, instanceId) error. jobName: %s  instanceId: %s: %v", jobName, instanceId, err)
-		// The Pod has been terminated when we are going to handle its Prometheus metrics in the channel
-		// Drop the metrics directly
-		return result
+		mc, err = mth.ms.Get(jobName, instanceId)
+		if err != nil {
+			log.Printf("E! metricsTypeHandler.mc.Get(jobName, instanceId) error. jobName: %s  instanceId: %s: %v", jobName, instanceId, err)
+			// The Pod has been terminated when we are going to handle its Prometheus metrics in the channel
+			// Drop the metrics directly
+			return result
+		}
 	}
 	for _, pm := range pmb {
 		// log for https://github.com/aws/amazon-cloudwatch-agent/issues/190
@@ -174,6 +193,10 @@ func (mth *metricsTypeHandler) Handle(pmb PrometheusMetricBatch) (result Prometh
 				mm, ok = mc.Metadata(standardMetricName)
 			}
 		}
+		if !ok && isScrapeReportMetric(pm.metricName) {
+			// The report metrics have no metadata, they are gauges.
+			mm, ok = scrape.MetricMetadata{Metric: pm.metricName, Type: model.MetricTypeGauge}, true
+		}
 		if ok {
 			pm.metricType = string(mm.Type)
 			pm.tags[prometheusMetricTypeKey] = pm.metricType
@@ -5,21 +5,25 @@ package prometheus
 
 import (
 	_ "embed"
+	"fmt"
 	"sync"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	"github.com/aws/amazon-cloudwatch-agent/internal/ecsservicediscovery"
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sannotationdiscovery"
 )
 
 //go:embed prometheus.toml
 var sampleConfig string
 
 type Prometheus struct {
-	PrometheusConfigPath string                                      `toml:"prometheus_config_path"`
-	ClusterName          string                                      `toml:"cluster_name"`
-	ECSSDConfig          *ecsservicediscovery.ServiceDiscoveryConfig `toml:"ecs_service_discovery"`
+	PrometheusConfigPath string                                         `toml:"prometheus_config_path"`
+	ClusterName          string                                         `toml:"cluster_name"`
+	ECSSDConfig          *ecsservicediscovery.ServiceDiscoveryConfig    `toml:"ecs_service_discovery"`
+	K8sAnnotationSD      *k8sannotationdiscovery.ServiceDiscoveryConfig `toml:"k8s_annotation_discovery"`
+	RemoteWrite          *RemoteWriteConfig                             `toml:"remote_write"`
 	mbCh                 chan PrometheusMetricBatch
 	shutDownChan         chan interface{}
 	wg                   sync.WaitGroup
@@ -48,6 +52,17 @@ func (p *Prometheus) Start(accIn telegraf.Accumulator) error {
 		mtHandler:   mth,
 	}
 
+	// Start receiving prometheus metrics pushed with remote write
+	if p.RemoteWrite != nil {
+		rw, err := newRemoteWriteReceiver(p.RemoteWrite, p.mbCh)
+		if err != nil {
+			return fmt.Errorf("unable to start the prometheus remote write receiver: %w", err)
+		}
+		mth.SetRemoteWriteMetadata(rw.metadata)
+		p.wg.Add(1)
+		go rw.start(p.shutDownChan, &p.wg)
+	}
+
 	ecssd := &ecsservicediscovery.ServiceDiscovery{Config: p.ECSSDConfig}
 
 	// Start ECS Service Discovery when in ECS
@@ -55,9 +70,16 @@ func (p *Prometheus) Start(accIn telegraf.Accumulator) error {
 	p.wg.Add(1)
 	go ecsservicediscovery.StartECSServiceDiscovery(ecssd, p.shutDownChan, &p.wg)
 
-	// Start scraping prometheus metrics from prometheus endpoints
+	// Start discovering the pods annotated with cloudwatch.aws/scrape-port when in Kubernetes
+	k8ssd := &k8sannotationdiscovery.ServiceDiscovery{Config: p.K8sAnnotationSD}
 	p.wg.Add(1)
-	go Start(p.PrometheusConfigPath, receiver, p.shutDownChan, &p.wg, mth)
+	go k8sannotationdiscovery.StartK8sAnnotationDiscovery(k8ssd, p.shutDownChan, &p.wg)
+
+	// Start scraping prometheus metrics from prometheus endpoints
+	if p.PrometheusConfigPath != "" {
+		p.wg.Add(1)
+		go Start(p.PrometheusConfigPath, receiver, p.shutDownChan, &p.wg, mth)
+	}
 
 	// Start filter our prometheus metrics, calculate delta value if its a Counter or Summary count sum
 	// and convert Prometheus metrics to Telegraf Metrics
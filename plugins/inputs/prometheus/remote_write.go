@@ -0,0 +1,308 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/scrape"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/mapWithExpiry"
+)
+
+const (
+	defaultRemoteWritePath = "/api/v1/write"
+
+	// The requests of the senders are far smaller, e.g. 2000 samples for Prometheus, so the
+	// limits only protect the agent from the memory used by oversized requests.
+	maxRemoteWriteBodySize    = 10 * 1024 * 1024
+	maxRemoteWriteDecodedSize = 32 * 1024 * 1024
+
+	quantileLabel = "quantile"
+
+	// The types inferred from the series of the metrics no longer pushed are removed after
+	// the expiry, so the metadata cache does not grow with the churn of the metric names.
+	inferredMetricTypeExpiry = 10 * time.Minute
+)
+
+// RemoteWriteConfig is the endpoint receiving the metrics pushed with the Prometheus
+// remote write protocol (protobuf + snappy).
+type RemoteWriteConfig struct {
+	ServiceAddress string `toml:"service_address"`
+	Path           string `toml:"path"`
+}
+
+// remoteWriteMetadata is the metadata cache of the metrics pushed with remote write.
+// The senders push the metadata of the metric families separately from the samples, e.g.
+// every minute for Prometheus, so the types inferred from the series are used until the
+// metadata is received.
+type remoteWriteMetadata struct {
+	mu          sync.RWMutex
+	sent        map[string]model.MetricType
+	inferred    *mapWithExpiry.MapWithExpiry
+	expiry      time.Duration
+	lastCleanUp time.Time
+}
+
+func newRemoteWriteMetadata(expiry time.Duration) *remoteWriteMetadata {
+	return &remoteWriteMetadata{
+		sent:        map[string]model.MetricType{},
+		inferred:    mapWithExpiry.NewMapWithExpiry(expiry),
+		expiry:      expiry,
+		lastCleanUp: time.Now(),
+	}
+}
+
+func (m *remoteWriteMetadata) Metadata(metricName string) (scrape.MetricMetadata, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if t, ok := m.sent[metricName]; ok {
+		return scrape.MetricMetadata{Metric: metricName, Type: t}, true
+	}
+	if t, ok := m.inferred.Get(metricName); ok {
+		return scrape.MetricMetadata{Metric: metricName, Type: t.(model.MetricType)}, true
+	}
+	return scrape.MetricMetadata{}, false
+}
+
+func (m *remoteWriteMetadata) update(req *prompb.WriteRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now := time.Now(); now.Sub(m.lastCleanUp) >= m.expiry {
+		m.inferred.CleanUp(now)
+		m.lastCleanUp = now
+	}
+	for _, md := range req.Metadata {
+		if md.MetricFamilyName == "" || md.Type == prompb.MetricMetadata_UNKNOWN {
+			continue
+		}
+		m.sent[md.MetricFamilyName] = model.MetricType(strings.ToLower(md.Type.String()))
+	}
+	for _, ts := range req.Timeseries {
+		var name string
+		var hasBucket, hasQuantile bool
+		for _, l := range ts.Labels {
+			switch l.Name {
+			case model.MetricNameLabel:
+				name = l.Value
+			case bucketLabel:
+				hasBucket = true
+			case quantileLabel:
+				hasQuantile = true
+			}
+		}
+		switch {
+		case name == "":
+		case len(ts.Histograms) > 0:
+			m.inferred.Set(name, model.MetricTypeHistogram)
+		case hasBucket && strings.HasSuffix(name, histogramBucketSuffix):
+			m.inferred.Set(strings.TrimSuffix(name, histogramBucketSuffix), model.MetricTypeHistogram)
+		case hasQuantile:
+			m.inferred.Set(name, model.MetricTypeSummary)
+		case strings.HasSuffix(name, counterSuffix):
+			m.inferred.Set(strings.TrimSuffix(name, counterSuffix), model.MetricTypeCounter)
+		case strings.HasSuffix(name, histogramSummaryCountSuffix) || strings.HasSuffix(name, histogramSummarySumSuffix):
+			// The type of the _sum and _count series is the type of their histogram or summary.
+		default:
+			// Keep the type inferred from the other series of the metric, e.g. the buckets.
+			if t, ok := m.inferred.Get(name); ok {
+				m.inferred.Set(name, t)
+			} else {
+				m.inferred.Set(name, model.MetricTypeGauge)
+			}
+		}
+	}
+}
+
+// remoteWriteReceiver converts the remote write requests into metric batches handled the
+// same way as the scraped ones.
+type remoteWriteReceiver struct {
+	pmbCh    chan<- PrometheusMetricBatch
+	metadata *remoteWriteMetadata
+	server   *http.Server
+	listener net.Listener
+}
+
+func newRemoteWriteReceiver(config *RemoteWriteConfig, pmbCh chan<- PrometheusMetricBatch) (*remoteWriteReceiver, error) {
+	if config.ServiceAddress == "" {
+		return nil, errors.New("remote_write service_address is not set")
+	}
+	path := config.Path
+	if path == "" {
+		path = defaultRemoteWritePath
+	}
+	listener, err := net.Listen("tcp", config.ServiceAddress)
+	if err != nil {
+		return nil, err
+	}
+	rw := &remoteWriteReceiver{
+		pmbCh:    pmbCh,
+		metadata: newRemoteWriteMetadata(inferredMetricTypeExpiry),
+		listener: listener,
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, rw)
+	rw.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return rw, nil
+}
+
+func (rw *remoteWriteReceiver) start(shutDownChan chan interface{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	log.Printf("I! Prometheus remote write receiver listening on %s", rw.listener.Addr())
+	go func() {
+		if err := rw.server.Serve(rw.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! Prometheus remote write receiver stopped: %v", err)
+		}
+	}()
+	<-shutDownChan
+	rw.server.Close()
+}
+
+func (rw *remoteWriteReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	req, err := decodeWriteRequest(http.MaxBytesReader(w, r.Body, maxRemoteWriteBodySize))
+	if err != nil {
+		log.Printf("E! Unable to decode the prometheus remote write request: %v", err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rw.metadata.update(req)
+	batch := newRemoteWriteBatch(req)
+	if len(batch) > 0 {
+		select {
+		case rw.pmbCh <- batch:
+		default:
+			// The sender retries the request on a 5xx status.
+			log.Println("W! Prometheus remote write request rejected due to channel full")
+			http.Error(w, "metric batch channel is full", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newRemoteWriteBatch converts the samples and the native histograms of the request.
+func newRemoteWriteBatch(req *prompb.WriteRequest) PrometheusMetricBatch {
+	var batch PrometheusMetricBatch
+	for _, ts := range req.Timeseries {
+		ls := make([]labels.Label, 0, len(ts.Labels))
+		for _, l := range ts.Labels {
+			ls = append(ls, labels.Label{Name: l.Name, Value: l.Value})
+		}
+		lbls := labels.New(ls...)
+		newPushedMetric := func(t int64, v float64) *PrometheusMetric {
+			pm, err := newPrometheusMetric(lbls, t, v)
+			if err != nil {
+				return nil
+			}
+			// The pushed metrics are not relabeled.
+			pm.metricNameBeforeRelabel = pm.metricName
+			pm.pushed = true
+			return pm
+		}
+		for _, s := range ts.Samples {
+			if pm := newPushedMetric(s.Timestamp, s.Value); pm != nil {
+				batch = append(batch, pm)
+			}
+		}
+		for _, h := range ts.Histograms {
+			fh := histogramProtoToFloatHistogram(h)
+			if pm := newPushedMetric(h.Timestamp, nativeHistogramValue(fh)); pm != nil {
+				pm.histogram = newNativeHistogram(fh)
+				batch = append(batch, pm)
+			}
+		}
+	}
+	return batch
+}
+
+// decodeWriteRequest decompresses and unmarshals the request. The storage/remote package of
+// prometheus is not used as it registers feature gates already registered by the collector.
+func decodeWriteRequest(r io.Reader) (*prompb.WriteRequest, error) {
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxRemoteWriteDecodedSize {
+		return nil, fmt.Errorf("decoded request of %d bytes exceeds the limit of %d bytes", size, maxRemoteWriteDecodedSize)
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, err
+	}
+	var req prompb.WriteRequest
+	if err = req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// histogramProtoToFloatHistogram converts both the integer and the float native histograms.
+func histogramProtoToFloatHistogram(hp prompb.Histogram) *histogram.FloatHistogram {
+	fh := &histogram.FloatHistogram{
+		CounterResetHint: histogram.CounterResetHint(hp.ResetHint),
+		Schema:           hp.Schema,
+		ZeroThreshold:    hp.ZeroThreshold,
+		Sum:              hp.Sum,
+		PositiveSpans:    spansProtoToSpans(hp.GetPositiveSpans()),
+		NegativeSpans:    spansProtoToSpans(hp.GetNegativeSpans()),
+	}
+	if hp.IsFloatHistogram() {
+		fh.ZeroCount = hp.GetZeroCountFloat()
+		fh.Count = hp.GetCountFloat()
+		fh.PositiveBuckets = hp.GetPositiveCounts()
+		fh.NegativeBuckets = hp.GetNegativeCounts()
+		return fh
+	}
+	fh.ZeroCount = float64(hp.GetZeroCountInt())
+	fh.Count = float64(hp.GetCountInt())
+	fh.PositiveBuckets = deltasToCounts(hp.GetPositiveDeltas())
+	fh.NegativeBuckets = deltasToCounts(hp.GetNegativeDeltas())
+	return fh
+}
+
+func spansProtoToSpans(s []prompb.BucketSpan) []histogram.Span {
+	spans := make([]histogram.Span, len(s))
+	for i, span := range s {
+		spans[i] = histogram.Span{Offset: span.Offset, Length: span.Length}
+	}
+	return spans
+}
+
+// deltasToCounts converts the bucket counts of the integer histograms, encoded as the delta
+// to the previous bucket.
+func deltasToCounts(deltas []int64) []float64 {
+	counts := make([]float64, len(deltas))
+	var current int64
+	for i, delta := range deltas {
+		current += delta
+		counts[i] = float64(current)
+	}
+	return counts
+}
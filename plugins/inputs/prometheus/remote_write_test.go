@@ -0,0 +1,230 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTimeSeries(name string, v float64, extraLabels ...string) prompb.TimeSeries {
+	ls := []prompb.Label{{Name: "__name__", Value: name}, {Name: "job", Value: "remote"}}
+	for i := 0; i+1 < len(extraLabels); i += 2 {
+		ls = append(ls, prompb.Label{Name: extraLabels[i], Value: extraLabels[i+1]})
+	}
+	return prompb.TimeSeries{Labels: ls, Samples: []prompb.Sample{{Timestamp: 1000, Value: v}}}
+}
+
+func postWriteRequest(t *testing.T, rw *remoteWriteReceiver, req *prompb.WriteRequest) int {
+	data, err := req.Marshal()
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(snappy.Encode(nil, data))))
+	return recorder.Code
+}
+
+func TestRemoteWriteReceiver(t *testing.T) {
+	pmbCh := make(chan PrometheusMetricBatch, 1)
+	rw := &remoteWriteReceiver{pmbCh: pmbCh, metadata: newRemoteWriteMetadata(inferredMetricTypeExpiry)}
+	mth := NewMetricsTypeHandler()
+	mth.SetRemoteWriteMetadata(rw.metadata)
+
+	nativeHistogram := newTimeSeries("rpc_latency", 0)
+	nativeHistogram.Samples = nil
+	nativeHistogram.Histograms = []prompb.Histogram{{
+		Count:          &prompb.Histogram_CountInt{CountInt: 3},
+		Sum:            4,
+		PositiveSpans:  []prompb.BucketSpan{{Offset: 0, Length: 1}},
+		PositiveDeltas: []int64{3},
+		Timestamp:      1000,
+	}}
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			newTimeSeries("http_requests_total", 10),
+			newTimeSeries("memory_bytes", 20),
+			newTimeSeries("latency_bucket", 1, "le", "0.5"),
+			newTimeSeries("latency_bucket", 2, "le", "+Inf"),
+			newTimeSeries("latency_sum", 0.7),
+			newTimeSeries("latency_count", 2),
+			newTimeSeries("queue_size", 5),
+			nativeHistogram,
+		},
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "queue_size", Type: prompb.MetricMetadata_COUNTER},
+		},
+	}
+	assert.Equal(t, http.StatusNoContent, postWriteRequest(t, rw, req))
+	pmb := <-pmbCh
+	require.Len(t, pmb, 8)
+	for _, pm := range pmb {
+		assert.True(t, pm.pushed)
+		assert.Equal(t, map[string]string{"job": "remote"}, excludeLabel(pm.tags, "le"))
+	}
+
+	typed := mth.Handle(pmb)
+	require.Len(t, typed, 8)
+	expected := map[string]string{
+		"http_requests_total": "counter",
+		"memory_bytes":        "gauge",
+		"latency_bucket":      "histogram",
+		"latency_sum":         "histogram",
+		"latency_count":       "histogram",
+		"queue_size":          "counter",
+		"rpc_latency":         "histogram",
+	}
+	for _, pm := range typed {
+		assert.Equal(t, expected[pm.metricName], pm.metricType, pm.metricName)
+		assert.Equal(t, pm.metricType, pm.tags[prometheusMetricTypeKey])
+	}
+	pm := findMetric(typed, "rpc_latency")
+	require.NotNil(t, pm.histogram)
+	assert.Equal(t, 3.0, pm.metricValue)
+	assert.Equal(t, 3.0, pm.histogram.count)
+
+	// The channel is full, the sender has to retry.
+	pmbCh <- PrometheusMetricBatch{}
+	assert.Equal(t, http.StatusServiceUnavailable, postWriteRequest(t, rw, req))
+	<-pmbCh
+
+	recorder := httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader([]byte("invalid"))))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, defaultRemoteWritePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	// The oversized requests are rejected before they are decoded.
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(make([]byte, maxRemoteWriteBodySize+1))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(binary.AppendUvarint(nil, maxRemoteWriteDecodedSize+1))))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "exceeds the limit")
+}
+
+func TestRemoteWriteMetadata_InferredTypesExpire(t *testing.T) {
+	expiry := 50 * time.Millisecond
+	metadata := newRemoteWriteMetadata(expiry)
+	metadata.update(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			newTimeSeries("old_requests_total", 1),
+			newTimeSeries("memory_bytes", 1),
+		},
+	})
+	_, ok := metadata.Metadata("old_requests")
+	assert.True(t, ok)
+
+	// only the types inferred again from the request are kept after the expiry
+	time.Sleep(expiry)
+	metadata.update(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{newTimeSeries("memory_bytes", 2)},
+	})
+	_, ok = metadata.Metadata("old_requests")
+	assert.False(t, ok)
+	md, ok := metadata.Metadata("memory_bytes")
+	assert.True(t, ok)
+	assert.Equal(t, model.MetricTypeGauge, md.Type)
+	assert.Equal(t, 1, metadata.inferred.Size())
+}
+
+func TestRemoteWriteReceiver_Start(t *testing.T) {
+	_, err := newRemoteWriteReceiver(&RemoteWriteConfig{}, nil)
+	assert.Error(t, err)
+
+	pmbCh := make(chan PrometheusMetricBatch, 1)
+	rw, err := newRemoteWriteReceiver(&RemoteWriteConfig{ServiceAddress: "127.0.0.1:0", Path: "/receive"}, pmbCh)
+	require.NoError(t, err)
+	shutDownChan := make(chan interface{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go rw.start(shutDownChan, &wg)
+
+	data, err := (&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{newTimeSeries("memory_bytes", 20)}}).Marshal()
+	require.NoError(t, err)
+	resp, err := http.Post("http://"+rw.listener.Addr().String()+"/receive", "application/x-protobuf", bytes.NewReader(snappy.Encode(nil, data)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	pmb := <-pmbCh
+	require.Len(t, pmb, 1)
+	assert.Equal(t, "memory_bytes", pmb[0].metricName)
+	assert.Equal(t, 20.0, pmb[0].metricValue)
+
+	close(shutDownChan)
+	wg.Wait()
+}
+
+func excludeLabel(tags map[string]string, name string) map[string]string {
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if k != name {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func TestHistogramProtoToFloatHistogram(t *testing.T) {
+	fh := histogramProtoToFloatHistogram(prompb.Histogram{
+		Count:          &prompb.Histogram_CountInt{CountInt: 6},
+		ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: 1},
+		PositiveSpans:  []prompb.BucketSpan{{Offset: 1, Length: 2}},
+		PositiveDeltas: []int64{2, 1},
+	})
+	assert.Equal(t, 6.0, fh.Count)
+	assert.Equal(t, 1.0, fh.ZeroCount)
+	assert.Equal(t, []float64{2, 3}, fh.PositiveBuckets)
+
+	fh = histogramProtoToFloatHistogram(prompb.Histogram{
+		Count:          &prompb.Histogram_CountFloat{CountFloat: 2.5},
+		PositiveSpans:  []prompb.BucketSpan{{Offset: 0, Length: 1}},
+		PositiveCounts: []float64{2.5},
+	})
+	assert.Equal(t, 2.5, fh.Count)
+	assert.Equal(t, []float64{2.5}, fh.PositiveBuckets)
+}
+
+func TestRemoteWriteBatch_SeveralSamplesPerSeries(t *testing.T) {
+	counter := newTimeSeries("http_requests_total", 0)
+	counter.Samples = []prompb.Sample{{Timestamp: 1000, Value: 10}, {Timestamp: 2000, Value: 15}, {Timestamp: 3000, Value: 25}}
+	gauge := newTimeSeries("memory_bytes", 0)
+	gauge.Samples = []prompb.Sample{{Timestamp: 2000, Value: 20}, {Timestamp: 3000, Value: 30}}
+	pmb := newRemoteWriteBatch(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{counter, gauge}})
+	require.Len(t, pmb, 5)
+	for _, pm := range pmb {
+		pm.metricType = "gauge"
+		if pm.metricName == "http_requests_total" {
+			pm.metricType = "counter"
+		}
+		pm.tags[prometheusMetricTypeKey] = pm.metricType
+	}
+
+	// Each sample is published with its own timestamp, and each delta of the counter is kept.
+	mms := mergeMetrics(NewCalculator().Calculate(pmb))
+	require.Len(t, mms, 4)
+	sort.Slice(mms, func(i, j int) bool { return mms[i].timeInMS < mms[j].timeInMS })
+	published := map[string][]interface{}{}
+	for _, mm := range mms {
+		for name, v := range mm.fields {
+			published[name] = append(published[name], mm.timeInMS, v)
+		}
+	}
+	assert.Equal(t, map[string][]interface{}{
+		"http_requests_total": {int64(2000), 5.0, int64(3000), 10.0},
+		"memory_bytes":        {int64(2000), 20.0, int64(3000), 30.0},
+	}, published)
+}
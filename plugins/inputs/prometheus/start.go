@@ -24,6 +24,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"slices"
 	"sync"
 	"syscall"
 
@@ -307,6 +308,7 @@ func reloadConfig(filename string, logger log.Logger, rls ...func(*config.Config
 	if err != nil {
 		return errors.Wrapf(err, "couldn't load configuration (--config.file=%q)", filename)
 	}
+	preferProtobufScrapes(conf)
 
 	// For saving name before relabel
 	// - __name__ https://github.com/aws/amazon-cloudwatch-agent/issues/190
@@ -352,3 +354,18 @@ func reloadConfig(filename string, logger log.Logger, rls ...func(*config.Config
 	level.Info(logger).Log("msg", "Completed loading of configuration file", "filename", filename)
 	return nil
 }
+
+// preferProtobufScrapes negotiates the protobuf format first with the targets, since the native
+// histograms are only exposed with it, like Prometheus does with its native-histograms feature
+// flag. The jobs keep the scrape_protocols set in the prometheus config, unless they are the
+// default ones.
+func preferProtobufScrapes(conf *config.Config) {
+	if slices.Equal(conf.GlobalConfig.ScrapeProtocols, config.DefaultScrapeProtocols) {
+		conf.GlobalConfig.ScrapeProtocols = config.DefaultProtoFirstScrapeProtocols
+	}
+	for _, sc := range conf.ScrapeConfigs {
+		if slices.Equal(sc.ScrapeProtocols, config.DefaultScrapeProtocols) {
+			sc.ScrapeProtocols = conf.GlobalConfig.ScrapeProtocols
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferProtobufScrapes(t *testing.T) {
+	conf, err := config.Load(`
+scrape_configs:
+  - job_name: default
+    static_configs:
+      - targets: ["localhost:9090"]
+  - job_name: text
+    scrape_protocols: ["PrometheusText0.0.4"]
+    static_configs:
+      - targets: ["localhost:9091"]
+`, false, log.NewNopLogger())
+	require.NoError(t, err)
+	preferProtobufScrapes(conf)
+
+	// The native histograms are only exposed with the protobuf format.
+	assert.Equal(t, config.DefaultProtoFirstScrapeProtocols, conf.GlobalConfig.ScrapeProtocols)
+	require.Len(t, conf.ScrapeConfigs, 2)
+	assert.Equal(t, config.DefaultProtoFirstScrapeProtocols, conf.ScrapeConfigs[0].ScrapeProtocols)
+	assert.Equal(t, []config.ScrapeProtocol{config.PrometheusText0_0_4}, conf.ScrapeConfigs[1].ScrapeProtocols)
+}
@@ -6,15 +6,18 @@ package prometheus
 import (
 	"bytes"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 )
 
-func getTagsKey(pm *PrometheusMetric) *bytes.Buffer {
+func getTagsKey(pm *PrometheusMetric, excludedTags ...string) *bytes.Buffer {
 	b := new(bytes.Buffer)
 	keys := make([]string, 0, len(pm.tags))
 	for k := range pm.tags {
-		keys = append(keys, k)
+		if !slices.Contains(excludedTags, k) {
+			keys = append(keys, k)
+		}
 	}
 	sort.Strings(keys)
 	for _, k := range keys {
@@ -24,13 +27,25 @@ func getTagsKey(pm *PrometheusMetric) *bytes.Buffer {
 }
 
 // return MetricKey from all tags which is used to merge metrics which are sharing same tags
+// The histograms are merged separately from the other metrics. A remote write request can
+// carry several samples of a series, so the pushed metrics are only merged with the metrics
+// of the same timestamp.
 func getMetricKeyForMerging(pm *PrometheusMetric) string {
-	return getTagsKey(pm).String()
+	buffer := getTagsKey(pm)
+	if pm.histogram != nil {
+		buffer.WriteString("histogram")
+	}
+	if pm.pushed {
+		_, _ = fmt.Fprintf(buffer, "timeInMS=%d,", pm.timeInMS)
+	}
+	return buffer.String()
 }
 
 // return uniq MetricKey, which is used to calculate delta of metrics.
+// The metric type tag is excluded, so that the key of a staleness marker whose type is unknown,
+// e.g. because its target has been removed, matches the key of the previous data points.
 func getUniqMetricKey(pm *PrometheusMetric) string {
-	buffer := getTagsKey(pm)
+	buffer := getTagsKey(pm, prometheusMetricTypeKey)
 	// We assume there won't be same metricName+tags with different metricType, so that it is not necessary to add metricType into uniqKey.
 	_, _ = fmt.Fprintf(buffer, "metricName=%s,", pm.metricName)
 	return buffer.String()
@@ -53,9 +68,13 @@ func mergeMetrics(pmb PrometheusMetricBatch) (result []*metricMaterial) {
 func mergePrometheusMetrics(mm *metricMaterial, pm *PrometheusMetric) *metricMaterial {
 	if mm == nil {
 		// metricType is not propagated to metricMaterial intentionally.
-		mm = &metricMaterial{tags: pm.tags, fields: map[string]interface{}{}, timeInMS: pm.timeInMS}
+		mm = &metricMaterial{tags: pm.tags, fields: map[string]interface{}{}, timeInMS: pm.timeInMS, histogram: pm.histogram != nil}
 	}
 
+	if pm.histogram != nil {
+		mm.fields[pm.metricName] = pm.histogram.distribution()
+		return mm
+	}
 	mm.fields[pm.metricName] = pm.metricValue
 	return mm
 }
@@ -64,3 +83,13 @@ func isInternalMetric(metricName string) bool {
 	//For each endpoint, Prometheus produces a set of internal metrics. See https://prometheus.io/docs/concepts/jobs_instances/
 	return metricName == "up" || strings.HasPrefix(metricName, "scrape_")
 }
+
+// isScrapeReportMetric returns true for the series the scraper reports for each target, which
+// are published as gauges so that the target-down conditions can be alarmed on.
+func isScrapeReportMetric(metricName string) bool {
+	switch metricName {
+	case "up", "scrape_duration_seconds", "scrape_samples_scraped":
+		return true
+	}
+	return false
+}
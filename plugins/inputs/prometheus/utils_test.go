@@ -32,6 +32,10 @@ func Test_getUniqMetricKey(t *testing.T) {
 	}
 	r := getUniqMetricKey(pm)
 	assert.Equal(t, "tagA=tagA_v,tagB=tagB_v,metricName=metric_name,", r)
+
+	// The key does not depend on the metric type tag.
+	pm.tags[prometheusMetricTypeKey] = "counter"
+	assert.Equal(t, r, getUniqMetricKey(pm))
 }
 
 func Test_mergeMetrics_merged(t *testing.T) {
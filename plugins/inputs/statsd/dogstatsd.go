@@ -0,0 +1,325 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+const (
+	eventPrefix        = "_e{"
+	serviceCheckPrefix = "_sc|"
+
+	containerIDTagKey = "container_id"
+)
+
+var serviceCheckStatuses = map[string]string{
+	"0": "OK",
+	"1": "WARNING",
+	"2": "CRITICAL",
+	"3": "UNKNOWN",
+}
+
+// dogStatsDEvent is the log message of a DogStatsD event or service check.
+type dogStatsDEvent struct {
+	Type           string            `json:"type"`
+	Title          string            `json:"title,omitempty"`
+	Text           string            `json:"text,omitempty"`
+	Name           string            `json:"name,omitempty"`
+	Status         string            `json:"status,omitempty"`
+	Message        string            `json:"message,omitempty"`
+	Hostname       string            `json:"hostname,omitempty"`
+	Priority       string            `json:"priority,omitempty"`
+	AlertType      string            `json:"alert_type,omitempty"`
+	AggregationKey string            `json:"aggregation_key,omitempty"`
+	SourceType     string            `json:"source_type_name,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+
+	timestamp time.Time
+}
+
+// parseDataDogTags adds the comma separated tags, e.g. country:china,production, to the map.
+func parseDataDogTags(tagstr string, tags map[string]string) {
+	for _, tag := range strings.Split(tagstr, ",") {
+		ts := strings.SplitN(tag, ":", 2)
+		var k, v string
+		switch len(ts) {
+		case 1:
+			// just a tag
+			k = ts[0]
+			v = "<empty>" //cloudwatch does not allow empty string
+		case 2:
+			k = ts[0]
+			v = ts[1]
+		}
+		if k != "" {
+			tags[k] = v
+		}
+	}
+}
+
+// parseEvent parses a DogStatsD event, which looks like this:
+// _e{<title length>,<text length>}:<title>|<text>|d:<timestamp>|h:<hostname>|p:<priority>|t:<alert type>|#<tags>
+func parseEvent(line string) (*dogStatsDEvent, error) {
+	lengths, rest, ok := strings.Cut(line[len(eventPrefix):], "}:")
+	if !ok {
+		return nil, errors.New("missing the title and text lengths")
+	}
+	titleLen, textLen, ok := strings.Cut(lengths, ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid title and text lengths %q", lengths)
+	}
+	titleLength, err := strconv.Atoi(titleLen)
+	if err != nil || titleLength < 0 {
+		return nil, fmt.Errorf("invalid title length %q", titleLen)
+	}
+	textLength, err := strconv.Atoi(textLen)
+	if err != nil || textLength < 0 {
+		return nil, fmt.Errorf("invalid text length %q", textLen)
+	}
+	// The lengths are in bytes, with the title and text separated by a pipe.
+	if len(rest) < titleLength+1+textLength || rest[titleLength] != '|' {
+		return nil, errors.New("title and text do not match their lengths")
+	}
+	e := &dogStatsDEvent{
+		Type:  "event",
+		Title: rest[:titleLength],
+		Text:  strings.ReplaceAll(rest[titleLength+1:titleLength+1+textLength], "\\n", "\n"),
+	}
+	rest = rest[titleLength+1+textLength:]
+	if rest == "" {
+		return e, nil
+	}
+	if rest[0] != '|' {
+		return nil, errors.New("text does not match its length")
+	}
+	for _, field := range strings.Split(rest[1:], "|") {
+		switch {
+		case strings.HasPrefix(field, "d:"):
+			if e.timestamp, err = parseUnixTimestamp(field[2:]); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(field, "h:"):
+			e.Hostname = field[2:]
+		case strings.HasPrefix(field, "p:"):
+			e.Priority = field[2:]
+		case strings.HasPrefix(field, "t:"):
+			e.AlertType = field[2:]
+		case strings.HasPrefix(field, "k:"):
+			e.AggregationKey = field[2:]
+		case strings.HasPrefix(field, "s:"):
+			e.SourceType = field[2:]
+		case strings.HasPrefix(field, "c:"):
+			e.addTag(containerIDTagKey, field[2:])
+		case strings.HasPrefix(field, "#"):
+			e.addTags(field[1:])
+		}
+	}
+	return e, nil
+}
+
+// parseServiceCheck parses a DogStatsD service check, which looks like this:
+// _sc|<name>|<status>|d:<timestamp>|h:<hostname>|#<tags>|m:<message>
+func parseServiceCheck(line string) (*dogStatsDEvent, error) {
+	fields := strings.Split(line[len(serviceCheckPrefix):], "|")
+	if len(fields) < 2 || fields[0] == "" {
+		return nil, errors.New("missing the name or status")
+	}
+	status, ok := serviceCheckStatuses[fields[1]]
+	if !ok {
+		return nil, fmt.Errorf("invalid status %q", fields[1])
+	}
+	e := &dogStatsDEvent{Type: "service_check", Name: fields[0], Status: status}
+	for i := 2; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case strings.HasPrefix(field, "d:"):
+			var err error
+			if e.timestamp, err = parseUnixTimestamp(field[2:]); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(field, "h:"):
+			e.Hostname = field[2:]
+		case strings.HasPrefix(field, "c:"):
+			e.addTag(containerIDTagKey, field[2:])
+		case strings.HasPrefix(field, "#"):
+			e.addTags(field[1:])
+		case strings.HasPrefix(field, "m:"):
+			// The message is last and may contain pipes.
+			e.Message = strings.ReplaceAll(strings.Join(fields[i:], "|")[2:], "\\n", "\n")
+			return e, nil
+		}
+	}
+	return e, nil
+}
+
+func (e *dogStatsDEvent) addTag(k, v string) {
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
+	}
+	e.Tags[k] = v
+}
+
+func (e *dogStatsDEvent) addTags(tagstr string) {
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
+	}
+	parseDataDogTags(tagstr, e.Tags)
+}
+
+func parseUnixTimestamp(s string) (time.Time, error) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+type eventLogEvent struct {
+	msg string
+	t   time.Time
+}
+
+var _ logs.LogEvent = (*eventLogEvent)(nil)
+
+func (e *eventLogEvent) Message() string {
+	return e.msg
+}
+
+func (e *eventLogEvent) Time() time.Time {
+	return e.t
+}
+
+func (e *eventLogEvent) Done() {}
+
+// eventSrc publishes the DogStatsD events and service checks as log events.
+type eventSrc struct {
+	group     string
+	stream    string
+	retention int
+
+	mu     sync.Mutex
+	output func(logs.LogEvent)
+	// The number of events being published, outside of the lock since the output
+	// blocks while its destinations are full.
+	publishing int
+	// The output to stop once the events being published are, when stopped meanwhile.
+	stopping func(logs.LogEvent)
+}
+
+var _ logs.LogSrc = (*eventSrc)(nil)
+
+func (s *eventSrc) SetOutput(fn func(logs.LogEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.output = fn
+}
+
+func (s *eventSrc) Group() string {
+	return s.group
+}
+
+func (s *eventSrc) Stream() string {
+	return s.stream
+}
+
+func (s *eventSrc) Destination() string {
+	return "cloudwatchlogs"
+}
+
+func (s *eventSrc) Description() string {
+	return "statsd events"
+}
+
+func (s *eventSrc) Retention() int {
+	return s.retention
+}
+
+func (s *eventSrc) Class() string {
+	return ""
+}
+
+// Stop disconnects the source and publishes the nil event to its output, like the
+// tailer does when it stops, so the output can release its destinations. It does not
+// wait for the events being published, since it can be called by the output itself
+// when a destination stops.
+func (s *eventSrc) Stop() {
+	s.mu.Lock()
+	output := s.output
+	s.output = nil
+	if output == nil {
+		s.mu.Unlock()
+		return
+	}
+	if s.publishing > 0 {
+		// The last event being published stops the output.
+		s.stopping = output
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	output(nil)
+}
+
+// publish returns false if the source is not connected to its destination yet.
+func (s *eventSrc) publish(e *dogStatsDEvent) bool {
+	msg, err := json.Marshal(e)
+	if err != nil {
+		return false
+	}
+	t := e.timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	s.mu.Lock()
+	output := s.output
+	if output == nil {
+		s.mu.Unlock()
+		return false
+	}
+	s.publishing++
+	s.mu.Unlock()
+
+	output(&eventLogEvent{msg: string(msg), t: t})
+
+	s.mu.Lock()
+	s.publishing--
+	stopping := s.stopping
+	if s.publishing > 0 {
+		stopping = nil
+	} else {
+		s.stopping = nil
+	}
+	s.mu.Unlock()
+	if stopping != nil {
+		stopping(nil)
+	}
+	return true
+}
+
+// parseEventLine publishes the DogStatsD event or service check as a log event.
+func (s *Statsd) parseEventLine(line string) error {
+	parse := parseEvent
+	if strings.HasPrefix(line, serviceCheckPrefix) {
+		parse = parseServiceCheck
+	}
+	e, err := parse(line)
+	if err != nil {
+		log.Printf("E! Error: %s, Unable to parse event: %s\n", err.Error(), line)
+		return errors.New("Error Parsing statsd line")
+	}
+	if s.events == nil || !s.events.publish(e) {
+		log.Printf("D! statsd: no log group to publish the %s to, dropping it: %s\n", e.Type, line)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+)
+
+func TestParse_DataDogDistribution(t *testing.T) {
+	s := NewTestStatsd()
+	s.ParseDataDogTags = true
+
+	require.NoError(t, s.parseStatsdLine("request.latency:1:2:3|d|@0.5|#env:prod|c:abc123|T1656581400"))
+	require.Len(t, s.timings, 1)
+	for _, cached := range s.timings {
+		assert.Equal(t, "request_latency", cached.name)
+		assert.Equal(t, map[string]string{
+			"metric_type":  "distribution",
+			"env":          "prod",
+			"container_id": "abc123",
+		}, cached.tags)
+		assert.Equal(t, time.Unix(1656581400, 0), cached.timestamp)
+		dist := cached.fields[defaultFieldName].(distribution.Distribution)
+		assert.Equal(t, 6.0, dist.SampleCount())
+		assert.Equal(t, 12.0, dist.Sum())
+	}
+
+	// Values without a type are invalid.
+	assert.Error(t, s.parseStatsdLine("request.latency:1:2"))
+	assert.Error(t, s.parseStatsdLine("request.latency:1|d|Tnow"))
+}
+
+func TestParse_MultipleValues(t *testing.T) {
+	s := NewTestStatsd()
+	s.ParseDataDogTags = true
+
+	require.NoError(t, s.parseStatsdLine("requests:1:2:3|c|#env:prod"))
+	require.NoError(t, s.parseStatsdLine("mixed:1|c:2|g"))
+	assert.NoError(t, test_validate_counter("requests", 6, s.counters))
+	assert.NoError(t, test_validate_counter("mixed", 1, s.counters))
+	assert.NoError(t, test_validate_gauge("mixed", 2, s.gauges))
+}
+
+func TestGather_Timestamps(t *testing.T) {
+	s := NewTestStatsd()
+	s.ParseDataDogTags = true
+
+	require.NoError(t, s.parseStatsdLine("temperature:20|g|T1656581400"))
+	require.NoError(t, s.parseStatsdLine("temperature:21|g|T1656581460"))
+	require.NoError(t, s.parseStatsdLine("temperature:22|g"))
+	require.Len(t, s.gauges, 3)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+	times := map[float64]time.Time{}
+	for _, m := range acc.Metrics {
+		times[m.Fields[defaultFieldName].(float64)] = m.Time
+	}
+	assert.Equal(t, time.Unix(1656581400, 0), times[20])
+	assert.Equal(t, time.Unix(1656581460, 0), times[21])
+	assert.WithinDuration(t, time.Now(), times[22], time.Minute)
+	// The metrics with a timestamp are not reported again.
+	assert.Len(t, s.gauges, 1)
+}
+
+func TestParseEvent(t *testing.T) {
+	e, err := parseEvent("_e{10,14}:Deployment|v1.2\\nfinished|d:1656581400|h:web-1|p:low|t:success|k:deploy|s:ci|#env:prod,team|c:abc123")
+	require.NoError(t, err)
+	assert.Equal(t, &dogStatsDEvent{
+		Type:           "event",
+		Title:          "Deployment",
+		Text:           "v1.2\nfinished",
+		Hostname:       "web-1",
+		Priority:       "low",
+		AlertType:      "success",
+		AggregationKey: "deploy",
+		SourceType:     "ci",
+		Tags:           map[string]string{"env": "prod", "team": "<empty>", "container_id": "abc123"},
+		timestamp:      time.Unix(1656581400, 0),
+	}, e)
+
+	// The title and text may contain pipes.
+	e, err = parseEvent("_e{3,3}:a|b|c|d")
+	require.NoError(t, err)
+	assert.Equal(t, "a|b", e.Title)
+	assert.Equal(t, "c|d", e.Text)
+
+	for _, line := range []string{
+		"_e{5,5}:title",
+		"_e{x,5}:title|texts",
+		"_e{5}:title|texts",
+		"_e{5,4}:title|texts",
+		"_e{5,5}:title|texts|d:yesterday",
+	} {
+		_, err = parseEvent(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParseServiceCheck(t *testing.T) {
+	e, err := parseServiceCheck("_sc|db.connection|2|d:1656581400|h:web-1|#env:prod|m:timeout | retrying")
+	require.NoError(t, err)
+	assert.Equal(t, &dogStatsDEvent{
+		Type:      "service_check",
+		Name:      "db.connection",
+		Status:    "CRITICAL",
+		Message:   "timeout | retrying",
+		Hostname:  "web-1",
+		Tags:      map[string]string{"env": "prod"},
+		timestamp: time.Unix(1656581400, 0),
+	}, e)
+
+	for _, line := range []string{
+		"_sc|db.connection",
+		"_sc||0",
+		"_sc|db.connection|4",
+	} {
+		_, err = parseServiceCheck(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParse_Events(t *testing.T) {
+	s := NewTestStatsd()
+	s.ParseDataDogTags = true
+	// Without a log group the events are dropped.
+	assert.NoError(t, s.parseStatsdLine("_sc|db.connection|0"))
+	assert.Nil(t, s.FindLogSrc())
+
+	s.events = &eventSrc{group: "group", stream: "stream", retention: -1}
+	srcs := s.FindLogSrc()
+	require.Len(t, srcs, 1)
+	assert.Nil(t, s.FindLogSrc())
+	assert.Equal(t, "group", srcs[0].Group())
+	assert.Equal(t, "stream", srcs[0].Stream())
+	assert.Equal(t, "cloudwatchlogs", srcs[0].Destination())
+
+	var events []logs.LogEvent
+	srcs[0].SetOutput(func(e logs.LogEvent) {
+		events = append(events, e)
+	})
+	assert.NoError(t, s.parseStatsdLine("_e{5,5}:title|texts|d:1656581400"))
+	assert.NoError(t, s.parseStatsdLine("_sc|db.connection|0|#env:prod"))
+	assert.Error(t, s.parseStatsdLine("_sc|db.connection|9"))
+	require.Len(t, events, 2)
+	assert.Equal(t, time.Unix(1656581400, 0), events[0].Time())
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(events[1].Message()), &got))
+	assert.Equal(t, map[string]interface{}{
+		"type":   "service_check",
+		"name":   "db.connection",
+		"status": "OK",
+		"tags":   map[string]interface{}{"env": "prod"},
+	}, got)
+	// No metrics are created from the events.
+	assert.Empty(t, s.gauges)
+	assert.Empty(t, s.counters)
+}
+
+func TestEventSrc_Stop(t *testing.T) {
+	src := &eventSrc{group: "group", stream: "stream", retention: -1}
+	var stopped bool
+	src.SetOutput(func(e logs.LogEvent) {
+		if e == nil {
+			stopped = true
+		}
+	})
+	src.Stop()
+	assert.True(t, stopped)
+	assert.False(t, src.publish(&dogStatsDEvent{Type: "event"}))
+
+	// The output stopping the source while it publishes, like the log agent does when
+	// a destination stops, gets the nil event once the event is published.
+	src = &eventSrc{group: "group", stream: "stream", retention: -1}
+	var published []logs.LogEvent
+	src.SetOutput(func(e logs.LogEvent) {
+		published = append(published, e)
+		if e != nil {
+			src.Stop()
+		}
+	})
+	done := make(chan bool)
+	go func() { done <- src.publish(&dogStatsDEvent{Type: "event"}) }()
+	select {
+	case ok := <-done:
+		assert.True(t, ok)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the source is deadlocked")
+	}
+	require.Len(t, published, 2)
+	assert.NotNil(t, published[0])
+	assert.Nil(t, published[1])
+	assert.False(t, src.publish(&dogStatsDEvent{Type: "event"}))
+}
@@ -4,10 +4,12 @@
 package statsd
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"log"
 	"net"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -18,7 +20,9 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
+	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/seh1"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/statsd/graphite"
 )
 
@@ -40,6 +44,17 @@ var dropwarn = "E! Error: statsd message queue full. " +
 type Statsd struct {
 	// Address & Port to serve from
 	ServiceAddress string
+	// TCPServiceAddress is the address to accept newline delimited metrics over TCP
+	// from, disabled if empty.
+	TCPServiceAddress string `toml:"tcp_service_address"`
+	// ServiceSocketPath is the path of the Unix datagram socket to serve from,
+	// disabled if empty.
+	ServiceSocketPath string `toml:"service_socket_path"`
+
+	// EventsLogGroupName and EventsLogStreamName are where the DogStatsD events and
+	// service checks are published as log events. They are dropped without a log group.
+	EventsLogGroupName  string `toml:"events_log_group_name"`
+	EventsLogStreamName string `toml:"events_log_stream_name"`
 
 	// Number of messages allowed to queue up in between calls to Gather. If this
 	// fills up, packets will get dropped until the next Gather interval is ran.
@@ -63,9 +78,12 @@ type Statsd struct {
 	int `toml:"udp_packet_size"`
 
 	sync.Mutex
-	wg sync.WaitGroup
+	wg        sync.WaitGroup
+	startOnce sync.Once
 	// drops tracks the number of dropped metrics.
 	drops int
+	// dropsMu guards drops, which is updated by all the listeners.
+	dropsMu sync.Mutex
 
 	// Channel for all incoming statsd packets
 	in   chan []byte
@@ -82,11 +100,21 @@ type Statsd struct {
 	// bucket -> influx templates
 	Templates []string
 
-	listener *net.UDPConn
+	listener       *net.UDPConn
+	tcpListener    net.Listener
+	socketListener *net.UnixConn
+	// tcpConns are the open TCP connections, closed on Stop.
+	tcpConns   map[net.Conn]struct{}
+	tcpConnsMu sync.Mutex
+
+	events      *eventSrc
+	eventsFound bool
 
 	graphiteParser *graphite.GraphiteParser
 }
 
+var _ logs.LogCollection = (*Statsd)(nil)
+
 // One statsd metric, form is <bucket>:<value>|<mtype>|@<samplerate>
 type metric struct {
 	name       string
@@ -100,30 +128,36 @@ type metric struct {
 	additive   bool
 	samplerate float64
 	tags       map[string]string
+	// timestamp is set by the DogStatsD |T extension, the time of Gather otherwise.
+	timestamp time.Time
 }
 
 type cachedset struct {
-	name   string
-	fields map[string]map[string]bool
-	tags   map[string]string
+	name      string
+	fields    map[string]map[string]bool
+	tags      map[string]string
+	timestamp time.Time
 }
 
 type cachedgauge struct {
-	name   string
-	fields map[string]interface{}
-	tags   map[string]string
+	name      string
+	fields    map[string]interface{}
+	tags      map[string]string
+	timestamp time.Time
 }
 
 type cachedcounter struct {
-	name   string
-	fields map[string]interface{}
-	tags   map[string]string
+	name      string
+	fields    map[string]interface{}
+	tags      map[string]string
+	timestamp time.Time
 }
 
 type cachedtimings struct {
-	name   string
-	fields map[string]interface{}
-	tags   map[string]string
+	name      string
+	fields    map[string]interface{}
+	tags      map[string]string
+	timestamp time.Time
 }
 
 func (_ *Statsd) Description() string {
@@ -133,6 +167,10 @@ func (_ *Statsd) Description() string {
 const sampleConfig = `
   ## Address and port to host UDP listener on
   service_address = ":8125"
+  ## Address and port to host the TCP listener on, for newline delimited metrics
+  # tcp_service_address = ":8125"
+  ## Path of the Unix datagram socket to listen on
+  # service_socket_path = "/var/run/statsd.sock"
 
   ## The following configuration options control when telegraf clears it's cache
   ## of previous values. If set to false, then telegraf will only clear it's
@@ -153,6 +191,11 @@ const sampleConfig = `
   ## http://docs.datadoghq.com/guides/dogstatsd/
   parse_data_dog_tags = false
 
+  ## Log group and stream to publish the DogStatsD events and service checks to.
+  ## They are dropped if no log group is set.
+  # events_log_group_name = "statsd-events"
+  # events_log_stream_name = "{instance_id}"
+
   ## Statsd data translation templates, more info can be read here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md#graphite
   # templates = [
@@ -177,33 +220,46 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 	defer s.Unlock()
 	now := time.Now()
 
-	for _, metric := range s.timings {
-		acc.AddHistogram(metric.name, metric.fields, metric.tags, now)
+	// The metrics with a timestamp of their own are only reported once.
+	for hash, metric := range s.timings {
+		acc.AddHistogram(metric.name, metric.fields, metric.tags, metricTime(metric.timestamp, now))
+		if !metric.timestamp.IsZero() {
+			delete(s.timings, hash)
+		}
 	}
 	if s.DeleteTimings {
 		s.timings = make(map[string]cachedtimings)
 	}
 
-	for _, metric := range s.gauges {
-		acc.AddFields(metric.name, metric.fields, metric.tags, now)
+	for hash, metric := range s.gauges {
+		acc.AddFields(metric.name, metric.fields, metric.tags, metricTime(metric.timestamp, now))
+		if !metric.timestamp.IsZero() {
+			delete(s.gauges, hash)
+		}
 	}
 	if s.DeleteGauges {
 		s.gauges = make(map[string]cachedgauge)
 	}
 
-	for _, metric := range s.counters {
-		acc.AddFields(metric.name, metric.fields, metric.tags, now)
+	for hash, metric := range s.counters {
+		acc.AddFields(metric.name, metric.fields, metric.tags, metricTime(metric.timestamp, now))
+		if !metric.timestamp.IsZero() {
+			delete(s.counters, hash)
+		}
 	}
 	if s.DeleteCounters {
 		s.counters = make(map[string]cachedcounter)
 	}
 
-	for _, metric := range s.sets {
+	for hash, metric := range s.sets {
 		fields := make(map[string]interface{})
 		for field, set := range metric.fields {
 			fields[field] = int64(len(set))
 		}
-		acc.AddFields(metric.name, fields, metric.tags, now)
+		acc.AddFields(metric.name, fields, metric.tags, metricTime(metric.timestamp, now))
+		if !metric.timestamp.IsZero() {
+			delete(s.sets, hash)
+		}
 	}
 	if s.DeleteSets {
 		s.sets = make(map[string]cachedset)
@@ -212,7 +268,21 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+func metricTime(timestamp, now time.Time) time.Time {
+	if timestamp.IsZero() {
+		return now
+	}
+	return timestamp
+}
+
+// Start is called by both the metrics and the logs pipelines, the listeners
+// are only started once.
 func (s *Statsd) Start(_ telegraf.Accumulator) error {
+	s.startOnce.Do(s.start)
+	return nil
+}
+
+func (s *Statsd) start() {
 	// Make data structures
 	s.done = make(chan struct{})
 	s.in = make(chan []byte, s.AllowedPendingMessages)
@@ -226,13 +296,54 @@ func (s *Statsd) Start(_ telegraf.Accumulator) error {
 		s.MetricSeparator = defaultSeparator
 	}
 
+	s.tcpConns = make(map[net.Conn]struct{})
+	if s.EventsLogGroupName != "" {
+		s.events = &eventSrc{group: s.EventsLogGroupName, stream: s.EventsLogStreamName, retention: -1}
+	}
+
 	s.wg.Add(2)
 	// Start the UDP listener
 	go s.udpListen()
 	// Start the line parser
 	go s.parser()
+	if s.TCPServiceAddress != "" {
+		var err error
+		if s.tcpListener, err = net.Listen("tcp", s.TCPServiceAddress); err != nil {
+			log.Printf("E! Error: unable to listen on TCP %s: %v\n", s.TCPServiceAddress, err)
+		} else {
+			log.Println("I! Statsd TCP listener listening on: ", s.tcpListener.Addr().String())
+			s.wg.Add(1)
+			go s.tcpListen()
+		}
+	}
+	if s.ServiceSocketPath != "" {
+		// Remove the socket left over by a previous run.
+		if err := os.Remove(s.ServiceSocketPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("W! Unable to remove the statsd socket %s: %v\n", s.ServiceSocketPath, err)
+		}
+		var err error
+		if s.socketListener, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: s.ServiceSocketPath, Net: "unixgram"}); err != nil {
+			log.Printf("E! Error: unable to listen on socket %s: %v\n", s.ServiceSocketPath, err)
+		} else {
+			log.Println("I! Statsd socket listener listening on: ", s.ServiceSocketPath)
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.readPackets(s.socketListener)
+			}()
+		}
+	}
 	log.Printf("I! Started the statsd service on %s\n", s.ServiceAddress)
-	return nil
+}
+
+// FindLogSrc returns the source of the DogStatsD events the first time it is
+// called, if a log group is configured for them.
+func (s *Statsd) FindLogSrc() []logs.LogSrc {
+	if s.events == nil || s.eventsFound {
+		return nil
+	}
+	s.eventsFound = true
+	return []logs.LogSrc{s.events}
 }
 
 // udpListen starts listening for udp packets on the configured port.
@@ -245,30 +356,87 @@ func (s *Statsd) udpListen() error {
 		log.Fatalf("ERROR: ListenUDP - %s", err)
 	}
 	log.Println("I! Statsd listener listening on: ", s.listener.LocalAddr().String())
+	s.readPackets(s.listener)
+	return nil
+}
 
+// readPackets reads the packets from the connection until the service is stopped.
+func (s *Statsd) readPackets(conn net.PacketConn) {
 	buf := make([]byte, UDP_MAX_PACKET_SIZE)
 	for {
 		select {
 		case <-s.done:
-			return nil
+			return
 		default:
-			n, _, err := s.listener.ReadFromUDP(buf)
+			n, _, err := conn.ReadFrom(buf)
 			if err != nil && !strings.Contains(err.Error(), "closed network") {
 				log.Printf("E! Error READ: %s\n", err.Error())
 				continue
 			}
 			bufCopy := make([]byte, n)
 			copy(bufCopy, buf[:n])
+			s.enqueue(bufCopy)
+		}
+	}
+}
 
+// tcpListen accepts the TCP connections until the service is stopped.
+func (s *Statsd) tcpListen() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.tcpListener.Accept()
+		if err != nil {
 			select {
-			case s.in <- bufCopy:
+			case <-s.done:
+				return
 			default:
-				s.drops++
-				if s.drops == 1 || s.AllowedPendingMessages == 0 || s.drops%s.AllowedPendingMessages == 0 {
-					log.Printf(dropwarn, s.drops)
-				}
 			}
+			log.Printf("E! Error accepting TCP connection: %s\n", err.Error())
+			continue
+		}
+		s.tcpConnsMu.Lock()
+		s.tcpConns[conn] = struct{}{}
+		s.tcpConnsMu.Unlock()
+		s.wg.Add(1)
+		go s.handleTCPConn(conn)
+	}
+}
+
+// handleTCPConn reads the newline delimited metrics of the connection.
+func (s *Statsd) handleTCPConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.tcpConnsMu.Lock()
+		delete(s.tcpConns, conn)
+		s.tcpConnsMu.Unlock()
+		conn.Close()
+	}()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), UDP_MAX_PACKET_SIZE)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		line := make([]byte, len(scanner.Bytes()))
+		copy(line, scanner.Bytes())
+		s.enqueue(line)
+	}
+	if err := scanner.Err(); err != nil && !strings.Contains(err.Error(), "closed network") {
+		log.Printf("E! Error reading TCP connection from %s: %s\n", conn.RemoteAddr(), err.Error())
+	}
+}
+
+// enqueue queues the packet for the parser, dropping it if the queue is full.
+func (s *Statsd) enqueue(packet []byte) {
+	select {
+	case s.in <- packet:
+	default:
+		s.dropsMu.Lock()
+		s.drops++
+		if s.drops == 1 || s.AllowedPendingMessages == 0 || s.drops%s.AllowedPendingMessages == 0 {
+			log.Printf(dropwarn, s.drops)
 		}
+		s.dropsMu.Unlock()
 	}
 }
 
@@ -297,8 +465,12 @@ func (s *Statsd) parser() error {
 // parseStatsdLine will parse the given statsd line, validating it as it goes.
 // If the line is valid, it will be cached for the next call to Gather()
 func (s *Statsd) parseStatsdLine(line string) error {
+	if strings.HasPrefix(line, eventPrefix) || strings.HasPrefix(line, serviceCheckPrefix) {
+		return s.parseEventLine(line)
+	}
 
 	lineTags := make(map[string]string)
+	var timestamp time.Time
 	if s.ParseDataDogTags {
 		recombinedSegments := make([]string, 0)
 		// datadog tags look like this:
@@ -306,28 +478,22 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		// users.online:1|c|#sometagwithnovalue
 		// we will split on the pipe and remove any elements that are datadog
 		// tags, parse them, and rebuild the line sans the datadog tags
+		// The container ID and timestamp extensions follow the type, e.g.
+		// users.online:1|c|c:<container id>|T<unix timestamp>
 		pipesplit := strings.Split(line, "|")
-		for _, segment := range pipesplit {
+		for i, segment := range pipesplit {
 			if len(segment) > 0 && segment[0] == '#' {
 				// we have ourselves a tag; they are comma separated
-				tagstr := segment[1:]
-				tags := strings.Split(tagstr, ",")
-				for _, tag := range tags {
-					ts := strings.SplitN(tag, ":", 2)
-					var k, v string
-					switch len(ts) {
-					case 1:
-						// just a tag
-						k = ts[0]
-						v = "<empty>" //cloudwatch does not allow empty string
-					case 2:
-						k = ts[0]
-						v = ts[1]
-					}
-					if k != "" {
-						lineTags[k] = v
-					}
+				parseDataDogTags(segment[1:], lineTags)
+			} else if i > 1 && strings.HasPrefix(segment, "c:") {
+				lineTags[containerIDTagKey] = segment[2:]
+			} else if i > 1 && len(segment) > 1 && segment[0] == 'T' {
+				ts, err := parseUnixTimestamp(segment[1:])
+				if err != nil {
+					log.Printf("E! Error: parsing timestamp, %s, Unable to parse metric: %s\n", err.Error(), line)
+					return errors.New("Error Parsing statsd line")
 				}
+				timestamp = ts
 			} else {
 				recombinedSegments = append(recombinedSegments, segment)
 			}
@@ -343,13 +509,14 @@ func (s *Statsd) parseStatsdLine(line string) error {
 	}
 
 	// Extract bucket name from individual metric bits
-	bucketName, bits := bits[0], bits[1:]
+	bucketName, bits := bits[0], expandMultiValues(bits[1:])
 
 	// Add a metric for each bit available
 	for _, bit := range bits {
 		m := metric{}
 
 		m.bucket = bucketName
+		m.timestamp = timestamp
 
 		// Validate splitting the bit on "|"
 		pipesplit := strings.Split(bit, "|")
@@ -375,7 +542,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 
 		// Validate metric type
 		switch pipesplit[1] {
-		case "g", "c", "s", "ms", "h":
+		case "g", "c", "s", "ms", "h", "d":
 			m.mtype = pipesplit[1]
 		default:
 			log.Printf("E! Error: Statsd Metric type %s unsupported", pipesplit[1])
@@ -392,7 +559,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		}
 
 		switch m.mtype {
-		case "g", "ms", "h":
+		case "g", "ms", "h", "d":
 			v, err := strconv.ParseFloat(pipesplit[0], 64)
 			if err != nil {
 				log.Printf("E! Error: parsing value to float64: %s\n", line)
@@ -432,6 +599,8 @@ func (s *Statsd) parseStatsdLine(line string) error {
 			m.tags["metric_type"] = "timing"
 		case "h":
 			m.tags["metric_type"] = "histogram"
+		case "d":
+			m.tags["metric_type"] = "distribution"
 		}
 
 		if len(lineTags) > 0 {
@@ -447,6 +616,9 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		}
 		sort.Strings(tg)
 		m.hash = fmt.Sprintf("%s%s", strings.Join(tg, ""), m.name)
+		if !m.timestamp.IsZero() {
+			m.hash = fmt.Sprintf("%s@%d", m.hash, m.timestamp.Unix())
+		}
 
 		s.aggregate(m)
 	}
@@ -454,6 +626,27 @@ func (s *Statsd) parseStatsdLine(line string) error {
 	return nil
 }
 
+// expandMultiValues gives the values packed by DogStatsD as name:1:2:3|d the
+// type and options of the value which follows them, e.g. 1|d, 2|d and 3|d.
+func expandMultiValues(bits []string) []string {
+	expanded := make([]string, 0, len(bits))
+	var pending []string
+	for _, bit := range bits {
+		_, options, ok := strings.Cut(bit, "|")
+		if !ok {
+			pending = append(pending, bit)
+			continue
+		}
+		for _, value := range pending {
+			expanded = append(expanded, value+"|"+options)
+		}
+		pending = pending[:0]
+		expanded = append(expanded, bit)
+	}
+	// Any value left without a type is invalid.
+	return append(expanded, pending...)
+}
+
 // parseName parses the given bucket name with the list of bucket maps in the
 // config file. If there is a match, it will parse the name of the metric and
 // map of tags.
@@ -511,6 +704,16 @@ func parseKeyValue(keyvalue string) (string, string) {
 	return key, val
 }
 
+// newDistribution creates the distribution the timing samples are added to. The
+// cloudwatch output picks the implementation, SEH1 is used until it does so that
+// the percentiles stay accurate.
+func newDistribution() distribution.Distribution {
+	if distribution.NewDistribution == nil {
+		return seh1.NewSEH1Distribution()
+	}
+	return distribution.NewDistribution()
+}
+
 // aggregate takes in a metric. It then
 // aggregates and caches the current value(s). It does not deal with the
 // Delete* options, because those are dealt with in the Gather function.
@@ -519,22 +722,22 @@ func (s *Statsd) aggregate(m metric) {
 	defer s.Unlock()
 
 	switch m.mtype {
-	case "ms", "h":
+	case "ms", "h", "d":
 		// Check if the measurement exists
 		cached, ok := s.timings[m.hash]
 		if !ok {
 			cached = cachedtimings{
-				name:   m.name,
-				fields: make(map[string]interface{}),
-				tags:   m.tags,
+				name:      m.name,
+				fields:    make(map[string]interface{}),
+				tags:      m.tags,
+				timestamp: m.timestamp,
 			}
 		}
 		// Check if the field exists. If we've not enabled multiple fields per timer
 		// this will be the default field name, eg. "value"
 		field, ok := cached.fields[m.field]
 		if !ok {
-			// Assume function pointer is valid.
-			field = distribution.NewDistribution()
+			field = newDistribution()
 		}
 		weight := 1.0
 		if m.samplerate > 0 {
@@ -551,9 +754,10 @@ func (s *Statsd) aggregate(m metric) {
 		_, ok := s.counters[m.hash]
 		if !ok {
 			s.counters[m.hash] = cachedcounter{
-				name:   m.name,
-				fields: make(map[string]interface{}),
-				tags:   m.tags,
+				name:      m.name,
+				fields:    make(map[string]interface{}),
+				tags:      m.tags,
+				timestamp: m.timestamp,
 			}
 		}
 		// check if the field exists
@@ -568,9 +772,10 @@ func (s *Statsd) aggregate(m metric) {
 		_, ok := s.gauges[m.hash]
 		if !ok {
 			s.gauges[m.hash] = cachedgauge{
-				name:   m.name,
-				fields: make(map[string]interface{}),
-				tags:   m.tags,
+				name:      m.name,
+				fields:    make(map[string]interface{}),
+				tags:      m.tags,
+				timestamp: m.timestamp,
 			}
 		}
 		// check if the field exists
@@ -589,9 +794,10 @@ func (s *Statsd) aggregate(m metric) {
 		_, ok := s.sets[m.hash]
 		if !ok {
 			s.sets[m.hash] = cachedset{
-				name:   m.name,
-				fields: make(map[string]map[string]bool),
-				tags:   m.tags,
+				name:      m.name,
+				fields:    make(map[string]map[string]bool),
+				tags:      m.tags,
+				timestamp: m.timestamp,
 			}
 		}
 		// check if the field exists
@@ -607,8 +813,23 @@ func (s *Statsd) Stop() {
 	log.Println("D! Stopping the statsd service")
 	close(s.done)
 	s.listener.Close()
+	if s.tcpListener != nil {
+		s.tcpListener.Close()
+		s.tcpConnsMu.Lock()
+		for conn := range s.tcpConns {
+			conn.Close()
+		}
+		s.tcpConnsMu.Unlock()
+	}
+	if s.socketListener != nil {
+		s.socketListener.Close()
+		os.Remove(s.ServiceSocketPath)
+	}
 	s.wg.Wait()
 	close(s.in)
+	if s.events != nil {
+		s.events.Stop()
+	}
 	log.Println("D! Stopped the statsd service")
 }
 
@@ -7,10 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/seh1"
@@ -293,6 +297,30 @@ func TestParse_Timings(t *testing.T) {
 	assert.Equal(t, dist, fields[defaultFieldName])
 }
 
+func TestParse_TimingsDistribution(t *testing.T) {
+	newDistributionFunc := distribution.NewDistribution
+	defer func() {
+		distribution.NewDistribution = newDistributionFunc
+	}()
+	// The timings use SEH1 until the output picks a distribution.
+	distribution.NewDistribution = nil
+	s := NewTestStatsd()
+	for _, line := range []string{"test.timing:10|ms|@0.25", "test.timing:20|ms", "test.timing:30|ms|@0.5"} {
+		assert.NoError(t, s.parseStatsdLine(line))
+	}
+
+	acc := &testutil.Accumulator{}
+	assert.NoError(t, s.Gather(acc))
+	require.Len(t, acc.Metrics, 1)
+	dist, ok := acc.Metrics[0].Fields[defaultFieldName].(*seh1.SEH1Distribution)
+	require.True(t, ok)
+	// The samples are weighted by their sample rate.
+	assert.Equal(t, 7.0, dist.SampleCount())
+	assert.Equal(t, 120.0, dist.Sum())
+	assert.Equal(t, 10.0, dist.Minimum())
+	assert.Equal(t, 30.0, dist.Maximum())
+}
+
 func TestParseScientificNotation(t *testing.T) {
 	s := NewTestStatsd()
 	sciNotationLines := []string{
@@ -1482,3 +1510,40 @@ func test_validate_gauge(
 func init() {
 	distribution.NewDistribution = seh1.NewSEH1Distribution
 }
+
+func TestListeners(t *testing.T) {
+	s := &Statsd{
+		ServiceAddress:         "127.0.0.1:0",
+		TCPServiceAddress:      "127.0.0.1:0",
+		ServiceSocketPath:      filepath.Join(t.TempDir(), "statsd.sock"),
+		MetricSeparator:        "_",
+		AllowedPendingMessages: defaultAllowPendingMessage,
+	}
+	require.NoError(t, s.Start(nil))
+	// Starting again, e.g. as a log collection, is a no-op.
+	require.NoError(t, s.Start(nil))
+	defer s.Stop()
+
+	tcp, err := net.Dial("tcp", s.tcpListener.Addr().String())
+	require.NoError(t, err)
+	defer tcp.Close()
+	_, err = tcp.Write([]byte("tcp.first:1|c\ntcp.second:2|g\n"))
+	require.NoError(t, err)
+
+	socket, err := net.Dial("unixgram", s.ServiceSocketPath)
+	require.NoError(t, err)
+	defer socket.Close()
+	_, err = socket.Write([]byte("socket.gauge:3|g"))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		s.Lock()
+		defer s.Unlock()
+		return len(s.counters) == 1 && len(s.gauges) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Lock()
+	defer s.Unlock()
+	assert.NoError(t, test_validate_counter("tcp_first", 1, s.counters))
+	assert.NoError(t, test_validate_gauge("tcp_second", 2, s.gauges))
+	assert.NoError(t, test_validate_gauge("socket_gauge", 3, s.gauges))
+}
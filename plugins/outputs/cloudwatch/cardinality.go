@@ -0,0 +1,214 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"hash/fnv"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
+)
+
+const (
+	CardinalityActionDrop   = "drop"
+	CardinalityActionRollup = "rollup"
+	CardinalityActionStrip  = "strip"
+
+	// cardinalityRollupValue replaces the value of the offending dimension on rollup.
+	cardinalityRollupValue             = "Other"
+	defaultCardinalityRotationInterval = time.Hour
+)
+
+// seriesSet holds the hashes of the series seen in the current and previous
+// rotation intervals, so the series that are still reported keep their slot.
+type seriesSet struct {
+	current  map[uint64]struct{}
+	previous map[uint64]struct{}
+}
+
+func newSeriesSet() seriesSet {
+	return seriesSet{current: map[uint64]struct{}{}, previous: map[uint64]struct{}{}}
+}
+
+// admit adds the series if it is already known or if there is room for it.
+func (s *seriesSet) admit(key uint64, limit int) bool {
+	if _, ok := s.current[key]; ok {
+		return true
+	}
+	_, known := s.previous[key]
+	if !known && len(s.current) >= limit {
+		return false
+	}
+	s.current[key] = struct{}{}
+	return true
+}
+
+func (s *seriesSet) rotate() {
+	s.previous = s.current
+	s.current = map[uint64]struct{}{}
+}
+
+type metricSeries struct {
+	series seriesSet
+	// overflow holds the series rewritten by the rollup and strip actions, which get
+	// a budget of their own so the rewritten series can not crowd out the others.
+	overflow seriesSet
+	// values holds the distinct values of each dimension, capped at the limit, to
+	// find the dimension responsible for the cardinality.
+	values map[string]map[string]struct{}
+	// exceeded holds the dimensions which had more distinct values than the limit.
+	exceeded map[string]bool
+	logged   bool
+}
+
+// seriesLimiter caps the number of distinct dimension sets published for each
+// metric name within a namespace. The metrics that exceed the limit are dropped,
+// or have their highest cardinality dimension rolled up or stripped.
+type seriesLimiter struct {
+	maxSeries        int
+	action           string
+	rotationInterval time.Duration
+
+	mu           sync.Mutex
+	metrics      map[string]*metricSeries
+	lastRotation time.Time
+}
+
+func newSeriesLimiter(config *CardinalityLimitConfig) *seriesLimiter {
+	rotationInterval := config.RotationInterval
+	if rotationInterval <= 0 {
+		rotationInterval = defaultCardinalityRotationInterval
+	}
+	action := config.Action
+	if action == "" {
+		action = CardinalityActionRollup
+	}
+	return &seriesLimiter{
+		maxSeries:        config.MaxSeriesPerMetric,
+		action:           action,
+		rotationInterval: rotationInterval,
+		metrics:          map[string]*metricSeries{},
+		lastRotation:     time.Now(),
+	}
+}
+
+// limit returns the dimensions to publish the metric with, or false if the
+// metric should be dropped.
+func (l *seriesLimiter) limit(namespace, metricName string, dimensions []*cloudwatch.Dimension) ([]*cloudwatch.Dimension, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if time.Since(l.lastRotation) >= l.rotationInterval {
+		l.rotate()
+	}
+	m, ok := l.metrics[namespace+"\x00"+metricName]
+	if !ok {
+		m = &metricSeries{series: newSeriesSet(), overflow: newSeriesSet(), values: map[string]map[string]struct{}{}, exceeded: map[string]bool{}}
+		l.metrics[namespace+"\x00"+metricName] = m
+	}
+	for _, d := range dimensions {
+		values, ok := m.values[*d.Name]
+		if !ok {
+			values = map[string]struct{}{}
+			m.values[*d.Name] = values
+		}
+		if _, ok := values[*d.Value]; ok {
+			continue
+		}
+		if len(values) < l.maxSeries {
+			values[*d.Value] = struct{}{}
+		} else {
+			m.exceeded[*d.Name] = true
+		}
+	}
+	// Without dimensions there is a single series, which is always published.
+	if len(dimensions) == 0 || m.series.admit(seriesKey(dimensions), l.maxSeries) {
+		return dimensions, true
+	}
+
+	agent.UsageCounters().Add(agent.CounterMetricSeriesLimited, 1)
+	offending := m.offendingDimensions(dimensions)
+	if !m.logged {
+		m.logged = true
+		log.Printf("W! cloudwatch: metric %s in namespace %s exceeded %d series, applying %s to dimension %s",
+			metricName, namespace, l.maxSeries, l.action, offending[0])
+	}
+	if l.action == CardinalityActionDrop {
+		return nil, false
+	}
+	// Rewrite the dimensions from the highest cardinality down until the series
+	// fits, ending with all of them rewritten which is a single series.
+	rewritten := dimensions
+	for _, name := range offending {
+		rewritten = l.rewrite(rewritten, name)
+		if m.overflow.admit(seriesKey(rewritten), l.maxSeries) {
+			break
+		}
+	}
+	return rewritten, true
+}
+
+// offendingDimensions returns the names of the dimensions ordered by their
+// number of distinct values, highest first, with the ones over the limit ahead.
+func (m *metricSeries) offendingDimensions(dimensions []*cloudwatch.Dimension) []string {
+	names := make([]string, len(dimensions))
+	for i, d := range dimensions {
+		names[i] = *d.Name
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		if m.exceeded[names[i]] != m.exceeded[names[j]] {
+			return m.exceeded[names[i]]
+		}
+		return len(m.values[names[i]]) > len(m.values[names[j]])
+	})
+	return names
+}
+
+func (l *seriesLimiter) rewrite(dimensions []*cloudwatch.Dimension, name string) []*cloudwatch.Dimension {
+	rewritten := make([]*cloudwatch.Dimension, 0, len(dimensions))
+	for _, d := range dimensions {
+		if *d.Name != name {
+			rewritten = append(rewritten, d)
+		} else if l.action == CardinalityActionRollup {
+			rewritten = append(rewritten, &cloudwatch.Dimension{Name: d.Name, Value: aws.String(cardinalityRollupValue)})
+		}
+	}
+	return rewritten
+}
+
+// rotate starts a new interval. Callers must hold the lock.
+func (l *seriesLimiter) rotate() {
+	l.lastRotation = time.Now()
+	for key, m := range l.metrics {
+		if len(m.series.current) == 0 && len(m.overflow.current) == 0 {
+			delete(l.metrics, key)
+			continue
+		}
+		m.series.rotate()
+		m.overflow.rotate()
+		m.values = map[string]map[string]struct{}{}
+		m.exceeded = map[string]bool{}
+		m.logged = false
+	}
+}
+
+// seriesKey hashes the dimensions regardless of their order.
+func seriesKey(dimensions []*cloudwatch.Dimension) uint64 {
+	pairs := make([]string, len(dimensions))
+	for i, d := range dimensions {
+		pairs[i] = *d.Name + "\x00" + *d.Value
+	}
+	sort.Strings(pairs)
+	h := fnv.New64a()
+	for _, p := range pairs {
+		h.Write([]byte(p))
+		h.Write([]byte{0xff})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,139 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
+)
+
+func newDimensions(pairs ...string) []*cloudwatch.Dimension {
+	var dimensions []*cloudwatch.Dimension
+	for i := 0; i+1 < len(pairs); i += 2 {
+		dimensions = append(dimensions, &cloudwatch.Dimension{Name: aws.String(pairs[i]), Value: aws.String(pairs[i+1])})
+	}
+	return dimensions
+}
+
+func dimensionMap(dimensions []*cloudwatch.Dimension) map[string]string {
+	m := map[string]string{}
+	for _, d := range dimensions {
+		m[*d.Name] = *d.Value
+	}
+	return m
+}
+
+func TestSeriesLimiter(t *testing.T) {
+	testCases := map[string]struct {
+		action string
+		want   map[string]string
+	}{
+		"WithDrop": {
+			action: CardinalityActionDrop,
+		},
+		"WithRollup": {
+			action: CardinalityActionRollup,
+			want:   map[string]string{"host": "a", "request_id": "Other"},
+		},
+		"WithStrip": {
+			action: CardinalityActionStrip,
+			want:   map[string]string{"host": "a"},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			l := newSeriesLimiter(&CardinalityLimitConfig{MaxSeriesPerMetric: 3, Action: testCase.action})
+			for i := 0; i < 3; i++ {
+				dimensions := newDimensions("host", "a", "request_id", fmt.Sprint(i))
+				got, ok := l.limit("namespace", "latency", dimensions)
+				assert.True(t, ok)
+				assert.Equal(t, dimensions, got)
+			}
+			before := agent.UsageCounters().Get(agent.CounterMetricSeriesLimited)
+			got, ok := l.limit("namespace", "latency", newDimensions("host", "a", "request_id", "3"))
+			assert.Equal(t, before+1, agent.UsageCounters().Get(agent.CounterMetricSeriesLimited))
+			if testCase.want == nil {
+				assert.False(t, ok)
+			} else {
+				assert.True(t, ok)
+				assert.Equal(t, testCase.want, dimensionMap(got))
+			}
+			// The series already admitted are still published.
+			dimensions := newDimensions("host", "a", "request_id", "0")
+			got, ok = l.limit("namespace", "latency", dimensions)
+			assert.True(t, ok)
+			assert.Equal(t, dimensions, got)
+			// The limit is per metric name and namespace.
+			dimensions = newDimensions("host", "a", "request_id", "3")
+			got, ok = l.limit("namespace", "errors", dimensions)
+			assert.True(t, ok)
+			assert.Equal(t, dimensions, got)
+			got, ok = l.limit("other", "latency", dimensions)
+			assert.True(t, ok)
+			assert.Equal(t, dimensions, got)
+		})
+	}
+}
+
+func TestSeriesLimiterOverflow(t *testing.T) {
+	l := newSeriesLimiter(&CardinalityLimitConfig{MaxSeriesPerMetric: 2})
+	for i := 0; i < 10; i++ {
+		_, ok := l.limit("namespace", "latency", newDimensions("host", fmt.Sprint(i%5), "request_id", fmt.Sprint(i)))
+		require.True(t, ok)
+	}
+	// Once the rolled up series are over the limit too, the next dimension is rolled up.
+	got, ok := l.limit("namespace", "latency", newDimensions("host", "5", "request_id", "10"))
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"host": "Other", "request_id": "Other"}, dimensionMap(got))
+}
+
+func TestSeriesLimiterValuesBoundary(t *testing.T) {
+	l := newSeriesLimiter(&CardinalityLimitConfig{MaxSeriesPerMetric: 3, Action: CardinalityActionDrop})
+	for i := 0; i < 3; i++ {
+		_, ok := l.limit("namespace", "latency", newDimensions("host", "a", "request_id", fmt.Sprint(i)))
+		require.True(t, ok)
+	}
+	m := l.metrics["namespace\x00latency"]
+	assert.Len(t, m.values["request_id"], 3)
+	// The series over the limit is dropped and its values are not kept.
+	_, ok := l.limit("namespace", "latency", newDimensions("host", "a", "request_id", "3"))
+	assert.False(t, ok)
+	assert.Len(t, m.values["request_id"], 3)
+	assert.Len(t, m.values["host"], 1)
+	assert.Equal(t, []string{"request_id", "host"}, m.offendingDimensions(newDimensions("host", "a", "request_id", "3")))
+}
+
+func TestSeriesLimiterRotation(t *testing.T) {
+	l := newSeriesLimiter(&CardinalityLimitConfig{MaxSeriesPerMetric: 1, Action: CardinalityActionDrop})
+	_, ok := l.limit("namespace", "latency", newDimensions("request_id", "0"))
+	assert.True(t, ok)
+	_, ok = l.limit("namespace", "latency", newDimensions("request_id", "1"))
+	assert.False(t, ok)
+
+	l.rotate()
+	// The series reported in the previous interval keeps its slot.
+	_, ok = l.limit("namespace", "latency", newDimensions("request_id", "0"))
+	assert.True(t, ok)
+	_, ok = l.limit("namespace", "latency", newDimensions("request_id", "1"))
+	assert.False(t, ok)
+
+	l.rotate()
+	l.rotate()
+	assert.Empty(t, l.metrics)
+	_, ok = l.limit("namespace", "latency", newDimensions("request_id", "1"))
+	assert.True(t, ok)
+}
+
+func TestSeriesKey(t *testing.T) {
+	assert.Equal(t, seriesKey(newDimensions("a", "1", "b", "2")), seriesKey(newDimensions("b", "2", "a", "1")))
+	assert.NotEqual(t, seriesKey(newDimensions("a", "1", "b", "2")), seriesKey(newDimensions("a", "2", "b", "1")))
+	assert.NotEqual(t, seriesKey(newDimensions("a", "1")), seriesKey(newDimensions("a", "1", "b", "2")))
+}
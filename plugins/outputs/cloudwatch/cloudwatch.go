@@ -27,6 +27,7 @@ import (
 	"golang.org/x/exp/maps"
 
 	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
 	"github.com/aws/amazon-cloudwatch-agent/handlers"
 	"github.com/aws/amazon-cloudwatch-agent/internal/publisher"
 	"github.com/aws/amazon-cloudwatch-agent/internal/retryer"
@@ -43,6 +44,7 @@ const (
 	maxConcurrentPublisher                = 10 // the number of CloudWatch clients send request concurrently
 	defaultForceFlushInterval             = time.Minute
 	highResolutionTagKey                  = "aws:StorageResolution"
+	unitTagKey                            = "aws:Unit"
 	defaultRetryCount                     = 5 // this is the retry count, the total attempts would be retry count + 1 at most.
 	backoffRetryBase                      = 200 * time.Millisecond
 	MaxDimensions                         = 30
@@ -69,9 +71,13 @@ type CloudWatch struct {
 	retryer                *retryer.LogThrottleRetryer
 	droppingOriginMetrics  collections.Set[string]
 	aggregator             Aggregator
+	deltas                 *deltaCalculator
 	aggregatorShutdownChan chan struct{}
 	aggregatorWaitGroup    sync.WaitGroup
 	lastRequestBytes       int
+	spill                  *spillQueue
+	seriesLimiter          *seriesLimiter
+	declarations           metricDeclarations
 }
 
 // Compile time interface check.
@@ -82,8 +88,23 @@ func (c *CloudWatch) Capabilities() consumer.Capabilities {
 }
 
 func (c *CloudWatch) Start(_ context.Context, host component.Host) error {
+	if c.config.SpillDirectory != "" {
+		var err error
+		if c.spill, err = newSpillQueue(c.config.SpillDirectory, c.config.SpillMaxSize); err != nil {
+			log.Printf("E! cloudwatch: Unable to create spill queue, undelivered metrics will not be persisted: %v", err)
+		}
+	}
+	if c.config.CardinalityLimit != nil {
+		c.seriesLimiter = newSeriesLimiter(c.config.CardinalityLimit)
+	}
+	if len(c.config.MetricDeclarations) > 0 {
+		var err error
+		if c.declarations, err = newMetricDeclarations(c.config.MetricDeclarations); err != nil {
+			return err
+		}
+	}
 	c.publisher, _ = publisher.NewPublisher(
-		publisher.NewNonBlockingFifoQueue(metricChanBufferSize),
+		publisher.NewNonBlockingFifoQueueWithDropFn(metricChanBufferSize, c.dropFromQueue),
 		maxConcurrentPublisher,
 		2*time.Second,
 		c.WriteToCloudWatch)
@@ -95,6 +116,12 @@ func (c *CloudWatch) Start(_ context.Context, host component.Host) error {
 		Profile:   c.config.Profile,
 		Filename:  c.config.SharedCredentialFilename,
 		Token:     c.config.Token,
+
+		CredentialProcess:    c.config.CredentialProcess,
+		WebIdentityTokenFile: c.config.WebIdentityTokenFile,
+		WebIdentityRoleARN:   c.config.WebIdentityRoleARN,
+		ExternalID:           c.config.ExternalID,
+		SessionTags:          c.config.SessionTags,
 	}
 	configProvider := credentialConfig.Credentials()
 	logger := models.NewLogger("outputs", "cloudwatch", "")
@@ -126,10 +153,14 @@ func (c *CloudWatch) startRoutines() {
 	c.shutdownChan = make(chan struct{})
 	c.aggregatorShutdownChan = make(chan struct{})
 	c.aggregator = NewAggregator(c.metricChan, c.aggregatorShutdownChan, &c.aggregatorWaitGroup)
+	c.deltas = newDeltaCalculator()
 	perRequestConstSize := overallConstPerRequestSize + len(c.config.Namespace) + namespaceOverheads
 	c.metricDatumBatch = newMetricDatumBatch(c.config.MaxDatumsPerCall, perRequestConstSize)
 	go c.pushMetricDatum()
 	go c.publish()
+	if c.spill != nil {
+		go c.replaySpilledLoop()
+	}
 }
 
 func (c *CloudWatch) Shutdown(ctx context.Context) error {
@@ -156,7 +187,7 @@ func (c *CloudWatch) Shutdown(ctx context.Context) error {
 // The actual publishing will occur in a long running goroutine.
 // This method can block when publishing is backed up.
 func (c *CloudWatch) ConsumeMetrics(ctx context.Context, metrics pmetric.Metrics) error {
-	datums := ConvertOtelMetrics(metrics)
+	datums := ConvertOtelMetrics(metrics, c.deltas)
 	for _, d := range datums {
 		c.aggregator.AddMetric(d)
 	}
@@ -369,6 +400,128 @@ func (c *CloudWatch) WriteToCloudWatch(req interface{}) {
 	}
 	if err != nil {
 		log.Println("E! cloudwatch: WriteToCloudWatch failure, err: ", err)
+		if isRejected(err) {
+			agent.UsageCounters().Add(agent.CounterMetricDatumsDropped, int64(len(datums)))
+			return
+		}
+		c.spillRequest(params)
+	}
+}
+
+// isRejected returns true if PutMetricData can never accept the request, so there is no point retrying it later.
+func isRejected(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch awsErr.Code() {
+		case cloudwatch.ErrCodeInvalidParameterValueException,
+			cloudwatch.ErrCodeInvalidParameterCombinationException,
+			cloudwatch.ErrCodeMissingRequiredParameterException:
+			return true
+		}
+	}
+	return false
+}
+
+// dropFromQueue is called with the requests the publisher queue drops when it is full.
+func (c *CloudWatch) dropFromQueue(req interface{}) {
+	datums := req.([]*cloudwatch.MetricDatum)
+	c.spillRequest(&cloudwatch.PutMetricDataInput{
+		MetricData: datums,
+		Namespace:  aws.String(c.config.Namespace),
+	})
+}
+
+// spillRequest persists the request to be replayed once PutMetricData recovers,
+// or drops it when the spill queue is not enabled.
+func (c *CloudWatch) spillRequest(params *cloudwatch.PutMetricDataInput) {
+	if c.spill == nil {
+		agent.UsageCounters().Add(agent.CounterMetricDatumsDropped, int64(len(params.MetricData)))
+		return
+	}
+	evicted, err := c.spill.push(params)
+	if evicted > 0 {
+		log.Printf("W! cloudwatch: Spill queue is full, dropped %v of the oldest spilled metric datums.", evicted)
+		agent.UsageCounters().Add(agent.CounterMetricDatumsDropped, int64(evicted))
+	}
+	if err != nil {
+		log.Printf("E! cloudwatch: Unable to spill %v metric datums to disk, request dropped: %v", len(params.MetricData), err)
+		agent.UsageCounters().Add(agent.CounterMetricDatumsDropped, int64(len(params.MetricData)))
+		return
+	}
+	log.Printf("D! cloudwatch: Spilled %v metric datums to disk, %v requests waiting.", len(params.MetricData), c.spill.Len())
+}
+
+// replaySpilledLoop periodically replays the spilled requests until a shutdown occurs.
+func (c *CloudWatch) replaySpilledLoop() {
+	if n := c.spill.Len(); n > 0 {
+		log.Printf("I! cloudwatch: Found %v spilled requests from a previous run.", n)
+	}
+	ticker := time.NewTicker(spillReplayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.replaySpilled()
+		case <-c.shutdownChan:
+			return
+		}
+	}
+}
+
+// replaySpilled sends the spilled requests, oldest first, until the queue is
+// empty or PutMetricData fails again.
+func (c *CloudWatch) replaySpilled() {
+	for {
+		select {
+		case <-c.shutdownChan:
+			return
+		default:
+		}
+
+		id, params, ok, err := c.spill.next()
+		if !ok {
+			return
+		}
+		if err != nil {
+			log.Printf("E! cloudwatch: Unable to read spilled request, request dropped: %v", err)
+			c.removeSpilled(id, true)
+			continue
+		}
+
+		datums := dropExpiredDatums(params.MetricData, time.Now())
+		if expired := len(params.MetricData) - len(datums); expired > 0 {
+			log.Printf("W! cloudwatch: Dropped %v spilled metric datums out of the accepted time range.", expired)
+			agent.UsageCounters().Add(agent.CounterMetricDatumsDropped, int64(expired))
+		}
+		if len(datums) == 0 {
+			c.removeSpilled(id, false)
+			continue
+		}
+		params.MetricData = datums
+
+		_, err = c.svc.PutMetricData(params)
+		if err != nil && !isRejected(err) {
+			log.Printf("W! cloudwatch: Unable to replay spilled request, %v requests waiting: %v", c.spill.Len(), err)
+			return
+		}
+		if err != nil {
+			log.Printf("E! cloudwatch: Spilled request rejected, request dropped: %v", err)
+			agent.UsageCounters().Add(agent.CounterMetricDatumsDropped, int64(len(datums)))
+		} else {
+			log.Printf("D! cloudwatch: Replayed %v spilled metric datums.", len(datums))
+			agent.UsageCounters().Add(agent.CounterMetricDatumsReplayed, int64(len(datums)))
+		}
+		c.removeSpilled(id, false)
+	}
+}
+
+// removeSpilled removes the request from the spill queue, counting its datums as dropped if asked to.
+func (c *CloudWatch) removeSpilled(id uint64, dropped bool) {
+	datums, err := c.spill.remove(id)
+	if err != nil {
+		log.Printf("E! cloudwatch: Unable to remove spilled request: %v", err)
+	}
+	if dropped {
+		agent.UsageCounters().Add(agent.CounterMetricDatumsDropped, int64(datums))
 	}
 }
 
@@ -388,20 +541,46 @@ func (c *CloudWatch) BuildMetricDatum(metric *aggregationDatum) []*cloudwatch.Me
 			metric.SetUnit(metric.distribution.Unit())
 		}
 		distList = resize(metric.distribution, c.config.MaxValuesPerDatum)
+	} else if !distribution.IsSupportedValue(*metric.Value, distribution.MinValue, distribution.MaxValue) {
+		log.Printf("E! metric (%s) has an unsupported value: %v, dropping it", *metric.MetricName, *metric.Value)
+		return datums
 	}
 
-	dimensionsList := c.ProcessRollup(metric.Dimensions)
+	if c.seriesLimiter != nil {
+		dimensions, ok := c.seriesLimiter.limit(c.config.Namespace, *metric.MetricName, metric.Dimensions)
+		if !ok {
+			return datums
+		}
+		metric.Dimensions = dimensions
+	}
+
+	derived := c.config.DerivedMetrics[*metric.MetricName]
+	var stats derivedStats
+	if derived != nil {
+		stats = newDerivedStats(metric)
+	}
+	var dimensionsList [][]*cloudwatch.Dimension
+	if c.declarations != nil {
+		// The declared dimension sets replace the original dimensions and their rollup.
+		if dimensionsList = c.declarations.dimensions(*metric.MetricName, metric.Dimensions); len(dimensionsList) == 0 {
+			return datums
+		}
+	} else {
+		dimensionsList = c.ProcessRollup(metric.Dimensions)
+	}
 	for index, dimensions := range dimensionsList {
 		//index == 0 means it's the original metrics, and if the metric name and dimension matches, skip creating
 		//metric datum
-		if index == 0 && c.IsDropping(*metric.MetricDatum.MetricName) {
+		if index == 0 && c.declarations == nil && c.IsDropping(*metric.MetricDatum.MetricName) {
 			continue
 		}
-		if len(distList) == 0 {
-			if !distribution.IsSupportedValue(*metric.Value, distribution.MinValue, distribution.MaxValue) {
-				log.Printf("E! metric (%s) has an unsupported value: %v, dropping it", *metric.MetricName, *metric.Value)
+		if derived != nil {
+			datums = append(datums, buildDerivedDatums(metric, derived, stats, dimensions)...)
+			if derived.DropOriginal {
 				continue
 			}
+		}
+		if len(distList) == 0 {
 			// Not a distribution.
 			datum := &cloudwatch.MetricDatum{
 				MetricName:        metric.MetricName,
@@ -17,6 +17,7 @@ import (
 	"github.com/amazon-contributing/opentelemetry-collector-contrib/extension/awsmiddleware"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/influxdata/telegraf"
@@ -27,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.uber.org/zap"
 
+	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/stats/agent"
 	"github.com/aws/amazon-cloudwatch-agent/internal/publisher"
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 )
@@ -213,6 +215,33 @@ func TestBuildMetricDatumDropUnsupported(t *testing.T) {
 	}
 }
 
+func TestBuildMetricDatumCardinalityLimit(t *testing.T) {
+	cw := &CloudWatch{
+		config: &Config{
+			Namespace:        "namespace",
+			RollupDimensions: [][]string{{"host"}},
+		},
+		seriesLimiter: newSeriesLimiter(&CardinalityLimitConfig{MaxSeriesPerMetric: 1, Action: CardinalityActionStrip}),
+	}
+	build := func(requestID string) []*cloudwatch.MetricDatum {
+		return cw.BuildMetricDatum(&aggregationDatum{
+			MetricDatum: cloudwatch.MetricDatum{
+				MetricName: aws.String("latency"),
+				Dimensions: BuildDimensions(map[string]string{"host": "a", "request_id": requestID}),
+				Value:      aws.Float64(1),
+			},
+		})
+	}
+	got := build("0")
+	require.Len(t, got, 2)
+	assert.Len(t, got[0].Dimensions, 2)
+	assert.Len(t, got[1].Dimensions, 1)
+	// The rollup is skipped since it matches the stripped dimensions.
+	got = build("1")
+	require.Len(t, got, 1)
+	assert.Equal(t, map[string]string{"host": "a"}, dimensionMap(got[0].Dimensions))
+}
+
 func TestGetUniqueRollupList(t *testing.T) {
 	testCases := map[string]struct {
 		input [][]string
@@ -463,6 +492,89 @@ func TestWriteError(t *testing.T) {
 	cw.Shutdown(ctx)
 }
 
+func TestWriteErrorSpill(t *testing.T) {
+	svc := new(mockCloudWatchClient)
+	res := cloudwatch.PutMetricDataOutput{}
+	requestErr := awserr.New(request.ErrCodeRequestError, "send request failed", nil)
+	svc.On("PutMetricData", mock.Anything).Return(&res, requestErr).Once()
+	cw := newCloudWatchClient(svc, time.Second)
+	defer close(cw.shutdownChan)
+	cw.config.Namespace = "namespace"
+	var err error
+	cw.spill, err = newSpillQueue(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	now := time.Now()
+	datums := newSpilledInput("namespace", now.Add(-time.Minute), now).MetricData
+	dropped := agent.UsageCounters().Get(agent.CounterMetricDatumsDropped)
+	replayed := agent.UsageCounters().Get(agent.CounterMetricDatumsReplayed)
+	cw.WriteToCloudWatch(datums)
+	assert.Equal(t, 1, cw.spill.Len())
+	assert.Equal(t, dropped, agent.UsageCounters().Get(agent.CounterMetricDatumsDropped))
+
+	// Still failing, so the request stays spilled.
+	svc.On("PutMetricData", mock.Anything).Return(&res, requestErr).Once()
+	cw.replaySpilled()
+	assert.Equal(t, 1, cw.spill.Len())
+
+	svc.On("PutMetricData", mock.Anything).Return(&res, nil).Once()
+	cw.replaySpilled()
+	assert.Equal(t, 0, cw.spill.Len())
+	assert.Equal(t, replayed+2, agent.UsageCounters().Get(agent.CounterMetricDatumsReplayed))
+	assert.Equal(t, dropped, agent.UsageCounters().Get(agent.CounterMetricDatumsDropped))
+	svc.AssertNumberOfCalls(t, "PutMetricData", 3)
+	replayedInput := svc.Calls[2].Arguments.Get(0).(*cloudwatch.PutMetricDataInput)
+	assert.Equal(t, "namespace", *replayedInput.Namespace)
+	assert.Len(t, replayedInput.MetricData, 2)
+
+	// The expired datums are dropped without being sent.
+	_, err = cw.spill.push(newSpilledInput("namespace", now.Add(-15*24*time.Hour)))
+	require.NoError(t, err)
+	cw.replaySpilled()
+	assert.Equal(t, 0, cw.spill.Len())
+	assert.Equal(t, dropped+1, agent.UsageCounters().Get(agent.CounterMetricDatumsDropped))
+	svc.AssertNumberOfCalls(t, "PutMetricData", 3)
+}
+
+func TestWriteErrorRejected(t *testing.T) {
+	svc := new(mockCloudWatchClient)
+	res := cloudwatch.PutMetricDataOutput{}
+	svc.On("PutMetricData", mock.Anything).Return(&res, awserr.New(cloudwatch.ErrCodeInvalidParameterValueException, "", nil))
+	cw := newCloudWatchClient(svc, time.Second)
+	defer close(cw.shutdownChan)
+	var err error
+	cw.spill, err = newSpillQueue(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	dropped := agent.UsageCounters().Get(agent.CounterMetricDatumsDropped)
+	cw.WriteToCloudWatch(newSpilledInput("namespace", time.Now()).MetricData)
+	assert.Equal(t, 0, cw.spill.Len())
+	assert.Equal(t, dropped+1, agent.UsageCounters().Get(agent.CounterMetricDatumsDropped))
+}
+
+func TestDropFromQueueSpill(t *testing.T) {
+	svc := new(mockCloudWatchClient)
+	cw := newCloudWatchClient(svc, time.Second)
+	defer close(cw.shutdownChan)
+	cw.config.Namespace = "namespace"
+
+	dropped := agent.UsageCounters().Get(agent.CounterMetricDatumsDropped)
+	queue := publisher.NewNonBlockingFifoQueueWithDropFn(1, cw.dropFromQueue)
+	queue.Enqueue(newSpilledInput("namespace", time.Now()).MetricData)
+	queue.Enqueue(newSpilledInput("namespace", time.Now()).MetricData)
+	assert.Equal(t, dropped+1, agent.UsageCounters().Get(agent.CounterMetricDatumsDropped))
+
+	var err error
+	cw.spill, err = newSpillQueue(t.TempDir(), 0)
+	require.NoError(t, err)
+	queue.Enqueue(newSpilledInput("namespace", time.Now()).MetricData)
+	assert.Equal(t, 1, cw.spill.Len())
+	_, params, _, err := cw.spill.next()
+	require.NoError(t, err)
+	assert.Equal(t, "namespace", *params.Namespace)
+	assert.Equal(t, dropped+1, agent.UsageCounters().Get(agent.CounterMetricDatumsDropped))
+}
+
 // TestPublish verifies metric batches do not get pushed immediately when
 // batch-buffer is full.
 func TestPublish(t *testing.T) {
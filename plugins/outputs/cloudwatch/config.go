@@ -5,6 +5,7 @@ package cloudwatch
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/resourcetotelemetry"
@@ -28,6 +29,30 @@ type Config struct {
 	DropOriginalConfigs      map[string]bool `mapstructure:"drop_original_metrics,omitempty"`
 	Namespace                string          `mapstructure:"namespace"`
 
+	// Additional credential sources and options of the assumed role, see configaws.CredentialConfig.
+	CredentialProcess    string            `mapstructure:"credential_process,omitempty"`
+	WebIdentityTokenFile string            `mapstructure:"web_identity_token_file,omitempty"`
+	WebIdentityRoleARN   string            `mapstructure:"web_identity_role_arn,omitempty"`
+	ExternalID           string            `mapstructure:"external_id,omitempty"`
+	SessionTags          map[string]string `mapstructure:"session_tags,omitempty"`
+
+	// SpillDirectory is the folder where the requests that could not be delivered
+	// are persisted to be replayed later, disabled if empty.
+	SpillDirectory string `mapstructure:"spill_directory,omitempty"`
+	// SpillMaxSize is the max size in bytes of the persisted requests.
+	SpillMaxSize int64 `mapstructure:"spill_max_size,omitempty"`
+
+	// CardinalityLimit caps the number of distinct dimension sets per metric name, disabled if nil.
+	CardinalityLimit *CardinalityLimitConfig `mapstructure:"cardinality_limit,omitempty"`
+
+	// DerivedMetrics configures the series published from the aggregated values of a
+	// metric, keyed by the metric name.
+	DerivedMetrics map[string]*DerivedMetricConfig `mapstructure:"derived_metrics,omitempty"`
+
+	// MetricDeclarations select the metrics to publish and their dimensions instead of the
+	// rollup dimensions, all the metrics are published if empty.
+	MetricDeclarations []*MetricDeclaration `mapstructure:"metric_declarations,omitempty"`
+
 	// ResourceToTelemetrySettings is the option for converting resource
 	// attributes to telemetry attributes.
 	// "Enabled" - A boolean field to enable/disable this option. Default is `false`.
@@ -37,6 +62,31 @@ type Config struct {
 	MiddlewareID *component.ID `mapstructure:"middleware,omitempty"`
 }
 
+// CardinalityLimitConfig configures the series limiter.
+type CardinalityLimitConfig struct {
+	// MaxSeriesPerMetric is the number of distinct dimension sets allowed for each metric name.
+	MaxSeriesPerMetric int `mapstructure:"max_series_per_metric"`
+	// Action is applied to the metrics over the limit. It is one of drop, rollup or strip.
+	// Defaults to rollup, which replaces the value of the offending dimension with "Other".
+	Action string `mapstructure:"action,omitempty"`
+	// RotationInterval is how long a series keeps its slot without being reported.
+	RotationInterval time.Duration `mapstructure:"rotation_interval,omitempty"`
+}
+
+// DerivedMetricConfig configures the series derived from a metric. Each is published
+// as a plain value named after the metric with a suffix.
+type DerivedMetricConfig struct {
+	// Percentiles are published with a _p suffix, e.g. latency_p99.
+	Percentiles []float64 `mapstructure:"percentiles,omitempty"`
+	// Statistics are any of sum, min, max, avg and count, published with the name of
+	// the statistic as a suffix, e.g. latency_max.
+	Statistics []string `mapstructure:"statistics,omitempty"`
+	// Rate publishes the sum per second over the aggregation interval with a _rate suffix.
+	Rate bool `mapstructure:"rate,omitempty"`
+	// DropOriginal publishes the derived series instead of the metric.
+	DropOriginal bool `mapstructure:"drop_original,omitempty"`
+}
+
 var _ component.Config = (*Config)(nil)
 
 // Validate checks if the exporter configuration is valid.
@@ -50,5 +100,33 @@ func (c *Config) Validate() error {
 	if c.ForceFlushInterval < time.Millisecond {
 		return errors.New("'force_flush_interval' must be at least 1 millisecond")
 	}
+	if c.CardinalityLimit != nil {
+		if c.CardinalityLimit.MaxSeriesPerMetric < 1 {
+			return errors.New("'cardinality_limit::max_series_per_metric' must be at least 1")
+		}
+		switch c.CardinalityLimit.Action {
+		case "", CardinalityActionDrop, CardinalityActionRollup, CardinalityActionStrip:
+		default:
+			return fmt.Errorf("'cardinality_limit::action' must be one of %s, %s or %s", CardinalityActionDrop, CardinalityActionRollup, CardinalityActionStrip)
+		}
+	}
+	for name, derived := range c.DerivedMetrics {
+		if derived == nil {
+			continue
+		}
+		for _, p := range derived.Percentiles {
+			if p < 0 || p > 100 {
+				return fmt.Errorf("'derived_metrics::%s::percentiles' must be between 0 and 100", name)
+			}
+		}
+		for _, statistic := range derived.Statistics {
+			if _, ok := derivedStatistics[statistic]; !ok {
+				return fmt.Errorf("'derived_metrics::%s::statistics' has an unsupported statistic %q", name, statistic)
+			}
+		}
+	}
+	if _, err := newMetricDeclarations(c.MetricDeclarations); err != nil {
+		return err
+	}
 	return nil
 }
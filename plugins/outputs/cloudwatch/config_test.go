@@ -111,3 +111,86 @@ func TestConfigDropOriginConfigs(t *testing.T) {
 	assert.True(t, drop["cpu_usage"])
 	assert.True(t, drop["foo_bar"])
 }
+
+func TestConfigCardinalityLimit(t *testing.T) {
+	factories, err := otelcoltest.NopFactories()
+	assert.NoError(t, err)
+	factory := NewFactory()
+	factories.Exporters[TypeStr] = factory
+
+	fp := filepath.Join("testdata", "cardinality_limit.yaml")
+	c, err := otelcoltest.LoadConfigAndValidate(fp, factories)
+	assert.NoError(t, err)
+
+	assert.NotNil(t, c)
+	c2, ok := c.Exporters[component.NewID(TypeStr)].(*Config)
+	assert.True(t, ok)
+	assert.Equal(t, &CardinalityLimitConfig{
+		MaxSeriesPerMetric: 100,
+		Action:             CardinalityActionStrip,
+		RotationInterval:   30 * time.Minute,
+	}, c2.CardinalityLimit)
+
+	// Expect invalid because of the unknown action.
+	fp = filepath.Join("testdata", "invalid_cardinality_limit.yaml")
+	_, err = otelcoltest.LoadConfigAndValidate(fp, factories)
+	assert.Error(t, err)
+}
+
+func TestConfigDerivedMetrics(t *testing.T) {
+	factories, err := otelcoltest.NopFactories()
+	assert.NoError(t, err)
+	factory := NewFactory()
+	factories.Exporters[TypeStr] = factory
+
+	fp := filepath.Join("testdata", "derived_metrics.yaml")
+	c, err := otelcoltest.LoadConfigAndValidate(fp, factories)
+	assert.NoError(t, err)
+
+	assert.NotNil(t, c)
+	c2, ok := c.Exporters[component.NewID(TypeStr)].(*Config)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]*DerivedMetricConfig{
+		"latency": {
+			Percentiles:  []float64{50, 99.9},
+			Statistics:   []string{DerivedStatisticMaximum},
+			DropOriginal: true,
+		},
+		"requests": {
+			Rate: true,
+		},
+	}, c2.DerivedMetrics)
+
+	// Expect invalid because of the unsupported statistic.
+	fp = filepath.Join("testdata", "invalid_derived_metrics.yaml")
+	_, err = otelcoltest.LoadConfigAndValidate(fp, factories)
+	assert.Error(t, err)
+}
+
+func TestConfigMetricDeclarations(t *testing.T) {
+	factories, err := otelcoltest.NopFactories()
+	assert.NoError(t, err)
+	factory := NewFactory()
+	factories.Exporters[TypeStr] = factory
+
+	fp := filepath.Join("testdata", "metric_declarations.yaml")
+	c, err := otelcoltest.LoadConfigAndValidate(fp, factories)
+	assert.NoError(t, err)
+
+	assert.NotNil(t, c)
+	c2, ok := c.Exporters[component.NewID(TypeStr)].(*Config)
+	assert.True(t, ok)
+	assert.Equal(t, []*MetricDeclaration{
+		{
+			Dimensions:          [][]string{{"ClusterName", "Namespace"}},
+			MetricNameSelectors: []string{"^jvm_threads_current$", "^jvm_memory_bytes_used$"},
+			SourceLabels:        []string{"job"},
+			LabelMatcher:        "^kubernetes-pod-jmx$",
+		},
+	}, c2.MetricDeclarations)
+
+	// Expect invalid because of the metric name selector.
+	fp = filepath.Join("testdata", "invalid_metric_declarations.yaml")
+	_, err = otelcoltest.LoadConfigAndValidate(fp, factories)
+	assert.Error(t, err)
+}
@@ -69,6 +69,21 @@ func getAggregationInterval(attributes *pcommon.Map) time.Duration {
 	return interval
 }
 
+// getUnit removes the special attribute and returns the unit and scale it
+// overrides the metric unit with, for receivers that cannot set the unit.
+func getUnit(name string, attributes *pcommon.Map, unit string, scale float64) (string, float64) {
+	v, ok := attributes.Get(unitTagKey)
+	if !ok {
+		return unit, scale
+	}
+	attributes.Remove(unitTagKey)
+	unit, scale, err := cloudwatchutil.ToStandardUnit(v.AsString())
+	if err != nil {
+		log.Printf("W! cloudwatch: metricname %q has %v", name, err)
+	}
+	return unit, scale
+}
+
 // ConvertOtelNumberDataPoints converts each datapoint in the given slice to
 // 1 or more MetricDatums and returns them.
 func ConvertOtelNumberDataPoints(
@@ -84,13 +99,14 @@ func ConvertOtelNumberDataPoints(
 		attrs := dp.Attributes()
 		storageResolution := checkHighResolution(&attrs)
 		aggregationInterval := getAggregationInterval(&attrs)
+		dpUnit, dpScale := getUnit(name, &attrs, unit, scale)
 		dimensions := ConvertOtelDimensions(attrs)
-		value := NumberDataPointValue(dp) * scale
+		value := NumberDataPointValue(dp) * dpScale
 		ad := aggregationDatum{
 			MetricDatum: cloudwatch.MetricDatum{
 				Dimensions:        dimensions,
 				MetricName:        aws.String(name),
-				Unit:              aws.String(unit),
+				Unit:              aws.String(dpUnit),
 				Timestamp:         aws.Time(dp.Timestamp().AsTime()),
 				Value:             aws.Float64(value),
 				StorageResolution: aws.Int64(storageResolution),
@@ -135,11 +151,79 @@ func ConvertOtelHistogramDataPoints(
 	return datums
 }
 
+// ConvertOtelExponentialHistogramDataPoints converts each datapoint in the given
+// slice to Distribution, keeping the percentiles of the exponential buckets.
+func ConvertOtelExponentialHistogramDataPoints(
+	dataPoints pmetric.ExponentialHistogramDataPointSlice,
+	name string,
+	unit string,
+	scale float64,
+) []*aggregationDatum {
+	datums := make([]*aggregationDatum, 0, dataPoints.Len())
+	for i := 0; i < dataPoints.Len(); i++ {
+		dp := dataPoints.At(i)
+		attrs := dp.Attributes()
+		storageResolution := checkHighResolution(&attrs)
+		aggregationInterval := getAggregationInterval(&attrs)
+		dpUnit, dpScale := getUnit(name, &attrs, unit, scale)
+		dimensions := ConvertOtelDimensions(attrs)
+		ad := aggregationDatum{
+			MetricDatum: cloudwatch.MetricDatum{
+				Dimensions:        dimensions,
+				MetricName:        aws.String(name),
+				Unit:              aws.String(dpUnit),
+				Timestamp:         aws.Time(dp.Timestamp().AsTime()),
+				StorageResolution: aws.Int64(storageResolution),
+			},
+			aggregationInterval: aggregationInterval,
+		}
+		ad.distribution = distribution.NewDistribution()
+		ad.distribution.ConvertFromOtelExponentialHistogram(dp, dpUnit, dpScale)
+		datums = append(datums, &ad)
+	}
+	return datums
+}
+
+// ConvertOtelSummaryDataPoints converts each datapoint in the given slice to
+// Distribution, with the quantiles as its values.
+func ConvertOtelSummaryDataPoints(
+	dataPoints pmetric.SummaryDataPointSlice,
+	name string,
+	unit string,
+	scale float64,
+) []*aggregationDatum {
+	datums := make([]*aggregationDatum, 0, dataPoints.Len())
+	for i := 0; i < dataPoints.Len(); i++ {
+		dp := dataPoints.At(i)
+		attrs := dp.Attributes()
+		storageResolution := checkHighResolution(&attrs)
+		aggregationInterval := getAggregationInterval(&attrs)
+		dpUnit, dpScale := getUnit(name, &attrs, unit, scale)
+		dimensions := ConvertOtelDimensions(attrs)
+		ad := aggregationDatum{
+			MetricDatum: cloudwatch.MetricDatum{
+				Dimensions:        dimensions,
+				MetricName:        aws.String(name),
+				Unit:              aws.String(dpUnit),
+				Timestamp:         aws.Time(dp.Timestamp().AsTime()),
+				StorageResolution: aws.Int64(storageResolution),
+			},
+			aggregationInterval: aggregationInterval,
+		}
+		ad.distribution = distribution.NewDistribution()
+		ad.distribution.ConvertFromOtelSummary(dp, dpUnit, dpScale)
+		datums = append(datums, &ad)
+	}
+	return datums
+}
+
 // ConvertOtelMetric creates a list of datums from the datapoints in the given
 // metric and returns it. Only supports the metric DataTypes that we plan to use.
-// Intentionally not caching previous values and converting cumulative to delta.
-// Instead use cumulativetodeltaprocessor which supports monotonic cumulative sums.
-func ConvertOtelMetric(m pmetric.Metric) []*aggregationDatum {
+// Intentionally not caching previous values and converting cumulative sums and
+// histograms to delta. Instead use cumulativetodeltaprocessor which supports them.
+// The cumulative exponential histograms and summaries it does not support are
+// converted to delta with the given calculator.
+func ConvertOtelMetric(m pmetric.Metric, deltas *deltaCalculator) []*aggregationDatum {
 	name := m.Name()
 	unit, scale, err := cloudwatchutil.ToStandardUnit(m.Unit())
 	if err != nil {
@@ -152,6 +236,14 @@ func ConvertOtelMetric(m pmetric.Metric) []*aggregationDatum {
 		return ConvertOtelNumberDataPoints(m.Sum().DataPoints(), name, unit, scale)
 	case pmetric.MetricTypeHistogram:
 		return ConvertOtelHistogramDataPoints(m.Histogram().DataPoints(), name, unit, scale)
+	case pmetric.MetricTypeExponentialHistogram:
+		dataPoints := m.ExponentialHistogram().DataPoints()
+		if m.ExponentialHistogram().AggregationTemporality() == pmetric.AggregationTemporalityCumulative {
+			dataPoints = deltas.exponentialHistograms(name, dataPoints)
+		}
+		return ConvertOtelExponentialHistogramDataPoints(dataPoints, name, unit, scale)
+	case pmetric.MetricTypeSummary:
+		return ConvertOtelSummaryDataPoints(deltas.summaries(name, m.Summary().DataPoints()), name, unit, scale)
 	default:
 		log.Printf("E! cloudwatch: Unsupported type, %s", m.Type())
 	}
@@ -161,7 +253,7 @@ func ConvertOtelMetric(m pmetric.Metric) []*aggregationDatum {
 // ConvertOtelMetrics only uses dimensions/attributes on each "datapoint",
 // not each "Resource".
 // This is acceptable because ResourceToTelemetrySettings defaults to true.
-func ConvertOtelMetrics(m pmetric.Metrics) []*aggregationDatum {
+func ConvertOtelMetrics(m pmetric.Metrics, deltas *deltaCalculator) []*aggregationDatum {
 	datums := make([]*aggregationDatum, 0, m.DataPointCount())
 	// Metrics -> ResourceMetrics -> ScopeMetrics -> MetricSlice -> DataPoints
 	resourceMetrics := m.ResourceMetrics()
@@ -171,7 +263,7 @@ func ConvertOtelMetrics(m pmetric.Metrics) []*aggregationDatum {
 			metrics := scopeMetrics.At(j).Metrics()
 			for k := 0; k < metrics.Len(); k++ {
 				metric := metrics.At(k)
-				newDatums := ConvertOtelMetric(metric)
+				newDatums := ConvertOtelMetric(metric, deltas)
 				datums = append(datums, newDatums...)
 			}
 		}
@@ -4,17 +4,20 @@
 package cloudwatch
 
 import (
+	"math"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
 	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/seh1"
 )
 
 const (
@@ -157,7 +160,7 @@ func checkDatum(
 func TestConvertOtelMetrics_NoDimensions(t *testing.T) {
 	for i := 0; i < 100; i++ {
 		metrics := createTestMetrics(i, i, 0, "Bytes")
-		datums := ConvertOtelMetrics(metrics)
+		datums := ConvertOtelMetrics(metrics, newDeltaCalculator())
 		// Expect nummetrics * numDatapointsPerMetric
 		assert.Equal(t, i*i, len(datums))
 
@@ -178,7 +181,7 @@ func TestConvertOtelMetrics_Histogram(t *testing.T) {
 			distribution.NewDistribution = regular.NewRegularDistribution
 		}
 		metrics := createTestHistogram(i, i, 0, "Bytes")
-		datums := ConvertOtelMetrics(metrics)
+		datums := ConvertOtelMetrics(metrics, newDeltaCalculator())
 		// Expect nummetrics * numDatapointsPerMetric
 		assert.Equal(t, i*i, len(datums))
 
@@ -194,7 +197,7 @@ func TestConvertOtelMetrics_Dimensions(t *testing.T) {
 	for i := 0; i < 100; i++ {
 		// 1 data point per metric, but vary the number dimensions.
 		metrics := createTestMetrics(i, 1, i, "s")
-		datums := ConvertOtelMetrics(metrics)
+		datums := ConvertOtelMetrics(metrics, newDeltaCalculator())
 		// Expect nummetrics * numDatapointsPerMetric
 		assert.Equal(t, i, len(datums))
 
@@ -214,5 +217,228 @@ func TestInvalidMetric(t *testing.T) {
 	m := pmetric.NewMetric()
 	m.SetName("name")
 	m.SetUnit("unit")
-	assert.Empty(t, ConvertOtelMetric(m))
+	assert.Empty(t, ConvertOtelMetric(m, newDeltaCalculator()))
+}
+
+func TestConvertOtelMetrics_UnitAttribute(t *testing.T) {
+	metrics := createTestMetrics(2, 1, 1, "s")
+	ms := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	ms.At(0).Gauge().DataPoints().At(0).Attributes().PutStr(unitTagKey, "ms")
+	ms.At(1).Sum().DataPoints().At(0).Attributes().PutStr(unitTagKey, "Count")
+	datums := ConvertOtelMetrics(metrics, newDeltaCalculator())
+	assert.Len(t, datums, 2)
+	assert.Equal(t, "Milliseconds", *datums[0].Unit)
+	assert.Equal(t, "Count", *datums[1].Unit)
+	for _, d := range datums {
+		assert.Equal(t, metricValue, *d.Value)
+		assert.Len(t, d.Dimensions, 1)
+	}
+}
+
+func TestConvertOtelMetrics_ExponentialHistogram(t *testing.T) {
+	distribution.NewDistribution = seh1.NewSEH1Distribution
+	defer func() {
+		distribution.NewDistribution = regular.NewRegularDistribution
+	}()
+	metrics := pmetric.NewMetrics()
+	ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	m := ms.AppendEmpty()
+	m.SetName("latency")
+	m.SetUnit("min")
+	dp := m.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	addDimensions(dp.Attributes(), 2)
+	// base = 2^(1/8)
+	dp.SetScale(3)
+	dp.SetZeroCount(1)
+	dp.Positive().SetOffset(80)
+	dp.Positive().BucketCounts().FromRaw([]uint64{2, 0, 5})
+	dp.SetCount(8)
+	dp.SetSum(8e3)
+	dp.SetMin(0)
+	dp.SetMax(1.4e3)
+	datums := ConvertOtelMetrics(metrics, newDeltaCalculator())
+	assert.Len(t, datums, 1)
+	d := datums[0]
+	assert.Equal(t, "latency", *d.MetricName)
+	assert.Equal(t, "Seconds", *d.Unit)
+	assert.Len(t, d.Dimensions, 2)
+	assert.NotNil(t, d.distribution)
+	assert.Equal(t, 8.0, d.distribution.SampleCount())
+	assert.InDelta(t, 4.8e5, d.distribution.Sum(), 1e-6)
+	assert.Equal(t, 0.0, d.distribution.Minimum())
+	assert.InDelta(t, 8.4e4, d.distribution.Maximum(), 1e-6)
+	// The buckets are in the SEH1 buckets of their midpoints, 2^(80.5/8) and 2^(82.5/8) minutes.
+	expected := seh1.NewSEH1Distribution()
+	assert.NoError(t, expected.AddEntry(0, 1))
+	assert.NoError(t, expected.AddEntry(math.Exp2(80.5/8)*60, 2))
+	assert.NoError(t, expected.AddEntry(math.Exp2(82.5/8)*60, 5))
+	assertValueCounts(t, expected, d.distribution)
+}
+
+func TestConvertOtelMetrics_Summary(t *testing.T) {
+	distribution.NewDistribution = seh1.NewSEH1Distribution
+	defer func() {
+		distribution.NewDistribution = regular.NewRegularDistribution
+	}()
+	metrics := pmetric.NewMetrics()
+	ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	m := ms.AppendEmpty()
+	m.SetName("duration")
+	m.SetUnit("s")
+	dps := m.SetEmptySummary().DataPoints()
+	dp := dps.AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	dp.Attributes().PutStr(highResolutionTagKey, "true")
+	dp.SetCount(10)
+	dp.SetSum(30)
+	for _, q := range [][2]float64{{0, 0.5}, {0.5, 2}, {0.9, 6}, {1, 9}} {
+		qv := dp.QuantileValues().AppendEmpty()
+		qv.SetQuantile(q[0])
+		qv.SetValue(q[1])
+	}
+	// Without quantiles
+	dp = dps.AppendEmpty()
+	dp.SetCount(4)
+	dp.SetSum(10)
+	// The summaries are cumulative, the first data points are the base of the deltas.
+	deltas := newDeltaCalculator()
+	baseline := pmetric.NewMetrics()
+	metrics.CopyTo(baseline)
+	baselineDps := baseline.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Summary().DataPoints()
+	for i := 0; i < baselineDps.Len(); i++ {
+		baselineDps.At(i).SetCount(0)
+		baselineDps.At(i).SetSum(0)
+	}
+	assert.Empty(t, ConvertOtelMetrics(baseline, deltas))
+	datums := ConvertOtelMetrics(metrics, deltas)
+	assert.Len(t, datums, 2)
+
+	d := datums[0]
+	assert.Equal(t, "duration", *d.MetricName)
+	assert.Equal(t, "Seconds", *d.Unit)
+	assert.Equal(t, int64(1), *d.StorageResolution)
+	assert.Empty(t, d.Dimensions)
+	assert.Equal(t, 10.0, d.distribution.SampleCount())
+	assert.Equal(t, 30.0, d.distribution.Sum())
+	assert.Equal(t, 0.5, d.distribution.Minimum())
+	assert.Equal(t, 9.0, d.distribution.Maximum())
+	expected := seh1.NewSEH1Distribution()
+	assert.NoError(t, expected.AddEntry(2, 5))
+	assert.NoError(t, expected.AddEntry(6, 4))
+	assert.NoError(t, expected.AddEntry(9, 1))
+	assertValueCounts(t, expected, d.distribution)
+
+	d = datums[1]
+	assert.Equal(t, 4.0, d.distribution.SampleCount())
+	assert.Equal(t, 10.0, d.distribution.Sum())
+	assert.Equal(t, 2.5, d.distribution.Minimum())
+	assert.Equal(t, 2.5, d.distribution.Maximum())
+	assert.Equal(t, 1, d.distribution.Size())
+}
+
+func TestConvertOtelMetrics_CumulativeToDelta(t *testing.T) {
+	distribution.NewDistribution = seh1.NewSEH1Distribution
+	defer func() {
+		distribution.NewDistribution = regular.NewRegularDistribution
+	}()
+	start := pcommon.NewTimestampFromTime(time.Now().Add(-time.Hour))
+	newMetrics := func(count uint64, sum float64, buckets []uint64) pmetric.Metrics {
+		metrics := pmetric.NewMetrics()
+		ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+		m := ms.AppendEmpty()
+		m.SetName("latency")
+		m.SetUnit("s")
+		m.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dp := m.ExponentialHistogram().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+		dp.Attributes().PutStr("service", "api")
+		dp.SetScale(0)
+		dp.SetZeroCount(1)
+		dp.Positive().SetOffset(1)
+		dp.Positive().BucketCounts().FromRaw(buckets)
+		dp.SetCount(count)
+		dp.SetSum(sum)
+		dp.SetMin(0)
+		dp.SetMax(7)
+		m = ms.AppendEmpty()
+		m.SetName("duration")
+		m.SetUnit("s")
+		sdp := m.SetEmptySummary().DataPoints().AppendEmpty()
+		sdp.SetStartTimestamp(start)
+		sdp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+		sdp.SetCount(count)
+		sdp.SetSum(sum)
+		qv := sdp.QuantileValues().AppendEmpty()
+		qv.SetQuantile(0.5)
+		qv.SetValue(3)
+		return metrics
+	}
+	deltas := newDeltaCalculator()
+	// The first cumulative data points are only the base of the next ones.
+	assert.Empty(t, ConvertOtelMetrics(newMetrics(8, 30, []uint64{2, 5}), deltas))
+
+	datums := ConvertOtelMetrics(newMetrics(14, 50, []uint64{3, 10}), deltas)
+	require.Len(t, datums, 2)
+	histogram := datums[0].distribution
+	assert.Equal(t, "latency", *datums[0].MetricName)
+	assert.Equal(t, 6.0, histogram.SampleCount())
+	assert.Equal(t, 20.0, histogram.Sum())
+	// The buckets (2, 4] and (4, 8] are in the SEH1 buckets of their midpoints, 2^1.5 and 2^2.5.
+	expected := seh1.NewSEH1Distribution()
+	assert.NoError(t, expected.AddEntry(math.Exp2(1.5), 1))
+	assert.NoError(t, expected.AddEntry(math.Exp2(2.5), 5))
+	assertValueCounts(t, expected, histogram)
+	assert.Equal(t, "duration", *datums[1].MetricName)
+	assert.Equal(t, 6.0, datums[1].distribution.SampleCount())
+	assert.Equal(t, 20.0, datums[1].distribution.Sum())
+	// The cumulative quantiles are not those of the interval, which is published as its mean.
+	assert.Equal(t, 1, datums[1].distribution.Size())
+	assert.InDelta(t, 20.0/6, datums[1].distribution.Maximum(), 1e-9)
+
+	// Unchanged series are not published.
+	assert.Empty(t, ConvertOtelMetrics(newMetrics(14, 50, []uint64{3, 10}), deltas))
+
+	// After a reset, the data points are the deltas.
+	datums = ConvertOtelMetrics(newMetrics(3, 9, []uint64{1, 1}), deltas)
+	require.Len(t, datums, 2)
+	assert.Equal(t, 3.0, datums[0].distribution.SampleCount())
+	assert.Equal(t, 3.0, datums[1].distribution.SampleCount())
+}
+
+func TestExponentialHistogramDelta_ScaleChange(t *testing.T) {
+	previous := pmetric.NewExponentialHistogramDataPoint()
+	previous.SetScale(1)
+	previous.SetCount(6)
+	previous.Positive().SetOffset(-1)
+	previous.Positive().BucketCounts().FromRaw([]uint64{1, 2, 3})
+	current := pmetric.NewExponentialHistogramDataPoint()
+	current.SetScale(0)
+	current.SetCount(10)
+	current.Positive().SetOffset(-1)
+	current.Positive().BucketCounts().FromRaw([]uint64{2, 8})
+
+	// The buckets -1, 0 and 1 of the scale 1 are the buckets -1, 0 and 0 of the scale 0.
+	delta := pmetric.NewExponentialHistogramDataPoint()
+	require.True(t, exponentialHistogramDelta(current, previous, delta))
+	assert.Equal(t, int32(0), delta.Scale())
+	assert.Equal(t, uint64(4), delta.Count())
+	assert.Equal(t, int32(-1), delta.Positive().Offset())
+	assert.Equal(t, []uint64{1, 3}, delta.Positive().BucketCounts().AsRaw())
+}
+
+// assertValueCounts verifies the distribution has the values of the expected one with their counts.
+func assertValueCounts(t *testing.T, expected distribution.Distribution, actual distribution.Distribution) {
+	expectedValues, expectedCounts := expected.ValuesAndCounts()
+	values, counts := actual.ValuesAndCounts()
+	assert.Len(t, values, len(expectedValues))
+	valueCounts := make(map[float64]float64, len(values))
+	for i := range values {
+		valueCounts[values[i]] = counts[i]
+	}
+	for i, value := range expectedValues {
+		assert.Contains(t, valueCounts, value)
+		assert.InDelta(t, expectedCounts[i], valueCounts[value], 1e-9)
+	}
 }
@@ -0,0 +1,209 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/mapWithExpiry"
+)
+
+const (
+	deltaCleanUpInterval = time.Minute
+	deltaCacheTTL        = 5 * time.Minute
+)
+
+// deltaCalculator converts the cumulative exponential histograms and summaries to deltas, which the
+// cumulativetodeltaprocessor does not support, the same way the prometheus input does for its metrics.
+// The first data point of a series is only kept as the base of the next one. When the series has been
+// reset, the current data point is kept as the delta.
+type deltaCalculator struct {
+	mu          sync.Mutex
+	previous    *mapWithExpiry.MapWithExpiry
+	lastCleanUp time.Time
+}
+
+func newDeltaCalculator() *deltaCalculator {
+	return &deltaCalculator{previous: mapWithExpiry.NewMapWithExpiry(deltaCacheTTL), lastCleanUp: time.Now()}
+}
+
+// exponentialHistograms returns the deltas of the cumulative data points.
+func (dc *deltaCalculator) exponentialHistograms(name string, dataPoints pmetric.ExponentialHistogramDataPointSlice) pmetric.ExponentialHistogramDataPointSlice {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.cleanUp()
+	deltas := pmetric.NewExponentialHistogramDataPointSlice()
+	for i := 0; i < dataPoints.Len(); i++ {
+		dp := dataPoints.At(i)
+		key := deltaKey(name, dp.Attributes())
+		v, ok := dc.previous.Get(key)
+		current := pmetric.NewExponentialHistogramDataPoint()
+		dp.CopyTo(current)
+		dc.previous.Set(key, current)
+		if !ok {
+			continue
+		}
+		delta := pmetric.NewExponentialHistogramDataPoint()
+		if !exponentialHistogramDelta(current, v.(pmetric.ExponentialHistogramDataPoint), delta) {
+			current.CopyTo(delta)
+		}
+		if delta.Count() > 0 {
+			delta.MoveTo(deltas.AppendEmpty())
+		}
+	}
+	return deltas
+}
+
+// summaries returns the deltas of the data points, whose count and sum are always cumulative.
+// The quantiles are the ones since the start of the series and cannot be subtracted, so they are
+// removed from the deltas, which are published as the mean of the interval, unless the previous data
+// point had no count.
+func (dc *deltaCalculator) summaries(name string, dataPoints pmetric.SummaryDataPointSlice) pmetric.SummaryDataPointSlice {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.cleanUp()
+	deltas := pmetric.NewSummaryDataPointSlice()
+	for i := 0; i < dataPoints.Len(); i++ {
+		dp := dataPoints.At(i)
+		key := deltaKey(name, dp.Attributes())
+		v, ok := dc.previous.Get(key)
+		current := pmetric.NewSummaryDataPoint()
+		dp.CopyTo(current)
+		dc.previous.Set(key, current)
+		if !ok {
+			continue
+		}
+		delta := deltas.AppendEmpty()
+		current.CopyTo(delta)
+		previous := v.(pmetric.SummaryDataPoint)
+		if current.StartTimestamp() == previous.StartTimestamp() && current.Count() >= previous.Count() {
+			delta.SetStartTimestamp(previous.Timestamp())
+			delta.SetCount(current.Count() - previous.Count())
+			delta.SetSum(current.Sum() - previous.Sum())
+			if previous.Count() > 0 {
+				delta.QuantileValues().RemoveIf(func(pmetric.SummaryDataPointValueAtQuantile) bool {
+					return true
+				})
+			}
+		}
+	}
+	deltas.RemoveIf(func(dp pmetric.SummaryDataPoint) bool {
+		return dp.Count() == 0
+	})
+	return deltas
+}
+
+// cleanUp forgets the series which were not seen for a while. It must be called with the lock held.
+func (dc *deltaCalculator) cleanUp() {
+	if now := time.Now(); now.Sub(dc.lastCleanUp) >= deltaCleanUpInterval {
+		dc.previous.CleanUp(now)
+		dc.lastCleanUp = now
+	}
+}
+
+// exponentialHistogramDelta sets the difference between the data points in delta. When the scale
+// has changed, the buckets of both data points are merged into the lower scale before they are
+// subtracted. It returns false if the series has been reset since the previous data point.
+func exponentialHistogramDelta(current, previous, delta pmetric.ExponentialHistogramDataPoint) bool {
+	if current.StartTimestamp() != previous.StartTimestamp() ||
+		current.Count() < previous.Count() || current.ZeroCount() < previous.ZeroCount() {
+		return false
+	}
+	scale := min(current.Scale(), previous.Scale())
+	positive, ok := bucketsDelta(
+		downscaleBuckets(current.Positive(), current.Scale()-scale),
+		downscaleBuckets(previous.Positive(), previous.Scale()-scale),
+	)
+	if !ok {
+		return false
+	}
+	negative, ok := bucketsDelta(
+		downscaleBuckets(current.Negative(), current.Scale()-scale),
+		downscaleBuckets(previous.Negative(), previous.Scale()-scale),
+	)
+	if !ok {
+		return false
+	}
+	current.CopyTo(delta)
+	delta.SetStartTimestamp(previous.Timestamp())
+	delta.SetScale(scale)
+	delta.SetCount(current.Count() - previous.Count())
+	delta.SetZeroCount(current.ZeroCount() - previous.ZeroCount())
+	if current.HasSum() && previous.HasSum() {
+		delta.SetSum(current.Sum() - previous.Sum())
+	} else {
+		delta.RemoveSum()
+	}
+	// The min and max are the ones since the start of the series, not of the delta.
+	delta.RemoveMin()
+	delta.RemoveMax()
+	positive.MoveTo(delta.Positive())
+	negative.MoveTo(delta.Negative())
+	return true
+}
+
+// bucketsDelta returns the difference between the buckets, which have the same scale. It returns
+// false if one of the buckets has fewer counts than before.
+func bucketsDelta(current, previous pmetric.ExponentialHistogramDataPointBuckets) (pmetric.ExponentialHistogramDataPointBuckets, bool) {
+	delta := pmetric.NewExponentialHistogramDataPointBuckets()
+	counts := current.BucketCounts().AsRaw()
+	for i := 0; i < previous.BucketCounts().Len(); i++ {
+		count := previous.BucketCounts().At(i)
+		if count == 0 {
+			continue
+		}
+		j := int(previous.Offset()) + i - int(current.Offset())
+		if j < 0 || j >= len(counts) || counts[j] < count {
+			return delta, false
+		}
+		counts[j] -= count
+	}
+	delta.SetOffset(current.Offset())
+	delta.BucketCounts().FromRaw(counts)
+	return delta, true
+}
+
+// downscaleBuckets returns the buckets reduced by shift scales, where each bucket is merged into
+// the one of index i>>shift, which covers it at the lower scale.
+func downscaleBuckets(buckets pmetric.ExponentialHistogramDataPointBuckets, shift int32) pmetric.ExponentialHistogramDataPointBuckets {
+	if shift == 0 || buckets.BucketCounts().Len() == 0 {
+		return buckets
+	}
+	downscaled := pmetric.NewExponentialHistogramDataPointBuckets()
+	offset := buckets.Offset() >> shift
+	last := (buckets.Offset() + int32(buckets.BucketCounts().Len()) - 1) >> shift
+	counts := make([]uint64, last-offset+1)
+	for i := 0; i < buckets.BucketCounts().Len(); i++ {
+		counts[((buckets.Offset()+int32(i))>>shift)-offset] += buckets.BucketCounts().At(i)
+	}
+	downscaled.SetOffset(offset)
+	downscaled.BucketCounts().FromRaw(counts)
+	return downscaled
+}
+
+// deltaKey identifies the series of the data point by the metric name and the attributes.
+func deltaKey(name string, attributes pcommon.Map) string {
+	keys := make([]string, 0, attributes.Len())
+	attributes.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, k := range keys {
+		v, _ := attributes.Get(k)
+		sb.WriteString("\x00")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(v.AsString())
+	}
+	return sb.String()
+}
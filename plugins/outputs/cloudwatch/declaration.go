@@ -0,0 +1,129 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+const (
+	defaultLabelMatcher   = ".*"
+	sourceLabelsSeparator = ";"
+)
+
+// MetricDeclaration selects the metrics to publish and their dimension sets, the same way
+// the metric declarations of the EMF exporter select the metrics extracted from the logs.
+type MetricDeclaration struct {
+	// Dimensions are the dimension sets of the matching metrics. The sets with a dimension
+	// the metric does not have are skipped.
+	Dimensions [][]string `mapstructure:"dimensions,omitempty"`
+	// MetricNameSelectors are the regular expressions matched against the metric names.
+	MetricNameSelectors []string `mapstructure:"metric_name_selectors"`
+	// SourceLabels are the labels whose values, joined with a semicolon, must match the
+	// LabelMatcher regular expression.
+	SourceLabels []string `mapstructure:"source_labels,omitempty"`
+	LabelMatcher string   `mapstructure:"label_matcher,omitempty"`
+}
+
+type compiledDeclaration struct {
+	*MetricDeclaration
+	metricNameSelectors []*regexp.Regexp
+	labelMatcher        *regexp.Regexp
+}
+
+// metricDeclarations is the list of compiled declarations. A metric is published with the
+// dimension sets of all the declarations it matches, and dropped if it matches none.
+type metricDeclarations []*compiledDeclaration
+
+func newMetricDeclarations(declarations []*MetricDeclaration) (metricDeclarations, error) {
+	var compiled metricDeclarations
+	for i, declaration := range declarations {
+		if declaration == nil {
+			continue
+		}
+		if len(declaration.MetricNameSelectors) == 0 {
+			return nil, fmt.Errorf("'metric_declarations[%d]' has no metric name selectors", i)
+		}
+		c := &compiledDeclaration{MetricDeclaration: declaration}
+		for _, selector := range declaration.MetricNameSelectors {
+			re, err := regexp.Compile(selector)
+			if err != nil {
+				return nil, fmt.Errorf("'metric_declarations[%d]' has an invalid metric name selector %q: %w", i, selector, err)
+			}
+			c.metricNameSelectors = append(c.metricNameSelectors, re)
+		}
+		labelMatcher := declaration.LabelMatcher
+		if labelMatcher == "" {
+			labelMatcher = defaultLabelMatcher
+		}
+		re, err := regexp.Compile(labelMatcher)
+		if err != nil {
+			return nil, fmt.Errorf("'metric_declarations[%d]' has an invalid label matcher %q: %w", i, labelMatcher, err)
+		}
+		c.labelMatcher = re
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+func (c *compiledDeclaration) matches(metricName string, labels map[string]string) bool {
+	matched := false
+	for _, re := range c.metricNameSelectors {
+		if re.MatchString(metricName) {
+			matched = true
+			break
+		}
+	}
+	if !matched || len(c.SourceLabels) == 0 {
+		return matched
+	}
+	values := make([]string, len(c.SourceLabels))
+	for i, label := range c.SourceLabels {
+		values[i] = labels[label]
+	}
+	return c.labelMatcher.MatchString(strings.Join(values, sourceLabelsSeparator))
+}
+
+// dimensions returns the distinct dimension sets the metric is published with.
+func (d metricDeclarations) dimensions(metricName string, dimensions []*cloudwatch.Dimension) [][]*cloudwatch.Dimension {
+	labels := make(map[string]string, len(dimensions))
+	for _, dimension := range dimensions {
+		labels[*dimension.Name] = *dimension.Value
+	}
+	var result [][]*cloudwatch.Dimension
+	seen := map[string]struct{}{}
+	for _, declaration := range d {
+		if !declaration.matches(metricName, labels) {
+			continue
+		}
+		for _, set := range declaration.Dimensions {
+			names := append([]string(nil), set...)
+			sort.Strings(names)
+			key := strings.Join(names, sourceLabelsSeparator)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			selected := make([]*cloudwatch.Dimension, 0, len(names))
+			for _, name := range names {
+				value, ok := labels[name]
+				if !ok {
+					break
+				}
+				selected = append(selected, &cloudwatch.Dimension{Name: aws.String(name), Value: aws.String(value)})
+			}
+			if len(selected) != len(names) {
+				continue
+			}
+			seen[key] = struct{}{}
+			result = append(result, selected)
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricDeclarations(t *testing.T) {
+	declarations, err := newMetricDeclarations([]*MetricDeclaration{
+		{
+			SourceLabels:        []string{"job", "service"},
+			LabelMatcher:        "^jmx;(api|web)$",
+			Dimensions:          [][]string{{"service", "ClusterName"}, {"ClusterName"}, {"missing"}},
+			MetricNameSelectors: []string{"^jvm_"},
+		},
+		{
+			Dimensions:          [][]string{{"ClusterName"}, {"job"}},
+			MetricNameSelectors: []string{"^jvm_threads_current$"},
+		},
+	})
+	require.NoError(t, err)
+
+	dims := BuildDimensions(map[string]string{"job": "jmx", "service": "api", "ClusterName": "prod", "pod": "api-1"})
+	assert.Equal(t, [][]*cloudwatch.Dimension{
+		BuildDimensions(map[string]string{"ClusterName": "prod", "service": "api"}),
+		BuildDimensions(map[string]string{"ClusterName": "prod"}),
+		BuildDimensions(map[string]string{"job": "jmx"}),
+	}, declarations.dimensions("jvm_threads_current", dims))
+	assert.Len(t, declarations.dimensions("jvm_memory_bytes_used", dims), 2)
+	assert.Empty(t, declarations.dimensions("go_goroutines", dims))
+
+	// The source labels do not match.
+	dims = BuildDimensions(map[string]string{"job": "jmx", "service": "db", "ClusterName": "prod"})
+	assert.Empty(t, declarations.dimensions("jvm_memory_bytes_used", dims))
+
+	_, err = newMetricDeclarations([]*MetricDeclaration{{Dimensions: [][]string{{"job"}}}})
+	assert.Error(t, err)
+	_, err = newMetricDeclarations([]*MetricDeclaration{{MetricNameSelectors: []string{".*"}, LabelMatcher: "("}})
+	assert.Error(t, err)
+}
+
+func TestBuildMetricDatumDeclarations(t *testing.T) {
+	declarations, err := newMetricDeclarations([]*MetricDeclaration{
+		{
+			Dimensions:          [][]string{{"service"}, {"host", "service"}},
+			MetricNameSelectors: []string{"^latency$"},
+		},
+	})
+	require.NoError(t, err)
+	cw := &CloudWatch{
+		config: &Config{
+			MaxValuesPerDatum:   defaultMaxValuesPerDatum,
+			RollupDimensions:    [][]string{{"host"}},
+			DropOriginalConfigs: map[string]bool{"latency": true},
+		},
+		declarations: declarations,
+	}
+	newDatum := func(name string) *aggregationDatum {
+		return &aggregationDatum{
+			MetricDatum: cloudwatch.MetricDatum{
+				MetricName: aws.String(name),
+				Dimensions: BuildDimensions(map[string]string{"host": "a", "service": "b", "pod": "c"}),
+				Timestamp:  aws.Time(time.Now()),
+				Value:      aws.Float64(1),
+			},
+		}
+	}
+
+	// The declared dimension sets replace the rollup and are not dropped as the original.
+	got := cw.BuildMetricDatum(newDatum("latency"))
+	require.Len(t, got, 2)
+	assert.Equal(t, BuildDimensions(map[string]string{"service": "b"}), got[0].Dimensions)
+	assert.Equal(t, BuildDimensions(map[string]string{"host": "a", "service": "b"}), got[1].Dimensions)
+
+	assert.Empty(t, cw.BuildMetricDatum(newDatum("requests")))
+}
@@ -0,0 +1,161 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+const (
+	DerivedStatisticSum     = "sum"
+	DerivedStatisticMinimum = "min"
+	DerivedStatisticMaximum = "max"
+	DerivedStatisticAverage = "avg"
+	DerivedStatisticCount   = "count"
+
+	derivedRateSuffix = "_rate"
+)
+
+var derivedStatistics = map[string]struct{}{
+	DerivedStatisticSum:     {},
+	DerivedStatisticMinimum: {},
+	DerivedStatisticMaximum: {},
+	DerivedStatisticAverage: {},
+	DerivedStatisticCount:   {},
+}
+
+// rateUnits maps the units to their per second counterpart.
+var rateUnits = map[string]string{
+	cloudwatch.StandardUnitBytes:     cloudwatch.StandardUnitBytesSecond,
+	cloudwatch.StandardUnitKilobytes: cloudwatch.StandardUnitKilobytesSecond,
+	cloudwatch.StandardUnitMegabytes: cloudwatch.StandardUnitMegabytesSecond,
+	cloudwatch.StandardUnitGigabytes: cloudwatch.StandardUnitGigabytesSecond,
+	cloudwatch.StandardUnitTerabytes: cloudwatch.StandardUnitTerabytesSecond,
+	cloudwatch.StandardUnitBits:      cloudwatch.StandardUnitBitsSecond,
+	cloudwatch.StandardUnitKilobits:  cloudwatch.StandardUnitKilobitsSecond,
+	cloudwatch.StandardUnitMegabits:  cloudwatch.StandardUnitMegabitsSecond,
+	cloudwatch.StandardUnitGigabits:  cloudwatch.StandardUnitGigabitsSecond,
+	cloudwatch.StandardUnitTerabits:  cloudwatch.StandardUnitTerabitsSecond,
+	cloudwatch.StandardUnitCount:     cloudwatch.StandardUnitCountSecond,
+}
+
+// derivedStats summarizes the values of a datum, either its distribution or its
+// single value.
+type derivedStats struct {
+	values []float64
+	counts []float64
+	min    float64
+	max    float64
+	sum    float64
+	count  float64
+}
+
+func newDerivedStats(metric *aggregationDatum) derivedStats {
+	if metric.distribution == nil {
+		v := *metric.Value
+		return derivedStats{values: []float64{v}, counts: []float64{1}, min: v, max: v, sum: v, count: 1}
+	}
+	s := derivedStats{
+		min:   metric.distribution.Minimum(),
+		max:   metric.distribution.Maximum(),
+		sum:   metric.distribution.Sum(),
+		count: metric.distribution.SampleCount(),
+	}
+	values, counts := metric.distribution.ValuesAndCounts()
+	indices := make([]int, len(values))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.Slice(indices, func(i, j int) bool { return values[indices[i]] < values[indices[j]] })
+	s.values = make([]float64, len(values))
+	s.counts = make([]float64, len(values))
+	for i, index := range indices {
+		s.values[i] = values[index]
+		s.counts[i] = counts[index]
+	}
+	return s
+}
+
+// percentile returns the smallest value with at least p percent of the samples
+// at or below it, bounded by the exact minimum and maximum.
+func (s derivedStats) percentile(p float64) float64 {
+	var total float64
+	for _, c := range s.counts {
+		total += c
+	}
+	rank := p / 100 * total
+	var cumulative float64
+	for i, v := range s.values {
+		cumulative += s.counts[i]
+		if cumulative >= rank {
+			return min(max(v, s.min), s.max)
+		}
+	}
+	return s.max
+}
+
+func (s derivedStats) statistic(name string) float64 {
+	switch name {
+	case DerivedStatisticSum:
+		return s.sum
+	case DerivedStatisticMinimum:
+		return s.min
+	case DerivedStatisticMaximum:
+		return s.max
+	case DerivedStatisticAverage:
+		return s.sum / s.count
+	default:
+		return s.count
+	}
+}
+
+// percentileSuffix formats the percentile as a metric name suffix, e.g. _p99.9.
+func percentileSuffix(p float64) string {
+	return "_p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// buildDerivedDatums creates the datums of the series derived from the metric,
+// published as plain values for the consumers that can not use distributions.
+func buildDerivedDatums(metric *aggregationDatum, config *DerivedMetricConfig, s derivedStats, dimensions []*cloudwatch.Dimension) []*cloudwatch.MetricDatum {
+	var datums []*cloudwatch.MetricDatum
+	newDatum := func(suffix string, unit *string, value float64) *cloudwatch.MetricDatum {
+		return &cloudwatch.MetricDatum{
+			MetricName:        aws.String(*metric.MetricName + suffix),
+			Dimensions:        dimensions,
+			Timestamp:         metric.Timestamp,
+			Unit:              unit,
+			StorageResolution: metric.StorageResolution,
+			Value:             aws.Float64(value),
+		}
+	}
+	for _, p := range config.Percentiles {
+		datums = append(datums, newDatum(percentileSuffix(p), metric.Unit, s.percentile(p)))
+	}
+	for _, name := range config.Statistics {
+		unit := metric.Unit
+		if name == DerivedStatisticCount {
+			unit = aws.String(cloudwatch.StandardUnitCount)
+		}
+		datums = append(datums, newDatum("_"+name, unit, s.statistic(name)))
+	}
+	if config.Rate {
+		if metric.aggregationInterval <= 0 {
+			log.Printf("D! cloudwatch: metric %s is not aggregated, skipping its rate", *metric.MetricName)
+		} else {
+			unit := aws.String(cloudwatch.StandardUnitNone)
+			if metric.Unit != nil {
+				if rateUnit, ok := rateUnits[*metric.Unit]; ok {
+					unit = aws.String(rateUnit)
+				}
+			}
+			datums = append(datums, newDatum(derivedRateSuffix, unit, s.sum/metric.aggregationInterval.Seconds()))
+		}
+	}
+	return datums
+}
@@ -0,0 +1,128 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+)
+
+func newDistributionDatum(t *testing.T, name, unit string, values ...float64) *aggregationDatum {
+	dist := regular.NewRegularDistribution()
+	for _, v := range values {
+		require.NoError(t, dist.AddEntryWithUnit(v, 1, unit))
+	}
+	return &aggregationDatum{
+		MetricDatum: cloudwatch.MetricDatum{
+			MetricName: aws.String(name),
+			Dimensions: BuildDimensions(map[string]string{"host": "a", "service": "b"}),
+			Timestamp:  aws.Time(time.Now()),
+			Unit:       aws.String(unit),
+		},
+		aggregationInterval: 10 * time.Second,
+		distribution:        dist,
+	}
+}
+
+func datumValues(datums []*cloudwatch.MetricDatum) map[string]float64 {
+	values := map[string]float64{}
+	for _, d := range datums {
+		if d.Value != nil {
+			values[*d.MetricName] = *d.Value
+		}
+	}
+	return values
+}
+
+func TestDerivedStats(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(100 - i)
+	}
+	s := newDerivedStats(newDistributionDatum(t, "latency", cloudwatch.StandardUnitMilliseconds, values...))
+	assert.Equal(t, 1.0, s.percentile(0))
+	assert.Equal(t, 50.0, s.percentile(50))
+	assert.Equal(t, 99.0, s.percentile(99))
+	assert.Equal(t, 100.0, s.percentile(100))
+	assert.Equal(t, 5050.0, s.statistic(DerivedStatisticSum))
+	assert.Equal(t, 1.0, s.statistic(DerivedStatisticMinimum))
+	assert.Equal(t, 100.0, s.statistic(DerivedStatisticMaximum))
+	assert.Equal(t, 50.5, s.statistic(DerivedStatisticAverage))
+	assert.Equal(t, 100.0, s.statistic(DerivedStatisticCount))
+
+	s = newDerivedStats(&aggregationDatum{MetricDatum: cloudwatch.MetricDatum{Value: aws.Float64(7)}})
+	assert.Equal(t, 7.0, s.percentile(90))
+	assert.Equal(t, 7.0, s.statistic(DerivedStatisticAverage))
+	assert.Equal(t, 1.0, s.statistic(DerivedStatisticCount))
+}
+
+func TestPercentileSuffix(t *testing.T) {
+	assert.Equal(t, "_p99", percentileSuffix(99))
+	assert.Equal(t, "_p99.9", percentileSuffix(99.9))
+	assert.Equal(t, "_p0", percentileSuffix(0))
+}
+
+func TestBuildMetricDatumDerived(t *testing.T) {
+	cw := &CloudWatch{
+		config: &Config{
+			MaxValuesPerDatum: defaultMaxValuesPerDatum,
+			RollupDimensions:  [][]string{{"service"}},
+			DerivedMetrics: map[string]*DerivedMetricConfig{
+				"latency": {
+					Percentiles:  []float64{50, 90},
+					Statistics:   []string{DerivedStatisticMaximum, DerivedStatisticCount},
+					DropOriginal: true,
+				},
+				"transferred": {
+					Rate: true,
+				},
+			},
+		},
+	}
+
+	got := cw.BuildMetricDatum(newDistributionDatum(t, "latency", cloudwatch.StandardUnitMilliseconds, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10))
+	// Published for the original and the rollup dimensions.
+	require.Len(t, got, 8)
+	for _, d := range got {
+		assert.Nil(t, d.Values)
+		assert.NotNil(t, d.Value)
+	}
+	assert.Len(t, got[0].Dimensions, 2)
+	assert.Len(t, got[4].Dimensions, 1)
+	assert.Equal(t, map[string]float64{
+		"latency_p50":   5,
+		"latency_p90":   9,
+		"latency_max":   10,
+		"latency_count": 10,
+	}, datumValues(got))
+	assert.Equal(t, cloudwatch.StandardUnitMilliseconds, *got[0].Unit)
+	assert.Equal(t, cloudwatch.StandardUnitCount, *got[3].Unit)
+
+	got = cw.BuildMetricDatum(newDistributionDatum(t, "transferred", cloudwatch.StandardUnitBytes, 100, 300))
+	// The distribution is published as well as its rate.
+	require.Len(t, got, 4)
+	assert.Equal(t, "transferred_rate", *got[0].MetricName)
+	assert.Equal(t, 40.0, *got[0].Value)
+	assert.Equal(t, cloudwatch.StandardUnitBytesSecond, *got[0].Unit)
+	assert.Equal(t, "transferred", *got[1].MetricName)
+	assert.NotEmpty(t, got[1].Values)
+
+	// Without an aggregation interval there is no rate.
+	got = cw.BuildMetricDatum(&aggregationDatum{
+		MetricDatum: cloudwatch.MetricDatum{
+			MetricName: aws.String("transferred"),
+			Unit:       aws.String(cloudwatch.StandardUnitBytes),
+			Value:      aws.Float64(1),
+		},
+	})
+	require.Len(t, got, 1)
+	assert.Equal(t, "transferred", *got[0].MetricName)
+}
@@ -0,0 +1,186 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/diskqueue"
+)
+
+const (
+	defaultSpillMaxSize = 100 * 1024 * 1024
+	// How often the spilled requests are retried.
+	spillReplayInterval = 30 * time.Second
+	// PutMetricData accepts datums up to two weeks old and up to two hours in the future.
+	maxDatumAge          = 14 * 24 * time.Hour
+	maxDatumFutureOffset = 2 * time.Hour
+)
+
+type spilledEntry struct {
+	oldest time.Time
+	datums int
+}
+
+// spillQueue is a queue on disk for the PutMetricData requests that could not be
+// delivered. The requests are replayed in the order of their oldest datum, which
+// may differ from the order they were spilled in since they are published concurrently.
+type spillQueue struct {
+	*diskqueue.DiskQueue
+
+	mu      sync.Mutex
+	entries map[uint64]spilledEntry
+	// The IDs in the order they are replayed in, kept in memory so the queue is not scanned on every replay.
+	order []uint64
+}
+
+func newSpillQueue(dir string, maxSize int64) (*spillQueue, error) {
+	if maxSize <= 0 {
+		maxSize = defaultSpillMaxSize
+	}
+	q, err := diskqueue.NewDiskQueue(dir, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	sq := &spillQueue{DiskQueue: q, entries: make(map[uint64]spilledEntry)}
+	// Index the requests left over from a previous run.
+	for _, id := range q.IDs() {
+		params, err := sq.read(id)
+		if err != nil {
+			// Replayed first, so it is dropped right away.
+			sq.entries[id] = spilledEntry{}
+			continue
+		}
+		sq.entries[id] = newSpilledEntry(params.MetricData)
+	}
+	sq.order = q.IDs()
+	sort.SliceStable(sq.order, func(i, j int) bool {
+		return sq.before(sq.order[i], sq.order[j])
+	})
+	return sq, nil
+}
+
+func newSpilledEntry(datums []*cloudwatch.MetricDatum) spilledEntry {
+	entry := spilledEntry{datums: len(datums)}
+	for _, d := range datums {
+		if d.Timestamp != nil && (entry.oldest.IsZero() || d.Timestamp.Before(entry.oldest)) {
+			entry.oldest = *d.Timestamp
+		}
+	}
+	return entry
+}
+
+// push writes the request to the queue and returns the number of datums that
+// were evicted to stay within the size limit.
+func (q *spillQueue) push(params *cloudwatch.PutMetricDataInput) (int, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return 0, err
+	}
+	id, evictedIDs, err := q.Push(data)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	evicted := 0
+	for _, e := range evictedIDs {
+		evicted += q.entries[e].datums
+		q.forget(e)
+	}
+	if err != nil {
+		return evicted, err
+	}
+	q.entries[id] = newSpilledEntry(params.MetricData)
+	// The requests are mostly spilled in the order of their datums, so the ID usually goes at the end.
+	i := len(q.order)
+	for i > 0 && q.before(id, q.order[i-1]) {
+		i--
+	}
+	q.order = append(q.order, 0)
+	copy(q.order[i+1:], q.order[i:])
+	q.order[i] = id
+	return evicted, nil
+}
+
+// before reports whether the request with ID a is replayed before the one with ID b. The IDs are in
+// the order the requests were spilled, which breaks ties. It must be called with the lock held.
+func (q *spillQueue) before(a, b uint64) bool {
+	ea, eb := q.entries[a], q.entries[b]
+	if !ea.oldest.Equal(eb.oldest) {
+		return ea.oldest.Before(eb.oldest)
+	}
+	return a < b
+}
+
+// forget removes the request from the index. It must be called with the lock held.
+func (q *spillQueue) forget(id uint64) {
+	if _, ok := q.entries[id]; !ok {
+		return
+	}
+	delete(q.entries, id)
+	for i, o := range q.order {
+		if o == id {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// next returns the request with the oldest datum, with its datums sorted by timestamp.
+func (q *spillQueue) next() (uint64, *cloudwatch.PutMetricDataInput, bool, error) {
+	q.mu.Lock()
+	if len(q.order) == 0 {
+		q.mu.Unlock()
+		return 0, nil, false, nil
+	}
+	id := q.order[0]
+	q.mu.Unlock()
+	params, err := q.read(id)
+	if err != nil {
+		return id, nil, true, err
+	}
+	datums := params.MetricData
+	sort.SliceStable(datums, func(i, j int) bool {
+		return datums[i].Timestamp != nil && datums[j].Timestamp != nil && datums[i].Timestamp.Before(*datums[j].Timestamp)
+	})
+	return id, params, true, nil
+}
+
+func (q *spillQueue) read(id uint64) (*cloudwatch.PutMetricDataInput, error) {
+	data, err := q.Read(id)
+	if err != nil {
+		return nil, err
+	}
+	var params cloudwatch.PutMetricDataInput
+	if err = json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("failed to decode spilled request %d: %w", id, err)
+	}
+	return &params, nil
+}
+
+// remove deletes the request from the queue and returns its number of datums.
+func (q *spillQueue) remove(id uint64) (int, error) {
+	q.mu.Lock()
+	datums := q.entries[id].datums
+	q.forget(id)
+	q.mu.Unlock()
+	return datums, q.Remove(id)
+}
+
+// dropExpiredDatums removes the datums that PutMetricData would reject for being
+// too old or too far in the future.
+func dropExpiredDatums(datums []*cloudwatch.MetricDatum, now time.Time) []*cloudwatch.MetricDatum {
+	valid := datums[:0]
+	for _, d := range datums {
+		if d.Timestamp == nil || (now.Sub(*d.Timestamp) < maxDatumAge && d.Timestamp.Sub(now) < maxDatumFutureOffset) {
+			valid = append(valid, d)
+		}
+	}
+	return valid
+}
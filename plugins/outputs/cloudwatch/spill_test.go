@@ -0,0 +1,132 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSpilledInput(namespace string, timestamps ...time.Time) *cloudwatch.PutMetricDataInput {
+	params := &cloudwatch.PutMetricDataInput{Namespace: aws.String(namespace)}
+	for _, ts := range timestamps {
+		params.MetricData = append(params.MetricData, &cloudwatch.MetricDatum{
+			MetricName: aws.String("metric"),
+			Timestamp:  aws.Time(ts),
+			Value:      aws.Float64(1),
+		})
+	}
+	return params
+}
+
+func TestSpillQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := newSpillQueue(dir, 0)
+	require.NoError(t, err)
+	_, _, ok, err := q.next()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	now := time.Now().Truncate(time.Second)
+	_, err = q.push(newSpilledInput("second", now.Add(-time.Minute), now.Add(-2*time.Minute)))
+	require.NoError(t, err)
+	_, err = q.push(newSpilledInput("first", now.Add(-3*time.Minute)))
+	require.NoError(t, err)
+	_, err = q.push(newSpilledInput("third", now))
+	require.NoError(t, err)
+	assert.Equal(t, 3, q.Len())
+
+	// The requests left over are reloaded in the same order.
+	q, err = newSpillQueue(dir, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, q.Len())
+	for _, namespace := range []string{"first", "second", "third"} {
+		id, params, ok, err := q.next()
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, namespace, *params.Namespace)
+		for i := 1; i < len(params.MetricData); i++ {
+			assert.True(t, params.MetricData[i-1].Timestamp.Before(*params.MetricData[i].Timestamp))
+		}
+		datums, err := q.remove(id)
+		require.NoError(t, err)
+		assert.Equal(t, len(params.MetricData), datums)
+	}
+	_, _, ok, err = q.next()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSpillQueueOrder(t *testing.T) {
+	q, err := newSpillQueue(t.TempDir(), 0)
+	require.NoError(t, err)
+	now := time.Now().Truncate(time.Second)
+	for _, params := range []*cloudwatch.PutMetricDataInput{
+		newSpilledInput("third", now.Add(-time.Minute)),
+		newSpilledInput("first", now.Add(-3*time.Minute)),
+		newSpilledInput("fourth", now.Add(-time.Minute)),
+		newSpilledInput("second", now.Add(-2*time.Minute)),
+		newSpilledInput("fifth", now),
+	} {
+		_, err = q.push(params)
+		require.NoError(t, err)
+	}
+	for _, namespace := range []string{"first", "second", "third", "fourth", "fifth"} {
+		id, params, ok, err := q.next()
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, namespace, *params.Namespace)
+		_, err = q.remove(id)
+		require.NoError(t, err)
+	}
+	_, _, ok, err := q.next()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, q.order)
+}
+
+func TestSpillQueueEviction(t *testing.T) {
+	now := time.Now()
+	params := newSpilledInput("namespace", now, now, now)
+	q, err := newSpillQueue(t.TempDir(), 1)
+	require.NoError(t, err)
+	_, err = q.push(params)
+	assert.Error(t, err)
+
+	q, err = newSpillQueue(t.TempDir(), 1024)
+	require.NoError(t, err)
+	evicted, err := q.push(params)
+	require.NoError(t, err)
+	assert.Equal(t, 0, evicted)
+	for evicted == 0 {
+		evicted, err = q.push(newSpilledInput("namespace", now))
+		require.NoError(t, err)
+	}
+	// The oldest request is evicted first.
+	assert.Equal(t, 3, evicted)
+	assert.Len(t, q.order, q.Len())
+}
+
+func TestDropExpiredDatums(t *testing.T) {
+	now := time.Now()
+	params := newSpilledInput("namespace",
+		now.Add(-15*24*time.Hour),
+		now.Add(-13*24*time.Hour),
+		now,
+		now.Add(time.Hour),
+		now.Add(3*time.Hour),
+	)
+	params.MetricData = append(params.MetricData, &cloudwatch.MetricDatum{MetricName: aws.String("no_timestamp")})
+	datums := dropExpiredDatums(params.MetricData, now)
+	require.Len(t, datums, 4)
+	assert.Equal(t, now.Add(-13*24*time.Hour), *datums[0].Timestamp)
+	assert.Equal(t, now, *datums[1].Timestamp)
+	assert.Equal(t, now.Add(time.Hour), *datums[2].Timestamp)
+	assert.Nil(t, datums[3].Timestamp)
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/internal"
 	"github.com/aws/amazon-cloudwatch-agent/internal/retryer"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/redaction"
 	"github.com/aws/amazon-cloudwatch-agent/tool/util"
 )
 
@@ -65,6 +66,12 @@ type CloudWatchLogs struct {
 	Filename         string `toml:"shared_credential_file"`
 	Token            string `toml:"token"`
 
+	CredentialProcess    string            `toml:"credential_process"`
+	WebIdentityTokenFile string            `toml:"web_identity_token_file"`
+	WebIdentityRoleARN   string            `toml:"web_identity_role_arn"`
+	ExternalID           string            `toml:"external_id"`
+	SessionTags          map[string]string `toml:"session_tags"`
+
 	//log group and stream names
 	LogStreamName string `toml:"log_stream_name"`
 	LogGroupName  string `toml:"log_group_name"`
@@ -74,16 +81,58 @@ type CloudWatchLogs struct {
 
 	ForceFlushInterval internal.Duration `toml:"force_flush_interval"` // unit is second
 
+	// Folder where requests that could not be delivered are persisted, disabled if empty
+	SpillDirectory string `toml:"spill_directory"`
+	// Max size in bytes of the requests persisted for each log stream
+	SpillMaxSize int64 `toml:"spill_max_size"`
+
+	// Redaction applied to all the log events collected by the agent before they are
+	// published to any destination, disabled if nil
+	Redaction *redaction.Config `toml:"redaction"`
+
 	Log telegraf.Logger `toml:"-"`
 
 	pusherStopChan  chan struct{}
 	pusherWaitGroup sync.WaitGroup
 	cwDests         map[Target]*cwDest
 	middleware      awsmiddleware.Middleware
+
+	redactorOnce sync.Once
+	redactor     *redaction.Redactor
+	redactorErr  error
 }
 
+var _ logs.LogEventProcessor = (*CloudWatchLogs)(nil)
+
 func (c *CloudWatchLogs) Connect() error {
-	return nil
+	return c.initRedactor()
+}
+
+// The log agent can start publishing before the output is connected, so the
+// redactor is created by whichever comes first.
+func (c *CloudWatchLogs) initRedactor() error {
+	c.redactorOnce.Do(func() {
+		if c.Redaction == nil {
+			return
+		}
+		if c.redactor, c.redactorErr = redaction.NewRedactor(*c.Redaction); c.redactorErr != nil {
+			c.redactorErr = fmt.Errorf("invalid log redaction config: %w", c.redactorErr)
+		}
+	})
+	return c.redactorErr
+}
+
+// ProcessLogEvent redacts the log events before they are published, whatever their
+// destination. The events are dropped if the redaction config is invalid, so that no
+// sensitive data is published while the agent fails to connect the output.
+func (c *CloudWatchLogs) ProcessLogEvent(e logs.LogEvent) logs.LogEvent {
+	if c.Redaction == nil {
+		return e
+	}
+	if err := c.initRedactor(); err != nil {
+		return nil
+	}
+	return c.redactor.ProcessLogEvent(e)
 }
 
 func (c *CloudWatchLogs) Close() error {
@@ -137,6 +186,12 @@ func (c *CloudWatchLogs) getDest(t Target) *cwDest {
 		Profile:   c.Profile,
 		Filename:  c.Filename,
 		Token:     c.Token,
+
+		CredentialProcess:    c.CredentialProcess,
+		WebIdentityTokenFile: c.WebIdentityTokenFile,
+		WebIdentityRoleARN:   c.WebIdentityRoleARN,
+		ExternalID:           c.ExternalID,
+		SessionTags:          c.SessionTags,
 	}
 
 	logThrottleRetryer := retryer.NewLogThrottleRetryer(c.Log)
@@ -162,7 +217,14 @@ func (c *CloudWatchLogs) getDest(t Target) *cwDest {
 			c.Log.Info("Configured middleware on AWS client")
 		}
 	}
-	pusher := NewPusher(t, client, c.ForceFlushInterval.Duration, maxRetryTimeout, c.Log, c.pusherStopChan, &c.pusherWaitGroup)
+	var spill *spillQueue
+	if c.SpillDirectory != "" {
+		var err error
+		if spill, err = newSpillQueue(c.SpillDirectory, t, c.SpillMaxSize); err != nil {
+			c.Log.Errorf("Unable to create spill queue for %v/%v, undelivered logs will not be persisted: %v", t.Group, t.Stream, err)
+		}
+	}
+	pusher := NewPusher(t, client, c.ForceFlushInterval.Duration, maxRetryTimeout, c.Log, c.pusherStopChan, &c.pusherWaitGroup, spill)
 	cwd := &cwDest{pusher: pusher, retryer: logThrottleRetryer}
 	c.cwDests[t] = cwd
 	return cwd
@@ -386,6 +448,26 @@ var sampleConfig = `
 
   # The log stream name.
   log_stream_name = "<log_stream_name>"
+
+  ## Folder to persist requests that could not be delivered, so they survive
+  ## restarts and network partitions. The log files are not read again once
+  ## their requests are on disk. The requests are delivered at least once, one
+  ## accepted right before a restart can be published again, and the oldest
+  ## ones are dropped when the size limit is reached. Disabled if empty.
+  #spill_directory = ""
+  ## Max size in bytes of the persisted requests for each log stream
+  #spill_max_size = 104857600
+
+  ## Redact sensitive data from the log events before they are published to any
+  ## destination, including the local file and stdout ones. Each rule
+  ## uses a built-in detector (credit_card, aws_access_key, email, jwt, ip_address)
+  ## or a custom regular expression, and masks, hashes or drops the matches.
+  #[[outputs.cloudwatchlogs.redaction.rules]]
+  #  detector = "credit_card"
+  #  action = "mask"
+  #[[outputs.cloudwatchlogs.redaction.rules]]
+  #  expression = "password=\\S+"
+  #  action = "drop"
 `
 
 // SampleConfig returns the default configuration of the Output
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/aws/amazon-cloudwatch-agent/logs/redaction"
 	"github.com/aws/amazon-cloudwatch-agent/tool/util"
 )
 
@@ -89,3 +90,20 @@ func TestDuplicateDestination(t *testing.T) {
 	// Then the destination for cloudwatchlogs endpoint would be the same
 	require.Equal(t, d1, d2)
 }
+
+func TestProcessLogEvent(t *testing.T) {
+	c := &CloudWatchLogs{}
+	e := &structuredLogEvent{msg: "user@example.com"}
+	require.NoError(t, c.Connect())
+	require.Same(t, e, c.ProcessLogEvent(e))
+
+	c = &CloudWatchLogs{Redaction: &redaction.Config{Rules: []*redaction.Rule{{Detector: redaction.DetectorEmail}}}}
+	require.NoError(t, c.Connect())
+	processed := c.ProcessLogEvent(e)
+	require.NotNil(t, processed)
+	require.Equal(t, "****", processed.Message())
+
+	c = &CloudWatchLogs{Redaction: &redaction.Config{Rules: []*redaction.Rule{{Detector: "unknown"}}}}
+	require.Nil(t, c.ProcessLogEvent(e))
+	require.Error(t, c.Connect())
+}
@@ -4,6 +4,7 @@
 package cloudwatchlogs
 
 import (
+	"errors"
 	"math/rand"
 	"sort"
 	"sync"
@@ -27,6 +28,8 @@ const (
 
 var (
 	seededRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	errSpilledRejected = errors.New("spilled request rejected")
 )
 
 type CloudWatchLogsService interface {
@@ -57,13 +60,14 @@ type pusher struct {
 	needSort            bool
 	stop                <-chan struct{}
 	lastSentTime        time.Time
+	spill               *spillQueue
 
 	initNonBlockingChOnce sync.Once
 	startNonBlockCh       chan struct{}
 	wg                    *sync.WaitGroup
 }
 
-func NewPusher(target Target, service CloudWatchLogsService, flushTimeout time.Duration, retryDuration time.Duration, logger telegraf.Logger, stop <-chan struct{}, wg *sync.WaitGroup) *pusher {
+func NewPusher(target Target, service CloudWatchLogsService, flushTimeout time.Duration, retryDuration time.Duration, logger telegraf.Logger, stop <-chan struct{}, wg *sync.WaitGroup, spill *spillQueue) *pusher {
 	p := &pusher{
 		Target:          target,
 		Service:         service,
@@ -76,6 +80,7 @@ func NewPusher(target Target, service CloudWatchLogsService, flushTimeout time.D
 		stop:            stop,
 		startNonBlockCh: make(chan struct{}),
 		wg:              wg,
+		spill:           spill,
 	}
 	p.putRetentionPolicy()
 	p.wg.Add(1)
@@ -148,6 +153,17 @@ func (p *pusher) start() {
 		}
 	}()
 
+	// Only retry spilled batches when the spill queue is enabled
+	var replayCh <-chan time.Time
+	if p.spill != nil {
+		replayTicker := time.NewTicker(spillReplayInterval)
+		defer replayTicker.Stop()
+		replayCh = replayTicker.C
+		if p.spill.Len() > 0 {
+			p.Log.Infof("Found %v spilled requests for %v/%v from a previous run", p.spill.Len(), p.Group, p.Stream)
+		}
+	}
+
 	for {
 		select {
 		case e := <-ec:
@@ -189,6 +205,8 @@ func (p *pusher) start() {
 			} else {
 				p.resetFlushTimer()
 			}
+		case <-replayCh:
+			p.replaySpilled()
 		case <-p.stop:
 			if len(p.events) > 0 {
 				p.send()
@@ -219,6 +237,18 @@ func (p *pusher) send() {
 		sort.Stable(ByTimestamp(p.events))
 	}
 
+	if p.spill != nil && p.spill.Len() > 0 {
+		// The endpoint has not recovered yet, queue the batch behind the ones already
+		// waiting on disk to keep them in order.
+		p.spillBatch()
+		return
+	}
+
+	retryDuration := p.RetryDuration
+	if p.spill != nil && spillRetryTimeout < retryDuration {
+		retryDuration = spillRetryTimeout
+	}
+
 	input := &cloudwatchlogs.PutLogEventsInput{
 		LogEvents:     p.events,
 		LogGroupName:  &p.Group,
@@ -308,7 +338,12 @@ func (p *pusher) send() {
 			retryCountShort++
 		}
 
-		if time.Since(startTime)+wait > p.RetryDuration {
+		if time.Since(startTime)+wait > retryDuration {
+			if p.spill != nil {
+				p.Log.Warnf("All %v retries to %v/%v failed for PutLogEvents, spilling request to disk.", retryCountShort+retryCountLong-1, p.Group, p.Stream)
+				p.spillBatch()
+				return
+			}
 			p.Log.Errorf("All %v retries to %v/%v failed for PutLogEvents, request dropped.", retryCountShort+retryCountLong-1, p.Group, p.Stream)
 			p.reset()
 			return
@@ -318,6 +353,11 @@ func (p *pusher) send() {
 
 		select {
 		case <-p.stop:
+			if p.spill != nil {
+				p.Log.Warnf("Stop requested after %v retries to %v/%v failed for PutLogEvents, spilling request to disk.", retryCountShort+retryCountLong-1, p.Group, p.Stream)
+				p.spillBatch()
+				return
+			}
 			p.Log.Errorf("Stop requested after %v retries to %v/%v failed for PutLogEvents, request dropped.", retryCountShort+retryCountLong-1, p.Group, p.Stream)
 			p.reset()
 			return
@@ -328,6 +368,100 @@ func (p *pusher) send() {
 
 }
 
+// spillBatch moves the current batch to the spill queue. The done callbacks are
+// called once the batch is on disk, which then owns its delivery.
+func (p *pusher) spillBatch() {
+	evicted, err := p.spill.push(p.events)
+	if evicted > 0 {
+		p.Log.Errorf("Spill queue for %v/%v is full, dropped the %v oldest spilled requests.", p.Group, p.Stream, evicted)
+		p.addStats("spillDropped", float64(evicted))
+	}
+	if err != nil {
+		p.Log.Errorf("Unable to spill %v log events for %v/%v to disk, request dropped: %v", len(p.events), p.Group, p.Stream, err)
+	} else {
+		p.Log.Debugf("Spilled %v log events for %v/%v to disk, %v requests waiting.", len(p.events), p.Group, p.Stream, p.spill.Len())
+		p.addStats("spilled", float64(len(p.events)))
+		for i := len(p.doneCallbacks) - 1; i >= 0; i-- {
+			p.doneCallbacks[i]()
+		}
+	}
+	p.reset()
+}
+
+// replaySpilled sends the spilled batches in order until the queue is empty or
+// the endpoint fails again.
+func (p *pusher) replaySpilled() {
+	for {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		id, events, ok, err := p.spill.peek()
+		if !ok {
+			return
+		}
+		if err != nil {
+			p.Log.Errorf("Unable to read spilled request for %v/%v, request dropped: %v", p.Group, p.Stream, err)
+			p.removeSpilled(id)
+			continue
+		}
+
+		events = dropExpiredEvents(events)
+		if len(events) == 0 {
+			p.Log.Warnf("All spilled log events for %v/%v are out of the accepted time range, request dropped.", p.Group, p.Stream)
+			p.removeSpilled(id)
+			continue
+		}
+
+		err = p.putSpilled(events)
+		if err == errSpilledRejected {
+			p.removeSpilled(id)
+			continue
+		}
+		if err != nil {
+			p.Log.Warnf("Unable to replay spilled request to %v/%v, %v requests waiting: %v", p.Group, p.Stream, p.spill.Len(), err)
+			return
+		}
+		p.Log.Debugf("Replayed %v spilled log events to %v/%v.", len(events), p.Group, p.Stream)
+		p.addStats("spillReplayed", float64(len(events)))
+		p.removeSpilled(id)
+	}
+}
+
+func (p *pusher) removeSpilled(id uint64) {
+	if err := p.spill.Remove(id); err != nil {
+		p.Log.Errorf("Unable to remove spilled request for %v/%v: %v", p.Group, p.Stream, err)
+	}
+}
+
+// putSpilled makes a single attempt to send the spilled events. It returns
+// errSpilledRejected when the request can never succeed.
+func (p *pusher) putSpilled(events []*cloudwatchlogs.InputLogEvent) error {
+	input := &cloudwatchlogs.PutLogEventsInput{
+		LogEvents:     events,
+		LogGroupName:  &p.Group,
+		LogStreamName: &p.Stream,
+	}
+	_, err := p.Service.PutLogEvents(input)
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch e := awsErr.(type) {
+		case *cloudwatchlogs.ResourceNotFoundException:
+			if err = p.createLogGroupAndStream(); err != nil {
+				return err
+			}
+			p.putRetentionPolicy()
+			_, err = p.Service.PutLogEvents(input)
+		case *cloudwatchlogs.InvalidParameterException,
+			*cloudwatchlogs.DataAlreadyAcceptedException:
+			p.Log.Errorf("%v, will not retry the spilled request", e)
+			return errSpilledRejected
+		}
+	}
+	return err
+}
+
 func (p *pusher) createLogGroupAndStream() error {
 	_, err := p.Service.CreateLogStream(&cloudwatchlogs.CreateLogStreamInput{
 		LogGroupName:  &p.Group,
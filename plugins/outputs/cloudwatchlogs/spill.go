@@ -0,0 +1,110 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatchlogs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/diskqueue"
+)
+
+const (
+	defaultSpillMaxSize = 100 * 1024 * 1024
+	// How long a batch is retried in memory before it is spilled to disk.
+	spillRetryTimeout = 2 * time.Minute
+	// How often the spilled batches are retried.
+	spillReplayInterval = 30 * time.Second
+)
+
+type spilledEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+type spilledBatch struct {
+	Events []spilledEvent `json:"events"`
+}
+
+// spillQueue is a write-ahead queue on disk for the batches of a single target
+// that could not be delivered. A batch is synced to disk before the done
+// callbacks of its events are called, so the sources move past the spilled data
+// and do not read it again after a restart, the queue reloaded from disk replays
+// it instead. A batch is removed once it has been accepted, but one accepted
+// right before the agent stops can still be published again after the restart,
+// the spilled batches are delivered at least once. Batches evicted to stay
+// within the size limit are lost.
+type spillQueue struct {
+	*diskqueue.DiskQueue
+}
+
+func newSpillQueue(dir string, t Target, maxSize int64) (*spillQueue, error) {
+	if maxSize <= 0 {
+		maxSize = defaultSpillMaxSize
+	}
+	q, err := diskqueue.NewDiskQueue(filepath.Join(dir, spillDirName(t)), maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return &spillQueue{DiskQueue: q}, nil
+}
+
+// spillDirName returns a file system safe directory name for the log stream of
+// the target. Log group and stream names can be long and contain path separators.
+func spillDirName(t Target) string {
+	sum := sha256.Sum256([]byte(t.Group + "\n" + t.Stream))
+	return hex.EncodeToString(sum[:])
+}
+
+// push writes the events to the back of the queue and returns the number of
+// batches that were evicted to stay within the size limit.
+func (q *spillQueue) push(events []*cloudwatchlogs.InputLogEvent) (int, error) {
+	batch := spilledBatch{Events: make([]spilledEvent, 0, len(events))}
+	for _, e := range events {
+		batch.Events = append(batch.Events, spilledEvent{Timestamp: *e.Timestamp, Message: *e.Message})
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return 0, err
+	}
+	_, evicted, err := q.Push(data)
+	return len(evicted), err
+}
+
+// peek returns the oldest batch in the queue.
+func (q *spillQueue) peek() (uint64, []*cloudwatchlogs.InputLogEvent, bool, error) {
+	id, data, ok, err := q.Peek()
+	if !ok || err != nil {
+		return id, nil, ok, err
+	}
+	var batch spilledBatch
+	if err = json.Unmarshal(data, &batch); err != nil {
+		return id, nil, ok, err
+	}
+	events := make([]*cloudwatchlogs.InputLogEvent, 0, len(batch.Events))
+	for i := range batch.Events {
+		events = append(events, &cloudwatchlogs.InputLogEvent{
+			Timestamp: &batch.Events[i].Timestamp,
+			Message:   &batch.Events[i].Message,
+		})
+	}
+	return id, events, ok, nil
+}
+
+// dropExpiredEvents removes the events that PutLogEvents would reject for being
+// too old or too far in the future.
+func dropExpiredEvents(events []*cloudwatchlogs.InputLogEvent) []*cloudwatchlogs.InputLogEvent {
+	valid := events[:0]
+	for _, e := range events {
+		if hasValidTime(&structuredLogEvent{t: time.UnixMilli(*e.Timestamp)}) {
+			valid = append(valid, e)
+		}
+	}
+	return valid
+}
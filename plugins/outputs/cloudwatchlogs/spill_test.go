@@ -0,0 +1,194 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatchlogs
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/influxdata/telegraf/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/tool/util"
+)
+
+func TestSpillOnExhaustedRetries(t *testing.T) {
+	var s svcMock
+	var failing atomic.Bool
+	failing.Store(true)
+	var mu sync.Mutex
+	var sent []string
+	s.ple = func(in *cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error) {
+		if failing.Load() {
+			return nil, &cloudwatchlogs.ServiceUnavailableException{}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, e := range in.LogEvents {
+			sent = append(sent, *e.Message)
+		}
+		return &cloudwatchlogs.PutLogEventsOutput{}, nil
+	}
+
+	stop, p := testSpillPreparation(t, &s, t.TempDir(), 10*time.Millisecond)
+
+	var done atomic.Int32
+	p.AddEvent(evtMock{"first", time.Now(), func() { done.Add(1) }})
+	waitForEvents(p, 1)
+	p.send()
+	assert.Equal(t, 1, p.spill.Len())
+	assert.EqualValues(t, 1, done.Load(), "done callbacks should be called once the request is on disk")
+
+	// The backlog is not empty so the next batch goes straight to disk behind the first one
+	p.AddEvent(evtMock{"second", time.Now(), func() { done.Add(1) }})
+	waitForEvents(p, 1)
+	p.send()
+	assert.Equal(t, 2, p.spill.Len())
+
+	p.replaySpilled()
+	assert.Equal(t, 2, p.spill.Len(), "spilled requests should be kept while the endpoint is failing")
+
+	failing.Store(false)
+	p.replaySpilled()
+	assert.Equal(t, 0, p.spill.Len())
+	assert.EqualValues(t, 2, done.Load())
+	mu.Lock()
+	assert.Equal(t, []string{"first", "second"}, sent)
+	mu.Unlock()
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestSpillRejectedRequestIsDropped(t *testing.T) {
+	var s svcMock
+	s.ple = func(in *cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error) {
+		return nil, &cloudwatchlogs.InvalidParameterException{}
+	}
+
+	stop, p := testSpillPreparation(t, &s, t.TempDir(), time.Second)
+	_, err := p.spill.push([]*cloudwatchlogs.InputLogEvent{
+		{Message: aws.String("msg"), Timestamp: aws.Int64(time.Now().UnixMilli())},
+	})
+	require.NoError(t, err)
+
+	p.replaySpilled()
+	assert.Equal(t, 0, p.spill.Len())
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestSpillQueueReload(t *testing.T) {
+	dir := t.TempDir()
+	target := Target{"G", "S", util.StandardLogGroupClass, -1}
+	q, err := newSpillQueue(dir, target, 0)
+	require.NoError(t, err)
+
+	now := time.Now()
+	_, err = q.push([]*cloudwatchlogs.InputLogEvent{
+		{Message: aws.String("expired"), Timestamp: aws.Int64(now.Add(-15 * 24 * time.Hour).UnixMilli())},
+		{Message: aws.String("valid"), Timestamp: aws.Int64(now.UnixMilli())},
+	})
+	require.NoError(t, err)
+
+	other, err := newSpillQueue(dir, Target{"G", "other", util.StandardLogGroupClass, -1}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, other.Len())
+
+	q, err = newSpillQueue(dir, target, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, q.Len())
+
+	id, events, ok, err := q.peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, events, 2)
+	events = dropExpiredEvents(events)
+	require.Len(t, events, 1)
+	assert.Equal(t, "valid", *events[0].Message)
+	assert.Equal(t, now.UnixMilli(), *events[0].Timestamp)
+
+	require.NoError(t, q.Remove(id))
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestSpillRestartDeliversOnce(t *testing.T) {
+	var s svcMock
+	var failing atomic.Bool
+	failing.Store(true)
+	var mu sync.Mutex
+	var calls int
+	var sent []string
+	s.ple = func(in *cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error) {
+		if failing.Load() {
+			return nil, &cloudwatchlogs.ServiceUnavailableException{}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		for _, e := range in.LogEvents {
+			sent = append(sent, *e.Message)
+		}
+		return &cloudwatchlogs.PutLogEventsOutput{}, nil
+	}
+
+	// The source saves the offset of the last line done, like the tailer does.
+	lines := []string{"first", "second"}
+	var offset atomic.Int32
+	dir := t.TempDir()
+	stop, p := testSpillPreparation(t, &s, dir, 10*time.Millisecond)
+	for i, line := range lines {
+		i := int32(i)
+		p.AddEvent(evtMock{line, time.Now(), func() { offset.Store(i + 1) }})
+		waitForEvents(p, 1)
+		p.send()
+	}
+	require.Equal(t, 2, p.spill.Len())
+	assert.EqualValues(t, len(lines), offset.Load())
+	close(stop)
+	wg.Wait()
+
+	// After the restart, the source resumes from the saved offset and the queue
+	// replays the spilled requests, each line is published once.
+	failing.Store(false)
+	stop, p = testSpillPreparation(t, &s, dir, time.Second)
+	require.Equal(t, 2, p.spill.Len())
+	if reread := lines[offset.Load():]; len(reread) > 0 {
+		for _, line := range reread {
+			p.AddEvent(evtMock{line, time.Now(), func() {}})
+		}
+		waitForEvents(p, len(reread))
+		p.send()
+	}
+	p.replaySpilled()
+	assert.Equal(t, 0, p.spill.Len())
+	mu.Lock()
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, lines, sent)
+	mu.Unlock()
+
+	close(stop)
+	wg.Wait()
+}
+
+func testSpillPreparation(t *testing.T, s *svcMock, dir string, retryDuration time.Duration) (chan struct{}, *pusher) {
+	target := Target{"G", "S", util.StandardLogGroupClass, -1}
+	spill, err := newSpillQueue(dir, target, 0)
+	require.NoError(t, err)
+	stop := make(chan struct{})
+	p := NewPusher(target, s, time.Hour, retryDuration, models.NewLogger("cloudwatchlogs", "test", ""), stop, &wg, spill)
+	return stop, p
+}
+
+func waitForEvents(p *pusher, n int) {
+	for len(p.events) < n {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+const (
+	TargetFile   = "file"
+	TargetStdout = "stdout"
+
+	defaultMaxSizeMB  = 100
+	defaultMaxBackups = 5
+)
+
+// LocalFile is a log backend writing the log events as JSON lines to local
+// rotating files or to stdout, instead of publishing them to CloudWatch Logs.
+// The events it gets are already redacted when the log redaction is configured.
+type LocalFile struct {
+	// Where the log events are written, file or stdout.
+	Target string `toml:"target"`
+	// The file the log events are written to when the target is file.
+	FilePath string `toml:"file_path"`
+	// Max size in MB of the file before it is rotated.
+	MaxSizeMB int `toml:"max_size_mb"`
+	// Max number of rotated files kept, all of them are kept if 0.
+	MaxBackups int `toml:"max_backups"`
+	// Max number of days the rotated files are kept, they are not removed based on age if 0.
+	MaxAgeDays int `toml:"max_age_days"`
+	// Compress the rotated files with gzip.
+	Compress bool `toml:"compress"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	initOnce sync.Once
+	initErr  error
+	mu       sync.Mutex
+	writer   io.Writer
+	closer   io.Closer
+	stopped  bool
+}
+
+var _ logs.LogBackend = (*LocalFile)(nil)
+
+// The log agent can start publishing before the output is connected, so the
+// writer is created by whichever comes first.
+func (l *LocalFile) init() error {
+	l.initOnce.Do(func() {
+		switch l.Target {
+		case TargetStdout:
+			l.writer = os.Stdout
+		case TargetFile, "":
+			if l.FilePath == "" {
+				l.initErr = fmt.Errorf("file_path is required when the target is %s", TargetFile)
+				return
+			}
+			w := &lumberjack.Logger{
+				Filename:   l.FilePath,
+				MaxSize:    l.MaxSizeMB,
+				MaxBackups: l.MaxBackups,
+				MaxAge:     l.MaxAgeDays,
+				Compress:   l.Compress,
+				LocalTime:  true,
+			}
+			l.writer = w
+			l.closer = w
+		default:
+			l.initErr = fmt.Errorf("invalid target %s, valid targets are %s and %s", l.Target, TargetFile, TargetStdout)
+		}
+	})
+	return l.initErr
+}
+
+func (l *LocalFile) Connect() error {
+	return l.init()
+}
+
+func (l *LocalFile) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopped = true
+	if l.closer != nil {
+		return l.closer.Close()
+	}
+	return nil
+}
+
+// Write drops the metrics, only the log events are written.
+func (l *LocalFile) Write([]telegraf.Metric) error {
+	return nil
+}
+
+func (l *LocalFile) CreateDest(group, stream string, _ int, _ string) logs.LogDest {
+	return &localDest{output: l, group: group, stream: stream}
+}
+
+// write writes the lines at once, so the lines of concurrent destinations are not interleaved.
+func (l *LocalFile) write(lines []byte) error {
+	if err := l.init(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return logs.ErrOutputStopped
+	}
+	_, err := l.writer.Write(lines)
+	return err
+}
+
+// logLine is the JSON line written for each log event.
+type logLine struct {
+	Group     string `json:"log_group"`
+	Stream    string `json:"log_stream"`
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+type localDest struct {
+	output *LocalFile
+	group  string
+	stream string
+}
+
+func (d *localDest) Publish(events []logs.LogEvent) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, e := range events {
+		t := e.Time()
+		if t.IsZero() {
+			t = time.Now()
+		}
+		if err := encoder.Encode(logLine{
+			Group:     d.group,
+			Stream:    d.stream,
+			Timestamp: t.UnixMilli(),
+			Message:   e.Message(),
+		}); err != nil {
+			return err
+		}
+	}
+	if err := d.output.write(buf.Bytes()); err != nil {
+		return err
+	}
+	for _, e := range events {
+		e.Done()
+	}
+	return nil
+}
+
+// Description returns a one-sentence description on the Output
+func (l *LocalFile) Description() string {
+	return "Configuration for the local file output writing log events to rotating files or stdout."
+}
+
+var sampleConfig = `
+  ## Where the log events are written, file or stdout. Each log event is written
+  ## as a JSON line with its log group, log stream, timestamp and message, after
+  ## the redaction configured on the cloudwatchlogs output is applied.
+  target = "file"
+
+  ## The file the log events are written to when the target is file.
+  file_path = "/opt/aws/amazon-cloudwatch-agent/logs/local/events.log"
+
+  ## Max size in MB of the file before it is rotated, and the number of rotated files kept.
+  #max_size_mb = 100
+  #max_backups = 5
+  ## Max number of days the rotated files are kept, and whether they are compressed.
+  #max_age_days = 0
+  #compress = false
+`
+
+// SampleConfig returns the default configuration of the Output
+func (l *LocalFile) SampleConfig() string {
+	return sampleConfig
+}
+
+func init() {
+	outputs.Add("localfile", func() telegraf.Output {
+		return &LocalFile{
+			Target:     TargetFile,
+			MaxSizeMB:  defaultMaxSizeMB,
+			MaxBackups: defaultMaxBackups,
+		}
+	})
+}
@@ -0,0 +1,148 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localfile
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/logs/redaction"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/outputs/cloudwatchlogs"
+)
+
+type testEvent struct {
+	msg  string
+	t    time.Time
+	done bool
+}
+
+func (e *testEvent) Message() string { return e.msg }
+func (e *testEvent) Time() time.Time { return e.t }
+func (e *testEvent) Done()           { e.done = true }
+
+func TestLocalFilePublish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	l := &LocalFile{Target: TargetFile, FilePath: path, MaxSizeMB: 1}
+	require.NoError(t, l.Connect())
+
+	ts := time.UnixMilli(1700000000123)
+	first := &testEvent{msg: "first line", t: ts}
+	second := &testEvent{msg: `{"level":"error"}`, t: ts.Add(time.Second)}
+	require.NoError(t, l.CreateDest("group", "stream", -1, "").Publish([]logs.LogEvent{first, second}))
+	third := &testEvent{msg: "multi\nline"}
+	require.NoError(t, l.CreateDest("other", "stream", -1, "").Publish([]logs.LogEvent{third}))
+	assert.True(t, first.done)
+	assert.True(t, second.done)
+	assert.True(t, third.done)
+	require.NoError(t, l.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var lines []logLine
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line logLine
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 3)
+	assert.Equal(t, logLine{Group: "group", Stream: "stream", Timestamp: 1700000000123, Message: "first line"}, lines[0])
+	assert.Equal(t, logLine{Group: "group", Stream: "stream", Timestamp: 1700000001123, Message: `{"level":"error"}`}, lines[1])
+	assert.Equal(t, "other", lines[2].Group)
+	assert.Equal(t, "multi\nline", lines[2].Message)
+	assert.NotZero(t, lines[2].Timestamp, "events without a timestamp are written with the current time")
+}
+
+func TestLocalFileStopped(t *testing.T) {
+	l := &LocalFile{Target: TargetStdout}
+	dest := l.CreateDest("group", "stream", -1, "")
+	require.NoError(t, l.Close())
+	e := &testEvent{msg: "not written"}
+	assert.Equal(t, logs.ErrOutputStopped, dest.Publish([]logs.LogEvent{e}))
+	assert.False(t, e.done)
+}
+
+func TestLocalFileInvalidConfig(t *testing.T) {
+	assert.Error(t, (&LocalFile{Target: TargetFile}).Connect())
+	assert.Error(t, (&LocalFile{Target: "syslog"}).Connect())
+	assert.NoError(t, (&LocalFile{Target: TargetStdout}).Connect())
+}
+
+type testSrc struct {
+	events []logs.LogEvent
+}
+
+func (s *testSrc) SetOutput(fn func(logs.LogEvent)) {
+	go func() {
+		for _, e := range s.events {
+			fn(e)
+		}
+	}()
+}
+func (s *testSrc) Group() string       { return "group" }
+func (s *testSrc) Stream() string      { return "stream" }
+func (s *testSrc) Destination() string { return "localfile" }
+func (s *testSrc) Description() string { return "description" }
+func (s *testSrc) Retention() int      { return -1 }
+func (s *testSrc) Class() string       { return "" }
+func (s *testSrc) Stop()               {}
+
+type testCollection struct {
+	srcs []logs.LogSrc
+}
+
+func (c *testCollection) FindLogSrc() []logs.LogSrc {
+	srcs := c.srcs
+	c.srcs = nil
+	return srcs
+}
+func (c *testCollection) Start(telegraf.Accumulator) error  { return nil }
+func (c *testCollection) Gather(telegraf.Accumulator) error { return nil }
+func (c *testCollection) SampleConfig() string              { return "" }
+func (c *testCollection) Description() string               { return "" }
+
+func TestLocalFileRedacted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	l := &LocalFile{Target: TargetFile, FilePath: path, MaxSizeMB: 1}
+	require.NoError(t, l.Connect())
+	defer l.Close()
+
+	// The redaction is configured on the cloudwatchlogs output, but applies to the
+	// events published to the local file as well.
+	c := config.NewConfig()
+	c.Outputs = []*models.RunningOutput{
+		{Output: &cloudwatchlogs.CloudWatchLogs{Redaction: &redaction.Config{Rules: []*redaction.Rule{{Detector: redaction.DetectorEmail}}}}, Config: &models.OutputConfig{Name: "cloudwatchlogs"}},
+		{Output: l, Config: &models.OutputConfig{Name: "localfile"}},
+	}
+	e := &testEvent{msg: "login from user@example.com"}
+	c.Inputs = []*models.RunningInput{
+		{Input: &testCollection{srcs: []logs.LogSrc{&testSrc{events: []logs.LogEvent{e}}}}, Config: &models.InputConfig{Name: "logfile"}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go logs.NewLogAgent(c).Run(ctx)
+
+	assert.Eventually(t, func() bool {
+		content, err := os.ReadFile(path)
+		return err == nil && len(content) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var line logLine
+	require.NoError(t, json.Unmarshal(content, &line))
+	assert.Equal(t, "login from ****", line.Message)
+}
@@ -3,6 +3,10 @@
 
 package config
 
+import (
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sattributes"
+)
+
 const (
 	// PlatformGeneric Platforms other than Amazon EKS
 	PlatformGeneric = "generic"
@@ -19,6 +23,9 @@ const (
 type Resolver struct {
 	Name     string `mapstructure:"name"`
 	Platform string `mapstructure:"platform"`
+	// PromotedAttributes are the pod labels, pod annotations and namespace labels added to the
+	// attributes by the Kubernetes resolvers.
+	PromotedAttributes *k8sattributes.Config `mapstructure:"promoted_attributes,omitempty"`
 }
 
 func NewEKSResolver(name string) Resolver {
@@ -21,6 +21,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sattributes"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals/common"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals/config"
 	attr "github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals/internal/attributes"
@@ -53,6 +54,9 @@ type kubernetesResolver struct {
 	workloadAndNamespaceToLabels   *sync.Map
 	serviceToWorkload              *sync.Map // computed from serviceAndNamespaceToSelectors and workloadAndNamespaceToLabels every 1 min
 	workloadPodCount               map[string]int
+	promotedAttributes             *k8sattributes.Config
+	podToPromotedAttributes        *sync.Map
+	namespaceToPromotedAttributes  *sync.Map
 	safeStopCh                     *safeChannel // trace and metric processors share the same kubernetesResolver and might close the same channel separately
 }
 
@@ -197,6 +201,10 @@ func (p *podWatcher) onAddOrUpdatePod(pod, oldPod *corev1.Pod) {
 			p.logger.Debug("Added pod", zap.String("pod", pod.Name), zap.String("workload", workloadAndNamespace), zap.Int("count", p.workloadPodCount[workloadAndNamespace]))
 		}
 	}
+
+	if !p.promotedAttributes.IsEmpty() {
+		p.podToPromotedAttributes.Store(attachNamespace(pod.Name, pod.Namespace), p.promotedAttributes.Promote(pod.Labels, pod.Annotations, nil))
+	}
 }
 
 func (p *podWatcher) onDeletePod(obj interface{}) {
@@ -221,6 +229,9 @@ func (p *podWatcher) onDeletePod(obj interface{}) {
 		p.logger.Error("failed to load pod workloadKey", zap.String("pod", pod.Name))
 	}
 	p.deleter.DeleteWithDelay(p.podToWorkloadAndNamespace, pod.Name)
+	if !p.promotedAttributes.IsEmpty() {
+		p.deleter.DeleteWithDelay(p.podToPromotedAttributes, attachNamespace(pod.Name, pod.Namespace))
+	}
 }
 
 type podWatcher struct {
@@ -228,17 +239,21 @@ type podWatcher struct {
 	podToWorkloadAndNamespace    *sync.Map
 	workloadAndNamespaceToLabels *sync.Map
 	workloadPodCount             map[string]int
+	promotedAttributes           *k8sattributes.Config
+	podToPromotedAttributes      *sync.Map
 	logger                       *zap.Logger
 	informer                     cache.SharedIndexInformer
 	deleter                      Deleter
 }
 
-func newPodWatcher(logger *zap.Logger, informer cache.SharedIndexInformer, deleter Deleter) *podWatcher {
+func newPodWatcher(logger *zap.Logger, informer cache.SharedIndexInformer, deleter Deleter, promotedAttributes *k8sattributes.Config) *podWatcher {
 	return &podWatcher{
 		ipToPod:                      &sync.Map{},
 		podToWorkloadAndNamespace:    &sync.Map{},
 		workloadAndNamespaceToLabels: &sync.Map{},
 		workloadPodCount:             make(map[string]int),
+		promotedAttributes:           promotedAttributes,
+		podToPromotedAttributes:      &sync.Map{},
 		logger:                       logger,
 		informer:                     informer,
 		deleter:                      deleter,
@@ -324,6 +339,57 @@ func (s *serviceWatcher) waitForCacheSync(stopCh chan struct{}) {
 	s.logger.Info("serviceWatcher: Cache synced")
 }
 
+type namespaceWatcher struct {
+	namespaceToPromotedAttributes *sync.Map
+	promotedAttributes            *k8sattributes.Config
+	logger                        *zap.Logger
+	informer                      cache.SharedIndexInformer
+	deleter                       Deleter
+}
+
+func newNamespaceWatcher(logger *zap.Logger, informer cache.SharedIndexInformer, deleter Deleter, promotedAttributes *k8sattributes.Config) *namespaceWatcher {
+	return &namespaceWatcher{
+		namespaceToPromotedAttributes: &sync.Map{},
+		promotedAttributes:            promotedAttributes,
+		logger:                        logger,
+		informer:                      informer,
+		deleter:                       deleter,
+	}
+}
+
+func (n *namespaceWatcher) onAddOrUpdateNamespace(namespace *corev1.Namespace) {
+	n.namespaceToPromotedAttributes.Store(namespace.Name, n.promotedAttributes.Promote(nil, nil, namespace.Labels))
+}
+
+func (n *namespaceWatcher) Run(stopCh chan struct{}) {
+	n.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			namespace := obj.(*corev1.Namespace)
+			n.logger.Debug("list and watch for namespaces: ADD " + namespace.Name)
+			n.onAddOrUpdateNamespace(namespace)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			namespace := newObj.(*corev1.Namespace)
+			n.logger.Debug("list and watch for namespaces: UPDATE " + namespace.Name)
+			n.onAddOrUpdateNamespace(namespace)
+		},
+		DeleteFunc: func(obj interface{}) {
+			namespace := obj.(*corev1.Namespace)
+			n.logger.Debug("list and watch for namespaces: DELETE " + namespace.Name)
+			n.deleter.DeleteWithDelay(n.namespaceToPromotedAttributes, namespace.Name)
+		},
+	})
+	go n.informer.Run(stopCh)
+}
+
+func (n *namespaceWatcher) waitForCacheSync(stopCh chan struct{}) {
+	if !cache.WaitForNamedCacheSync("namespaceWatcher", stopCh, n.informer.HasSynced) {
+		n.logger.Fatal("timed out waiting for kubernetes namespace watcher caches to sync")
+	}
+
+	n.logger.Info("namespaceWatcher: Cache synced")
+}
+
 type serviceToWorkloadMapper struct {
 	serviceAndNamespaceToSelectors *sync.Map
 	workloadAndNamespaceToLabels   *sync.Map
@@ -434,6 +500,29 @@ func minimizePod(obj interface{}) (interface{}, error) {
 	return obj, nil
 }
 
+// minimizePodKeepingAnnotations returns a minimizePod transform which retains the given annotations,
+// they are promoted to attributes.
+func minimizePodKeepingAnnotations(annotations []string) cache.TransformFunc {
+	if len(annotations) == 0 {
+		return minimizePod
+	}
+	return func(obj interface{}) (interface{}, error) {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			return minimizePod(obj)
+		}
+		kept := make(map[string]string)
+		for _, key := range annotations {
+			if value, ok := pod.Annotations[key]; ok {
+				kept[key] = value
+			}
+		}
+		obj, err := minimizePod(pod)
+		pod.Annotations = kept
+		return obj, err
+	}
+}
+
 // minimizeService removes fields that could contain large objects, and retain essential
 // fields needed for IP/name translation. The following fields must be kept:
 // - ObjectMeta: Namespace, Name
@@ -456,7 +545,7 @@ func minimizeService(obj interface{}) (interface{}, error) {
 	return obj, nil
 }
 
-func getKubernetesResolver(platformCode, clusterName string, logger *zap.Logger) subResolver {
+func getKubernetesResolver(platformCode, clusterName string, promotedAttributes *k8sattributes.Config, logger *zap.Logger) subResolver {
 	once.Do(func() {
 		config, err := clientcmd.BuildConfigFromFlags("", "")
 		if err != nil {
@@ -473,7 +562,11 @@ func getKubernetesResolver(platformCode, clusterName string, logger *zap.Logger)
 
 		sharedInformerFactory := informers.NewSharedInformerFactory(clientset, 0)
 		podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
-		err = podInformer.SetTransform(minimizePod)
+		var promotedAnnotations []string
+		if promotedAttributes != nil {
+			promotedAnnotations = promotedAttributes.PodAnnotations
+		}
+		err = podInformer.SetTransform(minimizePodKeepingAnnotations(promotedAnnotations))
 		if err != nil {
 			logger.Error("failed to minimize Pod objects", zap.Error(err))
 		}
@@ -484,7 +577,7 @@ func getKubernetesResolver(platformCode, clusterName string, logger *zap.Logger)
 		}
 
 		timedDeleter := &TimedDeleter{Delay: deletionDelay}
-		poWatcher := newPodWatcher(logger, podInformer, timedDeleter)
+		poWatcher := newPodWatcher(logger, podInformer, timedDeleter, promotedAttributes)
 		svcWatcher := newServiceWatcher(logger, serviceInformer, timedDeleter)
 
 		safeStopCh := &safeChannel{ch: make(chan struct{}), closed: false}
@@ -495,6 +588,15 @@ func getKubernetesResolver(platformCode, clusterName string, logger *zap.Logger)
 		poWatcher.waitForCacheSync(safeStopCh.ch)
 		svcWatcher.waitForCacheSync(safeStopCh.ch)
 
+		namespaceToPromotedAttributes := &sync.Map{}
+		// namespaces are only watched when their labels are promoted
+		if promotedAttributes != nil && len(promotedAttributes.NamespaceLabels) > 0 {
+			nsWatcher := newNamespaceWatcher(logger, sharedInformerFactory.Core().V1().Namespaces().Informer(), timedDeleter, promotedAttributes)
+			nsWatcher.Run(safeStopCh.ch)
+			nsWatcher.waitForCacheSync(safeStopCh.ch)
+			namespaceToPromotedAttributes = nsWatcher.namespaceToPromotedAttributes
+		}
+
 		serviceToWorkload := &sync.Map{}
 		svcToWorkloadMapper := newServiceToWorkloadMapper(svcWatcher.serviceAndNamespaceToSelectors, poWatcher.workloadAndNamespaceToLabels, serviceToWorkload, logger, timedDeleter)
 		svcToWorkloadMapper.Start(safeStopCh.ch)
@@ -511,6 +613,9 @@ func getKubernetesResolver(platformCode, clusterName string, logger *zap.Logger)
 			workloadAndNamespaceToLabels:   poWatcher.workloadAndNamespaceToLabels,
 			serviceToWorkload:              serviceToWorkload,
 			workloadPodCount:               poWatcher.workloadPodCount,
+			promotedAttributes:             promotedAttributes,
+			podToPromotedAttributes:        poWatcher.podToPromotedAttributes,
+			namespaceToPromotedAttributes:  namespaceToPromotedAttributes,
 			safeStopCh:                     safeStopCh,
 		}
 	})
@@ -577,6 +682,46 @@ func (e *kubernetesResolver) Process(attributes, resourceAttributes pcommon.Map)
 		}
 	}
 
+	if !e.promotedAttributes.IsEmpty() {
+		e.addPromotedAttributes(attributes, resourceAttributes)
+	}
+
+	return nil
+}
+
+// addPromotedAttributes adds the allow-listed labels and annotations of the local pod and of its namespace,
+// the pod ones take precedence. The attributes already set are never overridden.
+func (e *kubernetesResolver) addPromotedAttributes(attributes, resourceAttributes pcommon.Map) {
+	podName, ok := resourceAttributes.Get(semconv.AttributeK8SPodName)
+	if !ok {
+		return
+	}
+	namespace, ok := resourceAttributes.Get(semconv.AttributeK8SNamespaceName)
+	if !ok {
+		return
+	}
+
+	promoted := make(map[string]string)
+	for k, v := range loadPromotedAttributes(e.namespaceToPromotedAttributes, namespace.Str()) {
+		promoted[k] = v
+	}
+	for k, v := range loadPromotedAttributes(e.podToPromotedAttributes, attachNamespace(podName.Str(), namespace.Str())) {
+		promoted[k] = v
+	}
+	for k, v := range promoted {
+		if _, ok := attributes.Get(k); !ok {
+			attributes.PutStr(k, v)
+		}
+	}
+}
+
+func loadPromotedAttributes(m *sync.Map, key string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	if value, ok := m.Load(key); ok {
+		return value.(map[string]string)
+	}
 	return nil
 }
 
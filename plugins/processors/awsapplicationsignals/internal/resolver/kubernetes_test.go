@@ -19,6 +19,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sattributes"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals/common"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals/config"
 	attr "github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals/internal/attributes"
@@ -1155,6 +1156,73 @@ func TestFilterPodIPFields(t *testing.T) {
 	assert.Equal(t, podStatus, newPod.(*corev1.Pod).Status)
 }
 
+func TestMinimizePodKeepingAnnotations(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: map[string]string{"team": "payments", "kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+	}
+	newPod, err := minimizePodKeepingAnnotations([]string{"team", "cost-center"})(pod)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"team": "payments"}, newPod.(*corev1.Pod).Annotations)
+
+	newPod, err = minimizePodKeepingAnnotations(nil)(pod)
+	assert.Nil(t, err)
+	assert.Nil(t, newPod.(*corev1.Pod).Annotations)
+}
+
+func TestPromotedAttributes(t *testing.T) {
+	promotedAttributes := &k8sattributes.Config{
+		PodLabels:       []string{"team", "app.kubernetes.io/version"},
+		NamespaceLabels: []string{"team", "cost-center"},
+	}
+	poWatcher := newPodWatcherForTesting(&sync.Map{}, &sync.Map{}, &sync.Map{}, map[string]int{})
+	poWatcher.promotedAttributes = promotedAttributes
+	poWatcher.podToPromotedAttributes = &sync.Map{}
+	nsWatcher := newNamespaceWatcher(poWatcher.logger, nil, mockDeleter, promotedAttributes)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testPod",
+			Namespace: "testNamespace",
+			Labels:    map[string]string{"team": "payments", "app.kubernetes.io/version": "1.2.0"},
+		},
+	}
+	poWatcher.onAddOrUpdatePod(pod, nil)
+	nsWatcher.onAddOrUpdateNamespace(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "testNamespace",
+			Labels: map[string]string{"team": "platform", "cost-center": "cc-42"},
+		},
+	})
+
+	resolver := &kubernetesResolver{
+		logger:                        poWatcher.logger,
+		ipToPod:                       &sync.Map{},
+		ipToServiceAndNamespace:       &sync.Map{},
+		promotedAttributes:            promotedAttributes,
+		podToPromotedAttributes:       poWatcher.podToPromotedAttributes,
+		namespaceToPromotedAttributes: nsWatcher.namespaceToPromotedAttributes,
+	}
+	attributes := pcommon.NewMap()
+	attributes.PutStr("cost_center", "existing")
+	resourceAttributes := pcommon.NewMap()
+	resourceAttributes.PutStr(semconv.AttributeK8SPodName, "testPod")
+	resourceAttributes.PutStr(semconv.AttributeK8SNamespaceName, "testNamespace")
+	assert.NoError(t, resolver.Process(attributes, resourceAttributes))
+	assert.Equal(t, map[string]any{
+		"team":                      "payments",
+		"app_kubernetes_io_version": "1.2.0",
+		"cost_center":               "existing",
+	}, attributes.AsRaw())
+
+	poWatcher.onDeletePod(pod)
+	attributes = pcommon.NewMap()
+	assert.NoError(t, resolver.Process(attributes, resourceAttributes))
+	assert.Equal(t, map[string]any{"team": "platform", "cost_center": "cc-42"}, attributes.AsRaw())
+}
+
 func TestFilterServiceIPFields(t *testing.T) {
 	meta := metav1.ObjectMeta{
 		Name:      "test",
@@ -38,6 +38,12 @@ type Config struct {
 	Filename    string `mapstructure:"shared_credential_file,omitempty"`
 	Token       string `mapstructure:"token,omitempty"`
 	IMDSRetries int    `mapstructure:"imds_retries,omitempty"`
+
+	CredentialProcess    string            `mapstructure:"credential_process,omitempty"`
+	WebIdentityTokenFile string            `mapstructure:"web_identity_token_file,omitempty"`
+	WebIdentityRoleARN   string            `mapstructure:"web_identity_role_arn,omitempty"`
+	ExternalID           string            `mapstructure:"external_id,omitempty"`
+	SessionTags          map[string]string `mapstructure:"session_tags,omitempty"`
 }
 
 // Verify Config implements Processor interface.
@@ -324,6 +324,12 @@ func (t *Tagger) Start(ctx context.Context, _ component.Host) error {
 			Filename:  t.Filename,
 			Token:     t.Token,
 			Region:    t.ec2MetadataRespond.region,
+
+			CredentialProcess:    t.CredentialProcess,
+			WebIdentityTokenFile: t.WebIdentityTokenFile,
+			WebIdentityRoleARN:   t.WebIdentityRoleARN,
+			ExternalID:           t.ExternalID,
+			SessionTags:          t.SessionTags,
 		}
 		t.ec2API = t.ec2Provider(ec2CredentialConfig)
 		go func() { //Async start of initial retrieval to prevent block of agent start
@@ -254,6 +254,14 @@ func TestStatsDConfig(t *testing.T) {
 	}
 }
 
+// The statsd events are published to cloudwatchlogs even without a logs section
+func TestStatsDEventsConfig(t *testing.T) {
+	resetContext(t)
+	context.CurrentContext().SetMode(config.ModeEC2)
+	expectedEnvVars := map[string]string{}
+	checkTranslation(t, "statsd_events_config", "linux", expectedEnvVars, "")
+}
+
 // Linux only for CollectD
 func TestCollectDConfig(t *testing.T) {
 	resetContext(t)
@@ -570,6 +578,11 @@ func TestLogFilterConfig(t *testing.T) {
 	checkTranslation(t, "log_filter", "darwin", nil, "")
 }
 
+func TestLogMetricFiltersOnlyConfig(t *testing.T) {
+	resetContext(t)
+	checkTranslation(t, "log_metric_filters_only", "linux", nil, "")
+}
+
 func TestIgnoreInvalidAppendDimensions(t *testing.T) {
 	resetContext(t)
 	context.CurrentContext().SetMode(config.ModeEC2)
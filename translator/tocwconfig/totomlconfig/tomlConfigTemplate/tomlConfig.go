@@ -170,17 +170,45 @@ type (
 		ClusterName          string                              `toml:"cluster_name"`
 		PrometheusConfigPath string                              `toml:"prometheus_config_path"`
 		EcsServiceDiscovery  prometheusEcsServiceDiscoveryConfig `toml:"ecs_service_discovery"`
+		K8sAnnotationSD      prometheusK8sAnnotationSDConfig     `toml:"k8s_annotation_discovery"`
+		RemoteWrite          prometheusRemoteWriteConfig         `toml:"remote_write"`
 		Tags                 map[string]string
 	}
 
+	prometheusK8sAnnotationSDConfig struct {
+		SdFrequency  string `toml:"sd_frequency"`
+		SdResultFile string `toml:"sd_result_file"`
+	}
+
+	prometheusRemoteWriteConfig struct {
+		ServiceAddress string `toml:"service_address"`
+		Path           string
+	}
+
 	prometheusEcsServiceDiscoveryConfig struct {
 		SdClusterRegion         string                    `toml:"sd_cluster_region"`
 		SdFrequency             string                    `toml:"sd_frequency"`
 		SdResultFile            string                    `toml:"sd_result_file"`
 		SdTargetCluster         string                    `toml:"sd_target_cluster"`
+		SdTargetClusters        []sdTargetCluster         `toml:"sd_target_clusters"`
 		DockerLabel             map[string]string         `toml:"docker_label"`
 		ServiceNameListForTasks []serviceNameListForTasks `toml:"service_name_list_for_tasks"`
 		TaskDefinitionList      []taskDefinitionList      `toml:"task_definition_list"`
+		CloudMapServiceList     []cloudMapServiceList     `toml:"cloud_map_service_list"`
+	}
+
+	sdTargetCluster struct {
+		SdClusterRegion string `toml:"sd_cluster_region"`
+		SdRoleArn       string `toml:"sd_role_arn"`
+		SdTargetCluster string `toml:"sd_target_cluster"`
+	}
+
+	cloudMapServiceList struct {
+		SdJobName            string `toml:"sd_job_name"`
+		SdMetricsPath        string `toml:"sd_metrics_path"`
+		SdMetricsPorts       string `toml:"sd_metrics_ports"`
+		SdNamespaceName      string `toml:"sd_namespace_name"`
+		SdServiceNamePattern string `toml:"sd_service_name_pattern"`
 	}
 
 	serviceNameListForTasks struct {
@@ -25,13 +25,15 @@ func RegisterRule(fieldname string, r translator.Rule) {
 }
 
 type Agent struct {
-	Interval    string
-	Credentials map[string]interface{}
-	Region      string
-	RegionType  string
-	Mode        string
-	Internal    bool
-	Role_arn    string
+	Interval     string
+	Credentials  map[string]interface{}
+	Region       string
+	RegionType   string
+	Mode         string
+	Internal     bool
+	Role_arn     string
+	External_id  string
+	Session_tags map[string]interface{}
 }
 
 var Global_Config Agent = *new(Agent)
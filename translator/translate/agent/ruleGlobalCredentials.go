@@ -12,10 +12,12 @@ type GlobalCreds struct {
 
 const (
 	Role_Arn_Key          = "role_arn"
+	External_Id_Key       = "external_id"
+	Session_Tags_Key      = "session_tags"
 	CredentialsSectionKey = "credentials"
 )
 
-var credsTargetList = []string{Role_Arn_Key}
+var credsTargetList = []string{Role_Arn_Key, External_Id_Key, Session_Tags_Key}
 
 func (c *GlobalCreds) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
 	result := map[string]interface{}{}
@@ -28,6 +30,12 @@ func (c *GlobalCreds) ApplyRule(input interface{}) (returnKey string, returnVal
 	if role_arn, exist := result[Role_Arn_Key]; exist {
 		Global_Config.Role_arn = role_arn.(string)
 	}
+	if external_id, exist := result[External_Id_Key]; exist {
+		Global_Config.External_id = external_id.(string)
+	}
+	if session_tags, exist := result[Session_Tags_Key]; exist {
+		Global_Config.Session_tags = session_tags.(map[string]interface{})
+	}
 
 	return
 }
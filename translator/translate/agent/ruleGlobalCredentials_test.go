@@ -27,3 +27,19 @@ func TestWithAgentConfig(t *testing.T) {
 	}
 
 }
+
+func TestWithAssumeRoleOptions(t *testing.T) {
+	defer func() {
+		Global_Config.Role_arn = ""
+		Global_Config.External_id = ""
+		Global_Config.Session_tags = nil
+	}()
+	c := new(GlobalCreds)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{ "credentials" : {"role_arn": "role_value", "external_id": "external_value", "session_tags": {"team": "platform"}}}`), &input)
+	assert.NoError(t, err)
+	c.ApplyRule(input)
+	assert.Equal(t, "role_value", Global_Config.Role_arn)
+	assert.Equal(t, "external_value", Global_Config.External_id)
+	assert.Equal(t, map[string]interface{}{"team": "platform"}, Global_Config.Session_tags)
+}
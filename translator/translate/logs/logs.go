@@ -42,17 +42,22 @@ func (l *Logs) ApplyRule(input interface{}) (returnKey string, returnVal interfa
 	inputs := map[string]interface{}{}
 	processors := map[string]interface{}{}
 	cloudwatchConfig := map[string]interface{}{}
+	destinations := map[string]map[string]interface{}{}
 	GlobalLogConfig.MetadataInfo = util.GetMetadataInfo(util.Ec2MetadataInfoProvider)
 
 	//Check if this plugin exist in the input instance
-	//If not, not process
-	if _, ok := im[SectionKey]; !ok {
+	//If not, not process unless the statsd events have to be published to cloudwatchlogs
+	logsConfig, ok := im[SectionKey]
+	if !ok && hasStatsdEvents(im) {
+		logsConfig, ok = map[string]interface{}{}, true
+	}
+	if !ok {
 		returnKey = ""
 		returnVal = ""
 	} else {
 		//If yes, process it
 		for _, rule := range ChildRule {
-			key, val := rule.ApplyRule(im[SectionKey])
+			key, val := rule.ApplyRule(logsConfig)
 			//If key == "", then no instance of this class in input
 			if key != "" {
 				if key == "metrics_collected" {
@@ -74,12 +79,17 @@ func (l *Logs) ApplyRule(input interface{}) (returnKey string, returnVal interfa
 					inputs = translator.MergeTwoUniqueMaps(inputs, val.(map[string]interface{}))
 				} else if key == Output_Cloudwatch_Logs {
 					cloudwatchConfig = translator.MergeTwoUniqueMaps(cloudwatchConfig, val.(map[string]interface{}))
+				} else if key == DestinationsSectionKey {
+					destinations = val.(map[string]map[string]interface{})
 				}
 			}
 		}
 
 		cloudwatchInfo := map[string]interface{}{}
-		cloudwatchInfo["cloudwatchlogs"] = []interface{}{cloudwatchConfig}
+		cloudwatchInfo["cloudwatchlogs"] = append([]interface{}{cloudwatchConfig}, destinationOutputs(cloudwatchConfig, destinations[Output_Cloudwatch_Logs])...)
+		if localFiles := destinations[Output_Local_File]; len(localFiles) > 0 {
+			cloudwatchInfo[Output_Local_File] = localFileOutputs(localFiles)
+		}
 		result["outputs"] = cloudwatchInfo
 
 		if len(inputs) > 0 {
@@ -95,6 +105,16 @@ func (l *Logs) ApplyRule(input interface{}) (returnKey string, returnVal interfa
 	return
 }
 
+// hasStatsdEvents returns whether the statsd input publishes its events to a log group,
+// which requires the cloudwatchlogs output even without a logs section.
+func hasStatsdEvents(im map[string]interface{}) bool {
+	metrics, _ := im["metrics"].(map[string]interface{})
+	metricsCollected, _ := metrics["metrics_collected"].(map[string]interface{})
+	statsd, _ := metricsCollected["statsd"].(map[string]interface{})
+	_, ok := statsd["events_log_group_name"]
+	return ok
+}
+
 var MergeRuleMap = map[string]mergeJsonRule.MergeRule{}
 
 func (l *Logs) Merge(source map[string]interface{}, result map[string]interface{}) {
@@ -549,6 +549,58 @@ func TestAutoRemoval(t *testing.T) {
 	assert.Equal(t, expectVal, val)
 }
 
+func TestReadCompressed(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"collect_list":[
+			{
+				"file_path":"path1",
+				"read_compressed": true
+			}
+		]
+	}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":         "path1",
+		"from_beginning":    true,
+		"pipe":              false,
+		"retention_in_days": -1,
+		"log_group_class":   "",
+		"read_compressed":   true,
+	}}
+	assert.Equal(t, expectVal, val)
+}
+
+func TestKubernetesPodAnnotations(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"collect_list":[
+			{
+				"file_path":"/var/log/containers/*.log",
+				"kubernetes_pod_annotations": true
+			}
+		]
+	}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":                  "/var/log/containers/*.log",
+		"from_beginning":             true,
+		"pipe":                       false,
+		"retention_in_days":          -1,
+		"log_group_class":            "",
+		"kubernetes_pod_annotations": true,
+	}}
+	assert.Equal(t, expectVal, val)
+}
+
 func TestFileConfigOutputFile(t *testing.T) {
 	dir, err := os.MkdirTemp("", "")
 	assert.NoError(t, err)
@@ -0,0 +1,71 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const AdditionalDestinationsSectionKey = "additional_destinations"
+
+// The rules of the file config applied to the additional destinations, so their log
+// group and stream names resolve the placeholders the same way.
+var additionalDestinationRules = []Rule{new(LogGroupName), new(LogStreamName)}
+
+type AdditionalDestinations struct {
+}
+
+// ApplyRule publishes the log events of the file to other log outputs as well, e.g. one
+// of the destinations defined in the logs section.
+func (ad *AdditionalDestinations) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	val, ok := im[AdditionalDestinationsSectionKey]
+	if !ok {
+		return
+	}
+	destinations, ok := val.([]interface{})
+	if !ok {
+		translator.AddErrorMessages(GetCurPath()+AdditionalDestinationsSectionKey, fmt.Sprintf("Additional destinations %v are invalid", val))
+		return
+	}
+	var res []interface{}
+	for _, destination := range destinations {
+		destinationMap, ok := destination.(map[string]interface{})
+		if !ok {
+			translator.AddErrorMessages(GetCurPath()+AdditionalDestinationsSectionKey, fmt.Sprintf("Additional destination %v is invalid", destination))
+			continue
+		}
+		if name, ok := destinationMap[DestinationSectionKey].(string); !ok || name == "" {
+			translator.AddErrorMessages(GetCurPath()+AdditionalDestinationsSectionKey, fmt.Sprintf("Additional destination %v has no destination", destination))
+			continue
+		}
+		translated := map[string]interface{}{DestinationSectionKey: destinationMap[DestinationSectionKey]}
+		for _, rule := range additionalDestinationRules {
+			if key, v := rule.ApplyRule(destinationMap); key != "" {
+				translated[key] = v
+			}
+		}
+		if _, ok := destinationMap[LogGroupClassSectionKey]; ok {
+			_, translated[LogGroupClassSectionKey] = translator.DefaultLogGroupClassCase(LogGroupClassSectionKey, "", destinationMap)
+		}
+		if _, ok := destinationMap[RetentionInDaysSectionKey]; ok {
+			_, translated[RetentionInDaysSectionKey] = translator.DefaultRetentionInDaysCase(RetentionInDaysSectionKey, float64(-1), destinationMap)
+		}
+		res = append(res, translated)
+	}
+	if len(res) == 0 {
+		return
+	}
+	returnKey = AdditionalDestinationsSectionKey
+	returnVal = res
+	return
+}
+
+func init() {
+	ad := new(AdditionalDestinations)
+	r := []Rule{ad}
+	RegisterRule(AdditionalDestinationsSectionKey, r)
+}
@@ -0,0 +1,52 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+func TestApplyAdditionalDestinationsRule(t *testing.T) {
+	translator.ResetMessages()
+	r := new(AdditionalDestinations)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"additional_destinations": [
+			{"destination": "cloudwatchlogs_archive", "log_group_name": "security-archive", "retention_in_days": 365, "log_group_class": "infrequent_access"},
+			{"destination": "file", "log_stream_name": "copy"}
+		]
+	}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "additional_destinations", retKey)
+	assert.Len(t, translator.ErrorMessages, 0)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"destination":       "cloudwatchlogs_archive",
+			"log_group_name":    "security-archive",
+			"retention_in_days": 365,
+			"log_group_class":   "INFREQUENT_ACCESS",
+		},
+		map[string]interface{}{"destination": "file", "log_stream_name": "copy"},
+	}, retVal)
+}
+
+func TestApplyAdditionalDestinationsRuleInvalid(t *testing.T) {
+	translator.ResetMessages()
+	r := new(AdditionalDestinations)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"additional_destinations": [{"log_group_name": "archive"}, "file"]}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "", retKey)
+	assert.Nil(t, retVal)
+	assert.Len(t, translator.ErrorMessages, 2)
+}
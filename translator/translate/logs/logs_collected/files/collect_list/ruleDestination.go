@@ -0,0 +1,30 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const DestinationSectionKey = "destination"
+
+type Destination struct {
+}
+
+// ApplyRule overrides the log output the log events of the file are published to, e.g.
+// one of the destinations defined in the logs section instead of cloudwatchlogs.
+func (d *Destination) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(DestinationSectionKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	returnKey = DestinationSectionKey
+	return
+}
+
+func init() {
+	d := new(Destination)
+	r := []Rule{d}
+	RegisterRule(DestinationSectionKey, r)
+}
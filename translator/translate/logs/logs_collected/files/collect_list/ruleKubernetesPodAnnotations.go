@@ -0,0 +1,32 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const KubernetesPodAnnotationsSectionKey = "kubernetes_pod_annotations"
+
+type KubernetesPodAnnotations struct {
+}
+
+func (r *KubernetesPodAnnotations) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(KubernetesPodAnnotationsSectionKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	returnKey = KubernetesPodAnnotationsSectionKey
+	var ok bool
+	if returnVal, ok = returnVal.(bool); !ok {
+		returnVal = false
+	}
+	return
+}
+
+func init() {
+	l := new(KubernetesPodAnnotations)
+	r := []Rule{l}
+	RegisterRule(KubernetesPodAnnotationsSectionKey, r)
+}
@@ -0,0 +1,93 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	MetricFiltersSectionKey             = "metric_filters"
+	MetricFiltersMetricNameSectionKey   = "metric_name"
+	MetricFiltersPatternSectionKey      = "pattern"
+	MetricFiltersValueGroupSectionKey   = "value_group"
+	MetricFiltersUnitSectionKey         = "unit"
+	MetricFiltersDefaultValueSectionKey = "default_value"
+	MetricFiltersDimensionsSectionKey   = "dimensions"
+)
+
+type MetricFilter struct {
+}
+
+func (mf *MetricFilter) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	val, ok := im[MetricFiltersSectionKey]
+	if !ok {
+		return
+	}
+	var res []interface{}
+	filterArr, _ := val.([]interface{})
+	for _, filter := range filterArr {
+		filterMap, ok := filter.(map[string]interface{})
+		if !ok {
+			translator.AddErrorMessages(GetCurPath()+MetricFiltersSectionKey, fmt.Sprintf("Metric filter %v is invalid", filter))
+			continue
+		}
+		if name, _ := filterMap[MetricFiltersMetricNameSectionKey].(string); name == "" {
+			translator.AddErrorMessages(GetCurPath()+MetricFiltersSectionKey, fmt.Sprintf("Metric filter %v is missing the metric name", filter))
+			continue
+		}
+		pattern, _ := filterMap[MetricFiltersPatternSectionKey].(string)
+		r, err := regexp.Compile(pattern)
+		if err != nil || pattern == "" {
+			translator.AddErrorMessages(GetCurPath()+MetricFiltersSectionKey, fmt.Sprintf("Metric filter pattern %s is invalid", pattern))
+			continue
+		}
+		if group, ok := filterMap[MetricFiltersValueGroupSectionKey].(string); ok && r.SubexpIndex(group) < 0 {
+			translator.AddErrorMessages(GetCurPath()+MetricFiltersSectionKey, fmt.Sprintf("Metric filter pattern %s has no capture group named %s", pattern, group))
+			continue
+		}
+
+		m := map[string]interface{}{}
+		for _, key := range []string{
+			MetricFiltersMetricNameSectionKey,
+			MetricFiltersPatternSectionKey,
+			MetricFiltersValueGroupSectionKey,
+			MetricFiltersUnitSectionKey,
+			MetricFiltersDefaultValueSectionKey,
+			MetricFiltersDimensionsSectionKey,
+		} {
+			if v, ok := filterMap[key]; ok {
+				m[key] = v
+			}
+		}
+		res = append(res, m)
+	}
+	returnKey = MetricFiltersSectionKey
+	returnVal = res
+	return
+}
+
+// HasMetricFilters returns whether any entry of the collect list has metric filters,
+// in which case the logs input also has to run in the metrics pipeline.
+func HasMetricFilters(collectList interface{}) bool {
+	entries, _ := collectList.([]interface{})
+	for _, entry := range entries {
+		if m, ok := entry.(map[string]interface{}); ok {
+			if filters, ok := m[MetricFiltersSectionKey].([]interface{}); ok && len(filters) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	mf := new(MetricFilter)
+	r := []Rule{mf}
+	RegisterRule(MetricFiltersSectionKey, r)
+}
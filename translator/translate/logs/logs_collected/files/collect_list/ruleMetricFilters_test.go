@@ -0,0 +1,70 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+func TestApplyMetricFiltersRule(t *testing.T) {
+	translator.ResetMessages()
+	r := new(MetricFilter)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"metric_filters": [
+			{"metric_name": "Errors", "pattern": "ERROR", "default_value": 0},
+			{
+				"metric_name": "Latency",
+				"pattern": "status=(?P<status>\\d+) latency=(?P<latency>\\d+)",
+				"value_group": "latency",
+				"unit": "Milliseconds",
+				"dimensions": {"Status": "{status}"}
+			}
+		]
+	}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "metric_filters", retKey)
+	assert.Len(t, translator.ErrorMessages, 0)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"metric_name": "Errors", "pattern": "ERROR", "default_value": 0.0},
+		map[string]interface{}{
+			"metric_name": "Latency",
+			"pattern":     `status=(?P<status>\d+) latency=(?P<latency>\d+)`,
+			"value_group": "latency",
+			"unit":        "Milliseconds",
+			"dimensions":  map[string]interface{}{"Status": "{status}"},
+		},
+	}, retVal)
+	assert.True(t, HasMetricFilters([]interface{}{input}))
+}
+
+func TestApplyMetricFiltersRuleInvalid(t *testing.T) {
+	translator.ResetMessages()
+	r := new(MetricFilter)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"metric_filters": [
+			{"pattern": "ERROR"},
+			{"metric_name": "Errors", "pattern": "(?!re)"},
+			{"metric_name": "Latency", "pattern": "latency=(?P<latency>\\d+)", "value_group": "duration"}
+		]
+	}`), &input)
+	assert.Nil(t, e)
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "metric_filters", retKey)
+	assert.Nil(t, retVal)
+	assert.Len(t, translator.ErrorMessages, 3)
+
+	retKey, retVal = r.ApplyRule(map[string]interface{}{})
+	assert.Equal(t, "", retKey)
+	assert.Nil(t, retVal)
+	assert.False(t, HasMetricFilters([]interface{}{map[string]interface{}{}}))
+}
@@ -0,0 +1,89 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	ParserSectionKey             = "parser"
+	ParserFormatSectionKey       = "format"
+	ParserPatternSectionKey      = "pattern"
+	ParserDropFieldsSectionKey   = "drop_fields"
+	ParserRenameFieldsSectionKey = "rename_fields"
+	ParserMaskFieldsSectionKey   = "mask_fields"
+	ParserMaskValueSectionKey    = "mask_value"
+
+	parserRegexFormat = "regex"
+)
+
+type LogParser struct {
+}
+
+func (lp *LogParser) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	val, ok := im[ParserSectionKey]
+	if !ok {
+		return
+	}
+	parser, ok := val.(map[string]interface{})
+	if !ok {
+		translator.AddErrorMessages(GetCurPath()+ParserSectionKey, fmt.Sprintf("Parser %v is invalid", val))
+		return
+	}
+
+	format, ok := parser[ParserFormatSectionKey].(string)
+	if !ok || format == "" {
+		translator.AddErrorMessages(GetCurPath()+ParserSectionKey, fmt.Sprintf("Parser %v is missing the format", val))
+		return
+	}
+	if format == parserRegexFormat {
+		pattern, _ := parser[ParserPatternSectionKey].(string)
+		r, err := regexp.Compile(pattern)
+		if err != nil || pattern == "" {
+			translator.AddErrorMessages(GetCurPath()+ParserSectionKey, fmt.Sprintf("Parser pattern %s is invalid", pattern))
+			return
+		}
+		if !hasNamedGroup(r) {
+			translator.AddErrorMessages(GetCurPath()+ParserSectionKey, fmt.Sprintf("Parser pattern %s has no named capture group", pattern))
+			return
+		}
+	}
+
+	res := map[string]interface{}{}
+	for _, key := range []string{
+		ParserFormatSectionKey,
+		ParserPatternSectionKey,
+		ParserDropFieldsSectionKey,
+		ParserRenameFieldsSectionKey,
+		ParserMaskFieldsSectionKey,
+		ParserMaskValueSectionKey,
+	} {
+		if v, ok := parser[key]; ok {
+			res[key] = v
+		}
+	}
+	returnKey = ParserSectionKey
+	returnVal = res
+	return
+}
+
+func hasNamedGroup(r *regexp.Regexp) bool {
+	for _, name := range r.SubexpNames() {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	lp := new(LogParser)
+	r := []Rule{lp}
+	RegisterRule(ParserSectionKey, r)
+}
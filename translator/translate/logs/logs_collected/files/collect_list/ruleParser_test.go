@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+func TestApplyLogParserRule(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogParser)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"parser": {
+			"format": "json",
+			"drop_fields": ["debug"],
+			"rename_fields": {"msg": "message"},
+			"mask_fields": ["password"],
+			"mask_value": "[REDACTED]"
+		}
+	}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "parser", retKey)
+	assert.Len(t, translator.ErrorMessages, 0)
+	assert.Equal(t, map[string]interface{}{
+		"format":        "json",
+		"drop_fields":   []interface{}{"debug"},
+		"rename_fields": map[string]interface{}{"msg": "message"},
+		"mask_fields":   []interface{}{"password"},
+		"mask_value":    "[REDACTED]",
+	}, retVal)
+}
+
+func TestApplyLogParserRuleRegex(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogParser)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"parser": {"format": "regex", "pattern": "^(?P<level>\\w+) (?P<message>.*)$"}
+	}`), &input)
+	assert.Nil(t, e)
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "parser", retKey)
+	assert.Equal(t, map[string]interface{}{
+		"format":  "regex",
+		"pattern": `^(?P<level>\w+) (?P<message>.*)$`,
+	}, retVal)
+	assert.Len(t, translator.ErrorMessages, 0)
+
+	for _, config := range []string{
+		`{"parser": {"format": "regex", "pattern": "(?!re)"}}`,
+		`{"parser": {"format": "regex", "pattern": "^(\\w+)$"}}`,
+		`{"parser": {"format": "regex"}}`,
+		`{"parser": {"pattern": "^(?P<level>\\w+)$"}}`,
+	} {
+		translator.ResetMessages()
+		e = json.Unmarshal([]byte(config), &input)
+		assert.Nil(t, e)
+		retKey, retVal = r.ApplyRule(input)
+		assert.Equal(t, "", retKey, config)
+		assert.Nil(t, retVal, config)
+		assert.Len(t, translator.ErrorMessages, 1, config)
+	}
+}
+
+func TestApplyLogParserRuleMissing(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogParser)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"file_path": "path1"}`), &input)
+	assert.Nil(t, e)
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "", retKey)
+	assert.Nil(t, retVal)
+	assert.Len(t, translator.ErrorMessages, 0)
+}
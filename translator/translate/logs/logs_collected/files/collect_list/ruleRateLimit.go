@@ -0,0 +1,56 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	RateLimitSectionKey                = "rate_limit"
+	RateLimitEventsPerSecondSectionKey = "events_per_second"
+	RateLimitBurstSectionKey           = "burst"
+	RateLimitOnOverflowSectionKey      = "on_overflow"
+)
+
+type LogRateLimit struct {
+}
+
+func (lr *LogRateLimit) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	val, ok := im[RateLimitSectionKey]
+	if !ok {
+		return
+	}
+	rateLimit, ok := val.(map[string]interface{})
+	if !ok {
+		translator.AddErrorMessages(GetCurPath()+RateLimitSectionKey, fmt.Sprintf("Rate limit %v is invalid", val))
+		return
+	}
+	eventsPerSecond, ok := rateLimit[RateLimitEventsPerSecondSectionKey].(float64)
+	if !ok || eventsPerSecond <= 0 {
+		translator.AddErrorMessages(GetCurPath()+RateLimitSectionKey, fmt.Sprintf("Rate limit events_per_second %v is invalid, it must be positive", rateLimit[RateLimitEventsPerSecondSectionKey]))
+		return
+	}
+
+	res := map[string]interface{}{RateLimitEventsPerSecondSectionKey: eventsPerSecond}
+	// The burst is an integer in the logs input, while JSON numbers are decoded as float64.
+	if burst, ok := rateLimit[RateLimitBurstSectionKey].(float64); ok {
+		res[RateLimitBurstSectionKey] = int(burst)
+	}
+	if onOverflow, ok := rateLimit[RateLimitOnOverflowSectionKey]; ok {
+		res[RateLimitOnOverflowSectionKey] = onOverflow
+	}
+	returnKey = RateLimitSectionKey
+	returnVal = res
+	return
+}
+
+func init() {
+	lr := new(LogRateLimit)
+	r := []Rule{lr}
+	RegisterRule(RateLimitSectionKey, r)
+}
@@ -0,0 +1,39 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+func TestApplyRateLimitRule(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogRateLimit)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"rate_limit": {"events_per_second": 100, "burst": 200, "on_overflow": "summarize"}}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "rate_limit", retKey)
+	assert.Len(t, translator.ErrorMessages, 0)
+	assert.Equal(t, map[string]interface{}{"events_per_second": 100.0, "burst": 200, "on_overflow": "summarize"}, retVal)
+}
+
+func TestApplyRateLimitRuleInvalid(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogRateLimit)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"rate_limit": {"events_per_second": 0}}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "", retKey)
+	assert.Nil(t, retVal)
+	assert.Len(t, translator.ErrorMessages, 1)
+}
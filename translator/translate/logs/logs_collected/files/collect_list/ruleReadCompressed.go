@@ -0,0 +1,32 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const ReadCompressedSectionKey = "read_compressed"
+
+type ReadCompressed struct {
+}
+
+func (r *ReadCompressed) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(ReadCompressedSectionKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	returnKey = ReadCompressedSectionKey
+	var ok bool
+	if returnVal, ok = returnVal.(bool); !ok {
+		returnVal = false
+	}
+	return
+}
+
+func init() {
+	l := new(ReadCompressed)
+	r := []Rule{l}
+	RegisterRule(ReadCompressedSectionKey, r)
+}
@@ -0,0 +1,51 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	SamplingSectionKey          = "sampling"
+	SamplingRateSectionKey      = "rate"
+	SamplingHashFieldSectionKey = "hash_field"
+)
+
+type LogSampling struct {
+}
+
+func (ls *LogSampling) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	val, ok := im[SamplingSectionKey]
+	if !ok {
+		return
+	}
+	sampling, ok := val.(map[string]interface{})
+	if !ok {
+		translator.AddErrorMessages(GetCurPath()+SamplingSectionKey, fmt.Sprintf("Sampling %v is invalid", val))
+		return
+	}
+	rate, ok := sampling[SamplingRateSectionKey].(float64)
+	if !ok || rate < 0 || rate > 1 {
+		translator.AddErrorMessages(GetCurPath()+SamplingSectionKey, fmt.Sprintf("Sampling rate %v is invalid, it must be between 0 and 1", sampling[SamplingRateSectionKey]))
+		return
+	}
+
+	res := map[string]interface{}{SamplingRateSectionKey: rate}
+	if hashField, ok := sampling[SamplingHashFieldSectionKey]; ok {
+		res[SamplingHashFieldSectionKey] = hashField
+	}
+	returnKey = SamplingSectionKey
+	returnVal = res
+	return
+}
+
+func init() {
+	ls := new(LogSampling)
+	r := []Rule{ls}
+	RegisterRule(SamplingSectionKey, r)
+}
@@ -0,0 +1,43 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+func TestApplySamplingRule(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogSampling)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"sampling": {"rate": 0.25, "hash_field": "request_id"}}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "sampling", retKey)
+	assert.Len(t, translator.ErrorMessages, 0)
+	assert.Equal(t, map[string]interface{}{"rate": 0.25, "hash_field": "request_id"}, retVal)
+
+	retKey, retVal = r.ApplyRule(map[string]interface{}{})
+	assert.Equal(t, "", retKey)
+	assert.Nil(t, retVal)
+}
+
+func TestApplySamplingRuleInvalid(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogSampling)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{"sampling": {"rate": 2}}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "", retKey)
+	assert.Nil(t, retVal)
+	assert.Len(t, translator.ErrorMessages, 1)
+}
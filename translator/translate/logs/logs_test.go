@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/aws/amazon-cloudwatch-agent/translator"
 	"github.com/aws/amazon-cloudwatch-agent/translator/config"
 	"github.com/aws/amazon-cloudwatch-agent/translator/context"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
@@ -200,3 +201,206 @@ func TestLogs_EndpointOverride(t *testing.T) {
 
 	ctx.SetMode(config.ModeEC2) //reset back to default mode
 }
+
+func TestLogs_Spill(t *testing.T) {
+	context.ResetContext()
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{"log_stream_name":"LOG_STREAM_NAME","spill":{"max_size_mb":10}}}`), &input)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+
+	_, actual := l.ApplyRule(input)
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{
+				map[string]interface{}{
+					"region":               "us-east-1",
+					"region_type":          "any",
+					"mode":                 "",
+					"log_stream_name":      "LOG_STREAM_NAME",
+					"force_flush_interval": "5s",
+					"spill_directory":      "/opt/aws/amazon-cloudwatch-agent/logs/spill",
+					"spill_max_size":       int64(10 * 1024 * 1024),
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual, "Expected to be equal")
+}
+
+func TestLogs_Redaction(t *testing.T) {
+	context.ResetContext()
+	translator.ResetMessages()
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{"log_stream_name":"LOG_STREAM_NAME","redaction":{"rules":[
+		{"detector":"credit_card","action":"mask","mask_value":"[CARD]"},
+		{"expression":"password=\\S+","action":"drop"},
+		{"expression":"(","action":"hash"}
+	]}}}`), &input)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+
+	_, actual := l.ApplyRule(input)
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{
+				map[string]interface{}{
+					"region":               "us-east-1",
+					"region_type":          "any",
+					"mode":                 "",
+					"log_stream_name":      "LOG_STREAM_NAME",
+					"force_flush_interval": "5s",
+					"redaction": map[string]interface{}{
+						"rules": []interface{}{
+							map[string]interface{}{"detector": "credit_card", "action": "mask", "mask_value": "[CARD]"},
+							map[string]interface{}{"expression": `password=\S+`, "action": "drop"},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual, "Expected to be equal")
+	assert.Len(t, translator.ErrorMessages, 1)
+}
+
+func TestLogs_Destinations(t *testing.T) {
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+	translator.ResetMessages()
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{
+		"log_stream_name":"LOG_STREAM_NAME",
+		"destinations":{
+			"cloudwatchlogs_archive":{"region":"eu-west-1","credentials":{"role_arn":"arn:aws:iam::123456789012:role/archive"}},
+			"cloudwatchlogs_backup":{"endpoint_override":"https://logs.example.com"}
+		}
+	}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := l.ApplyRule(input)
+	base := map[string]interface{}{
+		"region":               "us-east-1",
+		"region_type":          "any",
+		"mode":                 "",
+		"log_stream_name":      "LOG_STREAM_NAME",
+		"force_flush_interval": "5s",
+	}
+	archive := map[string]interface{}{
+		"alias":       "cloudwatchlogs_archive",
+		"region":      "eu-west-1",
+		"region_type": config.RegionTypeAgentConfigJson,
+		"role_arn":    "arn:aws:iam::123456789012:role/archive",
+	}
+	backup := map[string]interface{}{
+		"alias":             "cloudwatchlogs_backup",
+		"endpoint_override": "https://logs.example.com",
+	}
+	for k, v := range base {
+		if _, ok := archive[k]; !ok {
+			archive[k] = v
+		}
+		if _, ok := backup[k]; !ok {
+			backup[k] = v
+		}
+	}
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{base, archive, backup},
+		},
+	}
+	assert.Equal(t, expected, actual)
+	assert.Len(t, translator.ErrorMessages, 0)
+}
+
+func TestLogs_DestinationsSpill(t *testing.T) {
+	context.ResetContext()
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+	translator.ResetMessages()
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{
+		"log_stream_name":"LOG_STREAM_NAME",
+		"spill":{},
+		"redaction":{"rules":[{"detector":"email"}]},
+		"destinations":{
+			"cloudwatchlogs_archive":{"region":"eu-west-1"},
+			"cloudwatchlogs_backup":{"region":"us-west-2"}
+		}
+	}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := l.ApplyRule(input)
+	outputs := actual.(map[string]interface{})["outputs"].(map[string]interface{})["cloudwatchlogs"].([]interface{})
+	assert.Len(t, outputs, 3)
+	// The destinations publish the same log group and stream, so they must not share a spill queue.
+	var dirs []interface{}
+	for _, output := range outputs {
+		assert.Equal(t, "LOG_STREAM_NAME", output.(map[string]interface{})["log_stream_name"])
+		dirs = append(dirs, output.(map[string]interface{})["spill_directory"])
+	}
+	// The redaction is applied once to the events of all the destinations, by the first output.
+	assert.Contains(t, outputs[0], "redaction")
+	assert.NotContains(t, outputs[1], "redaction")
+	assert.NotContains(t, outputs[2], "redaction")
+	assert.Equal(t, []interface{}{
+		"/opt/aws/amazon-cloudwatch-agent/logs/spill",
+		"/opt/aws/amazon-cloudwatch-agent/logs/spill/cloudwatchlogs_archive",
+		"/opt/aws/amazon-cloudwatch-agent/logs/spill/cloudwatchlogs_backup",
+	}, dirs)
+	assert.Len(t, translator.ErrorMessages, 0)
+}
+
+func TestLogs_LocalDestinations(t *testing.T) {
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+	translator.ResetMessages()
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{
+		"log_stream_name":"LOG_STREAM_NAME",
+		"destinations":{
+			"local":{"type":"file","file_path":"/tmp/events.log","max_size_mb":10,"compress":true},
+			"debug":{"type":"stdout"},
+			"missing_path":{"type":"file"},
+			"unknown":{"type":"syslog"}
+		}
+	}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := l.ApplyRule(input)
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{
+				map[string]interface{}{
+					"region":               "us-east-1",
+					"region_type":          "any",
+					"mode":                 "",
+					"log_stream_name":      "LOG_STREAM_NAME",
+					"force_flush_interval": "5s",
+				},
+			},
+			"localfile": []interface{}{
+				map[string]interface{}{"alias": "debug", "target": "stdout"},
+				map[string]interface{}{"alias": "local", "target": "file", "file_path": "/tmp/events.log", "max_size_mb": 10, "compress": true},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual)
+	assert.Len(t, translator.ErrorMessages, 2)
+}
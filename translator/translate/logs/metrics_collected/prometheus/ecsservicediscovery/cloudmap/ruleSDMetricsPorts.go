@@ -0,0 +1,39 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudmap
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	SectionKeySDMetricsPorts = "sd_metrics_ports"
+	expectedRegex            = "^[1-9][0-9]{0,4}(;[\\s]*[1-9][0-9]{0,4})*$"
+)
+
+type SDMetricsPorts struct {
+}
+
+// Optional Key, the port the instances are registered with is used if not defined
+func (d *SDMetricsPorts) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if val, ok := im[SectionKeySDMetricsPorts]; !ok {
+		returnKey = ""
+		returnVal = ""
+	} else {
+		if ok, _ := regexp.MatchString(expectedRegex, val.(string)); !ok {
+			translator.AddErrorMessages(GetCurPath()+SectionKeySDMetricsPorts, fmt.Sprintf("sd_metrics_ports does not follow pattern: %v.", expectedRegex))
+		}
+		returnKey = SectionKeySDMetricsPorts
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeySDMetricsPorts, new(SDMetricsPorts))
+}
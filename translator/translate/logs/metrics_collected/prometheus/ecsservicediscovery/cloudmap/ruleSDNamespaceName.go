@@ -0,0 +1,33 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudmap
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	SectionKeySDNamespaceName = "sd_namespace_name"
+)
+
+type SDNamespaceName struct {
+}
+
+// Mandatory Key
+func (d *SDNamespaceName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if val, ok := im[SectionKeySDNamespaceName]; !ok {
+		returnKey = ""
+		returnVal = ""
+		translator.AddErrorMessages(GetCurPath()+SectionKeySDNamespaceName, "sd_namespace_name is not defined.")
+	} else {
+		returnKey = SectionKeySDNamespaceName
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeySDNamespaceName, new(SDNamespaceName))
+}
@@ -0,0 +1,35 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/cfg/commonconfig"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
+)
+
+// SDCredential passes an option of the agent credentials to the service discovery, which
+// queries the clusters, or assumes their roles, with the credentials it provides.
+type SDCredential struct {
+	key string
+}
+
+func (d *SDCredential) ApplyRule(_ interface{}) (returnKey string, returnVal interface{}) {
+	if val, ok := agent.Global_Config.Credentials[d.key]; ok {
+		returnKey = d.key
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	for _, key := range []string{
+		agent.Profile_Key,
+		agent.CredentialsFile_Key,
+		commonconfig.CredentialProcess,
+		commonconfig.WebIdentityToken,
+		commonconfig.WebIdentityRole,
+	} {
+		RegisterRule(key, &SDCredential{key: key})
+	}
+}
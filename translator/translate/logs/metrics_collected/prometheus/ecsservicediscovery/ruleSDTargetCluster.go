@@ -10,14 +10,21 @@ import (
 
 const (
 	SectionKeySDTargetCluster = "sd_target_cluster"
+
+	sectionKeySDTargetClusters    = "sd_target_clusters"
+	sectionKeyCloudMapServiceList = "cloud_map_service_list"
 )
 
 type SDTargetCluster struct {
 }
 
 func (d *SDTargetCluster) ApplyRule(input interface{}) (string, interface{}) {
-	clusterName := util.GetECSClusterName(SectionKeySDTargetCluster, input.(map[string]interface{}))
-	if clusterName == "" {
+	im := input.(map[string]interface{})
+	clusterName := util.GetECSClusterName(SectionKeySDTargetCluster, im)
+	_, hasTargetClusters := im[sectionKeySDTargetClusters]
+	_, hasCloudMapServices := im[sectionKeyCloudMapServiceList]
+	// The cluster is optional when the targets are discovered in the sd_target_clusters or in Cloud Map
+	if clusterName == "" && !hasTargetClusters && !hasCloudMapServices {
 		translator.AddErrorMessages(GetCurPath(), "ECS Target Cluster Name is not defined")
 	}
 	return SectionKeySDTargetCluster, clusterName
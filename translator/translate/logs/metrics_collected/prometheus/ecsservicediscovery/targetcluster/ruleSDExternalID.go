@@ -0,0 +1,28 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package targetcluster
+
+const (
+	SectionKeySDExternalID = "sd_external_id"
+)
+
+type SDExternalID struct {
+}
+
+// Optional Key
+func (d *SDExternalID) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if val, ok := im[SectionKeySDExternalID]; !ok {
+		returnKey = ""
+		returnVal = ""
+	} else {
+		returnKey = SectionKeySDExternalID
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeySDExternalID, new(SDExternalID))
+}
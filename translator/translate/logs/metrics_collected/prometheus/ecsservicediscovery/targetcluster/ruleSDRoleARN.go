@@ -0,0 +1,28 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package targetcluster
+
+const (
+	SectionKeySDRoleARN = "sd_role_arn"
+)
+
+type SDRoleARN struct {
+}
+
+// Optional Key
+func (d *SDRoleARN) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if val, ok := im[SectionKeySDRoleARN]; !ok {
+		returnKey = ""
+		returnVal = ""
+	} else {
+		returnKey = SectionKeySDRoleARN
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeySDRoleARN, new(SDRoleARN))
+}
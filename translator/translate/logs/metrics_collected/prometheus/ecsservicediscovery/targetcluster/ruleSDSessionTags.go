@@ -0,0 +1,28 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package targetcluster
+
+const (
+	SectionKeySDSessionTags = "sd_session_tags"
+)
+
+type SDSessionTags struct {
+}
+
+// Optional Key
+func (d *SDSessionTags) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if val, ok := im[SectionKeySDSessionTags]; !ok {
+		returnKey = ""
+		returnVal = ""
+	} else {
+		returnKey = SectionKeySDSessionTags
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeySDSessionTags, new(SDSessionTags))
+}
@@ -0,0 +1,33 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package targetcluster
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	SectionKeySDTargetCluster = "sd_target_cluster"
+)
+
+type SDTargetCluster struct {
+}
+
+// Mandatory Key
+func (d *SDTargetCluster) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	if val, ok := im[SectionKeySDTargetCluster]; !ok {
+		returnKey = ""
+		returnVal = ""
+		translator.AddErrorMessages(GetCurPath()+SectionKeySDTargetCluster, "sd_target_cluster is not defined.")
+	} else {
+		returnKey = SectionKeySDTargetCluster
+		returnVal = val
+	}
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeySDTargetCluster, new(SDTargetCluster))
+}
@@ -0,0 +1,49 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package targetcluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+func TestTargetCluster(t *testing.T) {
+	translator.ResetMessages()
+	input := map[string]interface{}{
+		SubSectionKey: []interface{}{
+			map[string]interface{}{
+				SectionKeySDTargetCluster: "cluster-a",
+				SectionKeySDClusterRegion: "us-west-2",
+				SectionKeySDRoleARN:       "arn:aws:iam::123456789012:role/monitoring",
+				SectionKeySDExternalID:    "external-id",
+				SectionKeySDSessionTags:   map[string]interface{}{"team": "monitoring"},
+			},
+			map[string]interface{}{
+				SectionKeySDClusterRegion: "us-east-1",
+			},
+		},
+	}
+	key, val := new(TargetCluster).ApplyRule(input)
+	assert.Equal(t, SubSectionKey, key)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			SectionKeySDTargetCluster: "cluster-a",
+			SectionKeySDClusterRegion: "us-west-2",
+			SectionKeySDRoleARN:       "arn:aws:iam::123456789012:role/monitoring",
+			SectionKeySDExternalID:    "external-id",
+			SectionKeySDSessionTags:   map[string]interface{}{"team": "monitoring"},
+		},
+		map[string]interface{}{
+			SectionKeySDClusterRegion: "us-east-1",
+		},
+	}, val)
+	// the cluster name is mandatory
+	assert.Len(t, translator.ErrorMessages, 1)
+
+	key, _ = new(TargetCluster).ApplyRule(map[string]interface{}{})
+	assert.Equal(t, "", key)
+}
@@ -0,0 +1,25 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sannotationdiscovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestK8sAnnotationDiscovery(t *testing.T) {
+	k := new(K8sAnnotationDiscovery)
+	key, val := k.ApplyRule(map[string]interface{}{
+		SubSectionKey: map[string]interface{}{SectionKeySDFrequency: "30s"},
+	})
+	assert.Equal(t, SubSectionKey, key)
+	assert.Equal(t, map[string]interface{}{
+		SectionKeySDFrequency:  "30s",
+		SectionKeySDResultFile: defaultPath,
+	}, val)
+
+	key, _ = k.ApplyRule(map[string]interface{}{})
+	assert.Equal(t, "", key)
+}
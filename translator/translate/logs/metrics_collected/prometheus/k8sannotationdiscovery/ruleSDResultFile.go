@@ -0,0 +1,24 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8sannotationdiscovery
+
+import "github.com/aws/amazon-cloudwatch-agent/translator"
+
+const (
+	SectionKeySDResultFile = "sd_result_file"
+
+	defaultPath = "/tmp/cwagent_k8s_annotation_sd.yaml"
+)
+
+type SDResultFile struct {
+}
+
+func (d *SDResultFile) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	returnKey, returnVal = translator.DefaultCase(SectionKeySDResultFile, defaultPath, input)
+	return
+}
+
+func init() {
+	RegisterRule(SectionKeySDResultFile, new(SDResultFile))
+}
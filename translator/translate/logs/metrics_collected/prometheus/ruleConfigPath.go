@@ -50,6 +50,13 @@ func getDownloadPath() string {
 }
 
 func (obj *ConfigPath) ApplyRule(input interface{}) (string, interface{}) {
+	im := input.(map[string]interface{})
+	if _, ok := im[SectionKeyConfigPath]; !ok {
+		if _, ok := im[SectionKeyRemoteWrite]; ok {
+			// Only the metrics pushed with remote write are collected, there is nothing to scrape.
+			return "", nil
+		}
+	}
 
 	_, returnVal := translator.DefaultCase(SectionKeyConfigPath, defaultLinuxPath, input)
 	configPath := returnVal.(string)
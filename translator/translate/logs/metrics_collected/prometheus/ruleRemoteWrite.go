@@ -0,0 +1,37 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	SectionKeyRemoteWrite = "remote_write"
+
+	SectionKeyServiceAddress = "service_address"
+	SectionKeyPath           = "path"
+
+	defaultRemoteWriteServiceAddress = "127.0.0.1:9201"
+	defaultRemoteWritePath           = "/api/v1/write"
+)
+
+type RemoteWrite struct {
+}
+
+func (r *RemoteWrite) ApplyRule(input interface{}) (string, interface{}) {
+	im := input.(map[string]interface{})
+	remoteWrite, ok := im[SectionKeyRemoteWrite].(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+	result := map[string]interface{}{}
+	_, result[SectionKeyServiceAddress] = translator.DefaultCase(SectionKeyServiceAddress, defaultRemoteWriteServiceAddress, remoteWrite)
+	_, result[SectionKeyPath] = translator.DefaultCase(SectionKeyPath, defaultRemoteWritePath, remoteWrite)
+	return SectionKeyRemoteWrite, result
+}
+
+func init() {
+	RegisterRule(SectionKeyRemoteWrite, new(RemoteWrite))
+}
@@ -0,0 +1,54 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteWrite(t *testing.T) {
+	testCases := map[string]struct {
+		input          string
+		wantKey        string
+		wantVal        interface{}
+		wantConfigPath interface{}
+	}{
+		"WithoutRemoteWrite": {
+			input:          `{}`,
+			wantConfigPath: defaultLinuxPath,
+		},
+		"WithDefaults": {
+			input:   `{"remote_write": {}}`,
+			wantKey: SectionKeyRemoteWrite,
+			wantVal: map[string]interface{}{
+				"service_address": "127.0.0.1:9201",
+				"path":            "/api/v1/write",
+			},
+		},
+		"WithConfigPath": {
+			input:   `{"prometheus_config_path": "/tmp/prometheus.yaml", "remote_write": {"service_address": "127.0.0.1:9090", "path": "/receive"}}`,
+			wantKey: SectionKeyRemoteWrite,
+			wantVal: map[string]interface{}{
+				"service_address": "127.0.0.1:9090",
+				"path":            "/receive",
+			},
+			wantConfigPath: "/tmp/prometheus.yaml",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var input interface{}
+			require.NoError(t, json.Unmarshal([]byte(testCase.input), &input))
+			key, val := new(RemoteWrite).ApplyRule(input)
+			assert.Equal(t, testCase.wantKey, key)
+			assert.Equal(t, testCase.wantVal, val)
+			_, configPath := new(ConfigPath).ApplyRule(input)
+			assert.Equal(t, testCase.wantConfigPath, configPath)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/config"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
+	"github.com/aws/amazon-cloudwatch-agent/translator/util"
+)
+
+const (
+	DestinationsSectionKey = "destinations"
+	Output_Local_File      = "localfile"
+	aliasKey               = "alias"
+	destinationTypeKey     = "type"
+	localFileTargetKey     = "target"
+)
+
+var (
+	destinationTargetList = []string{agent.RegionKey, "endpoint_override"}
+	localFileTargetList   = []string{"file_path", "max_size_mb", "max_backups", "max_age_days", "compress"}
+	// The local file settings that are integers in the localfile output.
+	localFileIntegerKeys = []string{"max_size_mb", "max_backups", "max_age_days"}
+)
+
+type Destinations struct {
+}
+
+// ApplyRule returns the outputs of the additional log destinations, keyed by the output
+// plugin. A cloudwatchlogs destination is a copy of the cloudwatchlogs output with the
+// settings overridden, so the log files can publish to a log group in another region or
+// account. A file or stdout destination writes the log events locally instead. The name
+// of the destination is the alias of its output.
+func (d *Destinations) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	destinations, ok := im[DestinationsSectionKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	cloudwatchLogs := map[string]interface{}{}
+	localFiles := map[string]interface{}{}
+	for name, destination := range destinations {
+		destinationMap, ok := destination.(map[string]interface{})
+		if !ok || name == Output_Cloudwatch_Logs || name == Output_Local_File {
+			translator.AddErrorMessages(GetCurPath()+DestinationsSectionKey, fmt.Sprintf("Destination %s is invalid", name))
+			continue
+		}
+		switch destinationType, _ := destinationMap[destinationTypeKey].(string); destinationType {
+		case "", Output_Cloudwatch_Logs:
+			overrides := map[string]interface{}{aliasKey: name}
+			util.SetWithSameKeyIfFound(destinationMap, destinationTargetList, overrides)
+			if _, ok := overrides[agent.RegionKey]; ok {
+				overrides[agent.RegionType] = config.RegionTypeAgentConfigJson
+			}
+			if creds, ok := destinationMap[CredentialsSectionKey].(map[string]interface{}); ok {
+				util.SetWithSameKeyIfFound(creds, credsTargetList, overrides)
+			}
+			cloudwatchLogs[name] = overrides
+		case "file", "stdout":
+			if _, ok := destinationMap["file_path"]; !ok && destinationType == "file" {
+				translator.AddErrorMessages(GetCurPath()+DestinationsSectionKey, fmt.Sprintf("Destination %s has no file_path", name))
+				continue
+			}
+			localFile := map[string]interface{}{aliasKey: name, localFileTargetKey: destinationType}
+			util.SetWithSameKeyIfFound(destinationMap, localFileTargetList, localFile)
+			for _, key := range localFileIntegerKeys {
+				if v, ok := localFile[key].(float64); ok {
+					localFile[key] = int(v)
+				}
+			}
+			localFiles[name] = localFile
+		default:
+			translator.AddErrorMessages(GetCurPath()+DestinationsSectionKey, fmt.Sprintf("Destination %s has an invalid type %s", name, destinationType))
+		}
+	}
+	res := map[string]map[string]interface{}{}
+	if len(cloudwatchLogs) > 0 {
+		res[Output_Cloudwatch_Logs] = cloudwatchLogs
+	}
+	if len(localFiles) > 0 {
+		res[Output_Local_File] = localFiles
+	}
+	if len(res) == 0 {
+		return
+	}
+	returnKey = DestinationsSectionKey
+	returnVal = res
+	return
+}
+
+// destinationOutputs returns the cloudwatchlogs outputs of the additional destinations,
+// sorted by name so the translation is stable. The spilled requests of each destination
+// are kept apart from the others. The redaction is left to the first cloudwatchlogs
+// output, which applies it once to the events of every destination.
+func destinationOutputs(cloudwatchConfig map[string]interface{}, destinations map[string]interface{}) []interface{} {
+	var outputs []interface{}
+	for _, name := range sortedKeys(destinations) {
+		output := map[string]interface{}{}
+		for k, v := range cloudwatchConfig {
+			if k == RedactionSectionKey {
+				continue
+			}
+			output[k] = v
+		}
+		for k, v := range destinations[name].(map[string]interface{}) {
+			output[k] = v
+		}
+		if dir, ok := output[spillDirectoryKey].(string); ok {
+			output[spillDirectoryKey] = destinationSpillDirectory(dir, name)
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+// localFileOutputs returns the localfile outputs of the local destinations, sorted by name.
+func localFileOutputs(destinations map[string]interface{}) []interface{} {
+	var outputs []interface{}
+	for _, name := range sortedKeys(destinations) {
+		outputs = append(outputs, destinations[name])
+	}
+	return outputs
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	RegisterRule(DestinationsSectionKey, new(Destinations))
+}
@@ -16,7 +16,7 @@ const (
 	CredentialsSectionKey = "credentials"
 )
 
-var credsTargetList = []string{Role_Arn_Key}
+var credsTargetList = []string{Role_Arn_Key, agent.External_Id_Key, agent.Session_Tags_Key}
 
 func (c *LogCreds) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
 	result := map[string]interface{}{}
@@ -24,6 +24,12 @@ func (c *LogCreds) ApplyRule(input interface{}) (returnKey string, returnVal int
 	if agent.Global_Config.Role_arn != "" {
 		result[Role_Arn_Key] = agent.Global_Config.Role_arn
 	}
+	if agent.Global_Config.External_id != "" {
+		result[agent.External_Id_Key] = agent.Global_Config.External_id
+	}
+	if len(agent.Global_Config.Session_tags) > 0 {
+		result[agent.Session_Tags_Key] = agent.Global_Config.Session_tags
+	}
 
 	// Read fromm Json first.
 	if val, ok := input.(map[string]interface{})[CredentialsSectionKey]; ok {
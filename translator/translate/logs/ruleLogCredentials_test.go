@@ -47,3 +47,24 @@ func TestWithAgentConfig(t *testing.T) {
 
 	agent.Global_Config.Role_arn = ""
 }
+
+func TestWithAssumeRoleOptions(t *testing.T) {
+	agent.Global_Config.Role_arn = "global_role_arn_test"
+	agent.Global_Config.External_id = "global_external_id_test"
+	agent.Global_Config.Session_tags = map[string]interface{}{"team": "platform"}
+	defer func() {
+		agent.Global_Config.Role_arn = ""
+		agent.Global_Config.External_id = ""
+		agent.Global_Config.Session_tags = nil
+	}()
+	c := new(LogCreds)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{ "credentials" : {"role_arn": "role_value", "external_id": "external_value"}}`), &input)
+	assert.NoError(t, err)
+	_, returnVal := c.ApplyRule(input)
+	assert.Equal(t, map[string]interface{}{
+		"role_arn":     "role_value",
+		"external_id":  "external_value",
+		"session_tags": map[string]interface{}{"team": "platform"},
+	}, returnVal)
+}
@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	RedactionSectionKey = "redaction"
+	redactionRulesKey   = "rules"
+)
+
+var redactionRuleKeys = []string{"detector", "expression", "action", "mask_value"}
+
+type Redaction struct {
+}
+
+// ApplyRule redacts sensitive data from the log events before they are published.
+func (r *Redaction) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	redaction, ok := im[RedactionSectionKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	rules, _ := redaction[redactionRulesKey].([]interface{})
+	var res []interface{}
+	for _, rule := range rules {
+		ruleMap, ok := rule.(map[string]interface{})
+		if !ok {
+			translator.AddErrorMessages(GetCurPath()+RedactionSectionKey, fmt.Sprintf("Redaction rule %v is invalid", rule))
+			continue
+		}
+		if expression, ok := ruleMap["expression"].(string); ok {
+			if _, err := regexp.Compile(expression); err != nil {
+				translator.AddErrorMessages(GetCurPath()+RedactionSectionKey, fmt.Sprintf("Redaction expression %s is invalid", expression))
+				continue
+			}
+		}
+		translated := map[string]interface{}{}
+		for _, key := range redactionRuleKeys {
+			if val, ok := ruleMap[key]; ok {
+				translated[key] = val
+			}
+		}
+		res = append(res, translated)
+	}
+	if len(res) == 0 {
+		return
+	}
+	returnKey = Output_Cloudwatch_Logs
+	returnVal = map[string]interface{}{
+		RedactionSectionKey: map[string]interface{}{
+			redactionRulesKey: res,
+		},
+	}
+	return
+}
+
+func init() {
+	RegisterRule(RedactionSectionKey, new(Redaction))
+}
@@ -0,0 +1,52 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/config"
+	logUtil "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/util"
+)
+
+const (
+	SpillSectionKey   = "spill"
+	spillDirectoryKey = "spill_directory"
+	defaultSpillMB    = 100
+)
+
+type Spill struct {
+}
+
+// ApplyRule persists the requests that could not be delivered to a size limited folder.
+func (s *Spill) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	spill, ok := im[SpillSectionKey]
+	if !ok {
+		return
+	}
+	res := map[string]interface{}{}
+	_, dir := translator.DefaultCase("directory", logUtil.GetSpillFolder(), spill)
+	res[spillDirectoryKey] = dir
+	_, maxSizeMB := translator.DefaultIntegralCase("max_size_mb", float64(defaultSpillMB), spill)
+	if mb, ok := maxSizeMB.(int); ok {
+		res["spill_max_size"] = int64(mb) * 1024 * 1024
+	}
+	returnKey = Output_Cloudwatch_Logs
+	returnVal = res
+	return
+}
+
+// destinationSpillDirectory returns the spill folder of an additional destination. Each
+// destination spills to a folder of its own, since the queues are named after the log
+// group and stream only and the destinations can share them across regions or accounts.
+func destinationSpillDirectory(dir string, name string) string {
+	if translator.GetTargetPlatform() == config.OS_TYPE_WINDOWS {
+		return dir + "\\" + name
+	}
+	return dir + "/" + name
+}
+
+func init() {
+	RegisterRule(SpillSectionKey, new(Spill))
+}
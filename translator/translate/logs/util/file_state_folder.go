@@ -19,3 +19,14 @@ func GetFileStateFolder() (fileStateFolder string) {
 	}
 	return
 }
+
+const Spill_Folder_Linux = "/opt/aws/amazon-cloudwatch-agent/logs/spill"
+
+func GetSpillFolder() (spillFolder string) {
+	if translator.GetTargetPlatform() == config.OS_TYPE_WINDOWS {
+		spillFolder = util.GetWindowsProgramDataPath() + "\\Amazon\\AmazonCloudWatchAgent\\Logs\\spill"
+	} else {
+		spillFolder = Spill_Folder_Linux
+	}
+	return
+}
@@ -0,0 +1,30 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+type EventsLogGroupName struct {
+}
+
+const SectionKey_EventsLogGroupName = "events_log_group_name"
+
+func (obj *EventsLogGroupName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	key, val := translator.DefaultCase(SectionKey_EventsLogGroupName, "", input)
+	if val == "" {
+		return
+	}
+	returnKey = key
+	returnVal = util.ResolvePlaceholder(val.(string), logs.GlobalLogConfig.MetadataInfo)
+	return
+}
+
+func init() {
+	obj := new(EventsLogGroupName)
+	RegisterRule(SectionKey_EventsLogGroupName, obj)
+}
@@ -0,0 +1,31 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+type EventsLogStreamName struct {
+}
+
+const SectionKey_EventsLogStreamName = "events_log_stream_name"
+
+// ApplyRule defaults the log stream to the instance id when the events are published.
+func (obj *EventsLogStreamName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	if _, group := translator.DefaultCase(SectionKey_EventsLogGroupName, "", input); group == "" {
+		return
+	}
+	key, val := translator.DefaultCase(SectionKey_EventsLogStreamName, "", input)
+	returnKey = key
+	returnVal = util.ResolvePlaceholder(val.(string), logs.GlobalLogConfig.MetadataInfo)
+	return
+}
+
+func init() {
+	obj := new(EventsLogStreamName)
+	RegisterRule(SectionKey_EventsLogStreamName, obj)
+}
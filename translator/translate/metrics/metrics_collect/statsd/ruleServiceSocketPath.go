@@ -0,0 +1,26 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+type ServiceSocketPath struct {
+}
+
+const SectionKey_ServiceSocketPath = "service_socket_path"
+
+func (obj *ServiceSocketPath) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	key, val := translator.DefaultCase(SectionKey_ServiceSocketPath, "", input)
+	if val != "" {
+		return key, val
+	}
+	return
+}
+
+func init() {
+	obj := new(ServiceSocketPath)
+	RegisterRule(SectionKey_ServiceSocketPath, obj)
+}
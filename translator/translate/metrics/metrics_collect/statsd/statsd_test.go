@@ -100,3 +100,31 @@ func TestStatsD_MetricSeparator(t *testing.T) {
 
 	assert.Equal(t, expect, actual)
 }
+
+func TestStatsD_ListenersAndEvents(t *testing.T) {
+	obj := new(StatsD)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"statsd": {
+					"tcp_service_address": ":8126",
+					"service_socket_path": "/var/run/statsd.sock",
+					"events_log_group_name": "statsd-events"
+					}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	expect := []interface{}{
+		map[string]interface{}{
+			"service_address":        ":8125",
+			"tcp_service_address":    ":8126",
+			"service_socket_path":    "/var/run/statsd.sock",
+			"events_log_group_name":  "statsd-events",
+			"events_log_stream_name": "{instance_id}",
+			"interval":               "10s",
+			"parse_data_dog_tags":    true,
+			"tags":                   map[string]interface{}{"aws:AggregationInterval": "60s"},
+		},
+	}
+
+	assert.Equal(t, expect, actual)
+}
@@ -30,6 +30,8 @@ const (
 	PrometheusKey                      = "prometheus"
 	EMFProcessorKey                    = "emf_processor"
 	DisableMetricExtraction            = "disable_metric_extraction"
+	MetricsDestinationKey              = "metrics_destination"
+	MetricsDestinationCloudWatch       = "cloudwatch"
 	XrayKey                            = "xray"
 	OtlpKey                            = "otlp"
 	JmxKey                             = "jmx"
@@ -42,6 +44,8 @@ const (
 	LocalModeKey                       = "local_mode"
 	CredentialsKey                     = "credentials"
 	RoleARNKey                         = "role_arn"
+	ExternalIDKey                      = "external_id"
+	SessionTagsKey                     = "session_tags"
 	MetricsCollectionIntervalKey       = "metrics_collection_interval"
 	MeasurementKey                     = "measurement"
 	DropOriginalMetricsKey             = "drop_original_metrics"
@@ -49,6 +53,7 @@ const (
 	ContainerInsightsMetricGranularity = "metric_granularity" // replaced with enhanced_container_insights
 	EnhancedContainerInsights          = "enhanced_container_insights"
 	PreferFullPodName                  = "prefer_full_pod_name"
+	PromotedAttributesKey              = "promoted_attributes"
 	EnableAcceleratedComputeMetric     = "accelerated_compute_metrics"
 	AppendDimensionsKey                = "append_dimensions"
 	Console                            = "console"
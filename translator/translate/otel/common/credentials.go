@@ -0,0 +1,30 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/aws/amazon-cloudwatch-agent/cfg/commonconfig"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
+)
+
+// UnsupportedSessionCredentials returns the credential options, of the agent or of the credentials
+// section at credentialsKey, which the AWS session settings of the OpenTelemetry components cannot
+// represent. These components ignore them.
+func UnsupportedSessionCredentials(conf *confmap.Conf, credentialsKey string) []string {
+	var options []string
+	for _, key := range []string{commonconfig.CredentialProcess, commonconfig.WebIdentityToken} {
+		if _, ok := agent.Global_Config.Credentials[key]; ok {
+			options = append(options, key)
+		}
+	}
+	if agent.Global_Config.External_id != "" || (conf != nil && conf.IsSet(ConfigKey(credentialsKey, ExternalIDKey))) {
+		options = append(options, ExternalIDKey)
+	}
+	if len(agent.Global_Config.Session_tags) > 0 || (conf != nil && conf.IsSet(ConfigKey(credentialsKey, SessionTagsKey))) {
+		options = append(options, SessionTagsKey)
+	}
+	return options
+}
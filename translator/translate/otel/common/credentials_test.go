@@ -0,0 +1,43 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
+)
+
+func TestUnsupportedSessionCredentials(t *testing.T) {
+	t.Cleanup(func() {
+		agent.Global_Config.Credentials = nil
+		agent.Global_Config.External_id = ""
+		agent.Global_Config.Session_tags = nil
+	})
+	credentialsKey := ConfigKey(TracesKey, CredentialsKey)
+	conf := confmap.NewFromStringMap(map[string]interface{}{
+		"traces": map[string]interface{}{
+			"credentials": map[string]interface{}{"role_arn": "arn"},
+		},
+	})
+	agent.Global_Config.Credentials = map[string]interface{}{"profile": "default"}
+	assert.Empty(t, UnsupportedSessionCredentials(conf, credentialsKey))
+
+	conf = confmap.NewFromStringMap(map[string]interface{}{
+		"traces": map[string]interface{}{
+			"credentials": map[string]interface{}{"external_id": "id"},
+		},
+	})
+	assert.Equal(t, []string{"external_id"}, UnsupportedSessionCredentials(conf, credentialsKey))
+
+	agent.Global_Config.Credentials = map[string]interface{}{
+		"credential_process":      "/usr/bin/credentials",
+		"web_identity_token_file": "/var/run/token",
+	}
+	agent.Global_Config.Session_tags = map[string]interface{}{"team": "platform"}
+	assert.Equal(t, []string{"credential_process", "web_identity_token_file", "external_id", "session_tags"}, UnsupportedSessionCredentials(conf, credentialsKey))
+}
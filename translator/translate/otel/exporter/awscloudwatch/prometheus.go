@@ -0,0 +1,95 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awscloudwatch
+
+import (
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/outputs/cloudwatch"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/exporter/awsemf"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/extension/agenthealth"
+)
+
+var (
+	prometheusKey             = common.ConfigKey(common.LogsKey, common.MetricsCollectedKey, common.PrometheusKey)
+	prometheusDeclarationsKey = common.ConfigKey(prometheusKey, common.EMFProcessorKey, "metric_declaration")
+)
+
+// translatePrometheus creates the config of the exporter publishing the prometheus metrics
+// with PutMetricData instead of EMF logs. The metric declarations of the emf_processor select
+// the metrics and their dimensions the same way they do for EMF.
+func (t *translator) translatePrometheus(conf *confmap.Conf) (component.Config, error) {
+	if conf == nil || !conf.IsSet(prometheusKey) {
+		return nil, &common.MissingKeyError{ID: t.ID(), JsonKey: prometheusKey}
+	}
+	cfg := t.factory.CreateDefaultConfig().(*cloudwatch.Config)
+	credentials := confmap.NewFromStringMap(agent.Global_Config.Credentials)
+	_ = credentials.Unmarshal(cfg)
+	cfg.RoleARN = agent.Global_Config.Role_arn
+	if roleARN, ok := common.GetString(conf, common.ConfigKey(common.LogsKey, common.CredentialsKey, common.RoleARNKey)); ok {
+		cfg.RoleARN = roleARN
+	}
+	setAssumeRoleOptions(cfg, conf, common.ConfigKey(common.LogsKey, common.CredentialsKey))
+	cfg.Region = agent.Global_Config.Region
+	cfg.Namespace = awsemf.PrometheusNamespace(conf)
+	cfg.MetricDeclarations = getPrometheusMetricDeclarations(conf)
+	if len(cfg.MetricDeclarations) == 0 {
+		// Like for EMF, no metric is published without metric declarations.
+		cfg.MetricDeclarations = []*cloudwatch.MetricDeclaration{{MetricNameSelectors: []string{"$^"}}}
+	}
+	cfg.MiddlewareID = &agenthealth.MetricsID
+	return cfg, nil
+}
+
+// getPrometheusMetricDeclarations skips the declarations without metric selectors or source
+// labels, which are invalid for EMF.
+func getPrometheusMetricDeclarations(conf *confmap.Conf) []*cloudwatch.MetricDeclaration {
+	raw, ok := conf.Get(prometheusDeclarationsKey).([]interface{})
+	if !ok {
+		return nil
+	}
+	var declarations []*cloudwatch.MetricDeclaration
+	for _, md := range raw {
+		metricDeclaration, ok := md.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		metricSelectors := toStrings(metricDeclaration["metric_selectors"])
+		sourceLabels := toStrings(metricDeclaration["source_labels"])
+		if len(metricSelectors) == 0 || len(sourceLabels) == 0 {
+			continue
+		}
+		declaration := &cloudwatch.MetricDeclaration{
+			MetricNameSelectors: metricSelectors,
+			SourceLabels:        sourceLabels,
+		}
+		if labelMatcher, ok := metricDeclaration["label_matcher"].(string); ok {
+			declaration.LabelMatcher = labelMatcher
+		}
+		if dimensions, ok := metricDeclaration["dimensions"].([]interface{}); ok {
+			for _, set := range dimensions {
+				declaration.Dimensions = append(declaration.Dimensions, toStrings(set))
+			}
+		}
+		declarations = append(declarations, declaration)
+	}
+	return declarations
+}
+
+func toStrings(raw interface{}) []string {
+	values, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -4,6 +4,7 @@
 package awscloudwatch
 
 import (
+	"fmt"
 	"strings"
 
 	"go.opentelemetry.io/collector/component"
@@ -12,16 +13,23 @@ import (
 
 	"github.com/aws/amazon-cloudwatch-agent/internal/metric"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/outputs/cloudwatch"
+	translatorconfig "github.com/aws/amazon-cloudwatch-agent/translator/config"
+	translatorcontext "github.com/aws/amazon-cloudwatch-agent/translator/context"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
+	logUtil "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/util"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/config"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/rollup_dimensions"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/extension/agenthealth"
+	adaptertranslator "github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/receiver/adapter"
 )
 
 const (
 	namespaceKey          = "namespace"
 	forceFlushIntervalKey = "force_flush_interval"
+	spillKey              = "spill"
+	cardinalityLimitKey   = "cardinality_limit"
+	derivedMetricsKey     = "derived_metrics"
 	dropOriginalWildcard  = "*"
 
 	internalMaxValuesPerDatum = 5000
@@ -54,16 +62,21 @@ func (t *translator) ID() component.ID {
 }
 
 // Translate creates an exporter config based on the fields in the
-// metrics section of the JSON config.
+// metrics section of the JSON config. The metrics of the log metric filters
+// are exported with the defaults when there is no metrics section.
 // TODO: remove dependency on global config.
 func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
-	if conf == nil || !conf.IsSet(common.MetricsKey) {
+	if t.name == common.PrometheusKey {
+		return t.translatePrometheus(conf)
+	}
+	if conf == nil || (!conf.IsSet(common.MetricsKey) && !adaptertranslator.HasLogMetricFilters(conf)) {
 		return nil, &common.MissingKeyError{ID: t.ID(), JsonKey: common.MetricsKey}
 	}
 	cfg := t.factory.CreateDefaultConfig().(*cloudwatch.Config)
 	credentials := confmap.NewFromStringMap(agent.Global_Config.Credentials)
 	_ = credentials.Unmarshal(cfg)
 	cfg.RoleARN = getRoleARN(conf)
+	setAssumeRoleOptions(cfg, conf, common.ConfigKey(common.MetricsKey, common.CredentialsKey))
 	cfg.Region = agent.Global_Config.Region
 	if namespace, ok := common.GetString(conf, common.ConfigKey(common.MetricsKey, namespaceKey)); ok {
 		cfg.Namespace = namespace
@@ -83,6 +96,22 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	if dropOriginalMetrics := getDropOriginalMetrics(conf); len(dropOriginalMetrics) != 0 {
 		cfg.DropOriginalConfigs = dropOriginalMetrics
 	}
+	if spillKey := common.ConfigKey(common.MetricsKey, spillKey); conf.IsSet(spillKey) {
+		cfg.SpillDirectory = getSpillDirectory(conf, spillKey)
+		if maxSizeMB, ok := common.GetNumber(conf, common.ConfigKey(spillKey, "max_size_mb")); ok {
+			cfg.SpillMaxSize = int64(maxSizeMB) * 1024 * 1024
+		}
+	}
+	if cardinalityLimitKey := common.ConfigKey(common.MetricsKey, cardinalityLimitKey); conf.IsSet(cardinalityLimitKey) {
+		cfg.CardinalityLimit = getCardinalityLimit(conf, cardinalityLimitKey)
+	}
+	if derivedMetricsKey := common.ConfigKey(common.MetricsKey, derivedMetricsKey); conf.IsSet(derivedMetricsKey) {
+		derivedMetrics, err := getDerivedMetrics(conf, derivedMetricsKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal %s: %w", derivedMetricsKey, err)
+		}
+		cfg.DerivedMetrics = derivedMetrics
+	}
 	cfg.MiddlewareID = &agenthealth.MetricsID
 	return cfg, nil
 }
@@ -96,6 +125,64 @@ func getRoleARN(conf *confmap.Conf) string {
 	return roleARN
 }
 
+// setAssumeRoleOptions sets the external ID and the session tags of the assumed role from the
+// credentials section, or from the agent credentials by default.
+func setAssumeRoleOptions(cfg *cloudwatch.Config, conf *confmap.Conf, credentialsKey string) {
+	cfg.ExternalID = agent.Global_Config.External_id
+	if externalID, ok := common.GetString(conf, common.ConfigKey(credentialsKey, common.ExternalIDKey)); ok {
+		cfg.ExternalID = externalID
+	}
+	sessionTags := agent.Global_Config.Session_tags
+	if tags, ok := conf.Get(common.ConfigKey(credentialsKey, common.SessionTagsKey)).(map[string]any); ok {
+		sessionTags = tags
+	}
+	if len(sessionTags) == 0 {
+		return
+	}
+	cfg.SessionTags = make(map[string]string, len(sessionTags))
+	for k, v := range sessionTags {
+		cfg.SessionTags[k] = fmt.Sprint(v)
+	}
+}
+
+// getSpillDirectory returns the configured directory, or a metrics folder in the
+// spill folder of the logs by default.
+func getSpillDirectory(conf *confmap.Conf, spillKey string) string {
+	if directory, ok := common.GetString(conf, common.ConfigKey(spillKey, "directory")); ok {
+		return directory
+	}
+	if translatorcontext.CurrentContext().Os() == translatorconfig.OS_TYPE_WINDOWS {
+		return logUtil.GetSpillFolder() + "\\metrics"
+	}
+	return logUtil.GetSpillFolder() + "/metrics"
+}
+
+func getCardinalityLimit(conf *confmap.Conf, cardinalityLimitKey string) *cloudwatch.CardinalityLimitConfig {
+	cfg := &cloudwatch.CardinalityLimitConfig{}
+	if maxSeries, ok := common.GetNumber(conf, common.ConfigKey(cardinalityLimitKey, "max_series_per_metric")); ok {
+		cfg.MaxSeriesPerMetric = int(maxSeries)
+	}
+	if action, ok := common.GetString(conf, common.ConfigKey(cardinalityLimitKey, "action")); ok {
+		cfg.Action = action
+	}
+	if rotationInterval, ok := common.GetDuration(conf, common.ConfigKey(cardinalityLimitKey, "rotation_interval")); ok {
+		cfg.RotationInterval = rotationInterval
+	}
+	return cfg
+}
+
+func getDerivedMetrics(conf *confmap.Conf, derivedMetricsKey string) (map[string]*cloudwatch.DerivedMetricConfig, error) {
+	sub, err := conf.Sub(derivedMetricsKey)
+	if err != nil {
+		return nil, err
+	}
+	var derivedMetrics map[string]*cloudwatch.DerivedMetricConfig
+	if err = sub.Unmarshal(&derivedMetrics); err != nil {
+		return nil, err
+	}
+	return derivedMetrics, nil
+}
+
 // TODO: remove dependency on rule.
 func getRollupDimensions(conf *confmap.Conf) [][]string {
 	key := common.ConfigKey(common.MetricsKey, rollup_dimensions.SectionKey)
@@ -105,6 +105,107 @@ func TestTranslator(t *testing.T) {
 				SharedCredentialFilename: "shared",
 			},
 		},
+		"WithCredentialSources": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{}},
+			credentials: map[string]interface{}{
+				"credential_process":      "/usr/bin/credentials",
+				"web_identity_token_file": "/var/run/token",
+				"web_identity_role_arn":   "web_identity_arn",
+			},
+			want: &cloudwatch.Config{
+				Namespace:            "CWAgent",
+				Region:               "us-east-1",
+				ForceFlushInterval:   time.Minute,
+				MaxValuesPerDatum:    150,
+				RoleARN:              "global_arn",
+				CredentialProcess:    "/usr/bin/credentials",
+				WebIdentityTokenFile: "/var/run/token",
+				WebIdentityRoleARN:   "web_identity_arn",
+			},
+		},
+		"WithSpill": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"spill": map[string]interface{}{
+					"max_size_mb": 10,
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				SpillDirectory:     "/opt/aws/amazon-cloudwatch-agent/logs/spill/metrics",
+				SpillMaxSize:       10 * 1024 * 1024,
+			},
+		},
+		"WithSpillDirectory": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"spill": map[string]interface{}{
+					"directory": "/tmp/spill",
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				SpillDirectory:     "/tmp/spill",
+			},
+		},
+		"WithCardinalityLimit": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"cardinality_limit": map[string]interface{}{
+					"max_series_per_metric": 500,
+					"action":                "strip",
+					"rotation_interval":     "30m",
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				CardinalityLimit: &cloudwatch.CardinalityLimitConfig{
+					MaxSeriesPerMetric: 500,
+					Action:             "strip",
+					RotationInterval:   30 * time.Minute,
+				},
+			},
+		},
+		"WithDerivedMetrics": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"derived_metrics": map[string]interface{}{
+					"latency": map[string]interface{}{
+						"percentiles":   []interface{}{50.0, 99.9},
+						"statistics":    []interface{}{"max", "count"},
+						"drop_original": true,
+					},
+					"requests": map[string]interface{}{
+						"rate": true,
+					},
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				DerivedMetrics: map[string]*cloudwatch.DerivedMetricConfig{
+					"latency": {
+						Percentiles:  []float64{50, 99.9},
+						Statistics:   []string{"max", "count"},
+						DropOriginal: true,
+					},
+					"requests": {
+						Rate: true,
+					},
+				},
+			},
+		},
 		"WithInternal": {
 			input:    getJson(t, filepath.Join("testdata", "config.json")),
 			internal: true,
@@ -163,8 +264,17 @@ func TestTranslator(t *testing.T) {
 				assert.Equal(t, testCase.want.Token, gotCfg.Token)
 				assert.Equal(t, testCase.want.Profile, gotCfg.Profile)
 				assert.Equal(t, testCase.want.SharedCredentialFilename, gotCfg.SharedCredentialFilename)
+				assert.Equal(t, testCase.want.CredentialProcess, gotCfg.CredentialProcess)
+				assert.Equal(t, testCase.want.WebIdentityTokenFile, gotCfg.WebIdentityTokenFile)
+				assert.Equal(t, testCase.want.WebIdentityRoleARN, gotCfg.WebIdentityRoleARN)
 				assert.Equal(t, testCase.want.MaxValuesPerDatum, gotCfg.MaxValuesPerDatum)
 				assert.Equal(t, testCase.want.RollupDimensions, gotCfg.RollupDimensions)
+				assert.Equal(t, testCase.want.SpillMaxSize, gotCfg.SpillMaxSize)
+				assert.Equal(t, testCase.want.CardinalityLimit, gotCfg.CardinalityLimit)
+				assert.Equal(t, testCase.want.DerivedMetrics, gotCfg.DerivedMetrics)
+				if runtime.GOOS != "windows" {
+					assert.Equal(t, testCase.want.SpillDirectory, gotCfg.SpillDirectory)
+				}
 				assert.NotNil(t, gotCfg.MiddlewareID)
 				assert.Equal(t, "agenthealth/metrics", gotCfg.MiddlewareID.String())
 				if testCase.wantWindows != nil && runtime.GOOS == "windows" {
@@ -177,6 +287,98 @@ func TestTranslator(t *testing.T) {
 	}
 }
 
+func TestPrometheusTranslator(t *testing.T) {
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.Role_arn = "global_arn"
+	agent.Global_Config.Credentials = nil
+	cwt := NewTranslatorWithName("prometheus")
+	require.EqualValues(t, "awscloudwatch/prometheus", cwt.ID().String())
+	testCases := map[string]struct {
+		input   map[string]interface{}
+		want    *cloudwatch.Config
+		wantErr error
+	}{
+		"WithMissingKey": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{}},
+			wantErr: &common.MissingKeyError{
+				ID:      cwt.ID(),
+				JsonKey: "logs::metrics_collected::prometheus",
+			},
+		},
+		"WithoutMetricDeclarations": {
+			input: map[string]interface{}{"logs": map[string]interface{}{
+				"metrics_collected": map[string]interface{}{
+					"prometheus": map[string]interface{}{},
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace: "CWAgent/Prometheus",
+				Region:    "us-east-1",
+				RoleARN:   "global_arn",
+				MetricDeclarations: []*cloudwatch.MetricDeclaration{
+					{MetricNameSelectors: []string{"$^"}},
+				},
+			},
+		},
+		"WithMetricDeclarations": {
+			input: map[string]interface{}{"logs": map[string]interface{}{
+				"credentials": map[string]interface{}{
+					"role_arn": "logs_arn",
+				},
+				"metrics_collected": map[string]interface{}{
+					"prometheus": map[string]interface{}{
+						"emf_processor": map[string]interface{}{
+							"metric_namespace": "Prometheus",
+							"metric_declaration": []interface{}{
+								map[string]interface{}{
+									"source_labels":    []interface{}{"job"},
+									"label_matcher":    "^jmx$",
+									"dimensions":       []interface{}{[]interface{}{"ClusterName", "job"}},
+									"metric_selectors": []interface{}{"^jvm_threads_current$"},
+								},
+								map[string]interface{}{
+									"dimensions":       []interface{}{[]interface{}{"ClusterName"}},
+									"metric_selectors": []interface{}{"^go_"},
+								},
+							},
+						},
+					},
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace: "Prometheus",
+				Region:    "us-east-1",
+				RoleARN:   "logs_arn",
+				MetricDeclarations: []*cloudwatch.MetricDeclaration{
+					{
+						Dimensions:          [][]string{{"ClusterName", "job"}},
+						MetricNameSelectors: []string{"^jvm_threads_current$"},
+						SourceLabels:        []string{"job"},
+						LabelMatcher:        "^jmx$",
+					},
+				},
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			conf := confmap.NewFromStringMap(testCase.input)
+			got, err := cwt.Translate(conf)
+			require.Equal(t, testCase.wantErr, err)
+			if testCase.want != nil {
+				require.NoError(t, err)
+				gotCfg, ok := got.(*cloudwatch.Config)
+				require.True(t, ok)
+				assert.Equal(t, testCase.want.Namespace, gotCfg.Namespace)
+				assert.Equal(t, testCase.want.Region, gotCfg.Region)
+				assert.Equal(t, testCase.want.RoleARN, gotCfg.RoleARN)
+				assert.Equal(t, testCase.want.MetricDeclarations, gotCfg.MetricDeclarations)
+				assert.Equal(t, "agenthealth/metrics", gotCfg.MiddlewareID.String())
+			}
+		})
+	}
+}
+
 func getJson(t *testing.T, path string) map[string]interface{} {
 	t.Helper()
 
@@ -186,3 +388,32 @@ func getJson(t *testing.T, path string) map[string]interface{} {
 	require.NoError(t, json.Unmarshal(content, &result))
 	return result
 }
+
+func TestTranslatorAssumeRoleOptions(t *testing.T) {
+	agent.Global_Config.External_id = "global_external_id"
+	agent.Global_Config.Session_tags = map[string]interface{}{"team": "platform"}
+	t.Cleanup(func() {
+		agent.Global_Config.External_id = ""
+		agent.Global_Config.Session_tags = nil
+	})
+	cwt := NewTranslator()
+
+	got, err := cwt.Translate(confmap.NewFromStringMap(map[string]interface{}{"metrics": map[string]interface{}{}}))
+	require.NoError(t, err)
+	gotCfg := got.(*cloudwatch.Config)
+	assert.Equal(t, "global_external_id", gotCfg.ExternalID)
+	assert.Equal(t, map[string]string{"team": "platform"}, gotCfg.SessionTags)
+
+	got, err = cwt.Translate(confmap.NewFromStringMap(map[string]interface{}{"metrics": map[string]interface{}{
+		"credentials": map[string]interface{}{
+			"role_arn":     "metrics_arn",
+			"external_id":  "metrics_external_id",
+			"session_tags": map[string]interface{}{"team": "payments", "env": "prod"},
+		},
+	}}))
+	require.NoError(t, err)
+	gotCfg = got.(*cloudwatch.Config)
+	assert.Equal(t, "metrics_arn", gotCfg.RoleARN)
+	assert.Equal(t, "metrics_external_id", gotCfg.ExternalID)
+	assert.Equal(t, map[string]string{"team": "payments", "env": "prod"}, gotCfg.SessionTags)
+}
@@ -52,23 +52,24 @@ func setPrometheusLogGroup(conf *confmap.Conf, cfg *awsemfexporter.Config) error
 	return nil
 }
 func setPrometheusNamespace(conf *confmap.Conf, cfg *awsemfexporter.Config) error {
+	cfg.Namespace = PrometheusNamespace(conf)
+	return nil
+}
+
+// PrometheusNamespace returns the configured namespace of the prometheus metrics, or the
+// default one for the environment.
+func PrometheusNamespace(conf *confmap.Conf) string {
 	if namespace, ok := common.GetString(conf, common.ConfigKey(emfProcessorBasePathKey, metricNamespace)); ok {
-		cfg.Namespace = namespace
-		return nil
+		return namespace
 	}
 
 	if context.CurrentContext().RunInContainer() {
 		if ecsutil.GetECSUtilSingleton().IsECS() {
-			cfg.Namespace = ecsDefaultCloudWatchNamespace
-		} else {
-			cfg.Namespace = k8sDefaultCloudWatchNamespace
+			return ecsDefaultCloudWatchNamespace
 		}
-	} else {
-		cfg.Namespace = ec2DefaultCloudWatchNamespace
+		return k8sDefaultCloudWatchNamespace
 	}
-
-	return nil
-
+	return ec2DefaultCloudWatchNamespace
 }
 
 func setPrometheusMetricDescriptors(conf *confmap.Conf, cfg *awsemfexporter.Config) error {
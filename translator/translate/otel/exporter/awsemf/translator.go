@@ -6,6 +6,7 @@ package awsemf
 import (
 	_ "embed"
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awsemfexporter"
@@ -21,6 +22,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/extension/agenthealth"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/processor/awsapplicationsignals"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/receiver/awscontainerinsight"
 	"github.com/aws/amazon-cloudwatch-agent/translator/util/ecsutil"
 )
@@ -114,6 +116,9 @@ func (t *translator) Translate(c *confmap.Conf) (component.Config, error) {
 	if credentialsFileKey, ok := agent.Global_Config.Credentials[agent.CredentialsFile_Key]; ok {
 		cfg.AWSSessionSettings.SharedCredentialsFile = []string{fmt.Sprintf("%v", credentialsFileKey)}
 	}
+	if unsupported := common.UnsupportedSessionCredentials(c, common.ConfigKey(common.LogsKey, common.CredentialsKey)); len(unsupported) > 0 {
+		log.Printf("W! %s ignores the unsupported credential options %v", t.ID(), unsupported)
+	}
 	if context.CurrentContext().Mode() == config.ModeOnPrem || context.CurrentContext().Mode() == config.ModeOnPremise {
 		cfg.AWSSessionSettings.LocalMode = true
 	}
@@ -179,7 +184,23 @@ func isPrometheus(conf *confmap.Conf) bool {
 	return conf.IsSet(prometheusBasePathKey)
 }
 
-func setAppSignalsFields(_ *confmap.Conf, _ *awsemfexporter.Config) error {
+// setAppSignalsFields adds an {Environment, Service, attribute} dimension set for every pod label,
+// pod annotation and namespace label promoted to attributes by the Kubernetes resolvers.
+func setAppSignalsFields(conf *confmap.Conf, cfg *awsemfexporter.Config) error {
+	promotedAttributes, err := awsapplicationsignals.PromotedAttributes(conf)
+	if err != nil {
+		return err
+	}
+	for _, name := range promotedAttributes.Names() {
+		cfg.MetricDeclarations = append(cfg.MetricDeclarations, &awsemfexporter.MetricDeclaration{
+			Dimensions: [][]string{{"Environment", "Service", name}},
+			LabelMatchers: []*awsemfexporter.LabelMatcher{{
+				LabelNames: []string{"Telemetry.Source"},
+				Regex:      "^(ServerSpan|LocalRootSpan)$",
+			}},
+			MetricNameSelectors: []string{"Latency", "Fault", "Error"},
+		})
+	}
 	return nil
 }
 
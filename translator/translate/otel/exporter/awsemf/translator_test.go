@@ -969,3 +969,28 @@ func TestTranslateAppSignals(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslateAppSignalsWithPromotedAttributes(t *testing.T) {
+	context.CurrentContext().SetKubernetesMode(config.ModeEKS)
+	context.CurrentContext().SetMode(config.ModeEC2)
+	tt := NewTranslatorWithName(common.AppSignals)
+	conf := confmap.NewFromStringMap(map[string]any{
+		"logs": map[string]any{
+			"metrics_collected": map[string]any{
+				"application_signals": map[string]any{},
+				"kubernetes": map[string]any{
+					"promoted_attributes": map[string]any{
+						"pod_labels":       []any{"team"},
+						"namespace_labels": []any{"team", "cost-center"},
+					},
+				},
+			},
+		}})
+	got, err := tt.Translate(conf)
+	require.NoError(t, err)
+	declarations := got.(*awsemfexporter.Config).MetricDeclarations
+	require.Equal(t, 4, len(declarations))
+	assert.Equal(t, [][]string{{"Environment", "Service", "team"}}, declarations[2].Dimensions)
+	assert.Equal(t, [][]string{{"Environment", "Service", "cost_center"}}, declarations[3].Dimensions)
+	assert.Equal(t, []string{"Latency", "Fault", "Error"}, declarations[3].MetricNameSelectors)
+}
@@ -6,6 +6,7 @@ package awsxray
 import (
 	_ "embed"
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awsxrayexporter"
@@ -107,6 +108,9 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	if credentialsFileKey, ok := agent.Global_Config.Credentials[agent.CredentialsFile_Key]; ok {
 		cfg.AWSSessionSettings.SharedCredentialsFile = []string{fmt.Sprintf("%v", credentialsFileKey)}
 	}
+	if unsupported := common.UnsupportedSessionCredentials(conf, common.ConfigKey(common.TracesKey, common.CredentialsKey)); len(unsupported) > 0 {
+		log.Printf("W! %s ignores the unsupported credential options %v", t.ID(), unsupported)
+	}
 	cfg.MiddlewareID = &agenthealth.TracesID
 	return cfg, nil
 }
@@ -6,6 +6,7 @@ package otel_aws_cloudwatch_logs
 import (
 	_ "embed"
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awscloudwatchlogsexporter"
@@ -94,6 +95,9 @@ func (t *translator) Translate(c *confmap.Conf) (component.Config, error) {
 	if credentialsFileKey, ok := agent.Global_Config.Credentials[agent.CredentialsFile_Key]; ok {
 		cfg.AWSSessionSettings.SharedCredentialsFile = []string{fmt.Sprintf("%v", credentialsFileKey)}
 	}
+	if unsupported := common.UnsupportedSessionCredentials(c, common.ConfigKey(common.LogsKey, common.CredentialsKey)); len(unsupported) > 0 {
+		log.Printf("W! %s ignores the unsupported credential options %v", t.ID(), unsupported)
+	}
 	if context.CurrentContext().Mode() == config.ModeOnPrem || context.CurrentContext().Mode() == config.ModeOnPremise {
 		cfg.AWSSessionSettings.LocalMode = true
 	}
@@ -5,6 +5,7 @@ package awsproxy
 
 import (
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/awsproxy"
@@ -68,6 +69,9 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	if credentialsFileKey, ok := agent.Global_Config.Credentials[agent.CredentialsFile_Key]; ok {
 		cfg.ProxyConfig.SharedCredentialsFile = []string{fmt.Sprintf("%v", credentialsFileKey)}
 	}
+	if unsupported := common.UnsupportedSessionCredentials(conf, common.ConfigKey(common.TracesKey, common.CredentialsKey)); len(unsupported) > 0 {
+		log.Printf("W! %s ignores the unsupported credential options %v", t.ID(), unsupported)
+	}
 	return cfg, nil
 }
 
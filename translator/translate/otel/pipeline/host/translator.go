@@ -15,6 +15,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/processor/cumulativetodeltaprocessor"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/processor/ec2taggerprocessor"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/processor/metricsdecorator"
+	adaptertranslator "github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/receiver/adapter"
 	otlpReceiver "github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/receiver/otlp"
 )
 
@@ -39,9 +40,10 @@ func (t translator) ID() component.ID {
 	return component.NewIDWithName(component.DataTypeMetrics, t.name)
 }
 
-// Translate creates a pipeline if metrics section exists.
+// Translate creates a pipeline if metrics section exists, or if the log files
+// have metric filters.
 func (t translator) Translate(conf *confmap.Conf) (*common.ComponentTranslators, error) {
-	if conf == nil || !conf.IsSet(common.MetricsKey) {
+	if conf == nil || (!conf.IsSet(common.MetricsKey) && !adaptertranslator.HasLogMetricFilters(conf)) {
 		return nil, &common.MissingKeyError{ID: t.ID(), JsonKey: common.MetricsKey}
 	}
 
@@ -64,6 +64,32 @@ func TestTranslator(t *testing.T) {
 				extensions: []string{"agenthealth/metrics"},
 			},
 		},
+		"WithLogMetricFiltersWithoutMetricsKey": {
+			input: map[string]interface{}{
+				"logs": map[string]interface{}{
+					"logs_collected": map[string]interface{}{
+						"files": map[string]interface{}{
+							"collect_list": []interface{}{
+								map[string]interface{}{
+									"file_path": "/var/log/app.log",
+									"metric_filters": []interface{}{
+										map[string]interface{}{"metric_name": "Errors", "pattern": "ERROR"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			pipelineName: common.PipelineNameHost,
+			want: &want{
+				pipelineID: "metrics/host",
+				receivers:  []string{"nop", "other"},
+				processors: []string{},
+				exporters:  []string{"awscloudwatch"},
+				extensions: []string{"agenthealth/metrics"},
+			},
+		},
 		"WithMetricsKeyNet": {
 			input: map[string]interface{}{
 				"metrics": map[string]interface{}{
@@ -11,6 +11,7 @@ import (
 
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/metrics_collected/prometheus"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/exporter/awscloudwatch"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/exporter/awsemf"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/extension/agenthealth"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/processor/batchprocessor"
@@ -41,12 +42,18 @@ func (t *translator) Translate(conf *confmap.Conf) (*common.ComponentTranslators
 	if conf == nil || !conf.IsSet(key) {
 		return nil, &common.MissingKeyError{ID: t.ID(), JsonKey: key}
 	}
-	return &common.ComponentTranslators{
+	translators := &common.ComponentTranslators{
 		Receivers: common.NewTranslatorMap(adapter.NewTranslator(prometheus.SectionKey, key, time.Minute)),
 		Processors: common.NewTranslatorMap(
 			batchprocessor.NewTranslatorWithNameAndSection(pipelineName, common.LogsKey), // prometheus sits under metrics_collected in "logs"
 		),
 		Exporters:  common.NewTranslatorMap(awsemf.NewTranslatorWithName(pipelineName)),
 		Extensions: common.NewTranslatorMap(agenthealth.NewTranslator(component.DataTypeLogs, []string{agenthealth.OperationPutLogEvents})),
-	}, nil
+	}
+	// The metrics can be published with PutMetricData instead of EMF logs.
+	if destination, _ := common.GetString(conf, common.ConfigKey(key, common.MetricsDestinationKey)); destination == common.MetricsDestinationCloudWatch {
+		translators.Exporters = common.NewTranslatorMap(awscloudwatch.NewTranslatorWithName(pipelineName))
+		translators.Extensions = common.NewTranslatorMap(agenthealth.NewTranslator(component.DataTypeMetrics, []string{agenthealth.OperationPutMetricData}))
+	}
+	return translators, nil
 }
@@ -48,6 +48,23 @@ func TestTranslator(t *testing.T) {
 				extensions: []string{"agenthealth/logs"},
 			},
 		},
+		"WithCloudWatchDestination": {
+			input: map[string]interface{}{
+				"logs": map[string]interface{}{
+					"metrics_collected": map[string]interface{}{
+						"prometheus": map[string]interface{}{
+							"metrics_destination": "cloudwatch",
+						},
+					},
+				},
+			},
+			want: &want{
+				receivers:  []string{"telegraf_prometheus"},
+				processors: []string{"batch/prometheus"},
+				exporters:  []string{"awscloudwatch/prometheus"},
+				extensions: []string{"agenthealth/metrics"},
+			},
+		},
 	}
 	for name, testCase := range testCases {
 		t.Run(name, func(t *testing.T) {
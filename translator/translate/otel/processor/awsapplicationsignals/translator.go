@@ -6,12 +6,14 @@ package awsapplicationsignals
 import (
 	_ "embed"
 	"errors"
+	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/processor"
 
+	"github.com/aws/amazon-cloudwatch-agent/internal/k8sCommon/k8sattributes"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals"
 	appsignalsconfig "github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals/config"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsapplicationsignals/rules"
@@ -108,12 +110,44 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 		}
 	}
 
+	promotedAttributes, err := PromotedAttributes(conf)
+	if err != nil {
+		return nil, err
+	}
+	for i, resolver := range cfg.Resolvers {
+		if resolver.Platform == appsignalsconfig.PlatformEKS || resolver.Platform == appsignalsconfig.PlatformK8s {
+			cfg.Resolvers[i].PromotedAttributes = promotedAttributes
+		}
+	}
+
 	limiterConfig, _ := t.translateMetricLimiterConfig(conf, configKey)
 	cfg.Limiter = limiterConfig
 
 	return t.translateCustomRules(conf, configKey, cfg)
 }
 
+// PromotedAttributes returns the pod labels, pod annotations and namespace labels promoted to
+// dimensions in the kubernetes section, nil if none is configured. They are only promoted to
+// the dimensions of the Application Signals metrics.
+func PromotedAttributes(conf *confmap.Conf) (*k8sattributes.Config, error) {
+	key := common.ConfigKey(common.LogsKey, common.MetricsCollectedKey, common.KubernetesKey, common.PromotedAttributesKey)
+	if !conf.IsSet(key) {
+		return nil, nil
+	}
+	sub, err := conf.Sub(key)
+	if err != nil {
+		return nil, err
+	}
+	promotedAttributes := &k8sattributes.Config{}
+	if err = sub.Unmarshal(promotedAttributes); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal %s: %w", key, err)
+	}
+	if promotedAttributes.IsEmpty() {
+		return nil, nil
+	}
+	return promotedAttributes, nil
+}
+
 func (t *translator) translateMetricLimiterConfig(conf *confmap.Conf, configKey []string) (*appsignalsconfig.LimiterConfig, error) {
 	limiterConfigKey := common.ConfigKey(configKey[0], "limiter")
 	if !conf.IsSet(limiterConfigKey) {
@@ -26,6 +26,8 @@ var (
 	validAppSignalsYamlEKS string
 	//go:embed testdata/config_k8s.yaml
 	validAppSignalsYamlK8s string
+	//go:embed testdata/config_k8s_promoted_attributes.yaml
+	validAppSignalsYamlK8sPromotedAttributes string
 	//go:embed testdata/config_ec2.yaml
 	validAppSignalsYamlEC2 string
 	//go:embed testdata/config_generic.yaml
@@ -91,6 +93,26 @@ func TestTranslate(t *testing.T) {
 			kubernetesMode: translatorConfig.ModeK8sEC2,
 			mode:           translatorConfig.ModeEC2,
 		},
+		"WithAppSignalsEnabledK8SAndPromotedAttributes": {
+			input: map[string]interface{}{
+				"logs": map[string]interface{}{
+					"metrics_collected": map[string]interface{}{
+						"application_signals": map[string]interface{}{
+							"hosted_in": "test",
+						},
+						"kubernetes": map[string]interface{}{
+							"promoted_attributes": map[string]interface{}{
+								"pod_labels":       []interface{}{"team", "app.kubernetes.io/version"},
+								"namespace_labels": []interface{}{"team"},
+							},
+						},
+					},
+				}},
+			want:           validAppSignalsYamlK8sPromotedAttributes,
+			isKubernetes:   true,
+			kubernetesMode: translatorConfig.ModeK8sEC2,
+			mode:           translatorConfig.ModeEC2,
+		},
 		"WithAppSignalsEnabledGeneric": {
 			input: map[string]interface{}{
 				"logs": map[string]interface{}{
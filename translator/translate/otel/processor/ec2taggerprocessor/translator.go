@@ -4,6 +4,7 @@
 package ec2taggerprocessor
 
 import (
+	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -47,6 +48,7 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	cfg := t.factory.CreateDefaultConfig().(*ec2tagger.Config)
 	credentials := confmap.NewFromStringMap(agent.Global_Config.Credentials)
 	_ = credentials.Unmarshal(cfg)
+	setAssumeRoleCredentials(cfg, conf)
 	for k, v := range ec2tagger.SupportedAppendDimensions {
 		value, ok := common.GetString(conf, common.ConfigKey(Ec2taggerKey, k))
 		if ok && v == value {
@@ -68,3 +70,28 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 
 	return cfg, nil
 }
+
+// setAssumeRoleCredentials sets the role to assume with its external ID and session tags from the
+// credentials of the metrics, or from the agent credentials by default.
+func setAssumeRoleCredentials(cfg *ec2tagger.Config, conf *confmap.Conf) {
+	credentialsKey := common.ConfigKey(common.MetricsKey, common.CredentialsKey)
+	cfg.RoleARN = agent.Global_Config.Role_arn
+	if roleARN, ok := common.GetString(conf, common.ConfigKey(credentialsKey, common.RoleARNKey)); ok {
+		cfg.RoleARN = roleARN
+	}
+	cfg.ExternalID = agent.Global_Config.External_id
+	if externalID, ok := common.GetString(conf, common.ConfigKey(credentialsKey, common.ExternalIDKey)); ok {
+		cfg.ExternalID = externalID
+	}
+	sessionTags := agent.Global_Config.Session_tags
+	if tags, ok := conf.Get(common.ConfigKey(credentialsKey, common.SessionTagsKey)).(map[string]any); ok {
+		sessionTags = tags
+	}
+	if len(sessionTags) == 0 {
+		return
+	}
+	cfg.SessionTags = make(map[string]string, len(sessionTags))
+	for k, v := range sessionTags {
+		cfg.SessionTags[k] = fmt.Sprint(v)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 
 	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/ec2tagger"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
 )
 
@@ -92,3 +93,41 @@ func TestTranslator(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslatorCredentials(t *testing.T) {
+	agent.Global_Config.Role_arn = "global_arn"
+	agent.Global_Config.External_id = "global_external_id"
+	agent.Global_Config.Session_tags = map[string]interface{}{"team": "platform"}
+	t.Cleanup(func() {
+		agent.Global_Config.Role_arn = ""
+		agent.Global_Config.External_id = ""
+		agent.Global_Config.Session_tags = nil
+	})
+	etpTranslator := NewTranslator()
+	appendDimensions := map[string]interface{}{"InstanceId": "${aws:InstanceId}"}
+
+	got, err := etpTranslator.Translate(confmap.NewFromStringMap(map[string]interface{}{
+		"metrics": map[string]interface{}{"append_dimensions": appendDimensions},
+	}))
+	require.NoError(t, err)
+	gotCfg := got.(*ec2tagger.Config)
+	require.Equal(t, "global_arn", gotCfg.RoleARN)
+	require.Equal(t, "global_external_id", gotCfg.ExternalID)
+	require.Equal(t, map[string]string{"team": "platform"}, gotCfg.SessionTags)
+
+	got, err = etpTranslator.Translate(confmap.NewFromStringMap(map[string]interface{}{
+		"metrics": map[string]interface{}{
+			"append_dimensions": appendDimensions,
+			"credentials": map[string]interface{}{
+				"role_arn":     "metrics_arn",
+				"external_id":  "metrics_external_id",
+				"session_tags": map[string]interface{}{"team": "payments"},
+			},
+		},
+	}))
+	require.NoError(t, err)
+	gotCfg = got.(*ec2tagger.Config)
+	require.Equal(t, "metrics_arn", gotCfg.RoleARN)
+	require.Equal(t, "metrics_external_id", gotCfg.ExternalID)
+	require.Equal(t, map[string]string{"team": "payments"}, gotCfg.SessionTags)
+}
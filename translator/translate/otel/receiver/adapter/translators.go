@@ -14,6 +14,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/internal/util/collections"
 	translatorconfig "github.com/aws/amazon-cloudwatch-agent/translator/config"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/logs_collected/files"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/logs_collected/files/collect_list"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/logs_collected/windows_events"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/metrics_collect"
 	collectd "github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/metrics_collect/collectd"
@@ -154,7 +155,7 @@ func fromInputs(conf *confmap.Conf, validInputs map[string]bool, baseKey string)
 	translators := common.NewTranslatorMap[component.Config]()
 	if inputs, ok := conf.Get(baseKey).(map[string]interface{}); ok {
 		for inputName := range inputs {
-			if skipInputSet.Contains(inputName) {
+			if skipInputSet.Contains(inputName) && !hasMetricFilters(conf, baseKey, inputName) {
 				// logs agent is separate from otel agent
 				continue
 			}
@@ -182,6 +183,21 @@ func fromInputs(conf *confmap.Conf, validInputs map[string]bool, baseKey string)
 	return translators
 }
 
+// hasMetricFilters checks whether the log files have metric filters, which the
+// logs agent input emits as metrics in the otel agent as well.
+func hasMetricFilters(conf *confmap.Conf, baseKey, inputName string) bool {
+	if inputName != files.SectionKey {
+		return false
+	}
+	return collect_list.HasMetricFilters(conf.Get(common.ConfigKey(baseKey, inputName, collect_list.SectionKey)))
+}
+
+// HasLogMetricFilters checks whether the log files have metric filters, whose metrics
+// are published by the host pipeline even without a metrics section.
+func HasLogMetricFilters(conf *confmap.Conf) bool {
+	return hasMetricFilters(conf, logKey, files.SectionKey)
+}
+
 // fromMultipleInput generates multiple receivers with unique ID depends on the number of inputs.
 // Since there plugins from Telegraf that allows multiple inputs such as procstat, window_perf_counter;
 // therefore, generate a hash of the monitored process (e.g exe: hash(amazon-cloudwatch-agent))
@@ -26,6 +26,7 @@ func TestFindReceiversInConfig(t *testing.T) {
 	telegrafStatsdType, _ := component.NewType("telegraf_statsd")
 	telegrafProcstatType, _ := component.NewType("telegraf_procstat")
 	telegrafWinPerfCountersType, _ := component.NewType("telegraf_win_perf_counters")
+	telegrafLogfileType, _ := component.NewType("telegraf_logfile")
 	type wantResult struct {
 		cfgKey   string
 		interval time.Duration
@@ -124,6 +125,29 @@ func TestFindReceiversInConfig(t *testing.T) {
 			os:   translatorconfig.OS_TYPE_WINDOWS,
 			want: map[component.ID]wantResult{},
 		},
+		"WithLogMetricFilters": {
+			input: map[string]interface{}{
+				"logs": map[string]interface{}{
+					"logs_collected": map[string]interface{}{
+						"files": map[string]interface{}{
+							"collect_list": []interface{}{
+								map[string]interface{}{"file_path": "/tmp/a.log"},
+								map[string]interface{}{
+									"file_path": "/tmp/b.log",
+									"metric_filters": []interface{}{
+										map[string]interface{}{"metric_name": "Errors", "pattern": "ERROR"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			os: translatorconfig.OS_TYPE_LINUX,
+			want: map[component.ID]wantResult{
+				component.NewID(telegrafLogfileType): {"logs::logs_collected::files", time.Minute},
+			},
+		},
 		"WithNoSocketListener": {
 			input: map[string]interface{}{
 				"logs": map[string]interface{}{
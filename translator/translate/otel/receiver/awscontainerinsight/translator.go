@@ -6,6 +6,7 @@ package awscontainerinsight
 import (
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -89,6 +90,9 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	if credentialsFileKey, ok := agent.Global_Config.Credentials[agent.CredentialsFile_Key]; ok {
 		cfg.AWSSessionSettings.SharedCredentialsFile = []string{fmt.Sprintf("%v", credentialsFileKey)}
 	}
+	if unsupported := common.UnsupportedSessionCredentials(conf, common.ConfigKey(common.LogsKey, common.CredentialsKey)); len(unsupported) > 0 {
+		log.Printf("W! %s ignores the unsupported credential options %v", t.ID(), unsupported)
+	}
 	cfg.AWSSessionSettings.IMDSRetries = retryer.GetDefaultRetryNumber()
 
 	if configuredService.Value == eks {
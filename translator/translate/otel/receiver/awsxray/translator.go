@@ -5,6 +5,7 @@ package awsxray
 
 import (
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awsxrayreceiver"
@@ -86,6 +87,9 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	if credentialsFileKey, ok := agent.Global_Config.Credentials[agent.CredentialsFile_Key]; ok {
 		cfg.ProxyServer.SharedCredentialsFile = []string{fmt.Sprintf("%v", credentialsFileKey)}
 	}
+	if unsupported := common.UnsupportedSessionCredentials(conf, common.ConfigKey(common.TracesKey, common.CredentialsKey)); len(unsupported) > 0 {
+		log.Printf("W! %s ignores the unsupported credential options %v", t.ID(), unsupported)
+	}
 	cfg.ProxyServer.CertificateFilePath = os.Getenv(envconfig.AWS_CA_BUNDLE)
 	cfg.ProxyServer.IMDSRetries = retryer.GetDefaultRetryNumber()
 	return cfg, nil
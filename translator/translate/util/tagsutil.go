@@ -11,9 +11,10 @@ import (
 const (
 	High_Resolution_Tag_Key      = "aws:StorageResolution"
 	Aggregation_Interval_Tag_Key = "aws:AggregationInterval"
+	Unit_Tag_Key                 = "aws:Unit"
 )
 
-var ReservedTagKeySet = collections.NewSet[string](High_Resolution_Tag_Key, Aggregation_Interval_Tag_Key, ec2tagger.AttributeVolumeId)
+var ReservedTagKeySet = collections.NewSet[string](High_Resolution_Tag_Key, Aggregation_Interval_Tag_Key, Unit_Tag_Key, ec2tagger.AttributeVolumeId)
 
 func AddHighResolutionTag(tags interface{}) {
 	tagMap := tags.(map[string]interface{})